@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/logger"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/middleware"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/tax"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/client"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/handler"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/repository"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/service"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/session"
 )
 
 const serviceName = "cart-service"
@@ -23,9 +28,38 @@ func main() {
 
 	// Load configuration
 	httpPort := getEnv("HTTP_PORT", "8085")
+	// Anyone who knows the identity secret can act as any user, so there is no default
+	identitySecret := os.Getenv("IDENTITY_SECRET")
+	if identitySecret == "" {
+		log.Fatal().Msg("IDENTITY_SECRET is required")
+	}
 	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
 	redisPassword := getEnv("REDIS_PASSWORD", "")
 	productServiceAddr := getEnv("PRODUCT_SERVICE_ADDR", "localhost:9092")
+	orderServiceAddr := getEnv("ORDER_SERVICE_ADDR", "localhost:9093")
+	authServiceAddr := getEnv("AUTH_SERVICE_ADDR", "localhost:9091")
+	notificationServiceURL := getEnv("NOTIFICATION_SERVICE_URL", "http://localhost:8086")
+	// Abandoned cart reminders: how often to scan, after how long idle each reminder goes out, and the link they carry
+	reminderInterval, err := time.ParseDuration(getEnv("CART_REMINDER_INTERVAL", "15m"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid CART_REMINDER_INTERVAL")
+	}
+	reminderStages, err := loadReminderStages(getEnv("CART_REMINDER_STAGES", "1h,24h,72h"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid CART_REMINDER_STAGES")
+	}
+	cartURL := getEnv("CART_URL", "http://localhost:3000/cart")
+	// Signs the cookie identifying guest carts; a known secret would let anyone forge another visitor's cookie
+	cartSessionSecret := os.Getenv("CART_SESSION_SECRET")
+	if cartSessionSecret == "" {
+		log.Fatal().Msg("CART_SESSION_SECRET is required")
+	}
+	cartCookieSecure := getEnv("CART_COOKIE_SECURE", "false") == "true"
+	taxRatesFile := getEnv("TAX_RATES_FILE", "config/tax-rates.json")
+	taxRates, err := tax.LoadRateTable(taxRatesFile)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", taxRatesFile).Msg("Failed to load tax rates")
+	}
 
 	// Initialize Redis client
 	redisClient := redis.NewClient(&redis.Options{
@@ -50,10 +84,50 @@ func main() {
 	defer productClient.Close()
 	log.Info().Str("addr", productServiceAddr).Msg("Connected to Product Service gRPC")
 
+	// Initialize Order Service gRPC client, used to price coupons
+	orderClient, err := client.NewOrderClient(orderServiceAddr)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", orderServiceAddr).Msg("Failed to connect to Order Service")
+	}
+	defer orderClient.Close()
+	log.Info().Str("addr", orderServiceAddr).Msg("Connected to Order Service gRPC")
+
+	// Initialize Auth Service gRPC client, used to address reminders
+	authClient, err := client.NewAuthClient(authServiceAddr)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", authServiceAddr).Msg("Failed to connect to Auth Service")
+	}
+	defer authClient.Close()
+	log.Info().Str("addr", authServiceAddr).Msg("Connected to Auth Service gRPC")
+
+	identitySigner := auth.NewSigner(identitySecret)
+
 	// Initialize layers (Dependency Injection)
 	cartRepo := repository.NewRedisCartRepository(redisClient)
-	cartService := service.NewCartService(cartRepo, productClient)
-	cartHandler := handler.NewCartHandler(cartService)
+	cartService := service.NewCartService(cartRepo, productClient, orderClient, taxRates)
+	cartHandler := handler.NewCartHandler(cartService, session.NewGuestSessions(cartSessionSecret), cartCookieSecure)
+	listRepo := repository.NewRedisListRepository(redisClient)
+	listService := service.NewListService(listRepo, cartRepo, cartService, productClient)
+	listHandler := handler.NewListHandler(listService)
+	reminderService := service.NewReminderService(repository.NewRedisReminderRepository(redisClient), cartRepo, orderClient,
+		authClient, client.NewNotificationClient(notificationServiceURL, identitySigner), reminderStages, cartURL)
+	reminderHandler := handler.NewReminderHandler(reminderService)
+
+	// Remind customers of abandoned carts in the background. Replicas may scan at the same time:
+	// each reminder is claimed in Redis before it is sent, so it goes out once.
+	go func() {
+		ticker := time.NewTicker(reminderInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			sent, err := reminderService.SendReminders(context.Background(), now)
+			if sent > 0 {
+				log.Info().Int("count", sent).Msg("Sent abandoned cart reminders")
+			}
+			if err != nil {
+				log.Error().Err(err).Msg("Abandoned cart reminders failed")
+			}
+		}
+	}()
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
@@ -66,6 +140,7 @@ func main() {
 	router.Use(middleware.CORS())
 	router.Use(middleware.SecureHeaders())
 	router.Use(middleware.RateLimiter(100, 10))
+	router.Use(auth.Middleware(identitySigner)) // Trust only gateway-signed identity
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -97,6 +172,8 @@ func main() {
 	// Register API routes
 	api := router.Group("/api/v1")
 	cartHandler.RegisterRoutes(api)
+	listHandler.RegisterRoutes(api)
+	reminderHandler.RegisterRoutes(api)
 
 	// Start HTTP server
 	log.Info().Str("port", httpPort).Msg("Cart Service HTTP starting")
@@ -105,6 +182,26 @@ func main() {
 	}
 }
 
+// loadReminderStages parses a comma-separated, increasing list of idle durations, e.g. "1h,24h,72h".
+// The nth reminder is rendered from the abandoned_cart_<n> template.
+func loadReminderStages(raw string) ([]service.ReminderStage, error) {
+	var stages []service.ReminderStage
+	for i, part := range strings.Split(raw, ",") {
+		after, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if after <= 0 || (i > 0 && after <= stages[i-1].After) {
+			return nil, fmt.Errorf("stage %d: durations must be positive and increasing", i+1)
+		}
+		stages = append(stages, service.ReminderStage{
+			After:      after,
+			TemplateID: fmt.Sprintf("abandoned_cart_%d", i+1),
+		})
+	}
+	return stages, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
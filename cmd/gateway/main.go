@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/logger"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/middleware"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/gateway/client"
@@ -27,6 +29,11 @@ func main() {
 	paymentServiceURL := getEnv("PAYMENT_SERVICE_URL", "http://localhost:8084")
 	cartServiceURL := getEnv("CART_SERVICE_URL", "http://localhost:8085")
 	notificationServiceURL := getEnv("NOTIFICATION_SERVICE_URL", "http://localhost:8086")
+	// Anyone who knows the identity secret can act as any user, so there is no default
+	identitySecret := os.Getenv("IDENTITY_SECRET")
+	if identitySecret == "" {
+		log.Fatal().Msg("IDENTITY_SECRET is required")
+	}
 
 	// Initialize gRPC clients
 	authClient, err := client.NewAuthClient(authServiceAddr)
@@ -73,11 +80,15 @@ func main() {
 			BaseURL: notificationServiceURL,
 		},
 	}
-	proxyHandler := handler.NewProxyHandler(services)
+	proxyHandler := handler.NewProxyHandler(services, auth.NewSigner(identitySecret))
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	// Only proxies in TRUSTED_PROXIES may report the client address; by default it is the connection's
+	if err := router.SetTrustedProxies(trustedProxies(getEnv("TRUSTED_PROXIES", ""))); err != nil {
+		log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
 
 	// Apply global middleware
 	router.Use(middleware.Recovery())
@@ -130,6 +141,21 @@ func main() {
 			categories.GET("", proxyHandler.Proxy("product"))
 		}
 
+		// Cart routes (optional auth): anonymous visitors get a guest cart, merged into theirs when they sign in
+		cart := api.Group("/cart")
+		cart.Use(handler.OptionalAuthMiddleware(authClient))
+		{
+			cart.GET("", proxyHandler.Proxy("cart"))
+			cart.POST("/validate", proxyHandler.Proxy("cart"))
+			cart.POST("/items", proxyHandler.Proxy("cart"))
+			cart.PUT("/items/:product_id", proxyHandler.Proxy("cart"))
+			cart.DELETE("/items/:product_id", proxyHandler.Proxy("cart"))
+			cart.DELETE("", proxyHandler.Proxy("cart"))
+			cart.POST("/coupon", proxyHandler.Proxy("cart"))
+			cart.DELETE("/coupon", proxyHandler.Proxy("cart"))
+			cart.PUT("/reminders", proxyHandler.Proxy("cart"))
+		}
+
 		// ==================== PROTECTED ROUTES ====================
 		protected := api.Group("")
 		protected.Use(handler.AuthMiddleware(authClient))
@@ -148,12 +174,70 @@ func main() {
 			// Category management
 			protected.POST("/categories", proxyHandler.Proxy("product"))
 
+			// Wishlists and save-for-later, kept by the cart service
+			protected.GET("/lists", proxyHandler.Proxy("cart"))
+			protected.POST("/lists", proxyHandler.Proxy("cart"))
+			protected.GET("/lists/:list_id", proxyHandler.Proxy("cart"))
+			protected.PUT("/lists/:list_id", proxyHandler.Proxy("cart"))
+			protected.DELETE("/lists/:list_id", proxyHandler.Proxy("cart"))
+			protected.POST("/lists/:list_id/items", proxyHandler.Proxy("cart"))
+			protected.DELETE("/lists/:list_id/items/:product_id", proxyHandler.Proxy("cart"))
+			protected.POST("/lists/:list_id/items/:product_id/move-to-cart", proxyHandler.Proxy("cart"))
+			protected.POST("/lists/:list_id/move-from-cart", proxyHandler.Proxy("cart"))
+
 			// Order routes
 			protected.POST("/orders", proxyHandler.Proxy("order"))
 			protected.GET("/orders", proxyHandler.Proxy("order"))
 			protected.GET("/orders/:id", proxyHandler.Proxy("order"))
 			protected.PUT("/orders/:id/status", proxyHandler.Proxy("order"))
 			protected.POST("/orders/:id/cancel", proxyHandler.Proxy("order"))
+			protected.POST("/orders/:id/ship", proxyHandler.Proxy("order"))
+			protected.POST("/orders/:id/deliver", proxyHandler.Proxy("order"))
+			protected.GET("/orders/:id/invoice", proxyHandler.Proxy("order"))
+			protected.POST("/shipping/quote", proxyHandler.Proxy("order"))
+
+			// Return (RMA) routes
+			protected.POST("/orders/:id/returns", proxyHandler.Proxy("order"))
+			protected.GET("/returns", proxyHandler.Proxy("order"))
+			protected.GET("/returns/:id", proxyHandler.Proxy("order"))
+			protected.POST("/returns/:id/cancel", proxyHandler.Proxy("order"))
+			protected.POST("/returns/:id/approve", proxyHandler.Proxy("order"))
+			protected.POST("/returns/:id/reject", proxyHandler.Proxy("order"))
+			protected.POST("/returns/:id/receive", proxyHandler.Proxy("order"))
+			protected.POST("/returns/:id/refund", proxyHandler.Proxy("order"))
+
+			// Admin order management
+			protected.GET("/admin/orders", proxyHandler.Proxy("order"))
+			protected.GET("/admin/orders/export", proxyHandler.Proxy("order"))
+			protected.POST("/admin/orders/bulk-status", proxyHandler.Proxy("order"))
+			protected.POST("/admin/promotions", proxyHandler.Proxy("order"))
+			protected.GET("/admin/promotions", proxyHandler.Proxy("order"))
+			protected.GET("/admin/promotions/:id", proxyHandler.Proxy("order"))
+			protected.PUT("/admin/promotions/:id", proxyHandler.Proxy("order"))
+			protected.GET("/admin/payments/reconciliations", proxyHandler.Proxy("payment"))
+			protected.GET("/admin/payments/reconciliations/:id", proxyHandler.Proxy("payment"))
+			protected.GET("/admin/payments/reviews", proxyHandler.Proxy("payment"))
+			protected.GET("/admin/payments/reviews/:id", proxyHandler.Proxy("payment"))
+			protected.POST("/admin/payments/reviews/:id/approve", proxyHandler.Proxy("payment"))
+			protected.POST("/admin/payments/reviews/:id/reject", proxyHandler.Proxy("payment"))
+			protected.GET("/admin/notifications/dead-letters", proxyHandler.Proxy("notification"))
+			protected.POST("/admin/notifications/dead-letters/retry", proxyHandler.Proxy("notification"))
+			protected.GET("/admin/notifications/templates", proxyHandler.Proxy("notification"))
+			protected.POST("/admin/notifications/templates", proxyHandler.Proxy("notification"))
+			protected.GET("/admin/notifications/templates/:id", proxyHandler.Proxy("notification"))
+			protected.PUT("/admin/notifications/templates/:id", proxyHandler.Proxy("notification"))
+			protected.DELETE("/admin/notifications/templates/:id", proxyHandler.Proxy("notification"))
+			protected.POST("/admin/notifications/templates/:id/preview", proxyHandler.Proxy("notification"))
+			protected.PUT("/admin/notifications/templates/:id/locales/:locale", proxyHandler.Proxy("notification"))
+			protected.GET("/admin/notifications/templates/:id/locales/:locale/versions", proxyHandler.Proxy("notification"))
+			protected.POST("/admin/notifications/templates/:id/locales/:locale/versions/:version/restore", proxyHandler.Proxy("notification"))
+
+			// Address book routes
+			protected.GET("/addresses", proxyHandler.Proxy("order"))
+			protected.POST("/addresses", proxyHandler.Proxy("order"))
+			protected.PUT("/addresses/:id", proxyHandler.Proxy("order"))
+			protected.DELETE("/addresses/:id", proxyHandler.Proxy("order"))
+			protected.POST("/addresses/:id/default", proxyHandler.Proxy("order"))
 
 			// Payment routes
 			protected.POST("/payments", proxyHandler.Proxy("payment"))
@@ -162,23 +246,22 @@ func main() {
 			protected.GET("/payments/order/:order_id", proxyHandler.Proxy("payment"))
 			protected.POST("/payments/:id/cancel", proxyHandler.Proxy("payment"))
 			protected.POST("/payments/:id/refund", proxyHandler.Proxy("payment"))
-
-			// Cart routes
-			protected.GET("/cart", proxyHandler.Proxy("cart"))
-			protected.POST("/cart/items", proxyHandler.Proxy("cart"))
-			protected.PUT("/cart/items/:product_id", proxyHandler.Proxy("cart"))
-			protected.DELETE("/cart/items/:product_id", proxyHandler.Proxy("cart"))
-			protected.DELETE("/cart", proxyHandler.Proxy("cart"))
+			protected.GET("/payments/:id/refunds", proxyHandler.Proxy("payment"))
+			protected.GET("/payments/methods", proxyHandler.Proxy("payment"))
+			protected.POST("/payments/methods", proxyHandler.Proxy("payment"))
+			protected.PUT("/payments/methods/:id/default", proxyHandler.Proxy("payment"))
+			protected.DELETE("/payments/methods/:id", proxyHandler.Proxy("payment"))
 
 			// Notification routes
 			protected.GET("/notifications", proxyHandler.Proxy("notification"))
 			protected.POST("/notifications/email", proxyHandler.Proxy("notification"))
 			protected.POST("/notifications/sms", proxyHandler.Proxy("notification"))
 			protected.POST("/notifications/push", proxyHandler.Proxy("notification"))
+			protected.POST("/notifications/:id/delivered", proxyHandler.Proxy("notification"))
 		}
 
 		// Payment webhook (public - called by payment provider)
-		api.POST("/payments/webhook", proxyHandler.Proxy("payment"))
+		api.POST("/payments/webhooks/:provider", proxyHandler.Proxy("payment"))
 	}
 
 	// Start HTTP server
@@ -190,6 +273,17 @@ func main() {
 	}
 }
 
+// trustedProxies splits a comma-separated list of proxy addresses or CIDR ranges
+func trustedProxies(value string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/database"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/logger"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/middleware"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/handler"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/sender"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
 )
 
@@ -20,6 +27,38 @@ func main() {
 
 	// Load configuration
 	httpPort := getEnv("HTTP_PORT", "8086")
+	// Anyone who knows the identity secret can act as any user, so there is no default
+	identitySecret := os.Getenv("IDENTITY_SECRET")
+	if identitySecret == "" {
+		log.Fatal().Msg("IDENTITY_SECRET is required")
+	}
+	// Delivery queue: how often workers look for due notifications, how many send at once, and retries
+	pollInterval, err := time.ParseDuration(getEnv("NOTIFICATION_POLL_INTERVAL", "2s"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid NOTIFICATION_POLL_INTERVAL")
+	}
+	workers, err := strconv.Atoi(getEnv("NOTIFICATION_WORKERS", "4"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid NOTIFICATION_WORKERS")
+	}
+	retryPolicy, err := loadRetryPolicy()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid notification retry configuration")
+	}
+	emailSender, smsSender, pushSender, err := loadSenders()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid notification sender configuration")
+	}
+	// Notifications without a sender fail and end up as dead letters
+	if emailSender == nil {
+		log.Warn().Msg("SMTP_HOST not set, emails will not be sent")
+	}
+	if smsSender == nil {
+		log.Warn().Msg("SMS_API_URL not set, SMS will not be sent")
+	}
+	if pushSender == nil {
+		log.Warn().Msg("PUSH_API_URL not set, push notifications will not be sent")
+	}
 
 	dbConfig := database.Config{
 		Host:     getEnv("DB_HOST", "localhost"),
@@ -37,14 +76,29 @@ func main() {
 	}
 
 	// Auto-migrate database schema
-	if err := db.AutoMigrate(&domain.Notification{}, &domain.NotificationTemplate{}); err != nil {
+	if err := db.AutoMigrate(&domain.Notification{}, &domain.NotificationTemplate{}, &domain.TemplateVersion{}); err != nil {
 		log.Fatal().Err(err).Msg("Failed to migrate database")
 	}
 	log.Info().Msg("Database migrated successfully")
 
-	// Initialize service (no real email/sms/push senders for now, using mock)
-	notificationService := service.NewNotificationService(db, nil, nil, nil)
+	if err := service.SeedTemplates(db); err != nil {
+		log.Fatal().Err(err).Msg("Failed to seed notification templates")
+	}
+
+	// Initialize layers (Dependency Injection)
+	notificationRepo := repository.NewNotificationRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+	templateService := service.NewTemplateService(templateRepo)
+	notificationService := service.NewNotificationService(notificationRepo, templateService, retryPolicy)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
+	deadLetterHandler := handler.NewDeadLetterHandler(notificationService)
+	templateHandler := handler.NewTemplateHandler(templateService)
+
+	// Send queued notifications in the background
+	dispatcher := service.NewDispatcher(notificationRepo, emailSender, smsSender, pushSender, retryPolicy, workers, log)
+	go dispatcher.Start(context.Background(), pollInterval)
+
+	identitySigner := auth.NewSigner(identitySecret)
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
@@ -57,6 +111,7 @@ func main() {
 	router.Use(middleware.CORS())
 	router.Use(middleware.SecureHeaders())
 	router.Use(middleware.RateLimiter(100, 10))
+	router.Use(auth.Middleware(identitySigner)) // Trust only gateway-signed identity
 
 	// Health check endpoints
 	router.GET("/health", func(c *gin.Context) {
@@ -83,6 +138,8 @@ func main() {
 	// Register API routes
 	api := router.Group("/api/v1")
 	notificationHandler.RegisterRoutes(api)
+	deadLetterHandler.RegisterRoutes(api)
+	templateHandler.RegisterRoutes(api)
 
 	// Start HTTP server
 	log.Info().Str("port", httpPort).Msg("Notification Service HTTP starting")
@@ -91,6 +148,76 @@ func main() {
 	}
 }
 
+// loadRetryPolicy reads the notification retry policy from the environment, over the defaults
+func loadRetryPolicy() (service.RetryPolicy, error) {
+	policy := service.DefaultRetryPolicy()
+	var err error
+	if value := os.Getenv("NOTIFICATION_MAX_ATTEMPTS"); value != "" {
+		if policy.MaxAttempts, err = strconv.Atoi(value); err != nil {
+			return policy, fmt.Errorf("NOTIFICATION_MAX_ATTEMPTS: %w", err)
+		}
+		if policy.MaxAttempts < 1 {
+			return policy, fmt.Errorf("NOTIFICATION_MAX_ATTEMPTS must be at least 1")
+		}
+	}
+	if value := os.Getenv("NOTIFICATION_RETRY_BASE_DELAY"); value != "" {
+		if policy.BaseDelay, err = time.ParseDuration(value); err != nil {
+			return policy, fmt.Errorf("NOTIFICATION_RETRY_BASE_DELAY: %w", err)
+		}
+	}
+	if value := os.Getenv("NOTIFICATION_RETRY_MAX_DELAY"); value != "" {
+		if policy.MaxDelay, err = time.ParseDuration(value); err != nil {
+			return policy, fmt.Errorf("NOTIFICATION_RETRY_MAX_DELAY: %w", err)
+		}
+	}
+	return policy, nil
+}
+
+// loadSenders creates the senders configured in the environment. A sender whose server or URL
+// is not set is left nil.
+func loadSenders() (service.EmailSender, service.SMSSender, service.PushSender, error) {
+	var emailSender service.EmailSender
+	var smsSender service.SMSSender
+	var pushSender service.PushSender
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("SMTP_PORT: %w", err)
+		}
+		smtpSender, err := sender.NewSMTPSender(sender.SMTPConfig{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnv("SMTP_FROM", "GoShop <no-reply@goshop.local>"),
+			TLSMode:  sender.TLSMode(getEnv("SMTP_TLS", string(sender.TLSModeStartTLS))),
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		emailSender = smtpSender
+	}
+
+	if url := os.Getenv("SMS_API_URL"); url != "" {
+		httpSender, err := sender.NewHTTPSMSSender(sender.HTTPConfig{URL: url, APIKey: os.Getenv("SMS_API_KEY")}, os.Getenv("SMS_SENDER_ID"))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		smsSender = httpSender
+	}
+
+	if url := os.Getenv("PUSH_API_URL"); url != "" {
+		fcmSender, err := sender.NewFCMPushSender(sender.HTTPConfig{URL: url, APIKey: os.Getenv("PUSH_API_KEY")})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		pushSender = fcmSender
+	}
+
+	return emailSender, smsSender, pushSender, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"net"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/database"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/logger"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/middleware"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/scheduler"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/tax"
+	pb "github.com/herman-xphp/go-microservices-ecommerce/proto/order"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/client"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	ordergrpc "github.com/herman-xphp/go-microservices-ecommerce/services/order/grpc"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/handler"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
@@ -22,7 +33,52 @@ func main() {
 
 	// Load configuration from environment variables
 	httpPort := getEnv("HTTP_PORT", "8083")
+	grpcPort := getEnv("GRPC_PORT", "9093")
+	// Anyone who knows the identity secret can act as any user, so there is no default
+	identitySecret := os.Getenv("IDENTITY_SECRET")
+	if identitySecret == "" {
+		log.Fatal().Msg("IDENTITY_SECRET is required")
+	}
 	productServiceAddr := getEnv("PRODUCT_SERVICE_ADDR", "localhost:9092")
+	paymentServiceURL := getEnv("PAYMENT_SERVICE_URL", "http://localhost:8084")
+
+	// Pending orders are cancelled once this old; keep it above the longest payment window
+	paymentTimeout, err := time.ParseDuration(getEnv("ORDER_PAYMENT_TIMEOUT", "48h"))
+	if err != nil || paymentTimeout <= 0 {
+		log.Fatal().Err(err).Msg("Invalid ORDER_PAYMENT_TIMEOUT")
+	}
+	expiryInterval, err := time.ParseDuration(getEnv("ORDER_EXPIRY_INTERVAL", "1m"))
+	if err != nil || expiryInterval <= 0 {
+		log.Fatal().Err(err).Msg("Invalid ORDER_EXPIRY_INTERVAL")
+	}
+
+	// Catalogue prices are in IDR; orders in other currencies are priced with these rates
+	ratesFile := getEnv("EXCHANGE_RATES_FILE", "config/exchange-rates.json")
+	exchangeRates, err := currency.LoadStaticRates(ratesFile)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", ratesFile).Msg("Failed to load exchange rates")
+	}
+	taxRatesFile := getEnv("TAX_RATES_FILE", "config/tax-rates.json")
+	taxRates, err := tax.LoadRateTable(taxRatesFile)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", taxRatesFile).Msg("Failed to load tax rates")
+	}
+
+	invoiceTaxRate, err := strconv.ParseFloat(getEnv("INVOICE_TAX_RATE", "0.11"), 64)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid INVOICE_TAX_RATE")
+	}
+	invoiceSettings := service.InvoiceSettings{
+		Seller: domain.InvoiceSeller{
+			Name:    getEnv("SELLER_NAME", "GoShop"),
+			Address: getEnv("SELLER_ADDRESS", ""),
+			TaxID:   getEnv("SELLER_TAX_ID", ""),
+			Email:   getEnv("SELLER_EMAIL", ""),
+			Phone:   getEnv("SELLER_PHONE", ""),
+		},
+		TaxName: getEnv("INVOICE_TAX_NAME", "PPN"),
+		TaxRate: invoiceTaxRate,
+	}
 
 	dbConfig := database.Config{
 		Host:     getEnv("DB_HOST", "localhost"),
@@ -40,7 +96,14 @@ func main() {
 	}
 
 	// Auto-migrate database schema
-	if err := db.AutoMigrate(&domain.Order{}, &domain.OrderItem{}); err != nil {
+	if err := db.AutoMigrate(
+		&domain.Order{}, &domain.OrderItem{},
+		&domain.Promotion{}, &domain.PromotionRedemption{}, &domain.OrderDiscount{}, &domain.OrderTax{},
+		&domain.Address{}, &domain.Shipment{},
+		&domain.ReturnRequest{}, &domain.ReturnItem{}, &domain.ReturnEvent{},
+		&domain.Invoice{}, &domain.InvoiceLine{}, &domain.InvoiceTaxLine{}, &domain.InvoiceSequence{},
+		&scheduler.Lease{},
+	); err != nil {
 		log.Fatal().Err(err).Msg("Failed to migrate database")
 	}
 	log.Info().Msg("Database migrated successfully")
@@ -53,10 +116,48 @@ func main() {
 	defer productClient.Close()
 	log.Info().Str("addr", productServiceAddr).Msg("Connected to Product Service")
 
+	// Identity forwarded by the gateway is verified, and re-signed on calls to Payment Service
+	identitySigner := auth.NewSigner(identitySecret)
+
+	// Initialize HTTP client to Payment Service (used for refunds, admin payment status and invoices)
+	paymentClient := client.NewPaymentClient(paymentServiceURL, identitySigner)
+
 	// Initialize layers (Dependency Injection)
 	orderRepo := repository.NewOrderRepository(db)
-	orderService := service.NewOrderService(orderRepo, productClient)
+	addressRepo := repository.NewAddressRepository(db)
+	shipmentRepo := repository.NewShipmentRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
+	orderService := service.NewOrderService(orderRepo, addressRepo, shipmentRepo, promotionRepo, productClient, service.DefaultShippingRates(), exchangeRates, taxRates)
+	returnRepo := repository.NewReturnRepository(db)
+	addressService := service.NewAddressService(addressRepo)
+	returnService := service.NewReturnService(returnRepo, orderRepo, productClient, paymentClient)
+	adminService := service.NewAdminOrderService(orderRepo, orderService, paymentClient)
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	invoiceService := service.NewInvoiceService(invoiceRepo, orderRepo, paymentClient, invoiceSettings)
+	promotionService := service.NewPromotionService(promotionRepo)
 	orderHandler := handler.NewOrderHandler(orderService)
+	addressHandler := handler.NewAddressHandler(addressService)
+	returnHandler := handler.NewReturnHandler(returnService)
+	adminHandler := handler.NewAdminHandler(adminService)
+	invoiceHandler := handler.NewInvoiceHandler(orderService, invoiceService)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+
+	// Start gRPC server in a goroutine (used by Payment and Cart Services)
+	go startGRPCServer(grpcPort, orderService)
+
+	// Cancel orders left unpaid in the background; replicas take turns through a lease
+	jobs := scheduler.New(scheduler.NewLeaseStore(db), scheduler.DefaultHolder(), log)
+	go jobs.Start(context.Background(), scheduler.Job{
+		Name:     "order-expiry",
+		Interval: expiryInterval,
+		Run: func(ctx context.Context, now time.Time) error {
+			expired, err := orderService.ExpireOrders(ctx, now.Add(-paymentTimeout), 100)
+			if expired > 0 {
+				log.Info().Int("count", expired).Msg("Cancelled unpaid orders")
+			}
+			return err
+		},
+	})
 
 	// Setup Gin router with middleware
 	gin.SetMode(gin.ReleaseMode)
@@ -69,6 +170,7 @@ func main() {
 	router.Use(middleware.CORS())
 	router.Use(middleware.SecureHeaders())
 	router.Use(middleware.RateLimiter(100, 10))
+	router.Use(auth.Middleware(identitySigner)) // Trust only gateway-signed identity
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -76,13 +178,20 @@ func main() {
 			"status":               "ok",
 			"service":              serviceName,
 			"http_port":            httpPort,
+			"grpc_port":            grpcPort,
 			"product_service_addr": productServiceAddr,
+			"payment_service_url":  paymentServiceURL,
 		})
 	})
 
 	// Register API routes
 	api := router.Group("/api/v1")
 	orderHandler.RegisterRoutes(api)
+	addressHandler.RegisterRoutes(api)
+	returnHandler.RegisterRoutes(api)
+	adminHandler.RegisterRoutes(api)
+	invoiceHandler.RegisterRoutes(api)
+	promotionHandler.RegisterRoutes(api)
 
 	// Start HTTP server
 	log.Info().Str("port", httpPort).Msg("Order Service HTTP starting")
@@ -91,6 +200,24 @@ func main() {
 	}
 }
 
+func startGRPCServer(port string, orderService service.OrderService) {
+	log := logger.WithService(serviceName)
+
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatal().Err(err).Str("port", port).Msg("Failed to listen on gRPC port")
+	}
+
+	grpcServer := grpc.NewServer()
+	orderGRPCServer := ordergrpc.NewOrderGRPCServer(orderService)
+	pb.RegisterOrderServiceServer(grpcServer, orderGRPCServer)
+
+	log.Info().Str("port", port).Msg("Order Service gRPC starting")
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Fatal().Err(err).Msg("Failed to start gRPC server")
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,119 @@
+// Command payment-reconcile matches a provider's CSV settlement statement against the
+// payments table and stores a reconciliation report, which is then available at
+// GET /api/v1/admin/payments/reconciliations/:id.
+//
+// Usage:
+//
+//	payment-reconcile -provider simulator -from 2024-03-15 -file settlement-2024-03-15.csv
+//
+// The statement needs a header row with amount and transaction_id and/or provider_ref columns.
+// Database settings are read from the same environment variables as the payment service.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/database"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/logger"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
+)
+
+const serviceName = "payment-reconcile"
+
+func main() {
+	log := logger.WithService(serviceName)
+
+	providerName := flag.String("provider", "simulator", "provider that issued the statement")
+	file := flag.String("file", "", "path to the CSV settlement statement")
+	from := flag.String("from", "", "first day covered by the statement (YYYY-MM-DD)")
+	to := flag.String("to", "", "day after the last day covered (YYYY-MM-DD); defaults to the day after -from")
+	tz := flag.String("tz", "UTC", "time zone the statement days are in")
+	flag.Parse()
+
+	if *file == "" || *from == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	location, err := time.LoadLocation(*tz)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid -tz")
+	}
+	periodStart, err := time.ParseInLocation("2006-01-02", *from, location)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid -from")
+	}
+	periodEnd := periodStart.AddDate(0, 0, 1)
+	if *to != "" {
+		if periodEnd, err = time.ParseInLocation("2006-01-02", *to, location); err != nil {
+			log.Fatal().Err(err).Msg("Invalid -to")
+		}
+	}
+
+	statement, err := os.Open(*file)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open statement")
+	}
+	defer statement.Close()
+
+	dbConfig := database.Config{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "5432"),
+		User:     getEnv("DB_USER", "user"),
+		Password: getEnv("DB_PASSWORD", "password"),
+		DBName:   getEnv("DB_NAME", "goshop_payment"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	}
+
+	db, err := database.NewPostgresConnection(dbConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	if err := db.AutoMigrate(&domain.Reconciliation{}, &domain.ReconciliationEntry{}); err != nil {
+		log.Fatal().Err(err).Msg("Failed to migrate database")
+	}
+
+	reconciliationService := service.NewReconciliationService(
+		repository.NewPaymentRepository(db),
+		repository.NewReconciliationRepository(db),
+	)
+	report, err := reconciliationService.Reconcile(&service.ReconcileRequest{
+		Provider:    *providerName,
+		FileName:    filepath.Base(*file),
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Statement:   statement,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Reconciliation failed")
+	}
+
+	fmt.Printf("Reconciliation #%d: %d rows, total %.2f\n", report.ID, report.Rows, report.StatementTotal)
+	for _, status := range []domain.ReconciliationStatus{
+		domain.ReconciliationMatched,
+		domain.ReconciliationMissingPayment,
+		domain.ReconciliationMissingSettlement,
+		domain.ReconciliationDuplicate,
+		domain.ReconciliationAmountMismatch,
+		domain.ReconciliationStatusMismatch,
+	} {
+		fmt.Printf("  %-20s %d\n", status, report.Summary[string(status)])
+	}
+	if report.Discrepancies > 0 {
+		// A distinct exit code lets schedulers alert on discrepancies without parsing the output
+		os.Exit(3)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
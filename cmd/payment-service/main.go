@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/database"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/logger"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/middleware"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/scheduler"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/client"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/handler"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/risk"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
 )
 
@@ -21,6 +32,61 @@ func main() {
 
 	// Load configuration from environment variables
 	httpPort := getEnv("HTTP_PORT", "8084")
+	// Anyone who knows the identity secret can act as any user, so there is no default
+	identitySecret := os.Getenv("IDENTITY_SECRET")
+	if identitySecret == "" {
+		log.Fatal().Msg("IDENTITY_SECRET is required")
+	}
+	orderServiceAddr := getEnv("ORDER_SERVICE_ADDR", "localhost:9093")
+	authServiceAddr := getEnv("AUTH_SERVICE_ADDR", "localhost:9091")
+
+	// Webhook secrets per provider, e.g. "midtrans:secret1,xendit:secret2"
+	webhookSecrets, err := service.ParseWebhookSecrets(getEnv("PAYMENT_WEBHOOK_SECRETS", ""))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid PAYMENT_WEBHOOK_SECRETS")
+	}
+	if len(webhookSecrets) == 0 {
+		log.Warn().Msg("No webhook secrets configured; all payment webhooks will be rejected")
+	}
+
+	// Payment method routing, e.g. "qris:simulator,credit_card:simulator"
+	providerRoutes, err := provider.ParseRoutes(getEnv("PAYMENT_PROVIDER_ROUTES",
+		"bank_transfer:simulator,virtual_account:simulator,qris:simulator,e_wallet:simulator,credit_card:simulator"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid PAYMENT_PROVIDER_ROUTES")
+	}
+
+	simulatorConfig := provider.DefaultSimulatorConfig(
+		getEnv("SIMULATOR_WEBHOOK_URL", "http://localhost:"+httpPort+"/api/v1/payments/webhooks/"+provider.SimulatorName))
+	if simulatorConfig.SuccessRate, err = strconv.ParseFloat(getEnv("SIMULATOR_SUCCESS_RATE", "0.9"), 64); err != nil {
+		log.Fatal().Err(err).Msg("Invalid SIMULATOR_SUCCESS_RATE")
+	}
+	if simulatorConfig.Latency, err = time.ParseDuration(getEnv("SIMULATOR_LATENCY", "3s")); err != nil {
+		log.Fatal().Err(err).Msg("Invalid SIMULATOR_LATENCY")
+	}
+	simulatorConfig.FailureReason = getEnv("SIMULATOR_FAILURE_REASON", simulatorConfig.FailureReason)
+
+	// Payment windows per method, e.g. "qris:15m,virtual_account:24h"; unlisted methods keep their default
+	expiryPolicy, err := service.ParseExpiryPolicy(getEnv("PAYMENT_EXPIRY", ""))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid PAYMENT_EXPIRY")
+	}
+	expiryInterval, err := time.ParseDuration(getEnv("PAYMENT_EXPIRY_INTERVAL", "1m"))
+	if err != nil || expiryInterval <= 0 {
+		log.Fatal().Err(err).Msg("Invalid PAYMENT_EXPIRY_INTERVAL")
+	}
+
+	riskConfig, err := loadRiskConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid risk configuration")
+	}
+
+	// Each payment snapshots the rate of its currency against IDR for reporting
+	ratesFile := getEnv("EXCHANGE_RATES_FILE", "config/exchange-rates.json")
+	exchangeRates, err := currency.LoadStaticRates(ratesFile)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", ratesFile).Msg("Failed to load exchange rates")
+	}
 
 	dbConfig := database.Config{
 		Host:     getEnv("DB_HOST", "localhost"),
@@ -38,19 +104,92 @@ func main() {
 	}
 
 	// Auto-migrate database schema
-	if err := db.AutoMigrate(&domain.Payment{}); err != nil {
+	if err := db.AutoMigrate(
+		&domain.Payment{}, &domain.PaymentEvent{}, &domain.Refund{}, &domain.SavedPaymentMethod{},
+		&domain.RiskAssessment{},
+		&domain.WebhookLog{}, &domain.WebhookEvent{},
+		&domain.Reconciliation{}, &domain.ReconciliationEntry{},
+		&scheduler.Lease{},
+	); err != nil {
 		log.Fatal().Err(err).Msg("Failed to migrate database")
 	}
 	log.Info().Msg("Database migrated successfully")
 
+	// Initialize gRPC client to Order Service
+	orderClient, err := client.NewOrderClient(orderServiceAddr)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", orderServiceAddr).Msg("Failed to connect to Order Service")
+	}
+	defer orderClient.Close()
+	log.Info().Str("addr", orderServiceAddr).Msg("Connected to Order Service")
+
+	// Initialize gRPC client to Auth Service, for account ages in risk checks
+	authClient, err := client.NewAuthClient(authServiceAddr)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", authServiceAddr).Msg("Failed to connect to Auth Service")
+	}
+	defer authClient.Close()
+
 	// Initialize layers (Dependency Injection)
+	webhookVerifier := service.NewWebhookVerifier(webhookSecrets, service.DefaultWebhookTolerance)
+	gateways := map[string]provider.PaymentProvider{
+		provider.SimulatorName: provider.NewSimulator(simulatorConfig, webhookVerifier, log),
+	}
+	providerRouter := provider.NewRouter()
+	for method, name := range providerRoutes {
+		gateway, ok := gateways[name]
+		if !ok {
+			log.Fatal().Str("method", string(method)).Str("provider", name).Msg("Unknown payment provider in PAYMENT_PROVIDER_ROUTES")
+		}
+		if _, ok := webhookSecrets[name]; !ok {
+			log.Fatal().Str("provider", name).Msg("Payment provider has no secret in PAYMENT_WEBHOOK_SECRETS")
+		}
+		providerRouter.Route(gateway, method)
+	}
+
 	paymentRepo := repository.NewPaymentRepository(db)
-	paymentService := service.NewPaymentService(paymentRepo)
+	savedMethodRepo := repository.NewSavedMethodRepository(db)
+	riskRepo := repository.NewRiskRepository(db)
+	riskEngine := risk.NewDefaultEngine(riskConfig, risk.NewRepositoryHistory(riskRepo, paymentRepo), risk.NewAuthAccounts(authClient))
+	paymentService := service.NewPaymentService(paymentRepo, savedMethodRepo, providerRouter, orderClient, expiryPolicy, exchangeRates, riskEngine, riskRepo)
 	paymentHandler := handler.NewPaymentHandler(paymentService)
+	riskReviewService := service.NewRiskReviewService(riskRepo, paymentService)
+	riskReviewHandler := handler.NewRiskReviewHandler(riskReviewService)
+	refundRepo := repository.NewRefundRepository(db)
+	refundService := service.NewRefundService(paymentRepo, refundRepo, providerRouter)
+	refundHandler := handler.NewRefundHandler(paymentService, refundService)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo, paymentService, refundService, webhookVerifier, service.LogAlerter{Logger: log})
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	reconciliationRepo := repository.NewReconciliationRepository(db)
+	reconciliationService := service.NewReconciliationService(paymentRepo, reconciliationRepo)
+	reconciliationHandler := handler.NewReconciliationHandler(reconciliationService)
+	savedMethodService := service.NewSavedMethodService(savedMethodRepo, providerRouter)
+	savedMethodHandler := handler.NewSavedMethodHandler(savedMethodService)
+
+	identitySigner := auth.NewSigner(identitySecret)
+
+	// Expire unpaid payments in the background; replicas take turns through a lease
+	jobs := scheduler.New(scheduler.NewLeaseStore(db), scheduler.DefaultHolder(), log)
+	go jobs.Start(context.Background(), scheduler.Job{
+		Name:     "payment-expiry",
+		Interval: expiryInterval,
+		Run: func(ctx context.Context, now time.Time) error {
+			expired, err := paymentService.ExpirePayments(ctx, now, 100)
+			if expired > 0 {
+				log.Info().Int("count", expired).Msg("Expired unpaid payments")
+			}
+			return err
+		},
+	})
 
 	// Setup Gin router with middleware
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	// Only proxies in TRUSTED_PROXIES may report the client address; by default it is the connection's
+	if err := router.SetTrustedProxies(trustedProxies(getEnv("TRUSTED_PROXIES", ""))); err != nil {
+		log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
 
 	// Apply middleware
 	router.Use(middleware.Recovery())
@@ -59,19 +198,27 @@ func main() {
 	router.Use(middleware.CORS())
 	router.Use(middleware.SecureHeaders())
 	router.Use(middleware.RateLimiter(100, 10))
+	router.Use(auth.Middleware(identitySigner)) // Trust only gateway-signed identity
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
-			"status":    "ok",
-			"service":   serviceName,
-			"http_port": httpPort,
+			"status":             "ok",
+			"service":            serviceName,
+			"http_port":          httpPort,
+			"order_service_addr": orderServiceAddr,
+			"auth_service_addr":  authServiceAddr,
 		})
 	})
 
 	// Register API routes
 	api := router.Group("/api/v1")
 	paymentHandler.RegisterRoutes(api)
+	refundHandler.RegisterRoutes(api)
+	webhookHandler.RegisterRoutes(api)
+	reconciliationHandler.RegisterRoutes(api)
+	savedMethodHandler.RegisterRoutes(api)
+	riskReviewHandler.RegisterRoutes(api)
 
 	// Start HTTP server
 	log.Info().Str("port", httpPort).Msg("Payment Service HTTP starting")
@@ -80,6 +227,54 @@ func main() {
 	}
 }
 
+// loadRiskConfig applies RISK_* overrides to the default risk thresholds
+func loadRiskConfig() (risk.Config, error) {
+	cfg := risk.DefaultConfig()
+	var err error
+	if value := os.Getenv("RISK_REVIEW_AMOUNT"); value != "" {
+		if cfg.ReviewAmount, err = strconv.ParseFloat(value, 64); err != nil {
+			return cfg, fmt.Errorf("RISK_REVIEW_AMOUNT: %w", err)
+		}
+	}
+	if value := os.Getenv("RISK_DENY_AMOUNT"); value != "" {
+		if cfg.DenyAmount, err = strconv.ParseFloat(value, 64); err != nil {
+			return cfg, fmt.Errorf("RISK_DENY_AMOUNT: %w", err)
+		}
+	}
+	if value := os.Getenv("RISK_MAX_ATTEMPTS_PER_USER"); value != "" {
+		if cfg.MaxAttemptsPerUser, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return cfg, fmt.Errorf("RISK_MAX_ATTEMPTS_PER_USER: %w", err)
+		}
+	}
+	if value := os.Getenv("RISK_MAX_ATTEMPTS_PER_IP"); value != "" {
+		if cfg.MaxAttemptsPerIP, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return cfg, fmt.Errorf("RISK_MAX_ATTEMPTS_PER_IP: %w", err)
+		}
+	}
+	if value := os.Getenv("RISK_MAX_FAILED_ATTEMPTS"); value != "" {
+		if cfg.MaxFailedAttempts, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return cfg, fmt.Errorf("RISK_MAX_FAILED_ATTEMPTS: %w", err)
+		}
+	}
+	if value := os.Getenv("RISK_NEW_ACCOUNT_AGE"); value != "" {
+		if cfg.NewAccountAge, err = time.ParseDuration(value); err != nil {
+			return cfg, fmt.Errorf("RISK_NEW_ACCOUNT_AGE: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// trustedProxies splits a comma-separated list of proxy addresses or CIDR ranges
+func trustedProxies(value string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
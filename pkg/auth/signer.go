@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Identity headers set by the gateway
+const (
+	HeaderUserID    = "X-User-ID"
+	HeaderUserEmail = "X-User-Email"
+	HeaderUserRole  = "X-User-Role"
+	HeaderClientIP  = "X-Client-IP"
+	HeaderTimestamp = "X-Identity-Timestamp"
+	HeaderSignature = "X-Identity-Signature"
+)
+
+// DefaultMaxSkew is how old (or how far in the future) a signed identity may be
+const DefaultMaxSkew = 5 * time.Minute
+
+var (
+	ErrMissingIdentity   = errors.New("identity headers missing")
+	ErrInvalidIdentity   = errors.New("identity signature is invalid")
+	ErrExpiredIdentity   = errors.New("identity signature has expired")
+	ErrMalformedIdentity = errors.New("identity headers are malformed")
+)
+
+// identityHeaders are stripped from untrusted requests before signing
+var identityHeaders = []string{HeaderUserID, HeaderUserEmail, HeaderUserRole, HeaderClientIP, HeaderTimestamp, HeaderSignature}
+
+// Signer signs and verifies identity headers with a shared HMAC secret
+type Signer struct {
+	secret  []byte
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+// NewSigner creates a Signer using the shared identity secret
+func NewSigner(secret string) *Signer {
+	return &Signer{
+		secret:  []byte(secret),
+		maxSkew: DefaultMaxSkew,
+		now:     time.Now,
+	}
+}
+
+// Sign replaces any identity headers on req with a signed assertion of identity.
+// The signature covers the method, path, query string and body, so it cannot be replayed against
+// another endpoint, for another user_id, or with another payload. The body is read to hash it and
+// replaced with a buffered copy.
+func (s *Signer) Sign(req *http.Request, identity Identity) error {
+	StripIdentity(req.Header)
+
+	contentHash, err := hashBody(req)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	userID := strconv.FormatUint(uint64(identity.UserID), 10)
+
+	req.Header.Set(HeaderUserID, userID)
+	req.Header.Set(HeaderUserEmail, identity.Email)
+	req.Header.Set(HeaderUserRole, identity.Role)
+	if identity.ClientIP != "" {
+		req.Header.Set(HeaderClientIP, identity.ClientIP)
+	}
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, s.signature(req.Method, req.URL.Path, req.URL.RawQuery, contentHash,
+		userID, identity.Email, identity.Role, identity.ClientIP, timestamp))
+	return nil
+}
+
+// Verify returns the identity asserted by req's headers if the signature is valid.
+// The body is read to check it was signed and replaced with a buffered copy.
+func (s *Signer) Verify(req *http.Request) (*Identity, error) {
+	signature := req.Header.Get(HeaderSignature)
+	if signature == "" {
+		return nil, ErrMissingIdentity
+	}
+
+	userID := req.Header.Get(HeaderUserID)
+	email := req.Header.Get(HeaderUserEmail)
+	role := req.Header.Get(HeaderUserRole)
+	clientIP := req.Header.Get(HeaderClientIP)
+	timestamp := req.Header.Get(HeaderTimestamp)
+
+	contentHash, err := hashBody(req)
+	if err != nil {
+		return nil, ErrMalformedIdentity
+	}
+	expected := s.signature(req.Method, req.URL.Path, req.URL.RawQuery, contentHash, userID, email, role, clientIP, timestamp)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidIdentity
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrMalformedIdentity
+	}
+	if skew := s.now().Sub(time.Unix(unix, 0)); skew > s.maxSkew || skew < -s.maxSkew {
+		return nil, ErrExpiredIdentity
+	}
+
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return nil, ErrMalformedIdentity
+	}
+
+	return &Identity{UserID: uint(id), Email: email, Role: role, ClientIP: clientIP}, nil
+}
+
+// hashBody returns the hex SHA-256 of req's body, leaving a buffered copy of the body in its place
+func hashBody(req *http.Request) (string, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *Signer) signature(parts ...string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StripIdentity removes identity headers, e.g. those sent by a client to the gateway
+func StripIdentity(header http.Header) {
+	for _, name := range identityHeaders {
+		header.Del(name)
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	// Arrange
+	signer := NewSigner("test-secret")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", nil)
+
+	// Act
+	signer.Sign(req, Identity{UserID: 42, Email: "buyer@example.com", Role: RoleCustomer, ClientIP: "203.0.113.7"})
+	identity, err := signer.Verify(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), identity.UserID)
+	assert.Equal(t, "buyer@example.com", identity.Email)
+	assert.Equal(t, RoleCustomer, identity.Role)
+	assert.Equal(t, "203.0.113.7", identity.ClientIP)
+}
+
+func TestSigner_SignAndVerify_WithQueryAndBody(t *testing.T) {
+	// Arrange
+	signer := NewSigner("test-secret")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments?user_id=9", strings.NewReader(`{"order_id":7}`))
+
+	// Act
+	require.NoError(t, signer.Sign(req, Identity{UserID: 1, Role: RoleStaff}))
+	identity, err := signer.Verify(req)
+
+	// Assert: the body is still there for the handler
+	require.NoError(t, err)
+	assert.Equal(t, RoleStaff, identity.Role)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"order_id":7}`, string(body))
+}
+
+func TestSigner_Verify_RejectsUnsignedHeaders(t *testing.T) {
+	signer := NewSigner("test-secret")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", nil)
+	req.Header.Set(HeaderUserID, "42")
+
+	_, err := signer.Verify(req)
+
+	assert.ErrorIs(t, err, ErrMissingIdentity)
+}
+
+func TestSigner_Verify_RejectsTampering(t *testing.T) {
+	signer := NewSigner("test-secret")
+
+	tests := map[string]func(req *http.Request) *http.Request{
+		"user id": func(req *http.Request) *http.Request {
+			req.Header.Set(HeaderUserID, "1")
+			return req
+		},
+		"role": func(req *http.Request) *http.Request {
+			req.Header.Set(HeaderUserRole, RoleAdmin)
+			return req
+		},
+		"client ip": func(req *http.Request) *http.Request {
+			req.Header.Set(HeaderClientIP, "198.51.100.1")
+			return req
+		},
+		"path": func(req *http.Request) *http.Request {
+			other := httptest.NewRequest(http.MethodGet, "/api/v1/orders/8", nil)
+			other.Header = req.Header
+			return other
+		},
+		"query": func(req *http.Request) *http.Request {
+			other := httptest.NewRequest(http.MethodGet, "/api/v1/orders/7?user_id=9", nil)
+			other.Header = req.Header
+			return other
+		},
+		"body": func(req *http.Request) *http.Request {
+			other := httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", strings.NewReader(`{"status":"paid"}`))
+			other.Header = req.Header
+			return other
+		},
+		"secret": func(req *http.Request) *http.Request {
+			NewSigner("other-secret").Sign(req, Identity{UserID: 42, Role: RoleCustomer})
+			return req
+		},
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", nil)
+			signer.Sign(req, Identity{UserID: 42, Role: RoleCustomer})
+
+			_, err := signer.Verify(tamper(req))
+
+			assert.ErrorIs(t, err, ErrInvalidIdentity)
+		})
+	}
+}
+
+func TestSigner_Verify_RejectsExpired(t *testing.T) {
+	// Arrange
+	signer := NewSigner("test-secret")
+	signedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	signer.now = func() time.Time { return signedAt }
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments", nil)
+	signer.Sign(req, Identity{UserID: 42, Role: RoleCustomer})
+
+	// Act
+	signer.now = func() time.Time { return signedAt.Add(DefaultMaxSkew + time.Second) }
+	_, err := signer.Verify(req)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrExpiredIdentity)
+}
+
+func TestIdentity_CanAccess(t *testing.T) {
+	customer := &Identity{UserID: 42, Role: RoleCustomer}
+	staff := &Identity{UserID: 1, Role: RoleStaff}
+	var anonymous *Identity
+
+	assert.True(t, customer.CanAccess(42))
+	assert.False(t, customer.CanAccess(43))
+	assert.True(t, staff.CanAccess(43))
+	assert.False(t, anonymous.CanAccess(42))
+	assert.False(t, (&Identity{Role: RoleCustomer}).CanAccess(0))
+}
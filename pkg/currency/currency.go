@@ -0,0 +1,112 @@
+// Package currency describes the currencies the shop sells in and converts amounts between them.
+//
+// Amounts are kept as float64 in major units (rupiah, dollars, ringgit) like the rest of the code base,
+// rounded to the number of decimals each currency uses.
+package currency
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Code is an ISO 4217 currency code
+type Code string
+
+const (
+	IDR Code = "IDR"
+	SGD Code = "SGD"
+	MYR Code = "MYR"
+)
+
+// Base is the currency catalogue prices are kept in and payments are reported in
+const Base = IDR
+
+var ErrUnsupported = errors.New("unsupported currency")
+
+type format struct {
+	symbol    string
+	decimals  int
+	thousands byte
+	decimal   byte
+}
+
+var formats = map[Code]format{
+	IDR: {symbol: "Rp", decimals: 0, thousands: '.', decimal: ','},
+	SGD: {symbol: "S$", decimals: 2, thousands: ',', decimal: '.'},
+	MYR: {symbol: "RM", decimals: 2, thousands: ',', decimal: '.'},
+}
+
+// Parse returns the supported currency for a code, case-insensitively.
+// An empty code is the base currency.
+func Parse(value string) (Code, error) {
+	if value == "" {
+		return Base, nil
+	}
+	code := Code(strings.ToUpper(strings.TrimSpace(value)))
+	if !code.IsSupported() {
+		return "", ErrUnsupported
+	}
+	return code, nil
+}
+
+// IsSupported reports whether the shop can sell in the currency
+func (c Code) IsSupported() bool {
+	_, ok := formats[c]
+	return ok
+}
+
+// Decimals returns the number of minor-unit digits the currency uses, e.g. 0 for IDR and 2 for SGD
+func (c Code) Decimals() int {
+	return c.format().decimals
+}
+
+// Round rounds an amount to the currency's smallest unit
+func (c Code) Round(amount float64) float64 {
+	scale := math.Pow10(c.Decimals())
+	return math.Round(amount*scale) / scale
+}
+
+// Format formats an amount the way customers of the currency expect,
+// e.g. "Rp 1.250.000", "S$ 1,250.50" or "RM 12.00"
+func (c Code) Format(amount float64) string {
+	f := c.format()
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatFloat(c.Round(amount), 'f', f.decimals, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	b.WriteString(f.symbol)
+	b.WriteByte(' ')
+	b.WriteString(sign)
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(f.thousands)
+		}
+		b.WriteRune(d)
+	}
+	if fraction != "" {
+		b.WriteByte(f.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// format returns the formatting rules of the currency. Unknown codes are printed
+// with their code as the symbol and two decimals, so nothing is ever rounded away.
+func (c Code) format() format {
+	if f, ok := formats[c]; ok {
+		return f
+	}
+	if c == "" {
+		return formats[Base]
+	}
+	return format{symbol: string(c), decimals: 2, thousands: ',', decimal: '.'}
+}
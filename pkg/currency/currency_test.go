@@ -0,0 +1,75 @@
+package currency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "Rp 0", IDR.Format(0))
+	assert.Equal(t, "Rp 1.250.000", IDR.Format(1_250_000))
+	assert.Equal(t, "Rp 125.360", IDR.Format(125_359.64))
+	assert.Equal(t, "Rp -15.000", IDR.Format(-15_000))
+	assert.Equal(t, "S$ 1,250.50", SGD.Format(1250.5))
+	assert.Equal(t, "S$ 0.99", SGD.Format(0.985))
+	assert.Equal(t, "RM 12.00", MYR.Format(12))
+	assert.Equal(t, "RM 1,000,000.10", MYR.Format(1_000_000.1))
+}
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, 12_346.0, IDR.Round(12_345.5))
+	assert.Equal(t, 10.73, SGD.Round(10.7296))
+	assert.Equal(t, 2, MYR.Decimals())
+	assert.Equal(t, 0, IDR.Decimals())
+}
+
+func TestParse(t *testing.T) {
+	code, err := Parse("sgd")
+	require.NoError(t, err)
+	assert.Equal(t, SGD, code)
+
+	code, err = Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, Base, code)
+
+	_, err = Parse("USD")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestLoadStaticRates(t *testing.T) {
+	// Arrange
+	rates, err := LoadStaticRates("testdata/rates.json")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Act
+	toIDR, err := rates.Rate(ctx, SGD, IDR)
+	require.NoError(t, err)
+	toSGD, err := rates.Rate(ctx, IDR, SGD)
+	require.NoError(t, err)
+	cross, err := rates.Rate(ctx, MYR, SGD)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 11650.0, toIDR.Value)
+	assert.Equal(t, "static:rates.json", toIDR.Source)
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), toIDR.AsOf)
+	assert.Equal(t, 1_165_000.0, toIDR.Convert(100))
+	assert.Equal(t, 85.84, toSGD.Convert(1_000_000))
+	assert.InDelta(t, 3320.0/11650.0, cross.Value, 1e-12)
+}
+
+func TestStaticRates_Unavailable(t *testing.T) {
+	rates := NewStaticRates(IDR, time.Now(), "test", nil)
+
+	same, err := rates.Rate(context.Background(), SGD, SGD)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, same.Value)
+
+	_, err = rates.Rate(context.Background(), SGD, IDR)
+	assert.ErrorIs(t, err, ErrRateUnavailable)
+}
@@ -0,0 +1,113 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// Rate is the price of one unit of From in To, as quoted by a source at a point in time
+type Rate struct {
+	From   Code
+	To     Code
+	Value  float64
+	Source string
+	AsOf   time.Time
+}
+
+// Convert converts an amount in From to To, rounded to To's smallest unit
+func (r Rate) Convert(amount float64) float64 {
+	return r.To.Round(amount * r.Value)
+}
+
+// RateProvider quotes exchange rates between supported currencies
+type RateProvider interface {
+	Rate(ctx context.Context, from, to Code) (Rate, error)
+}
+
+// StaticRates quotes fixed rates, e.g. a daily rate sheet published by finance
+type StaticRates struct {
+	base   Code
+	asOf   time.Time
+	source string
+	// units holds the value of one unit of each currency in the base currency
+	units map[Code]float64
+}
+
+// NewStaticRates creates a provider from the value of one unit of each currency in base
+func NewStaticRates(base Code, asOf time.Time, source string, units map[Code]float64) *StaticRates {
+	rates := &StaticRates{
+		base:   base,
+		asOf:   asOf,
+		source: source,
+		units:  map[Code]float64{base: 1},
+	}
+	for code, value := range units {
+		rates.units[code] = value
+	}
+	return rates
+}
+
+// rateFile is the on-disk format read by LoadStaticRates:
+//
+//	{"base": "IDR", "as_of": "2024-03-15T00:00:00Z", "rates": {"SGD": 11650, "MYR": 3320}}
+//
+// Each rate is the value of one unit of the currency in the base currency.
+type rateFile struct {
+	Base  Code             `json:"base"`
+	AsOf  time.Time        `json:"as_of"`
+	Rates map[Code]float64 `json:"rates"`
+}
+
+// LoadStaticRates reads a rate sheet from a JSON file
+func LoadStaticRates(path string) (*StaticRates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid rate file %s: %w", path, err)
+	}
+	if file.Base == "" {
+		file.Base = Base
+	}
+	for code, value := range file.Rates {
+		if value <= 0 {
+			return nil, fmt.Errorf("invalid rate file %s: rate for %s must be positive", path, code)
+		}
+	}
+
+	return NewStaticRates(file.Base, file.AsOf, "static:"+filepath.Base(path), file.Rates), nil
+}
+
+// Rate quotes from in to, crossing through the base currency when neither is the base
+func (s *StaticRates) Rate(_ context.Context, from, to Code) (Rate, error) {
+	if from == to {
+		return Rate{From: from, To: to, Value: 1, Source: s.source, AsOf: s.asOf}, nil
+	}
+
+	fromValue, ok := s.units[from]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s", ErrRateUnavailable, from)
+	}
+	toValue, ok := s.units[to]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s", ErrRateUnavailable, to)
+	}
+
+	return Rate{
+		From:   from,
+		To:     to,
+		Value:  fromValue / toValue,
+		Source: s.source,
+		AsOf:   s.asOf,
+	}, nil
+}
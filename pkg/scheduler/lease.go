@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lease records which replica currently runs a job and until when
+type Lease struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	Holder    string    `json:"holder" gorm:"not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (Lease) TableName() string {
+	return "scheduler_leases"
+}
+
+// LeaseStore grants leases on named jobs
+type LeaseStore interface {
+	// Acquire takes or renews the lease on name for holder until now+ttl.
+	// It reports false if another holder's lease has not expired yet.
+	Acquire(name, holder string, now time.Time, ttl time.Duration) (bool, error)
+}
+
+type gormLeaseStore struct {
+	db *gorm.DB
+}
+
+// NewLeaseStore creates a LeaseStore backed by the scheduler_leases table
+func NewLeaseStore(db *gorm.DB) LeaseStore {
+	return &gormLeaseStore{db: db}
+}
+
+func (s *gormLeaseStore) Acquire(name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	lease := &Lease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)}
+
+	// A single upsert, so two replicas racing for an expired lease can't both win
+	result := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"holder", "expires_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "scheduler_leases.holder = ? OR scheduler_leases.expires_at <= ?", Vars: []interface{}{holder, now}},
+		}},
+	}).Create(lease)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
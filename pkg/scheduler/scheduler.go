@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Job is a task run periodically by at most one replica at a time
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Run does one pass of the job. now is the scheduler's clock, so jobs never read the time themselves.
+	Run func(ctx context.Context, now time.Time) error
+}
+
+// Scheduler runs jobs on their interval. Before each run it takes a lease on the job,
+// so replicas sharing a database take turns instead of running the job concurrently.
+type Scheduler struct {
+	leases LeaseStore
+	holder string
+	now    func() time.Time
+	log    zerolog.Logger
+}
+
+// New creates a scheduler identified by holder; use DefaultHolder for a per-process identity
+func New(leases LeaseStore, holder string, log zerolog.Logger) *Scheduler {
+	return &Scheduler{
+		leases: leases,
+		holder: holder,
+		now:    time.Now,
+		log:    log,
+	}
+}
+
+// WithClock replaces the clock used for leases and passed to jobs
+func (s *Scheduler) WithClock(now func() time.Time) *Scheduler {
+	s.now = now
+	return s
+}
+
+// DefaultHolder identifies this process among the replicas of a service
+func DefaultHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.New().String()[:8])
+}
+
+// Start runs the job every interval until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx, job); err != nil {
+			s.log.Error().Err(err).Str("job", job.Name).Msg("Scheduled job failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs the job if this replica can take its lease. It reports whether the job ran.
+func (s *Scheduler) RunOnce(ctx context.Context, job Job) (bool, error) {
+	now := s.now()
+
+	// The lease outlives the run's deadline, so a slow run finishes before another replica can start
+	acquired, err := s.leases.Acquire(job.Name, s.holder, now, 2*job.Interval)
+	if err != nil {
+		return false, fmt.Errorf("acquire lease: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, job.Interval)
+	defer cancel()
+	return true, job.Run(ctx, now)
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLeaseStore mirrors the upsert of the gorm store
+type memoryLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]Lease
+}
+
+func (s *memoryLeaseStore) Acquire(name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, ok := s.leases[name]; ok && lease.Holder != holder && lease.ExpiresAt.After(now) {
+		return false, nil
+	}
+	s.leases[name] = Lease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestScheduler_RunOnce_OneReplicaAtATime(t *testing.T) {
+	// Arrange: two replicas sharing a lease store
+	leases := &memoryLeaseStore{leases: make(map[string]Lease)}
+	clock := &fakeClock{now: time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)}
+	first := New(leases, "replica-1", zerolog.Nop()).WithClock(clock.Now)
+	second := New(leases, "replica-2", zerolog.Nop()).WithClock(clock.Now)
+
+	var runs []time.Time
+	job := Job{Name: "expire", Interval: time.Minute, Run: func(ctx context.Context, now time.Time) error {
+		runs = append(runs, now)
+		return nil
+	}}
+
+	// Act & Assert: the first replica keeps the lease while it renews it
+	ran, err := first.RunOnce(context.Background(), job)
+	require.NoError(t, err)
+	assert.True(t, ran)
+
+	ran, err = second.RunOnce(context.Background(), job)
+	require.NoError(t, err)
+	assert.False(t, ran)
+
+	clock.now = clock.now.Add(time.Minute)
+	ran, err = first.RunOnce(context.Background(), job)
+	require.NoError(t, err)
+	assert.True(t, ran)
+
+	// The second replica takes over once the first stops renewing
+	clock.now = clock.now.Add(2 * time.Minute)
+	ran, err = second.RunOnce(context.Background(), job)
+	require.NoError(t, err)
+	assert.True(t, ran)
+
+	require.Len(t, runs, 3)
+	assert.Equal(t, clock.now, runs[2])
+}
@@ -0,0 +1,229 @@
+// Package tax works out the tax on carts and orders.
+//
+// Products carry a tax category. Each region taxes categories at one or more rates and either
+// includes tax in catalogue prices, like PPN in Indonesia, or adds it on top. Amounts are in
+// major units and rounded to the currency being calculated in, like package currency.
+package tax
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+)
+
+var ErrUnknownCategory = errors.New("unknown tax category")
+
+// Category groups goods that are taxed alike
+type Category string
+
+const (
+	CategoryStandard Category = "standard"
+	CategoryReduced  Category = "reduced"
+	CategoryExempt   Category = "exempt"
+)
+
+// ParseCategory returns the category for a value; an empty value is the standard category
+func ParseCategory(value string) (Category, error) {
+	category := Category(strings.ToLower(strings.TrimSpace(value)))
+	switch category {
+	case "":
+		return CategoryStandard, nil
+	case CategoryStandard, CategoryReduced, CategoryExempt:
+		return category, nil
+	}
+	return "", ErrUnknownCategory
+}
+
+// Rate is one tax levied on a category
+type Rate struct {
+	Name string  `json:"name"` // e.g. "PPN"
+	Rate float64 `json:"rate"` // As a fraction, e.g. 0.11
+}
+
+// Region is how goods are taxed in a region
+type Region struct {
+	Code  string   `json:"code"`            // e.g. "ID"
+	Names []string `json:"names,omitempty"` // Other names addresses may use, e.g. "Indonesia"
+	// Inclusive regions have tax contained in catalogue prices; elsewhere it is added on top
+	Inclusive bool `json:"inclusive"`
+	// Rates holds the taxes on each category; categories without rates are not taxed
+	Rates map[Category][]Rate `json:"rates"`
+}
+
+// Line is an amount to tax, net of discounts, in the currency being calculated
+type Line struct {
+	Category Category
+	Amount   float64
+}
+
+// TaxLine is the total of one tax over a basket
+type TaxLine struct {
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	Amount        float64 `json:"amount"`
+}
+
+// Breakdown is the tax on a basket
+type Breakdown struct {
+	Region    string
+	Inclusive bool
+	Lines     []TaxLine
+	// LineAmounts is the tax on each basket line, in basket order
+	LineAmounts []float64
+	Total       float64
+}
+
+// TaxCalculator works out the tax on a basket. region is a region code or name, e.g. from a
+// shipping address; an empty or unknown region is taxed as the default region.
+type TaxCalculator interface {
+	Calculate(region string, lines []Line, code currency.Code) (*Breakdown, error)
+}
+
+// RateTable is a TaxCalculator over fixed rates, e.g. from a file maintained by finance
+type RateTable struct {
+	defaultRegion string
+	regions       map[string]Region // By upper-case code and name
+}
+
+// NewRateTable creates a rate table. defaultRegion must be the code of one of regions.
+func NewRateTable(defaultRegion string, regions ...Region) (*RateTable, error) {
+	table := &RateTable{
+		defaultRegion: strings.ToUpper(defaultRegion),
+		regions:       make(map[string]Region),
+	}
+	for _, region := range regions {
+		region.Code = strings.ToUpper(region.Code)
+		table.regions[region.Code] = region
+		for _, name := range region.Names {
+			table.regions[strings.ToUpper(name)] = region
+		}
+	}
+	if _, ok := table.regions[table.defaultRegion]; !ok {
+		return nil, fmt.Errorf("default tax region %q has no rates", defaultRegion)
+	}
+	return table, nil
+}
+
+// rateFile is the on-disk format read by LoadRateTable:
+//
+//	{"default_region": "ID", "regions": [{"code": "ID", "inclusive": true, "rates": {"standard": [{"name": "PPN", "rate": 0.11}]}}]}
+type rateFile struct {
+	DefaultRegion string   `json:"default_region"`
+	Regions       []Region `json:"regions"`
+}
+
+// LoadRateTable reads a rate table from a JSON file
+func LoadRateTable(path string) (*RateTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid tax rate file %s: %w", path, err)
+	}
+	return NewRateTable(file.DefaultRegion, file.Regions...)
+}
+
+// Region returns how a region is taxed, falling back to the default region
+func (t *RateTable) Region(region string) Region {
+	if r, ok := t.regions[strings.ToUpper(strings.TrimSpace(region))]; ok {
+		return r
+	}
+	return t.regions[t.defaultRegion]
+}
+
+func (t *RateTable) Calculate(region string, lines []Line, code currency.Code) (*Breakdown, error) {
+	r := t.Region(region)
+	breakdown := &Breakdown{
+		Region:      r.Code,
+		Inclusive:   r.Inclusive,
+		LineAmounts: make([]float64, len(lines)),
+	}
+
+	// Tax is worked out on each category's total, then split over its lines
+	totals := make(map[Category]float64)
+	var categories []Category
+	for _, line := range lines {
+		category := line.Category
+		if category == "" {
+			category = CategoryStandard
+		}
+		if _, ok := totals[category]; !ok {
+			categories = append(categories, category)
+		}
+		totals[category] += line.Amount
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+
+	taxLines := make(map[Rate]*TaxLine)
+	var order []Rate
+	for _, category := range categories {
+		rates := r.Rates[category]
+		amount := totals[category]
+		if len(rates) == 0 || amount <= 0 {
+			continue
+		}
+
+		combined := 0.0
+		for _, rate := range rates {
+			combined += rate.Rate
+		}
+		taxable := amount
+		if r.Inclusive {
+			taxable = code.Round(amount / (1 + combined))
+		}
+
+		categoryTax := 0.0
+		for _, rate := range rates {
+			tax := code.Round(taxable * rate.Rate)
+			categoryTax += tax
+
+			line, ok := taxLines[rate]
+			if !ok {
+				line = &TaxLine{Name: rate.Name, Rate: rate.Rate, Inclusive: r.Inclusive}
+				taxLines[rate] = line
+				order = append(order, rate)
+			}
+			line.TaxableAmount += taxable
+			line.Amount += tax
+		}
+		breakdown.Total += categoryTax
+		allocate(breakdown.LineAmounts, lines, category, amount, categoryTax, code)
+	}
+
+	for _, rate := range order {
+		breakdown.Lines = append(breakdown.Lines, *taxLines[rate])
+	}
+	return breakdown, nil
+}
+
+// allocate splits a category's tax over its lines in proportion to their amounts.
+// The last line takes the rounding difference so the shares add up to tax.
+func allocate(shares []float64, lines []Line, category Category, amount, tax float64, code currency.Code) {
+	last := -1
+	allocated := 0.0
+	for i, line := range lines {
+		lineCategory := line.Category
+		if lineCategory == "" {
+			lineCategory = CategoryStandard
+		}
+		if lineCategory != category || line.Amount <= 0 {
+			continue
+		}
+		shares[i] = code.Round(tax * line.Amount / amount)
+		allocated += shares[i]
+		last = i
+	}
+	if last >= 0 {
+		shares[last] += tax - allocated
+	}
+}
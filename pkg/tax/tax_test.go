@@ -0,0 +1,99 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTable(t *testing.T) *RateTable {
+	table, err := NewRateTable("ID",
+		Region{
+			Code:      "ID",
+			Names:     []string{"Indonesia"},
+			Inclusive: true,
+			Rates:     map[Category][]Rate{CategoryStandard: {{Name: "PPN", Rate: 0.11}}},
+		},
+		Region{
+			Code: "MY",
+			Rates: map[Category][]Rate{
+				CategoryStandard: {{Name: "SST", Rate: 0.10}},
+				CategoryReduced:  {{Name: "SST", Rate: 0.05}},
+			},
+		},
+	)
+	require.NoError(t, err)
+	return table
+}
+
+func TestRateTable_Calculate_Inclusive(t *testing.T) {
+	// Arrange
+	lines := []Line{
+		{Category: CategoryStandard, Amount: 100_000},
+		{Category: CategoryExempt, Amount: 50_000},
+		{Category: "", Amount: 122_000},
+	}
+
+	// Act
+	breakdown, err := testTable(t).Calculate("indonesia", lines, currency.IDR)
+
+	// Assert: 222.000 contains 200.000 taxable and 22.000 PPN; exempt goods carry none
+	require.NoError(t, err)
+	assert.Equal(t, "ID", breakdown.Region)
+	assert.True(t, breakdown.Inclusive)
+	assert.Equal(t, []TaxLine{{Name: "PPN", Rate: 0.11, Inclusive: true, TaxableAmount: 200_000, Amount: 22_000}}, breakdown.Lines)
+	assert.Equal(t, 22_000.0, breakdown.Total)
+	assert.Equal(t, []float64{9_910, 0, 12_090}, breakdown.LineAmounts)
+}
+
+func TestRateTable_Calculate_Exclusive(t *testing.T) {
+	// Arrange
+	lines := []Line{
+		{Category: CategoryStandard, Amount: 100.50},
+		{Category: CategoryReduced, Amount: 40},
+	}
+
+	// Act
+	breakdown, err := testTable(t).Calculate("MY", lines, currency.MYR)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, breakdown.Inclusive)
+	assert.Equal(t, []TaxLine{
+		{Name: "SST", Rate: 0.05, TaxableAmount: 40, Amount: 2},
+		{Name: "SST", Rate: 0.10, TaxableAmount: 100.50, Amount: 10.05},
+	}, breakdown.Lines)
+	assert.InDelta(t, 12.05, breakdown.Total, 1e-9)
+	assert.InDeltaSlice(t, []float64{10.05, 2}, breakdown.LineAmounts, 1e-9)
+}
+
+func TestRateTable_Calculate_UnknownRegionUsesDefault(t *testing.T) {
+	breakdown, err := testTable(t).Calculate("Atlantis", []Line{{Amount: 111_000}}, currency.IDR)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ID", breakdown.Region)
+	assert.Equal(t, 11_000.0, breakdown.Total)
+}
+
+func TestLoadRateTable(t *testing.T) {
+	table, err := LoadRateTable("../../config/tax-rates.json")
+
+	require.NoError(t, err)
+	assert.True(t, table.Region("").Inclusive)
+	assert.Equal(t, "SG", table.Region("singapore").Code)
+}
+
+func TestParseCategory(t *testing.T) {
+	category, err := ParseCategory("")
+	require.NoError(t, err)
+	assert.Equal(t, CategoryStandard, category)
+
+	category, err = ParseCategory(" Exempt ")
+	require.NoError(t, err)
+	assert.Equal(t, CategoryExempt, category)
+
+	_, err = ParseCategory("luxury")
+	assert.ErrorIs(t, err, ErrUnknownCategory)
+}
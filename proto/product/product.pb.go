@@ -76,6 +76,8 @@ type GetProductResponse struct {
 	CategoryId    uint64                 `protobuf:"varint,7,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
 	CategoryName  string                 `protobuf:"bytes,8,opt,name=category_name,json=categoryName,proto3" json:"category_name,omitempty"`
 	IsActive      bool                   `protobuf:"varint,9,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	WeightGrams   int32                  `protobuf:"varint,10,opt,name=weight_grams,json=weightGrams,proto3" json:"weight_grams,omitempty"`
+	TaxCategory   string                 `protobuf:"bytes,11,opt,name=tax_category,json=taxCategory,proto3" json:"tax_category,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -173,6 +175,108 @@ func (x *GetProductResponse) GetIsActive() bool {
 	return false
 }
 
+func (x *GetProductResponse) GetWeightGrams() int32 {
+	if x != nil {
+		return x.WeightGrams
+	}
+	return 0
+}
+
+func (x *GetProductResponse) GetTaxCategory() string {
+	if x != nil {
+		return x.TaxCategory
+	}
+	return ""
+}
+
+type GetProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductIds    []uint64               `protobuf:"varint,1,rep,packed,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsRequest) Reset() {
+	*x = GetProductsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsRequest) ProtoMessage() {}
+
+func (x *GetProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsRequest.ProtoReflect.Descriptor instead.
+func (*GetProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetProductsRequest) GetProductIds() []uint64 {
+	if x != nil {
+		return x.ProductIds
+	}
+	return nil
+}
+
+type GetProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*GetProductResponse  `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsResponse) Reset() {
+	*x = GetProductsResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsResponse) ProtoMessage() {}
+
+func (x *GetProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsResponse.ProtoReflect.Descriptor instead.
+func (*GetProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetProductsResponse) GetProducts() []*GetProductResponse {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
 type CheckStockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ProductId     uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
@@ -182,7 +286,7 @@ type CheckStockRequest struct {
 
 func (x *CheckStockRequest) Reset() {
 	*x = CheckStockRequest{}
-	mi := &file_proto_product_product_proto_msgTypes[2]
+	mi := &file_proto_product_product_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -194,7 +298,7 @@ func (x *CheckStockRequest) String() string {
 func (*CheckStockRequest) ProtoMessage() {}
 
 func (x *CheckStockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[2]
+	mi := &file_proto_product_product_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -207,7 +311,7 @@ func (x *CheckStockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckStockRequest.ProtoReflect.Descriptor instead.
 func (*CheckStockRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{2}
+	return file_proto_product_product_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CheckStockRequest) GetProductId() uint64 {
@@ -228,7 +332,7 @@ type CheckStockResponse struct {
 
 func (x *CheckStockResponse) Reset() {
 	*x = CheckStockResponse{}
-	mi := &file_proto_product_product_proto_msgTypes[3]
+	mi := &file_proto_product_product_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -240,7 +344,7 @@ func (x *CheckStockResponse) String() string {
 func (*CheckStockResponse) ProtoMessage() {}
 
 func (x *CheckStockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[3]
+	mi := &file_proto_product_product_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -253,7 +357,7 @@ func (x *CheckStockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckStockResponse.ProtoReflect.Descriptor instead.
 func (*CheckStockResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{3}
+	return file_proto_product_product_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CheckStockResponse) GetFound() bool {
@@ -287,7 +391,7 @@ type DecreaseStockRequest struct {
 
 func (x *DecreaseStockRequest) Reset() {
 	*x = DecreaseStockRequest{}
-	mi := &file_proto_product_product_proto_msgTypes[4]
+	mi := &file_proto_product_product_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -299,7 +403,7 @@ func (x *DecreaseStockRequest) String() string {
 func (*DecreaseStockRequest) ProtoMessage() {}
 
 func (x *DecreaseStockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[4]
+	mi := &file_proto_product_product_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -312,7 +416,7 @@ func (x *DecreaseStockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DecreaseStockRequest.ProtoReflect.Descriptor instead.
 func (*DecreaseStockRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{4}
+	return file_proto_product_product_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *DecreaseStockRequest) GetProductId() uint64 {
@@ -340,7 +444,7 @@ type DecreaseStockResponse struct {
 
 func (x *DecreaseStockResponse) Reset() {
 	*x = DecreaseStockResponse{}
-	mi := &file_proto_product_product_proto_msgTypes[5]
+	mi := &file_proto_product_product_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -352,7 +456,7 @@ func (x *DecreaseStockResponse) String() string {
 func (*DecreaseStockResponse) ProtoMessage() {}
 
 func (x *DecreaseStockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[5]
+	mi := &file_proto_product_product_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -365,7 +469,7 @@ func (x *DecreaseStockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DecreaseStockResponse.ProtoReflect.Descriptor instead.
 func (*DecreaseStockResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{5}
+	return file_proto_product_product_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DecreaseStockResponse) GetSuccess() bool {
@@ -389,6 +493,118 @@ func (x *DecreaseStockResponse) GetErrorMessage() string {
 	return ""
 }
 
+type IncreaseStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncreaseStockRequest) Reset() {
+	*x = IncreaseStockRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncreaseStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncreaseStockRequest) ProtoMessage() {}
+
+func (x *IncreaseStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncreaseStockRequest.ProtoReflect.Descriptor instead.
+func (*IncreaseStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *IncreaseStockRequest) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *IncreaseStockRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type IncreaseStockResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	RemainingStock int32                  `protobuf:"varint,2,opt,name=remaining_stock,json=remainingStock,proto3" json:"remaining_stock,omitempty"`
+	ErrorMessage   string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *IncreaseStockResponse) Reset() {
+	*x = IncreaseStockResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncreaseStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncreaseStockResponse) ProtoMessage() {}
+
+func (x *IncreaseStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncreaseStockResponse.ProtoReflect.Descriptor instead.
+func (*IncreaseStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *IncreaseStockResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *IncreaseStockResponse) GetRemainingStock() int32 {
+	if x != nil {
+		return x.RemainingStock
+	}
+	return 0
+}
+
+func (x *IncreaseStockResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
 var File_proto_product_product_proto protoreflect.FileDescriptor
 
 const file_proto_product_product_proto_rawDesc = "" +
@@ -396,7 +612,7 @@ const file_proto_product_product_proto_rawDesc = "" +
 	"\x1bproto/product/product.proto\x12\aproduct\"2\n" +
 	"\x11GetProductRequest\x12\x1d\n" +
 	"\n" +
-	"product_id\x18\x01 \x01(\x04R\tproductId\"\xff\x01\n" +
+	"product_id\x18\x01 \x01(\x04R\tproductId\"\xc5\x02\n" +
 	"\x12GetProductResponse\x12\x14\n" +
 	"\x05found\x18\x01 \x01(\bR\x05found\x12\x0e\n" +
 	"\x02id\x18\x02 \x01(\x04R\x02id\x12\x12\n" +
@@ -407,7 +623,15 @@ const file_proto_product_product_proto_rawDesc = "" +
 	"\vcategory_id\x18\a \x01(\x04R\n" +
 	"categoryId\x12#\n" +
 	"\rcategory_name\x18\b \x01(\tR\fcategoryName\x12\x1b\n" +
-	"\tis_active\x18\t \x01(\bR\bisActive\"2\n" +
+	"\tis_active\x18\t \x01(\bR\bisActive\x12!\n" +
+	"\fweight_grams\x18\n" +
+	" \x01(\x05R\vweightGrams\x12!\n" +
+	"\ftax_category\x18\v \x01(\tR\vtaxCategory\"5\n" +
+	"\x12GetProductsRequest\x12\x1f\n" +
+	"\vproduct_ids\x18\x01 \x03(\x04R\n" +
+	"productIds\"N\n" +
+	"\x13GetProductsResponse\x127\n" +
+	"\bproducts\x18\x01 \x03(\v2\x1b.product.GetProductResponseR\bproducts\"2\n" +
 	"\x11CheckStockRequest\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\x04R\tproductId\"e\n" +
@@ -422,13 +646,23 @@ const file_proto_product_product_proto_rawDesc = "" +
 	"\x15DecreaseStockResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12'\n" +
 	"\x0fremaining_stock\x18\x02 \x01(\x05R\x0eremainingStock\x12#\n" +
-	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage2\xee\x01\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"Q\n" +
+	"\x14IncreaseStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x04R\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x7f\n" +
+	"\x15IncreaseStockResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12'\n" +
+	"\x0fremaining_stock\x18\x02 \x01(\x05R\x0eremainingStock\x12#\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage2\x88\x03\n" +
 	"\x0eProductService\x12E\n" +
 	"\n" +
-	"GetProduct\x12\x1a.product.GetProductRequest\x1a\x1b.product.GetProductResponse\x12E\n" +
+	"GetProduct\x12\x1a.product.GetProductRequest\x1a\x1b.product.GetProductResponse\x12H\n" +
+	"\vGetProducts\x12\x1b.product.GetProductsRequest\x1a\x1c.product.GetProductsResponse\x12E\n" +
 	"\n" +
 	"CheckStock\x12\x1a.product.CheckStockRequest\x1a\x1b.product.CheckStockResponse\x12N\n" +
-	"\rDecreaseStock\x12\x1d.product.DecreaseStockRequest\x1a\x1e.product.DecreaseStockResponseBAZ?github.com/herman-xphp/go-microservices-ecommerce/proto/productb\x06proto3"
+	"\rDecreaseStock\x12\x1d.product.DecreaseStockRequest\x1a\x1e.product.DecreaseStockResponse\x12N\n" +
+	"\rIncreaseStock\x12\x1d.product.IncreaseStockRequest\x1a\x1e.product.IncreaseStockResponseBAZ?github.com/herman-xphp/go-microservices-ecommerce/proto/productb\x06proto3"
 
 var (
 	file_proto_product_product_proto_rawDescOnce sync.Once
@@ -442,27 +676,36 @@ func file_proto_product_product_proto_rawDescGZIP() []byte {
 	return file_proto_product_product_proto_rawDescData
 }
 
-var file_proto_product_product_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_product_product_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_proto_product_product_proto_goTypes = []any{
 	(*GetProductRequest)(nil),     // 0: product.GetProductRequest
 	(*GetProductResponse)(nil),    // 1: product.GetProductResponse
-	(*CheckStockRequest)(nil),     // 2: product.CheckStockRequest
-	(*CheckStockResponse)(nil),    // 3: product.CheckStockResponse
-	(*DecreaseStockRequest)(nil),  // 4: product.DecreaseStockRequest
-	(*DecreaseStockResponse)(nil), // 5: product.DecreaseStockResponse
+	(*GetProductsRequest)(nil),    // 2: product.GetProductsRequest
+	(*GetProductsResponse)(nil),   // 3: product.GetProductsResponse
+	(*CheckStockRequest)(nil),     // 4: product.CheckStockRequest
+	(*CheckStockResponse)(nil),    // 5: product.CheckStockResponse
+	(*DecreaseStockRequest)(nil),  // 6: product.DecreaseStockRequest
+	(*DecreaseStockResponse)(nil), // 7: product.DecreaseStockResponse
+	(*IncreaseStockRequest)(nil),  // 8: product.IncreaseStockRequest
+	(*IncreaseStockResponse)(nil), // 9: product.IncreaseStockResponse
 }
 var file_proto_product_product_proto_depIdxs = []int32{
-	0, // 0: product.ProductService.GetProduct:input_type -> product.GetProductRequest
-	2, // 1: product.ProductService.CheckStock:input_type -> product.CheckStockRequest
-	4, // 2: product.ProductService.DecreaseStock:input_type -> product.DecreaseStockRequest
-	1, // 3: product.ProductService.GetProduct:output_type -> product.GetProductResponse
-	3, // 4: product.ProductService.CheckStock:output_type -> product.CheckStockResponse
-	5, // 5: product.ProductService.DecreaseStock:output_type -> product.DecreaseStockResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: product.GetProductsResponse.products:type_name -> product.GetProductResponse
+	0, // 1: product.ProductService.GetProduct:input_type -> product.GetProductRequest
+	2, // 2: product.ProductService.GetProducts:input_type -> product.GetProductsRequest
+	4, // 3: product.ProductService.CheckStock:input_type -> product.CheckStockRequest
+	6, // 4: product.ProductService.DecreaseStock:input_type -> product.DecreaseStockRequest
+	8, // 5: product.ProductService.IncreaseStock:input_type -> product.IncreaseStockRequest
+	1, // 6: product.ProductService.GetProduct:output_type -> product.GetProductResponse
+	3, // 7: product.ProductService.GetProducts:output_type -> product.GetProductsResponse
+	5, // 8: product.ProductService.CheckStock:output_type -> product.CheckStockResponse
+	7, // 9: product.ProductService.DecreaseStock:output_type -> product.DecreaseStockResponse
+	9, // 10: product.ProductService.IncreaseStock:output_type -> product.IncreaseStockResponse
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_proto_product_product_proto_init() }
@@ -476,7 +719,7 @@ func file_proto_product_product_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_product_product_proto_rawDesc), len(file_proto_product_product_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
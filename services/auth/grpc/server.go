@@ -46,10 +46,11 @@ func (s *AuthGRPCServer) GetUserById(ctx context.Context, req *pb.GetUserByIdReq
 	}
 
 	return &pb.GetUserByIdResponse{
-		Found:  true,
-		UserId: uint64(user.ID),
-		Email:  user.Email,
-		Name:   user.Name,
-		Role:   user.Role,
+		Found:     true,
+		UserId:    uint64(user.ID),
+		Email:     user.Email,
+		Name:      user.Name,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt.Unix(),
 	}, nil
 }
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/herman-xphp/go-microservices-ecommerce/proto/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// AuthClientImpl implements service.UserClient using gRPC
+type AuthClientImpl struct {
+	conn   *grpc.ClientConn
+	client pb.AuthServiceClient
+}
+
+// NewAuthClient creates a new gRPC client for Auth Service
+func NewAuthClient(address string) (*AuthClientImpl, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthClientImpl{
+		conn:   conn,
+		client: pb.NewAuthServiceClient(conn),
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (c *AuthClientImpl) Close() error {
+	return c.conn.Close()
+}
+
+// GetUser fetches a user by ID. It returns nil if the user does not exist.
+func (c *AuthClientImpl) GetUser(ctx context.Context, userID uint) (*service.UserInfo, error) {
+	resp, err := c.client.GetUserById(ctx, &pb.GetUserByIdRequest{UserId: uint64(userID)})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Found {
+		return nil, nil
+	}
+
+	return &service.UserInfo{
+		ID:    uint(resp.UserId),
+		Email: resp.Email,
+		Name:  resp.Name,
+	}, nil
+}
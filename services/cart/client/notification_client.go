@@ -0,0 +1,69 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/service"
+)
+
+// NotificationClient implements service.Notifier using the Notification Service HTTP API
+type NotificationClient struct {
+	baseURL    string
+	httpClient *http.Client
+	signer     *auth.Signer
+}
+
+// NewNotificationClient creates a new HTTP client for Notification Service.
+// Requests are made as this service, signed with signer.
+func NewNotificationClient(baseURL string, signer *auth.Signer) *NotificationClient {
+	return &NotificationClient{
+		baseURL: baseURL,
+		signer:  signer,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// SendEmail asks Notification Service to render the email's template and send it
+func (c *NotificationClient) SendEmail(ctx context.Context, email *service.ReminderEmail) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"user_id":     email.UserID,
+		"to":          email.To,
+		"template_id": email.TemplateID,
+		"variables":   email.Variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/notifications/email", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.signer.Sign(req, auth.ServiceIdentity); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var envelope struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&envelope)
+		return fmt.Errorf("notification service: %d %s", resp.StatusCode, envelope.Message)
+	}
+	return nil
+}
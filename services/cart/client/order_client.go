@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/herman-xphp/go-microservices-ecommerce/proto/order"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// OrderClientImpl implements service.OrderClient using gRPC
+type OrderClientImpl struct {
+	conn   *grpc.ClientConn
+	client pb.OrderServiceClient
+}
+
+// NewOrderClient creates a new gRPC client for Order Service
+func NewOrderClient(address string) (*OrderClientImpl, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderClientImpl{
+		conn:   conn,
+		client: pb.NewOrderServiceClient(conn),
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (c *OrderClientImpl) Close() error {
+	return c.conn.Close()
+}
+
+// PreviewDiscount asks Order Service what a coupon takes off the given items
+func (c *OrderClientImpl) PreviewDiscount(ctx context.Context, userID uint, code string, items []domain.CartItem) (*service.CouponPreview, error) {
+	req := &pb.PreviewDiscountRequest{
+		UserId:     uint64(userID),
+		CouponCode: code,
+		Items:      make([]*pb.DiscountItem, len(items)),
+	}
+	for i, item := range items {
+		req.Items[i] = &pb.DiscountItem{ProductId: uint64(item.ProductID), Quantity: int32(item.Quantity)}
+	}
+
+	resp, err := c.client.PreviewDiscount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.CouponPreview{
+		Valid:        resp.Valid,
+		Reason:       resp.ErrorMessage,
+		Code:         resp.Code,
+		Name:         resp.Name,
+		Type:         resp.Type,
+		ItemsAmount:  resp.ItemsAmount,
+		FreeShipping: resp.FreeShipping,
+	}, nil
+}
+
+// LastOrderAt returns when the user last placed an order, or nil if they never did
+func (c *OrderClientImpl) LastOrderAt(ctx context.Context, userID uint) (*time.Time, error) {
+	resp, err := c.client.GetLastOrderTime(ctx, &pb.GetLastOrderTimeRequest{UserId: uint64(userID)})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Found {
+		return nil, nil
+	}
+
+	at := time.Unix(resp.CreatedAt, 0)
+	return &at, nil
+}
@@ -54,11 +54,39 @@ func (c *ProductClientImpl) GetProduct(ctx context.Context, productID uint) (*se
 	}
 
 	return &service.ProductInfo{
-		ID:       uint(resp.Id),
-		Name:     resp.Name,
-		Price:    resp.Price,
-		Stock:    int(resp.Stock),
-		ImageURL: "", // ImageURL not in proto
-		IsActive: resp.IsActive,
+		ID:          uint(resp.Id),
+		Name:        resp.Name,
+		Price:       resp.Price,
+		Stock:       int(resp.Stock),
+		ImageURL:    "", // ImageURL not in proto
+		IsActive:    resp.IsActive,
+		TaxCategory: resp.TaxCategory,
 	}, nil
 }
+
+// GetProducts fetches product info for several IDs in one call, keyed by ID.
+// Products that no longer exist are left out.
+func (c *ProductClientImpl) GetProducts(ctx context.Context, productIDs []uint) (map[uint]*service.ProductInfo, error) {
+	ids := make([]uint64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = uint64(id)
+	}
+
+	resp, err := c.client.GetProducts(ctx, &pb.GetProductsRequest{ProductIds: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[uint]*service.ProductInfo, len(resp.Products))
+	for _, product := range resp.Products {
+		products[uint(product.Id)] = &service.ProductInfo{
+			ID:          uint(product.Id),
+			Name:        product.Name,
+			Price:       product.Price,
+			Stock:       int(product.Stock),
+			IsActive:    product.IsActive,
+			TaxCategory: product.TaxCategory,
+		}
+	}
+	return products, nil
+}
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+)
+
 // CartItem represents an item in the shopping cart
 type CartItem struct {
 	ProductID   uint    `json:"product_id"`
@@ -7,31 +12,83 @@ type CartItem struct {
 	Price       float64 `json:"price"`
 	Quantity    int     `json:"quantity"`
 	ImageURL    string  `json:"image_url,omitempty"`
+	TaxCategory string  `json:"tax_category,omitempty"` // Empty is the standard category
+	// Unavailable lines are out of stock or discontinued. They stay in the cart
+	// but don't count towards its totals until the product is back.
+	Unavailable bool `json:"unavailable,omitempty"`
+}
+
+// CartOwner identifies a cart: a signed-in user's, or an anonymous visitor's by their guest session id
+type CartOwner struct {
+	UserID  uint
+	GuestID string
+}
+
+// UserCart returns the owner of a user's cart
+func UserCart(userID uint) CartOwner {
+	return CartOwner{UserID: userID}
+}
+
+// GuestCart returns the owner of a guest session's cart
+func GuestCart(guestID string) CartOwner {
+	return CartOwner{GuestID: guestID}
+}
+
+// IsGuest reports whether the cart belongs to an anonymous visitor
+func (o CartOwner) IsGuest() bool {
+	return o.UserID == 0
+}
+
+// String identifies the owner, e.g. in storage keys
+func (o CartOwner) String() string {
+	if o.IsGuest() {
+		return "guest:" + o.GuestID
+	}
+	return fmt.Sprintf("%d", o.UserID)
 }
 
-// Cart represents a user's shopping cart
+// Cart represents a user's or guest's shopping cart
 type Cart struct {
 	UserID     uint       `json:"user_id"`
+	GuestID    string     `json:"guest_id,omitempty"`
 	Items      []CartItem `json:"items"`
 	TotalItems int        `json:"total_items"`
 	TotalPrice float64    `json:"total_price"`
+	CouponCode string     `json:"coupon_code,omitempty"` // Priced by Order Service whenever the cart is shown
+	// Version increases with every change; clients send it back in If-Match to avoid overwriting changes they have not seen
+	Version int64 `json:"version"`
+	// StartedAt is when the first item went into the cart since it was last empty, and UpdatedAt when it last changed
+	StartedAt time.Time `json:"started_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
 
+// AnyVersion updates a cart whatever its version
+const AnyVersion int64 = -1
+
 // CalculateTotals calculates total items and price
 func (c *Cart) CalculateTotals() {
 	c.TotalItems = 0
 	c.TotalPrice = 0
 	for _, item := range c.Items {
+		if item.Unavailable {
+			continue
+		}
 		c.TotalItems += item.Quantity
 		c.TotalPrice += item.Price * float64(item.Quantity)
 	}
 }
 
+// Owner returns who the cart belongs to
+func (c *Cart) Owner() CartOwner {
+	return CartOwner{UserID: c.UserID, GuestID: c.GuestID}
+}
+
 // AddItem adds an item to the cart or updates quantity if exists
 func (c *Cart) AddItem(item CartItem) {
 	for i, existing := range c.Items {
 		if existing.ProductID == item.ProductID {
 			c.Items[i].Quantity += item.Quantity
+			c.Items[i].Unavailable = item.Unavailable
 			c.CalculateTotals()
 			return
 		}
@@ -68,9 +125,123 @@ func (c *Cart) RemoveItem(productID uint) bool {
 	return false
 }
 
-// Clear removes all items from the cart
+// Clear removes all items and the coupon from the cart
 func (c *Cart) Clear() {
 	c.Items = []CartItem{}
+	c.CouponCode = ""
 	c.TotalItems = 0
 	c.TotalPrice = 0
 }
+
+// Merge moves the items of a guest cart into this cart. When both carts hold a product,
+// the larger quantity wins rather than the sum: shoppers who add an item while signed out
+// usually mean the one already in their cart, not a second one. The guest line's details
+// are kept as they are the more recent. The guest's coupon is kept if the cart has none.
+func (c *Cart) Merge(guest *Cart) {
+	if c.CouponCode == "" {
+		c.CouponCode = guest.CouponCode
+	}
+	for _, item := range guest.Items {
+		merged := false
+		for i, existing := range c.Items {
+			if existing.ProductID == item.ProductID {
+				item.Quantity = max(item.Quantity, existing.Quantity)
+				c.Items[i] = item
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			c.Items = append(c.Items, item)
+		}
+	}
+	c.CalculateTotals()
+}
+
+// ProductState is a product's current price and availability in the catalogue
+type ProductState struct {
+	Price       float64
+	Stock       int
+	IsActive    bool
+	TaxCategory string
+}
+
+// WarningCode identifies why a cart line needs the shopper's attention
+type WarningCode string
+
+const (
+	WarningPriceChanged    WarningCode = "price_changed"
+	WarningQuantityReduced WarningCode = "quantity_reduced"
+	WarningOutOfStock      WarningCode = "out_of_stock"
+	WarningDiscontinued    WarningCode = "discontinued"
+)
+
+// CartWarning describes how a cart line differs from what the shopper added
+type CartWarning struct {
+	ProductID   uint
+	ProductName string
+	Code        WarningCode
+	OldPrice    float64 // price_changed
+	NewPrice    float64 // price_changed
+	OldQuantity int     // quantity_reduced
+	NewQuantity int     // quantity_reduced
+}
+
+// Revalidate brings the cart in line with the catalogue: prices are refreshed, quantities are
+// clamped to the stock, and lines that are out of stock or discontinued are marked unavailable.
+// Products missing from products are treated as discontinued.
+// It returns a warning for every line the shopper should review, and whether the cart changed.
+// Unavailable lines are warned about on every call; other changes only when they are made.
+func (c *Cart) Revalidate(products map[uint]ProductState) ([]CartWarning, bool) {
+	var warnings []CartWarning
+	changed := false
+	for i := range c.Items {
+		item := &c.Items[i]
+		product, found := products[item.ProductID]
+
+		var unavailable WarningCode
+		switch {
+		case !found || !product.IsActive:
+			unavailable = WarningDiscontinued
+		case product.Stock <= 0:
+			unavailable = WarningOutOfStock
+		}
+		if unavailable != "" {
+			warnings = append(warnings, CartWarning{ProductID: item.ProductID, ProductName: item.ProductName, Code: unavailable})
+			if !item.Unavailable {
+				item.Unavailable = true
+				changed = true
+			}
+			continue
+		}
+		if item.Unavailable {
+			item.Unavailable = false
+			changed = true
+		}
+
+		if item.Price != product.Price {
+			warnings = append(warnings, CartWarning{
+				ProductID: item.ProductID, ProductName: item.ProductName, Code: WarningPriceChanged,
+				OldPrice: item.Price, NewPrice: product.Price,
+			})
+			item.Price = product.Price
+			changed = true
+		}
+		if product.TaxCategory != "" && item.TaxCategory != product.TaxCategory {
+			item.TaxCategory = product.TaxCategory
+			changed = true
+		}
+		if item.Quantity > product.Stock {
+			warnings = append(warnings, CartWarning{
+				ProductID: item.ProductID, ProductName: item.ProductName, Code: WarningQuantityReduced,
+				OldQuantity: item.Quantity, NewQuantity: product.Stock,
+			})
+			item.Quantity = product.Stock
+			changed = true
+		}
+	}
+	if changed {
+		c.CalculateTotals()
+	}
+	return warnings, changed
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCart_Merge(t *testing.T) {
+	// Arrange
+	cart := &Cart{UserID: 7, Items: []CartItem{
+		{ProductID: 1, ProductName: "Kopi", Price: 50_000, Quantity: 2},
+		{ProductID: 2, ProductName: "Teh", Price: 20_000, Quantity: 1},
+	}}
+	guest := &Cart{GuestID: "abc", CouponCode: "HEMAT10", Items: []CartItem{
+		{ProductID: 2, ProductName: "Teh", Price: 22_000, Quantity: 3},
+		{ProductID: 1, ProductName: "Kopi", Price: 50_000, Quantity: 1},
+		{ProductID: 3, ProductName: "Gula", Price: 15_000, Quantity: 1},
+	}}
+
+	// Act
+	cart.Merge(guest)
+
+	// Assert: the larger quantity wins and the guest's price is kept
+	assert.Equal(t, []CartItem{
+		{ProductID: 1, ProductName: "Kopi", Price: 50_000, Quantity: 2},
+		{ProductID: 2, ProductName: "Teh", Price: 22_000, Quantity: 3},
+		{ProductID: 3, ProductName: "Gula", Price: 15_000, Quantity: 1},
+	}, cart.Items)
+	assert.Equal(t, 6, cart.TotalItems)
+	assert.Equal(t, 181_000.0, cart.TotalPrice)
+	assert.Equal(t, UserCart(7), cart.Owner())
+	assert.Equal(t, "HEMAT10", cart.CouponCode)
+}
+
+func TestCartOwner_String(t *testing.T) {
+	assert.Equal(t, "7", UserCart(7).String())
+	assert.Equal(t, "guest:abc", GuestCart("abc").String())
+	assert.True(t, GuestCart("abc").IsGuest())
+	assert.False(t, UserCart(7).IsGuest())
+}
+
+func TestCart_Revalidate(t *testing.T) {
+	// Arrange
+	cart := &Cart{UserID: 7, Items: []CartItem{
+		{ProductID: 1, ProductName: "Kopi", Price: 50_000, Quantity: 2},
+		{ProductID: 2, ProductName: "Teh", Price: 20_000, Quantity: 5},
+		{ProductID: 3, ProductName: "Gula", Price: 15_000, Quantity: 1},
+		{ProductID: 4, ProductName: "Susu", Price: 18_000, Quantity: 1},
+		{ProductID: 5, ProductName: "Roti", Price: 12_000, Quantity: 1},
+	}}
+	products := map[uint]ProductState{
+		1: {Price: 55_000, Stock: 10, IsActive: true},
+		2: {Price: 20_000, Stock: 3, IsActive: true},
+		3: {Price: 15_000, Stock: 0, IsActive: true},
+		4: {Price: 18_000, Stock: 8, IsActive: false},
+		// 5 was deleted from the catalogue
+	}
+
+	// Act
+	warnings, changed := cart.Revalidate(products)
+
+	// Assert
+	assert.True(t, changed)
+	assert.Equal(t, []CartWarning{
+		{ProductID: 1, ProductName: "Kopi", Code: WarningPriceChanged, OldPrice: 50_000, NewPrice: 55_000},
+		{ProductID: 2, ProductName: "Teh", Code: WarningQuantityReduced, OldQuantity: 5, NewQuantity: 3},
+		{ProductID: 3, ProductName: "Gula", Code: WarningOutOfStock},
+		{ProductID: 4, ProductName: "Susu", Code: WarningDiscontinued},
+		{ProductID: 5, ProductName: "Roti", Code: WarningDiscontinued},
+	}, warnings)
+	assert.Equal(t, 55_000.0, cart.Items[0].Price)
+	assert.Equal(t, 3, cart.Items[1].Quantity)
+	assert.True(t, cart.Items[2].Unavailable)
+	assert.Equal(t, 1, cart.Items[2].Quantity)
+	assert.Equal(t, 5, cart.TotalItems)
+	assert.Equal(t, 170_000.0, cart.TotalPrice)
+
+	// Act: unavailable lines are still flagged, corrections aren't repeated
+	warnings, changed = cart.Revalidate(products)
+
+	// Assert
+	assert.False(t, changed)
+	assert.Len(t, warnings, 3)
+
+	// Act: back in stock
+	products[3] = ProductState{Price: 15_000, Stock: 4, IsActive: true}
+	_, changed = cart.Revalidate(products)
+
+	// Assert
+	assert.True(t, changed)
+	assert.False(t, cart.Items[2].Unavailable)
+	assert.Equal(t, 185_000.0, cart.TotalPrice)
+}
@@ -0,0 +1,128 @@
+package domain
+
+import "time"
+
+// ListKind tells apart the lists a user keeps besides their cart
+type ListKind string
+
+const (
+	// ListKindSaveForLater is the list items moved out of the cart go to; every user has exactly one
+	ListKindSaveForLater ListKind = "save_for_later"
+	ListKindWishlist     ListKind = "wishlist"
+)
+
+// SaveForLaterListID is the id of the user's save-for-later list
+const SaveForLaterListID = "save-for-later"
+
+// ListItem is a product kept in a list. Price and InStock are as last seen in the catalogue.
+type ListItem struct {
+	ProductID   uint    `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	ImageURL    string  `json:"image_url,omitempty"`
+	Quantity    int     `json:"quantity"`
+	AddedPrice  float64 `json:"added_price"` // The baseline for price drops
+	Price       float64 `json:"price"`
+	InStock     bool    `json:"in_stock"`
+	// BackInStock is set when a product seen out of stock becomes available again, and
+	// PriceDropped while the price is below AddedPrice. They are kept so notifications can use them.
+	BackInStock  bool      `json:"back_in_stock,omitempty"`
+	PriceDropped bool      `json:"price_dropped,omitempty"`
+	AddedAt      time.Time `json:"added_at"`
+}
+
+// List is a named list of products a signed-in user keeps, such as a wishlist
+type List struct {
+	ID        string     `json:"id"`
+	UserID    uint       `json:"user_id"`
+	Name      string     `json:"name"`
+	Kind      ListKind   `json:"kind"`
+	Items     []ListItem `json:"items"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// NewSaveForLaterList returns the user's save-for-later list before anything is saved to it
+func NewSaveForLaterList(userID uint, now time.Time) *List {
+	return &List{
+		ID:        SaveForLaterListID,
+		UserID:    userID,
+		Name:      "Saved for later",
+		Kind:      ListKindSaveForLater,
+		Items:     []ListItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// FindItem returns the list's item for a product
+func (l *List) FindItem(productID uint) (ListItem, bool) {
+	for _, item := range l.Items {
+		if item.ProductID == productID {
+			return item, true
+		}
+	}
+	return ListItem{}, false
+}
+
+// AddItem adds an item to the list. A product already in the list gets the added quantity
+// and fresh details, but keeps the price it was first added at.
+func (l *List) AddItem(item ListItem) {
+	for i := range l.Items {
+		existing := &l.Items[i]
+		if existing.ProductID == item.ProductID {
+			existing.Quantity += item.Quantity
+			existing.ProductName = item.ProductName
+			existing.ImageURL = item.ImageURL
+			existing.Price = item.Price
+			existing.InStock = item.InStock
+			existing.PriceDropped = existing.Price < existing.AddedPrice
+			return
+		}
+	}
+	item.AddedPrice = item.Price
+	l.Items = append(l.Items, item)
+}
+
+// RemoveItem removes a product from the list, reporting whether it was there
+func (l *List) RemoveItem(productID uint) bool {
+	for i, item := range l.Items {
+		if item.ProductID == productID {
+			l.Items = append(l.Items[:i], l.Items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// TotalItems returns the number of units in the list
+func (l *List) TotalItems() int {
+	total := 0
+	for _, item := range l.Items {
+		total += item.Quantity
+	}
+	return total
+}
+
+// Refresh updates the items from the catalogue and sets their back-in-stock and price-drop flags.
+// Products missing from products are treated as unavailable. It reports whether anything changed.
+func (l *List) Refresh(products map[uint]ProductState) bool {
+	changed := false
+	for i := range l.Items {
+		item := &l.Items[i]
+		before := *item
+
+		product, found := products[item.ProductID]
+		inStock := found && product.IsActive && product.Stock > 0
+		if found {
+			item.Price = product.Price
+		}
+		item.BackInStock = inStock && (item.BackInStock || !item.InStock)
+		item.InStock = inStock
+		item.PriceDropped = item.Price < item.AddedPrice
+
+		if *item != before {
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList_AddItem_KeepsFirstPrice(t *testing.T) {
+	// Arrange
+	list := NewSaveForLaterList(7, time.Now())
+	list.AddItem(ListItem{ProductID: 1, ProductName: "Kopi", Price: 50_000, Quantity: 1, InStock: true})
+
+	// Act
+	list.AddItem(ListItem{ProductID: 1, ProductName: "Kopi Arabika", Price: 45_000, Quantity: 2, InStock: true})
+
+	// Assert
+	assert.Len(t, list.Items, 1)
+	item := list.Items[0]
+	assert.Equal(t, 3, item.Quantity)
+	assert.Equal(t, "Kopi Arabika", item.ProductName)
+	assert.Equal(t, 50_000.0, item.AddedPrice)
+	assert.Equal(t, 45_000.0, item.Price)
+	assert.True(t, item.PriceDropped)
+}
+
+func TestList_Refresh(t *testing.T) {
+	// Arrange
+	list := &List{Items: []ListItem{
+		{ProductID: 1, AddedPrice: 50_000, Price: 50_000, InStock: true},
+		{ProductID: 2, AddedPrice: 20_000, Price: 20_000, InStock: false},
+		{ProductID: 3, AddedPrice: 15_000, Price: 12_000, InStock: true, PriceDropped: true},
+		{ProductID: 4, AddedPrice: 30_000, Price: 30_000, InStock: true},
+	}}
+	products := map[uint]ProductState{
+		1: {Price: 45_000, Stock: 10, IsActive: true}, // price drop
+		2: {Price: 20_000, Stock: 5, IsActive: true},  // back in stock
+		3: {Price: 16_000, Stock: 0, IsActive: true},  // out of stock, no longer cheaper
+		// 4 is discontinued
+	}
+
+	// Act
+	changed := list.Refresh(products)
+
+	// Assert
+	assert.True(t, changed)
+	assert.Equal(t, []ListItem{
+		{ProductID: 1, AddedPrice: 50_000, Price: 45_000, InStock: true, PriceDropped: true},
+		{ProductID: 2, AddedPrice: 20_000, Price: 20_000, InStock: true, BackInStock: true},
+		{ProductID: 3, AddedPrice: 15_000, Price: 16_000, InStock: false},
+		{ProductID: 4, AddedPrice: 30_000, Price: 30_000, InStock: false},
+	}, list.Items)
+
+	// The back-in-stock flag stays while the product is available
+	assert.False(t, list.Refresh(products))
+	assert.True(t, list.Items[1].BackInStock)
+}
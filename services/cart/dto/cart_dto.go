@@ -19,14 +19,65 @@ type CartItemResponse struct {
 	Quantity    int     `json:"quantity"`
 	Subtotal    float64 `json:"subtotal"`
 	ImageURL    string  `json:"image_url,omitempty"`
+	// Unavailable lines are out of stock or discontinued and left out of the totals
+	Unavailable bool `json:"unavailable,omitempty"`
+}
+
+// ApplyCouponRequest represents applying a coupon code to the cart
+type ApplyCouponRequest struct {
+	Code string `json:"code" binding:"required,max=50"`
+}
+
+// CartDiscountResponse represents a coupon discount on the cart
+type CartDiscountResponse struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Type        string  `json:"type"`
+	Amount      float64 `json:"amount"`
+	// FreeShipping discounts are taken off the shipping cost at checkout
+	FreeShipping bool `json:"free_shipping,omitempty"`
+}
+
+// CartWarningResponse tells the shopper how a cart line, or the coupon, changed since they added it
+type CartWarningResponse struct {
+	ProductID   uint    `json:"product_id,omitempty"`
+	Code        string  `json:"code"`
+	Message     string  `json:"message"`
+	OldPrice    float64 `json:"old_price,omitempty"`
+	NewPrice    float64 `json:"new_price,omitempty"`
+	OldQuantity int     `json:"old_quantity,omitempty"`
+	NewQuantity int     `json:"new_quantity,omitempty"`
 }
 
 // CartResponse represents the cart in API responses
 type CartResponse struct {
-	UserID     uint               `json:"user_id"`
-	Items      []CartItemResponse `json:"items"`
-	TotalItems int                `json:"total_items"`
-	TotalPrice float64            `json:"total_price"`
+	UserID     uint                   `json:"user_id"`
+	Items      []CartItemResponse     `json:"items"`
+	TotalItems int                    `json:"total_items"`
+	TotalPrice float64                `json:"total_price"`
+	CouponCode string                 `json:"coupon_code,omitempty"`
+	Discounts  []CartDiscountResponse `json:"discounts,omitempty"`
+	// DiscountTotal is taken off TotalPrice; shipping discounts are only known at checkout
+	DiscountTotal      float64 `json:"discount_total"`
+	TotalAfterDiscount float64 `json:"total_after_discount"`
+	// Taxes are estimated for the default tax region; the order is taxed by its shipping address
+	Taxes        []CartTaxResponse `json:"taxes,omitempty"`
+	TaxTotal     float64           `json:"tax_total"`
+	TaxInclusive bool              `json:"tax_inclusive"` // Tax is contained in the prices rather than added on top
+	// GrandTotal is what the cart costs before shipping: TotalAfterDiscount plus any tax added on top
+	GrandTotal float64 `json:"grand_total"`
+	Version    int64   `json:"version"`
+	// Warnings list lines that changed since the last time the cart was checked against the catalogue
+	Warnings []CartWarningResponse `json:"warnings,omitempty"`
+}
+
+// CartTaxResponse is the total of one tax over the cart
+type CartTaxResponse struct {
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	Amount        float64 `json:"amount"`
 }
 
 // CheckoutRequest represents a checkout request
@@ -34,3 +85,8 @@ type CheckoutRequest struct {
 	ShippingAddress string `json:"shipping_address" binding:"required"`
 	PaymentMethod   string `json:"payment_method" binding:"required"`
 }
+
+// ReminderPreferenceRequest represents turning abandoned cart reminders on or off
+type ReminderPreferenceRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
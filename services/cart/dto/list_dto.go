@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// CreateListRequest represents creating a wishlist
+type CreateListRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// RenameListRequest represents renaming a list
+type RenameListRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// AddListItemRequest represents adding a product to a list
+type AddListItemRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"omitempty,min=1"` // Defaults to 1
+}
+
+// MoveFromCartRequest represents moving a cart line to a list
+type MoveFromCartRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+}
+
+// ListItemResponse represents a list item in responses
+type ListItemResponse struct {
+	ProductID    uint      `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	ImageURL     string    `json:"image_url,omitempty"`
+	Quantity     int       `json:"quantity"`
+	Price        float64   `json:"price"`
+	AddedPrice   float64   `json:"added_price"`
+	InStock      bool      `json:"in_stock"`
+	BackInStock  bool      `json:"back_in_stock"`
+	PriceDropped bool      `json:"price_dropped"`
+	AddedAt      time.Time `json:"added_at"`
+}
+
+// ListResponse represents a list in API responses
+type ListResponse struct {
+	ID         string             `json:"id"`
+	UserID     uint               `json:"user_id"`
+	Name       string             `json:"name"`
+	Kind       string             `json:"kind"`
+	Items      []ListItemResponse `json:"items"`
+	TotalItems int                `json:"total_items"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// MoveItemResponse represents the cart and the list after moving an item between them
+type MoveItemResponse struct {
+	Cart *CartResponse `json:"cart"`
+	List *ListResponse `json:"list"`
+}
@@ -1,22 +1,38 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/repository"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/service"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/session"
 )
 
-// CartHandler handles HTTP requests for cart operations
+// guestCookiePath limits the guest session cookie to cart requests
+const guestCookiePath = "/api/v1/cart"
+
+// CartHandler handles HTTP requests for cart operations.
+// Anonymous visitors get a guest cart, identified by a signed session cookie.
 type CartHandler struct {
-	cartService service.CartService
+	cartService  service.CartService
+	guests       *session.GuestSessions
+	secureCookie bool // Only send the guest cookie over HTTPS
 }
 
 // NewCartHandler creates a new CartHandler
-func NewCartHandler(cartService service.CartService) *CartHandler {
-	return &CartHandler{cartService: cartService}
+func NewCartHandler(cartService service.CartService, guests *session.GuestSessions, secureCookie bool) *CartHandler {
+	return &CartHandler{
+		cartService:  cartService,
+		guests:       guests,
+		secureCookie: secureCookie,
+	}
 }
 
 // RegisterRoutes registers cart routes
@@ -24,38 +40,118 @@ func (h *CartHandler) RegisterRoutes(router *gin.RouterGroup) {
 	cart := router.Group("/cart")
 	{
 		cart.GET("", h.GetCart)
+		cart.POST("/validate", h.ValidateCart)
 		cart.POST("/items", h.AddToCart)
 		cart.PUT("/items/:product_id", h.UpdateItem)
 		cart.DELETE("/items/:product_id", h.RemoveItem)
 		cart.DELETE("", h.ClearCart)
+		cart.POST("/coupon", h.ApplyCoupon)
+		cart.DELETE("/coupon", h.RemoveCoupon)
 	}
 }
 
-func (h *CartHandler) getUserID(c *gin.Context) (uint, bool) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		if uid := c.GetHeader("X-User-ID"); uid != "" {
-			id, _ := strconv.ParseUint(uid, 10, 32)
-			return uint(id), true
+// getOwner returns the cart being acted on. Signed-in callers act on their own cart, or for staff
+// the user_id they name; a guest cart they built before signing in is merged into theirs first.
+// Anonymous callers act on the cart of their guest session, which is started if they have none.
+func (h *CartHandler) getOwner(c *gin.Context) (domain.CartOwner, bool) {
+	token, _ := c.Cookie(session.CookieName)
+	guestID, hasGuestCart := h.guests.Verify(token)
+
+	if identity, ok := auth.Current(c); ok {
+		userID, ok := auth.TargetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"message": "User not authenticated",
+			})
+			return domain.CartOwner{}, false
+		}
+		if hasGuestCart && userID == identity.UserID {
+			if err := h.cartService.MergeGuestCart(c.Request.Context(), userID, guestID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"message": err.Error(),
+				})
+				return domain.CartOwner{}, false
+			}
+			h.setGuestCookie(c, "", -1)
+		}
+		return domain.UserCart(userID), true
+	}
+
+	if !hasGuestCart {
+		var err error
+		guestID, token, err = h.guests.New()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return domain.CartOwner{}, false
 		}
-		c.JSON(http.StatusUnauthorized, gin.H{
+	}
+	// Refreshed on every request so the cookie lives as long as the cart
+	h.setGuestCookie(c, token, int(repository.GuestCartTTL.Seconds()))
+	return domain.GuestCart(guestID), true
+}
+
+// setGuestCookie sets the guest session cookie; a negative maxAge deletes it
+func (h *CartHandler) setGuestCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(session.CookieName, token, maxAge, guestCookiePath, "", h.secureCookie, true)
+}
+
+// ifMatchVersion reads the cart version the client expects from the If-Match header, e.g. "3".
+// Without the header, or with "*", the change applies to whatever version is stored.
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return domain.AnyVersion, true
+	}
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"message": "User not authenticated",
+			"message": "Invalid If-Match header",
 		})
 		return 0, false
 	}
-	return userID.(uint), true
+	return version, true
+}
+
+// setETag exposes the cart version for clients to send back in If-Match
+func setETag(c *gin.Context, cart *dto.CartResponse) {
+	c.Header("ETag", `"`+strconv.FormatInt(cart.Version, 10)+`"`)
+}
+
+// errorStatus maps cart service errors to HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrProductNotFound), errors.Is(err, service.ErrItemNotInCart):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrCartEmpty):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrProductUnavailable), errors.Is(err, service.ErrInsufficientStock):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrCouponInvalid):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, repository.ErrUpdateConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
 }
 
 // GetCart retrieves the user's cart
 // GET /api/v1/cart
 func (h *CartHandler) GetCart(c *gin.Context) {
-	userID, ok := h.getUserID(c)
+	owner, ok := h.getOwner(c)
 	if !ok {
 		return
 	}
 
-	cart, err := h.cartService.GetCart(c.Request.Context(), userID)
+	cart, err := h.cartService.GetCart(c.Request.Context(), owner)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -64,8 +160,44 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 		return
 	}
 
+	setETag(c, cart)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    cart,
+	})
+}
+
+// ValidateCart checks the cart against current prices and stock before checkout.
+// It responds 409 with the warnings when the shopper should review the cart first.
+// POST /api/v1/cart/validate
+func (h *CartHandler) ValidateCart(c *gin.Context) {
+	owner, ok := h.getOwner(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.ValidateCart(c.Request.Context(), owner)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setETag(c, cart)
+	if len(cart.Warnings) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": "Cart changed, please review it before checkout",
+			"data":    cart,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
+		"message": "Cart is ready for checkout",
 		"data":    cart,
 	})
 }
@@ -73,7 +205,11 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 // AddToCart adds an item to the cart
 // POST /api/v1/cart/items
 func (h *CartHandler) AddToCart(c *gin.Context) {
-	userID, ok := h.getUserID(c)
+	owner, ok := h.getOwner(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
 	if !ok {
 		return
 	}
@@ -88,19 +224,16 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 		return
 	}
 
-	cart, err := h.cartService.AddToCart(c.Request.Context(), userID, &req)
+	cart, err := h.cartService.AddToCart(c.Request.Context(), owner, version, &req)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrProductNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{
+		c.JSON(errorStatus(err), gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
 		return
 	}
 
+	setETag(c, cart)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Item added to cart",
@@ -111,7 +244,11 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 // UpdateItem updates item quantity in cart
 // PUT /api/v1/cart/items/:product_id
 func (h *CartHandler) UpdateItem(c *gin.Context) {
-	userID, ok := h.getUserID(c)
+	owner, ok := h.getOwner(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
 	if !ok {
 		return
 	}
@@ -135,19 +272,16 @@ func (h *CartHandler) UpdateItem(c *gin.Context) {
 		return
 	}
 
-	cart, err := h.cartService.UpdateItem(c.Request.Context(), userID, uint(productID), &req)
+	cart, err := h.cartService.UpdateItem(c.Request.Context(), owner, version, uint(productID), &req)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrItemNotInCart {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{
+		c.JSON(errorStatus(err), gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
 		return
 	}
 
+	setETag(c, cart)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Cart updated",
@@ -158,7 +292,11 @@ func (h *CartHandler) UpdateItem(c *gin.Context) {
 // RemoveItem removes an item from the cart
 // DELETE /api/v1/cart/items/:product_id
 func (h *CartHandler) RemoveItem(c *gin.Context) {
-	userID, ok := h.getUserID(c)
+	owner, ok := h.getOwner(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
 	if !ok {
 		return
 	}
@@ -172,19 +310,16 @@ func (h *CartHandler) RemoveItem(c *gin.Context) {
 		return
 	}
 
-	cart, err := h.cartService.RemoveItem(c.Request.Context(), userID, uint(productID))
+	cart, err := h.cartService.RemoveItem(c.Request.Context(), owner, version, uint(productID))
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrItemNotInCart {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{
+		c.JSON(errorStatus(err), gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
 		return
 	}
 
+	setETag(c, cart)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Item removed from cart",
@@ -195,21 +330,96 @@ func (h *CartHandler) RemoveItem(c *gin.Context) {
 // ClearCart clears all items from the cart
 // DELETE /api/v1/cart
 func (h *CartHandler) ClearCart(c *gin.Context) {
-	userID, ok := h.getUserID(c)
+	owner, ok := h.getOwner(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
 	if !ok {
 		return
 	}
 
-	if err := h.cartService.ClearCart(c.Request.Context(), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	cart, err := h.cartService.ClearCart(c.Request.Context(), owner, version)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
 		return
 	}
 
+	setETag(c, cart)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Cart cleared",
+		"data":    cart,
+	})
+}
+
+// ApplyCoupon applies a coupon code to the cart
+// POST /api/v1/cart/coupon
+func (h *CartHandler) ApplyCoupon(c *gin.Context) {
+	owner, ok := h.getOwner(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ApplyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	cart, err := h.cartService.ApplyCoupon(c.Request.Context(), owner, version, req.Code)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setETag(c, cart)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Coupon applied",
+		"data":    cart,
+	})
+}
+
+// RemoveCoupon removes the coupon from the cart
+// DELETE /api/v1/cart/coupon
+func (h *CartHandler) RemoveCoupon(c *gin.Context) {
+	owner, ok := h.getOwner(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.RemoveCoupon(c.Request.Context(), owner, version)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setETag(c, cart)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Coupon removed",
+		"data":    cart,
 	})
 }
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/service"
+)
+
+// ListHandler handles HTTP requests for wishlists and the save-for-later list.
+// Lists belong to signed-in users; guests keep only a cart.
+type ListHandler struct {
+	listService service.ListService
+}
+
+// NewListHandler creates a new ListHandler
+func NewListHandler(listService service.ListService) *ListHandler {
+	return &ListHandler{listService: listService}
+}
+
+// RegisterRoutes registers list routes
+func (h *ListHandler) RegisterRoutes(router *gin.RouterGroup) {
+	lists := router.Group("/lists")
+	lists.Use(auth.RequireIdentity())
+	{
+		lists.GET("", h.GetLists)
+		lists.POST("", h.CreateList)
+		lists.GET("/:list_id", h.GetList)
+		lists.PUT("/:list_id", h.RenameList)
+		lists.DELETE("/:list_id", h.DeleteList)
+		lists.POST("/:list_id/items", h.AddItem)
+		lists.DELETE("/:list_id/items/:product_id", h.RemoveItem)
+		lists.POST("/:list_id/items/:product_id/move-to-cart", h.MoveToCart)
+		lists.POST("/:list_id/move-from-cart", h.MoveFromCart)
+	}
+}
+
+// getUserID returns the user whose lists are acted on: the caller, or for staff the user_id they name
+func getUserID(c *gin.Context) (uint, bool) {
+	userID, ok := auth.TargetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "User not authenticated",
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+// listErrorStatus maps list service errors to HTTP status codes
+func listErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrListNotFound), errors.Is(err, service.ErrItemNotInList):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrDefaultList):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrListLimitReached), errors.Is(err, service.ErrListFull):
+		return http.StatusConflict
+	}
+	return errorStatus(err)
+}
+
+func (h *ListHandler) respondError(c *gin.Context, err error) {
+	c.JSON(listErrorStatus(err), gin.H{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+// parseProductID reads the product_id path parameter
+func parseProductID(c *gin.Context) (uint, bool) {
+	productID, err := strconv.ParseUint(c.Param("product_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid product ID",
+		})
+		return 0, false
+	}
+	return uint(productID), true
+}
+
+// GetLists retrieves the user's lists
+// GET /api/v1/lists
+func (h *ListHandler) GetLists(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	lists, err := h.listService.GetLists(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Lists retrieved",
+		"data":    lists,
+	})
+}
+
+// CreateList creates a wishlist
+// POST /api/v1/lists
+func (h *ListHandler) CreateList(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	list, err := h.listService.CreateList(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "List created",
+		"data":    list,
+	})
+}
+
+// GetList retrieves a list
+// GET /api/v1/lists/:list_id
+func (h *ListHandler) GetList(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.listService.GetList(c.Request.Context(), userID, c.Param("list_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "List retrieved",
+		"data":    list,
+	})
+}
+
+// RenameList renames a wishlist
+// PUT /api/v1/lists/:list_id
+func (h *ListHandler) RenameList(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RenameListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	list, err := h.listService.RenameList(c.Request.Context(), userID, c.Param("list_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "List renamed",
+		"data":    list,
+	})
+}
+
+// DeleteList deletes a wishlist and its items
+// DELETE /api/v1/lists/:list_id
+func (h *ListHandler) DeleteList(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.listService.DeleteList(c.Request.Context(), userID, c.Param("list_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "List deleted",
+	})
+}
+
+// AddItem adds a product to a list
+// POST /api/v1/lists/:list_id/items
+func (h *ListHandler) AddItem(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddListItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	list, err := h.listService.AddItem(c.Request.Context(), userID, c.Param("list_id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Item added to list",
+		"data":    list,
+	})
+}
+
+// RemoveItem removes a product from a list
+// DELETE /api/v1/lists/:list_id/items/:product_id
+func (h *ListHandler) RemoveItem(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.listService.RemoveItem(c.Request.Context(), userID, c.Param("list_id"), productID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Item removed from list",
+		"data":    list,
+	})
+}
+
+// MoveToCart moves a list item to the cart. If-Match carries the cart version.
+// POST /api/v1/lists/:list_id/items/:product_id/move-to-cart
+func (h *ListHandler) MoveToCart(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	moved, err := h.listService.MoveToCart(c.Request.Context(), userID, c.Param("list_id"), productID, version)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	setETag(c, moved.Cart)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Item moved to cart",
+		"data":    moved,
+	})
+}
+
+// MoveFromCart moves a cart line to a list. If-Match carries the cart version.
+// POST /api/v1/lists/:list_id/move-from-cart
+func (h *ListHandler) MoveFromCart(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MoveFromCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	moved, err := h.listService.MoveFromCart(c.Request.Context(), userID, c.Param("list_id"), req.ProductID, version)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	setETag(c, moved.Cart)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Item moved to list",
+		"data":    moved,
+	})
+}
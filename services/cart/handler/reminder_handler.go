@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/service"
+)
+
+// ReminderHandler handles HTTP requests for abandoned cart reminder preferences
+type ReminderHandler struct {
+	reminderService service.ReminderService
+}
+
+// NewReminderHandler creates a new ReminderHandler
+func NewReminderHandler(reminderService service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{reminderService: reminderService}
+}
+
+// RegisterRoutes registers reminder routes
+func (h *ReminderHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.PUT("/cart/reminders", auth.RequireIdentity(), h.SetPreference)
+}
+
+// SetPreference turns abandoned cart reminders on or off for the user
+// PUT /api/v1/cart/reminders
+func (h *ReminderHandler) SetPreference(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReminderPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.reminderService.SetOptOut(c.Request.Context(), userID, !*req.Enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	message := "Cart reminders turned off"
+	if *req.Enabled {
+		message = "Cart reminders turned on"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    gin.H{"enabled": *req.Enabled},
+	})
+}
@@ -3,7 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
@@ -13,13 +13,30 @@ import (
 const (
 	cartKeyPrefix = "cart:"
 	cartTTL       = 7 * 24 * time.Hour // 7 days
+	// GuestCartTTL is shorter as guest carts are abandoned sooner and cannot be recovered by logging in
+	GuestCartTTL = 2 * 24 * time.Hour
+	// cartActivityKey is a sorted set of user ids scored by when their cart last changed
+	cartActivityKey = "carts:activity"
+	// maxUpdateAttempts bounds the retries of an update that keeps losing races with other writers
+	maxUpdateAttempts = 50
+)
+
+var (
+	// ErrVersionMismatch is returned when a cart changed since the version the caller last saw
+	ErrVersionMismatch = errors.New("cart has been modified")
+	// ErrUpdateConflict is returned when an update kept conflicting with concurrent writers
+	ErrUpdateConflict = errors.New("cart is being modified concurrently")
 )
 
 // CartRepository defines the interface for cart storage
 type CartRepository interface {
-	Get(ctx context.Context, userID uint) (*domain.Cart, error)
-	Save(ctx context.Context, cart *domain.Cart) error
-	Delete(ctx context.Context, userID uint) error
+	Get(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error)
+	// Update applies change to the stored cart and saves it with the next version, atomically:
+	// when another writer gets in between, change is re-applied to the fresh cart.
+	// Unless version is domain.AnyVersion, the update fails with ErrVersionMismatch if the stored
+	// cart has another version. An error from change aborts the update.
+	Update(ctx context.Context, owner domain.CartOwner, version int64, change func(cart *domain.Cart) error) (*domain.Cart, error)
+	Delete(ctx context.Context, owner domain.CartOwner) error
 }
 
 type redisCartRepository struct {
@@ -31,19 +48,23 @@ func NewRedisCartRepository(client *redis.Client) CartRepository {
 	return &redisCartRepository{client: client}
 }
 
-func (r *redisCartRepository) cartKey(userID uint) string {
-	return fmt.Sprintf("%s%d", cartKeyPrefix, userID)
+func (r *redisCartRepository) cartKey(owner domain.CartOwner) string {
+	return cartKeyPrefix + owner.String()
 }
 
-func (r *redisCartRepository) Get(ctx context.Context, userID uint) (*domain.Cart, error) {
-	key := r.cartKey(userID)
+func (r *redisCartRepository) Get(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error) {
+	return r.get(ctx, r.client, owner)
+}
 
-	data, err := r.client.Get(ctx, key).Bytes()
+// get reads a cart through client, which may be a transaction watching the cart's key
+func (r *redisCartRepository) get(ctx context.Context, client redis.Cmdable, owner domain.CartOwner) (*domain.Cart, error) {
+	data, err := client.Get(ctx, r.cartKey(owner)).Bytes()
 	if err == redis.Nil {
 		// Return empty cart if not found
 		return &domain.Cart{
-			UserID: userID,
-			Items:  []domain.CartItem{},
+			UserID:  owner.UserID,
+			GuestID: owner.GuestID,
+			Items:   []domain.CartItem{},
 		}, nil
 	}
 	if err != nil {
@@ -58,18 +79,73 @@ func (r *redisCartRepository) Get(ctx context.Context, userID uint) (*domain.Car
 	return &cart, nil
 }
 
-func (r *redisCartRepository) Save(ctx context.Context, cart *domain.Cart) error {
-	key := r.cartKey(cart.UserID)
+// Update uses optimistic locking: the key is WATCHed while the cart is read and changed,
+// and the write is discarded by Redis if the key changed in the meantime.
+func (r *redisCartRepository) Update(ctx context.Context, owner domain.CartOwner, version int64, change func(cart *domain.Cart) error) (*domain.Cart, error) {
+	key := r.cartKey(owner)
+	ttl := cartTTL
+	if owner.IsGuest() {
+		ttl = GuestCartTTL
+	}
+
+	var updated *domain.Cart
+	update := func(tx *redis.Tx) error {
+		cart, err := r.get(ctx, tx, owner)
+		if err != nil {
+			return err
+		}
+		if version != domain.AnyVersion && cart.Version != version {
+			return ErrVersionMismatch
+		}
+		if err := change(cart); err != nil {
+			return err
+		}
+		cart.Version++
+		cart.UpdatedAt = time.Now()
+		switch {
+		case len(cart.Items) == 0:
+			cart.StartedAt = time.Time{}
+		case cart.StartedAt.IsZero():
+			cart.StartedAt = cart.UpdatedAt
+		}
 
-	data, err := json.Marshal(cart)
-	if err != nil {
+		data, err := json.Marshal(cart)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, ttl)
+			// Guests can't be reminded of their carts, so only user carts are tracked
+			if !owner.IsGuest() {
+				pipe.ZAdd(ctx, cartActivityKey, redis.Z{Score: float64(cart.UpdatedAt.Unix()), Member: owner.UserID})
+			}
+			return nil
+		})
+		if err == nil {
+			updated = cart
+		}
 		return err
 	}
 
-	return r.client.Set(ctx, key, data, cartTTL).Err()
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		err := r.client.Watch(ctx, update, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, ErrUpdateConflict
 }
 
-func (r *redisCartRepository) Delete(ctx context.Context, userID uint) error {
-	key := r.cartKey(userID)
+func (r *redisCartRepository) Delete(ctx context.Context, owner domain.CartOwner) error {
+	key := r.cartKey(owner)
+	if !owner.IsGuest() {
+		if err := r.client.ZRem(ctx, cartActivityKey, owner.UserID).Err(); err != nil {
+			return err
+		}
+	}
 	return r.client.Del(ctx, key).Err()
 }
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepository(t *testing.T) (CartRepository, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisCartRepository(client), server
+}
+
+func addItem(productID uint) func(*domain.Cart) error {
+	return func(cart *domain.Cart) error {
+		cart.AddItem(domain.CartItem{ProductID: productID, ProductName: "Product", Price: 10_000, Quantity: 1})
+		return nil
+	}
+}
+
+func TestRedisCartRepository_Update_ParallelAdds(t *testing.T) {
+	// Arrange: two tabs' worth of requests hitting the same cart at once
+	repo, _ := newTestRepository(t)
+	owner := domain.UserCart(7)
+	const adds = 20
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make(chan error, adds)
+	for i := 1; i <= adds; i++ {
+		wg.Add(1)
+		go func(productID uint) {
+			defer wg.Done()
+			_, err := repo.Update(context.Background(), owner, domain.AnyVersion, addItem(productID))
+			errs <- err
+		}(uint(i))
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert: no add was lost
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	cart, err := repo.Get(context.Background(), owner)
+	require.NoError(t, err)
+	assert.Len(t, cart.Items, adds)
+	assert.Equal(t, adds, cart.TotalItems)
+	assert.Equal(t, int64(adds), cart.Version)
+}
+
+func TestRedisCartRepository_Update_VersionMismatch(t *testing.T) {
+	// Arrange
+	repo, _ := newTestRepository(t)
+	owner := domain.GuestCart("abc")
+	cart, err := repo.Update(context.Background(), owner, 0, addItem(1))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cart.Version)
+
+	// Act: a client that has not seen the first change
+	_, err = repo.Update(context.Background(), owner, 0, addItem(2))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	cart, err = repo.Update(context.Background(), owner, 1, addItem(2))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cart.Version)
+	assert.Len(t, cart.Items, 2)
+}
+
+func TestRedisCartRepository_Update_ChangeErrorAborts(t *testing.T) {
+	// Arrange
+	repo, server := newTestRepository(t)
+	owner := domain.UserCart(7)
+	errNotInCart := errors.New("item not in cart")
+
+	// Act
+	_, err := repo.Update(context.Background(), owner, domain.AnyVersion, func(cart *domain.Cart) error {
+		return errNotInCart
+	})
+
+	// Assert: nothing was written
+	assert.ErrorIs(t, err, errNotInCart)
+	assert.False(t, server.Exists("cart:7"))
+}
+
+func TestRedisCartRepository_Update_ExpiresGuestCartsSooner(t *testing.T) {
+	// Arrange
+	repo, server := newTestRepository(t)
+
+	// Act
+	_, err := repo.Update(context.Background(), domain.UserCart(7), domain.AnyVersion, addItem(1))
+	require.NoError(t, err)
+	_, err = repo.Update(context.Background(), domain.GuestCart("abc"), domain.AnyVersion, addItem(1))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, cartTTL, server.TTL("cart:7"))
+	assert.Equal(t, GuestCartTTL, server.TTL("cart:guest:abc"))
+}
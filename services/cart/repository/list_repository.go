@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// listKeyPrefix prefixes the hash holding a user's lists, keyed by list id.
+// Unlike carts, lists don't expire.
+const listKeyPrefix = "lists:"
+
+var (
+	ErrListNotFound = errors.New("list not found")
+	// ErrListLimitReached is returned when a user already has the most lists allowed
+	ErrListLimitReached = errors.New("list limit reached")
+)
+
+// ListRepository defines the interface for storing users' lists.
+// The save-for-later list exists for every user: it is returned empty until something is saved.
+type ListRepository interface {
+	FindAll(ctx context.Context, userID uint) ([]domain.List, error)
+	Get(ctx context.Context, userID uint, listID string) (*domain.List, error)
+	// Create stores a new list unless the user already has maxLists lists
+	Create(ctx context.Context, list *domain.List, maxLists int) error
+	// Update applies change to the stored list and saves it atomically, like CartRepository.Update.
+	// An error from change aborts the update.
+	Update(ctx context.Context, userID uint, listID string, change func(list *domain.List) error) (*domain.List, error)
+	Delete(ctx context.Context, userID uint, listID string) error
+}
+
+type redisListRepository struct {
+	client *redis.Client
+}
+
+// NewRedisListRepository creates a new Redis-based list repository
+func NewRedisListRepository(client *redis.Client) ListRepository {
+	return &redisListRepository{client: client}
+}
+
+func (r *redisListRepository) listsKey(userID uint) string {
+	return fmt.Sprintf("%s%d", listKeyPrefix, userID)
+}
+
+func (r *redisListRepository) FindAll(ctx context.Context, userID uint) ([]domain.List, error) {
+	fields, err := r.client.HGetAll(ctx, r.listsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]domain.List, 0, len(fields)+1)
+	if _, ok := fields[domain.SaveForLaterListID]; !ok {
+		lists = append(lists, *domain.NewSaveForLaterList(userID, time.Now()))
+	}
+	for _, data := range fields {
+		var list domain.List
+		if err := json.Unmarshal([]byte(data), &list); err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	// Save-for-later first, then the oldest lists
+	sort.Slice(lists, func(i, j int) bool {
+		if (lists[i].ID == domain.SaveForLaterListID) != (lists[j].ID == domain.SaveForLaterListID) {
+			return lists[i].ID == domain.SaveForLaterListID
+		}
+		return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+	})
+	return lists, nil
+}
+
+func (r *redisListRepository) Get(ctx context.Context, userID uint, listID string) (*domain.List, error) {
+	return r.get(ctx, r.client, userID, listID)
+}
+
+// get reads a list through client, which may be a transaction watching the user's lists
+func (r *redisListRepository) get(ctx context.Context, client redis.Cmdable, userID uint, listID string) (*domain.List, error) {
+	data, err := client.HGet(ctx, r.listsKey(userID), listID).Bytes()
+	if err == redis.Nil {
+		if listID == domain.SaveForLaterListID {
+			return domain.NewSaveForLaterList(userID, time.Now()), nil
+		}
+		return nil, ErrListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list domain.List
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *redisListRepository) Create(ctx context.Context, list *domain.List, maxLists int) error {
+	key := r.listsKey(list.UserID)
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	return r.watch(ctx, key, func(tx *redis.Tx) error {
+		count, err := tx.HLen(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if count >= int64(maxLists) {
+			return ErrListLimitReached
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, list.ID, data)
+			return nil
+		})
+		return err
+	})
+}
+
+func (r *redisListRepository) Update(ctx context.Context, userID uint, listID string, change func(list *domain.List) error) (*domain.List, error) {
+	key := r.listsKey(userID)
+
+	var updated *domain.List
+	err := r.watch(ctx, key, func(tx *redis.Tx) error {
+		list, err := r.get(ctx, tx, userID, listID)
+		if err != nil {
+			return err
+		}
+		if err := change(list); err != nil {
+			return err
+		}
+		list.UpdatedAt = time.Now()
+
+		data, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, listID, data)
+			return nil
+		})
+		if err == nil {
+			updated = list
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *redisListRepository) Delete(ctx context.Context, userID uint, listID string) error {
+	deleted, err := r.client.HDel(ctx, r.listsKey(userID), listID).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrListNotFound
+	}
+	return nil
+}
+
+// watch runs fn in a transaction watching key, retrying when another writer gets in between
+func (r *redisListRepository) watch(ctx context.Context, key string, fn func(tx *redis.Tx) error) error {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		err := r.client.Watch(ctx, fn, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		return err
+	}
+	return ErrUpdateConflict
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestListRepository(t *testing.T) (ListRepository, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisListRepository(client), server
+}
+
+func TestRedisListRepository_Update_CreatesSaveForLater(t *testing.T) {
+	// Arrange
+	repo, server := newTestListRepository(t)
+
+	// Act
+	list, err := repo.Update(context.Background(), 7, domain.SaveForLaterListID, func(list *domain.List) error {
+		list.AddItem(domain.ListItem{ProductID: 1, ProductName: "Kopi", Price: 50_000, Quantity: 1})
+		return nil
+	})
+
+	// Assert: stored without expiry
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListKindSaveForLater, list.Kind)
+	assert.Len(t, list.Items, 1)
+	assert.True(t, server.Exists("lists:7"))
+	assert.Zero(t, server.TTL("lists:7"))
+}
+
+func TestRedisListRepository_Update_UnknownList(t *testing.T) {
+	// Arrange
+	repo, _ := newTestListRepository(t)
+
+	// Act
+	_, err := repo.Update(context.Background(), 7, "missing", func(list *domain.List) error { return nil })
+
+	// Assert
+	assert.ErrorIs(t, err, ErrListNotFound)
+}
+
+func TestRedisListRepository_Create_Limit(t *testing.T) {
+	// Arrange
+	repo, _ := newTestListRepository(t)
+	now := time.Now()
+	for i, id := range []string{"a", "b"} {
+		list := &domain.List{ID: id, UserID: 7, Name: id, Kind: domain.ListKindWishlist, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		require.NoError(t, repo.Create(context.Background(), list, 2))
+	}
+
+	// Act
+	err := repo.Create(context.Background(), &domain.List{ID: "c", UserID: 7, Kind: domain.ListKindWishlist}, 2)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrListLimitReached)
+	lists, err := repo.FindAll(context.Background(), 7)
+	require.NoError(t, err)
+	require.Len(t, lists, 3)
+	assert.Equal(t, []string{domain.SaveForLaterListID, "a", "b"}, []string{lists[0].ID, lists[1].ID, lists[2].ID})
+}
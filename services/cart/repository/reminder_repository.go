@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// reminderKeyPrefix prefixes the markers of reminders sent, one per cart and stage
+	reminderKeyPrefix = "cart:reminder:"
+	// reminderOptOutKey is the set of users who don't want abandoned cart reminders
+	reminderOptOutKey = "carts:reminders:opted_out"
+)
+
+// ReminderRepository keeps what abandoned cart reminders need: which carts went idle,
+// which reminders were sent and who opted out. Carts are identified by user and StartedAt,
+// so a cart emptied and filled again counts as a new cart.
+type ReminderRepository interface {
+	// FindIdle returns the users whose cart last changed at or after from and before to
+	FindIdle(ctx context.Context, from, to time.Time) ([]uint, error)
+	// PruneExpired forgets carts that changed too long ago to still exist
+	PruneExpired(ctx context.Context, now time.Time) error
+	// MarkSent records that a stage's reminder is going out for a cart. It reports false if
+	// one already did, so each cart gets at most one reminder per stage.
+	MarkSent(ctx context.Context, userID uint, startedAt time.Time, stage int) (bool, error)
+	// UnmarkSent forgets a reminder that could not be sent, so a later scan retries it
+	UnmarkSent(ctx context.Context, userID uint, startedAt time.Time, stage int) error
+	SetOptOut(ctx context.Context, userID uint, optOut bool) error
+	IsOptedOut(ctx context.Context, userID uint) (bool, error)
+}
+
+type redisReminderRepository struct {
+	client *redis.Client
+}
+
+// NewRedisReminderRepository creates a new Redis-based reminder repository
+func NewRedisReminderRepository(client *redis.Client) ReminderRepository {
+	return &redisReminderRepository{client: client}
+}
+
+func (r *redisReminderRepository) reminderKey(userID uint, startedAt time.Time, stage int) string {
+	return fmt.Sprintf("%s%d:%d:%d", reminderKeyPrefix, userID, startedAt.Unix(), stage)
+}
+
+func (r *redisReminderRepository) FindIdle(ctx context.Context, from, to time.Time) ([]uint, error) {
+	members, err := r.client.ZRangeByScore(ctx, cartActivityKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(from.Unix(), 10),
+		Max: "(" + strconv.FormatInt(to.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, uint(id))
+	}
+	return userIDs, nil
+}
+
+func (r *redisReminderRepository) PruneExpired(ctx context.Context, now time.Time) error {
+	before := strconv.FormatInt(now.Add(-cartTTL).Unix(), 10)
+	return r.client.ZRemRangeByScore(ctx, cartActivityKey, "-inf", "("+before).Err()
+}
+
+func (r *redisReminderRepository) MarkSent(ctx context.Context, userID uint, startedAt time.Time, stage int) (bool, error) {
+	// Kept as long as the cart itself can live
+	return r.client.SetNX(ctx, r.reminderKey(userID, startedAt, stage), time.Now().Unix(), cartTTL).Result()
+}
+
+func (r *redisReminderRepository) UnmarkSent(ctx context.Context, userID uint, startedAt time.Time, stage int) error {
+	return r.client.Del(ctx, r.reminderKey(userID, startedAt, stage)).Err()
+}
+
+func (r *redisReminderRepository) SetOptOut(ctx context.Context, userID uint, optOut bool) error {
+	if optOut {
+		return r.client.SAdd(ctx, reminderOptOutKey, userID).Err()
+	}
+	return r.client.SRem(ctx, reminderOptOutKey, userID).Err()
+}
+
+func (r *redisReminderRepository) IsOptedOut(ctx context.Context, userID uint) (bool, error) {
+	return r.client.SIsMember(ctx, reminderOptOutKey, userID).Result()
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/tax"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/dto"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/repository"
@@ -13,54 +16,152 @@ var (
 	ErrProductNotFound = errors.New("product not found")
 	ErrCartEmpty       = errors.New("cart is empty")
 	ErrItemNotInCart   = errors.New("item not in cart")
+	// ErrProductUnavailable is returned when adding a product that is no longer sold
+	ErrProductUnavailable = errors.New("product is not available")
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	// ErrCouponInvalid is returned with the reason when a coupon can't be applied to the cart
+	ErrCouponInvalid = errors.New("coupon cannot be applied")
 )
 
 // ProductInfo represents product info from Product Service
 type ProductInfo struct {
-	ID       uint
-	Name     string
-	Price    float64
-	Stock    int
-	ImageURL string
-	IsActive bool
+	ID          uint
+	Name        string
+	Price       float64
+	Stock       int
+	ImageURL    string
+	IsActive    bool
+	TaxCategory string
 }
 
 // ProductClient interface for getting product info
 type ProductClient interface {
 	GetProduct(ctx context.Context, productID uint) (*ProductInfo, error)
+	// GetProducts returns the products found among productIDs, keyed by ID
+	GetProducts(ctx context.Context, productIDs []uint) (map[uint]*ProductInfo, error)
 }
 
-// CartService defines the interface for cart operations
+// CouponPreview is what a coupon takes off the cart, as priced by Order Service.
+// Amounts are in the base currency.
+type CouponPreview struct {
+	Valid        bool
+	Reason       string // Why the coupon can't be applied
+	Code         string
+	Name         string
+	Type         string
+	ItemsAmount  float64
+	FreeShipping bool
+}
+
+// OrderClient interface for pricing coupons; Order Service owns promotions
+type OrderClient interface {
+	PreviewDiscount(ctx context.Context, userID uint, code string, items []domain.CartItem) (*CouponPreview, error)
+}
+
+// CartService defines the interface for cart operations.
+// Changes take the cart version the caller last saw; domain.AnyVersion applies them regardless.
 type CartService interface {
-	GetCart(ctx context.Context, userID uint) (*dto.CartResponse, error)
-	AddToCart(ctx context.Context, userID uint, req *dto.AddToCartRequest) (*dto.CartResponse, error)
-	UpdateItem(ctx context.Context, userID uint, productID uint, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error)
-	RemoveItem(ctx context.Context, userID uint, productID uint) (*dto.CartResponse, error)
-	ClearCart(ctx context.Context, userID uint) error
+	// GetCart returns the cart checked against current prices and stock, with warnings for the lines that changed
+	GetCart(ctx context.Context, owner domain.CartOwner) (*dto.CartResponse, error)
+	// ValidateCart checks the cart before checkout like GetCart, but fails if the catalogue cannot be reached
+	ValidateCart(ctx context.Context, owner domain.CartOwner) (*dto.CartResponse, error)
+	AddToCart(ctx context.Context, owner domain.CartOwner, version int64, req *dto.AddToCartRequest) (*dto.CartResponse, error)
+	UpdateItem(ctx context.Context, owner domain.CartOwner, version int64, productID uint, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error)
+	RemoveItem(ctx context.Context, owner domain.CartOwner, version int64, productID uint) (*dto.CartResponse, error)
+	ClearCart(ctx context.Context, owner domain.CartOwner, version int64) (*dto.CartResponse, error)
+	// ApplyCoupon checks a coupon against the cart and keeps it there, replacing any other
+	ApplyCoupon(ctx context.Context, owner domain.CartOwner, version int64, code string) (*dto.CartResponse, error)
+	RemoveCoupon(ctx context.Context, owner domain.CartOwner, version int64) (*dto.CartResponse, error)
+	// MergeGuestCart moves a guest's cart into a user's cart when they sign in, then deletes it
+	MergeGuestCart(ctx context.Context, userID uint, guestID string) error
 }
 
 type cartServiceImpl struct {
 	cartRepo      repository.CartRepository
 	productClient ProductClient
+	orderClient   OrderClient
+	taxes         tax.TaxCalculator
 }
 
-// NewCartService creates a new CartService
-func NewCartService(cartRepo repository.CartRepository, productClient ProductClient) CartService {
+// NewCartService creates a new CartService. Cart totals are taxed as in the default tax region,
+// since the shipping address is only known at checkout.
+func NewCartService(cartRepo repository.CartRepository, productClient ProductClient, orderClient OrderClient, taxes tax.TaxCalculator) CartService {
 	return &cartServiceImpl{
 		cartRepo:      cartRepo,
 		productClient: productClient,
+		orderClient:   orderClient,
+		taxes:         taxes,
 	}
 }
 
-func (s *cartServiceImpl) GetCart(ctx context.Context, userID uint) (*dto.CartResponse, error) {
-	cart, err := s.cartRepo.Get(ctx, userID)
+func (s *cartServiceImpl) GetCart(ctx context.Context, owner domain.CartOwner) (*dto.CartResponse, error) {
+	cart, err := s.cartRepo.Get(ctx, owner)
 	if err != nil {
 		return nil, err
 	}
-	return s.toCartResponse(cart), nil
+
+	revalidated, warnings, err := s.revalidate(ctx, cart)
+	if err != nil {
+		// Viewing the cart doesn't depend on the Product Service; checkout validation does
+		return s.toCartResponse(ctx, cart, nil), nil
+	}
+	return s.toCartResponse(ctx, revalidated, warnings), nil
 }
 
-func (s *cartServiceImpl) AddToCart(ctx context.Context, userID uint, req *dto.AddToCartRequest) (*dto.CartResponse, error) {
+func (s *cartServiceImpl) ValidateCart(ctx context.Context, owner domain.CartOwner) (*dto.CartResponse, error) {
+	cart, err := s.cartRepo.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	cart, warnings, err := s.revalidate(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+	return s.toCartResponse(ctx, cart, warnings), nil
+}
+
+// revalidate checks the cart against the catalogue in one batch call and saves the corrections.
+// If the cart changed in the meantime the corrections are left for the next check.
+func (s *cartServiceImpl) revalidate(ctx context.Context, cart *domain.Cart) (*domain.Cart, []domain.CartWarning, error) {
+	if len(cart.Items) == 0 {
+		return cart, nil, nil
+	}
+
+	ids := make([]uint, len(cart.Items))
+	for i, item := range cart.Items {
+		ids[i] = item.ProductID
+	}
+	products, err := s.productClient.GetProducts(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	states := make(map[uint]domain.ProductState, len(products))
+	for id, product := range products {
+		states[id] = domain.ProductState{Price: product.Price, Stock: product.Stock, IsActive: product.IsActive, TaxCategory: product.TaxCategory}
+	}
+
+	warnings, changed := cart.Revalidate(states)
+	if !changed {
+		return cart, warnings, nil
+	}
+	saved, err := s.cartRepo.Update(ctx, cart.Owner(), cart.Version, func(stored *domain.Cart) error {
+		stored.Revalidate(states)
+		return nil
+	})
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		return cart, warnings, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return saved, warnings, nil
+}
+
+func (s *cartServiceImpl) AddToCart(ctx context.Context, owner domain.CartOwner, version int64, req *dto.AddToCartRequest) (*dto.CartResponse, error) {
 	// Get product info from Product Service
 	product, err := s.productClient.GetProduct(ctx, req.ProductID)
 	if err != nil {
@@ -69,69 +170,195 @@ func (s *cartServiceImpl) AddToCart(ctx context.Context, userID uint, req *dto.A
 	if product == nil {
 		return nil, ErrProductNotFound
 	}
+	if !product.IsActive {
+		return nil, ErrProductUnavailable
+	}
 
-	// Get current cart
-	cart, err := s.cartRepo.Get(ctx, userID)
+	cart, err := s.cartRepo.Update(ctx, owner, version, func(cart *domain.Cart) error {
+		if quantityInCart(cart, product.ID)+req.Quantity > product.Stock {
+			return ErrInsufficientStock
+		}
+		cart.AddItem(domain.CartItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Price:       product.Price,
+			Quantity:    req.Quantity,
+			ImageURL:    product.ImageURL,
+			TaxCategory: product.TaxCategory,
+		})
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Add item to cart
-	cart.AddItem(domain.CartItem{
-		ProductID:   product.ID,
-		ProductName: product.Name,
-		Price:       product.Price,
-		Quantity:    req.Quantity,
-		ImageURL:    product.ImageURL,
+	return s.toCartResponse(ctx, cart, nil), nil
+}
+
+func (s *cartServiceImpl) UpdateItem(ctx context.Context, owner domain.CartOwner, version int64, productID uint, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error) {
+	if req.Quantity > 0 {
+		product, err := s.productClient.GetProduct(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		if product == nil || !product.IsActive {
+			return nil, ErrProductUnavailable
+		}
+		if req.Quantity > product.Stock {
+			return nil, ErrInsufficientStock
+		}
+	}
+
+	cart, err := s.cartRepo.Update(ctx, owner, version, func(cart *domain.Cart) error {
+		if !cart.UpdateItemQuantity(productID, req.Quantity) {
+			return ErrItemNotInCart
+		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Save cart
-	if err := s.cartRepo.Save(ctx, cart); err != nil {
+	return s.toCartResponse(ctx, cart, nil), nil
+}
+
+func (s *cartServiceImpl) RemoveItem(ctx context.Context, owner domain.CartOwner, version int64, productID uint) (*dto.CartResponse, error) {
+	cart, err := s.cartRepo.Update(ctx, owner, version, func(cart *domain.Cart) error {
+		if !cart.RemoveItem(productID) {
+			return ErrItemNotInCart
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return s.toCartResponse(cart), nil
+	return s.toCartResponse(ctx, cart, nil), nil
 }
 
-func (s *cartServiceImpl) UpdateItem(ctx context.Context, userID uint, productID uint, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error) {
-	cart, err := s.cartRepo.Get(ctx, userID)
+// ClearCart empties the cart rather than deleting it, so its version keeps increasing
+// and a client holding an older version cannot write over the cleared cart
+func (s *cartServiceImpl) ClearCart(ctx context.Context, owner domain.CartOwner, version int64) (*dto.CartResponse, error) {
+	cart, err := s.cartRepo.Update(ctx, owner, version, func(cart *domain.Cart) error {
+		cart.Clear()
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !cart.UpdateItemQuantity(productID, req.Quantity) {
-		return nil, ErrItemNotInCart
+	return s.toCartResponse(ctx, cart, nil), nil
+}
+
+func (s *cartServiceImpl) ApplyCoupon(ctx context.Context, owner domain.CartOwner, version int64, code string) (*dto.CartResponse, error) {
+	cart, err := s.cartRepo.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(availableItems(cart)) == 0 {
+		return nil, ErrCartEmpty
 	}
 
-	if err := s.cartRepo.Save(ctx, cart); err != nil {
+	preview, err := s.orderClient.PreviewDiscount(ctx, owner.UserID, code, availableItems(cart))
+	if err != nil {
 		return nil, err
 	}
+	if !preview.Valid {
+		return nil, fmt.Errorf("%w: %s", ErrCouponInvalid, preview.Reason)
+	}
 
-	return s.toCartResponse(cart), nil
+	cart, err = s.cartRepo.Update(ctx, owner, version, func(cart *domain.Cart) error {
+		cart.CouponCode = preview.Code
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.toCartResponse(ctx, cart, nil), nil
 }
 
-func (s *cartServiceImpl) RemoveItem(ctx context.Context, userID uint, productID uint) (*dto.CartResponse, error) {
-	cart, err := s.cartRepo.Get(ctx, userID)
+func (s *cartServiceImpl) RemoveCoupon(ctx context.Context, owner domain.CartOwner, version int64) (*dto.CartResponse, error) {
+	cart, err := s.cartRepo.Update(ctx, owner, version, func(cart *domain.Cart) error {
+		cart.CouponCode = ""
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return s.toCartResponse(ctx, cart, nil), nil
+}
 
-	if !cart.RemoveItem(productID) {
-		return nil, ErrItemNotInCart
+func (s *cartServiceImpl) MergeGuestCart(ctx context.Context, userID uint, guestID string) error {
+	guest, err := s.cartRepo.Get(ctx, domain.GuestCart(guestID))
+	if err != nil {
+		return err
+	}
+	if len(guest.Items) == 0 {
+		return nil
 	}
 
-	if err := s.cartRepo.Save(ctx, cart); err != nil {
-		return nil, err
+	_, err = s.cartRepo.Update(ctx, domain.UserCart(userID), domain.AnyVersion, func(cart *domain.Cart) error {
+		cart.Merge(guest)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	return s.cartRepo.Delete(ctx, guest.Owner())
+}
 
-	return s.toCartResponse(cart), nil
+// availableItems returns the lines that can be ordered, leaving out unavailable ones
+func availableItems(cart *domain.Cart) []domain.CartItem {
+	var items []domain.CartItem
+	for _, item := range cart.Items {
+		if !item.Unavailable {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
-func (s *cartServiceImpl) ClearCart(ctx context.Context, userID uint) error {
-	return s.cartRepo.Delete(ctx, userID)
+// quantityInCart returns how many of a product the cart already holds
+func quantityInCart(cart *domain.Cart, productID uint) int {
+	for _, item := range cart.Items {
+		if item.ProductID == productID {
+			return item.Quantity
+		}
+	}
+	return 0
 }
 
-func (s *cartServiceImpl) toCartResponse(cart *domain.Cart) *dto.CartResponse {
+// priceCoupon works out the cart's coupon discount. A coupon that no longer applies, e.g. after
+// items were removed, stays on the cart with a warning as it may apply again later.
+func (s *cartServiceImpl) priceCoupon(ctx context.Context, cart *domain.Cart) (*dto.CartDiscountResponse, *dto.CartWarningResponse) {
+	items := availableItems(cart)
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	preview, err := s.orderClient.PreviewDiscount(ctx, cart.UserID, cart.CouponCode, items)
+	if err != nil {
+		return nil, &dto.CartWarningResponse{
+			Code:    "coupon_unavailable",
+			Message: fmt.Sprintf("Coupon %s could not be checked right now", cart.CouponCode),
+		}
+	}
+	if !preview.Valid {
+		return nil, &dto.CartWarningResponse{
+			Code:    "coupon_not_applicable",
+			Message: fmt.Sprintf("Coupon %s cannot be applied: %s", cart.CouponCode, preview.Reason),
+		}
+	}
+	return &dto.CartDiscountResponse{
+		Code:         preview.Code,
+		Description:  preview.Name,
+		Type:         preview.Type,
+		Amount:       preview.ItemsAmount,
+		FreeShipping: preview.FreeShipping,
+	}, nil
+}
+
+func (s *cartServiceImpl) toCartResponse(ctx context.Context, cart *domain.Cart, warnings []domain.CartWarning) *dto.CartResponse {
 	items := make([]dto.CartItemResponse, len(cart.Items))
 	for i, item := range cart.Items {
 		items[i] = dto.CartItemResponse{
@@ -141,13 +368,105 @@ func (s *cartServiceImpl) toCartResponse(cart *domain.Cart) *dto.CartResponse {
 			Quantity:    item.Quantity,
 			Subtotal:    item.Price * float64(item.Quantity),
 			ImageURL:    item.ImageURL,
+			Unavailable: item.Unavailable,
+		}
+		if item.Unavailable {
+			items[i].Subtotal = 0
+		}
+	}
+
+	resp := &dto.CartResponse{
+		UserID:             cart.UserID,
+		Items:              items,
+		TotalItems:         cart.TotalItems,
+		TotalPrice:         cart.TotalPrice,
+		CouponCode:         cart.CouponCode,
+		TotalAfterDiscount: cart.TotalPrice,
+		Version:            cart.Version,
+		Warnings:           toWarningResponses(warnings),
+	}
+	if cart.CouponCode != "" {
+		discount, warning := s.priceCoupon(ctx, cart)
+		if discount != nil {
+			resp.Discounts = append(resp.Discounts, *discount)
+			resp.DiscountTotal += discount.Amount
+		}
+		if warning != nil {
+			resp.Warnings = append(resp.Warnings, *warning)
+		}
+		resp.TotalAfterDiscount = cart.TotalPrice - resp.DiscountTotal
+	}
+	s.applyTax(resp, cart)
+	return resp
+}
+
+// applyTax adds the tax on the cart's available lines, net of discounts, to resp.
+// Discounts are spread over the lines in proportion to their subtotals.
+func (s *cartServiceImpl) applyTax(resp *dto.CartResponse, cart *domain.Cart) {
+	resp.GrandTotal = resp.TotalAfterDiscount
+	if s.taxes == nil || cart.TotalPrice <= 0 {
+		return
+	}
+
+	share := resp.TotalAfterDiscount / cart.TotalPrice
+	var lines []tax.Line
+	for _, item := range availableItems(cart) {
+		lines = append(lines, tax.Line{
+			Category: tax.Category(item.TaxCategory),
+			Amount:   currency.Base.Round(item.Price * float64(item.Quantity) * share),
+		})
+	}
+	breakdown, err := s.taxes.Calculate("", lines, currency.Base)
+	if err != nil {
+		return
+	}
+
+	resp.TaxInclusive = breakdown.Inclusive
+	resp.TaxTotal = breakdown.Total
+	for _, line := range breakdown.Lines {
+		resp.Taxes = append(resp.Taxes, dto.CartTaxResponse{
+			Name:          line.Name,
+			Rate:          line.Rate,
+			Inclusive:     line.Inclusive,
+			TaxableAmount: line.TaxableAmount,
+			Amount:        line.Amount,
+		})
+	}
+	if !breakdown.Inclusive {
+		resp.GrandTotal += breakdown.Total
+	}
+}
+
+func toWarningResponses(warnings []domain.CartWarning) []dto.CartWarningResponse {
+	if len(warnings) == 0 {
+		return nil
+	}
+	responses := make([]dto.CartWarningResponse, len(warnings))
+	for i, w := range warnings {
+		responses[i] = dto.CartWarningResponse{
+			ProductID:   w.ProductID,
+			Code:        string(w.Code),
+			Message:     warningMessage(w),
+			OldPrice:    w.OldPrice,
+			NewPrice:    w.NewPrice,
+			OldQuantity: w.OldQuantity,
+			NewQuantity: w.NewQuantity,
 		}
 	}
+	return responses
+}
 
-	return &dto.CartResponse{
-		UserID:     cart.UserID,
-		Items:      items,
-		TotalItems: cart.TotalItems,
-		TotalPrice: cart.TotalPrice,
+func warningMessage(w domain.CartWarning) string {
+	switch w.Code {
+	case domain.WarningPriceChanged:
+		return fmt.Sprintf("The price of %s changed from %s to %s", w.ProductName,
+			currency.Base.Format(w.OldPrice), currency.Base.Format(w.NewPrice))
+	case domain.WarningQuantityReduced:
+		return fmt.Sprintf("Only %d of %s left in stock; the quantity was reduced from %d", w.NewQuantity, w.ProductName, w.OldQuantity)
+	case domain.WarningOutOfStock:
+		return fmt.Sprintf("%s is out of stock", w.ProductName)
+	case domain.WarningDiscontinued:
+		return fmt.Sprintf("%s is no longer available", w.ProductName)
 	}
+	return string(w.Code)
 }
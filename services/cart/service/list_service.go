@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/repository"
+)
+
+const (
+	// maxLists is how many lists a user can have, their save-for-later list included
+	maxLists = 20
+	// maxListItems is how many products a list can hold
+	maxListItems = 100
+)
+
+var (
+	ErrItemNotInList = errors.New("item not in list")
+	ErrListFull      = errors.New("list is full")
+	// ErrDefaultList is returned when renaming or deleting the save-for-later list
+	ErrDefaultList = errors.New("the save-for-later list cannot be renamed or deleted")
+)
+
+// ListService defines the interface for users' wishlists and save-for-later list.
+// Lists belong to signed-in users; moves between a list and the cart act on the user's cart
+// and take the cart version the caller last saw, like CartService.
+type ListService interface {
+	// GetLists returns the user's lists, refreshed against the catalogue
+	GetLists(ctx context.Context, userID uint) ([]dto.ListResponse, error)
+	GetList(ctx context.Context, userID uint, listID string) (*dto.ListResponse, error)
+	CreateList(ctx context.Context, userID uint, req *dto.CreateListRequest) (*dto.ListResponse, error)
+	RenameList(ctx context.Context, userID uint, listID string, req *dto.RenameListRequest) (*dto.ListResponse, error)
+	DeleteList(ctx context.Context, userID uint, listID string) error
+	AddItem(ctx context.Context, userID uint, listID string, req *dto.AddListItemRequest) (*dto.ListResponse, error)
+	RemoveItem(ctx context.Context, userID uint, listID string, productID uint) (*dto.ListResponse, error)
+	// MoveToCart adds a list item to the cart, subject to the cart's stock checks, and takes it off the list
+	MoveToCart(ctx context.Context, userID uint, listID string, productID uint, cartVersion int64) (*dto.MoveItemResponse, error)
+	// MoveFromCart takes a line out of the cart and keeps it in the list
+	MoveFromCart(ctx context.Context, userID uint, listID string, productID uint, cartVersion int64) (*dto.MoveItemResponse, error)
+}
+
+type listServiceImpl struct {
+	listRepo      repository.ListRepository
+	cartRepo      repository.CartRepository
+	cartService   CartService
+	productClient ProductClient
+}
+
+// NewListService creates a new ListService
+func NewListService(listRepo repository.ListRepository, cartRepo repository.CartRepository, cartService CartService, productClient ProductClient) ListService {
+	return &listServiceImpl{
+		listRepo:      listRepo,
+		cartRepo:      cartRepo,
+		cartService:   cartService,
+		productClient: productClient,
+	}
+}
+
+func (s *listServiceImpl) GetLists(ctx context.Context, userID uint) ([]dto.ListResponse, error) {
+	lists, err := s.listRepo.FindAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lists = s.refresh(ctx, lists)
+	responses := make([]dto.ListResponse, len(lists))
+	for i := range lists {
+		responses[i] = *toListResponse(&lists[i])
+	}
+	return responses, nil
+}
+
+func (s *listServiceImpl) GetList(ctx context.Context, userID uint, listID string) (*dto.ListResponse, error) {
+	list, err := s.listRepo.Get(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed := s.refresh(ctx, []domain.List{*list})
+	return toListResponse(&refreshed[0]), nil
+}
+
+// refresh checks the lists against the catalogue in one batch call and saves the new flags.
+// Lists are returned as stored if the Product Service can't be reached.
+func (s *listServiceImpl) refresh(ctx context.Context, lists []domain.List) []domain.List {
+	var ids []uint
+	for _, list := range lists {
+		for _, item := range list.Items {
+			ids = append(ids, item.ProductID)
+		}
+	}
+	if len(ids) == 0 {
+		return lists
+	}
+	products, err := s.productClient.GetProducts(ctx, ids)
+	if err != nil {
+		return lists
+	}
+	states := make(map[uint]domain.ProductState, len(products))
+	for id, product := range products {
+		states[id] = domain.ProductState{Price: product.Price, Stock: product.Stock, IsActive: product.IsActive}
+	}
+
+	for i := range lists {
+		if !lists[i].Refresh(states) {
+			continue
+		}
+		saved, err := s.listRepo.Update(ctx, lists[i].UserID, lists[i].ID, func(stored *domain.List) error {
+			stored.Refresh(states)
+			return nil
+		})
+		if err == nil {
+			lists[i] = *saved
+		}
+	}
+	return lists
+}
+
+func (s *listServiceImpl) CreateList(ctx context.Context, userID uint, req *dto.CreateListRequest) (*dto.ListResponse, error) {
+	id, err := newListID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := &domain.List{
+		ID:        id,
+		UserID:    userID,
+		Name:      req.Name,
+		Kind:      domain.ListKindWishlist,
+		Items:     []domain.ListItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.listRepo.Create(ctx, list, maxLists); err != nil {
+		return nil, err
+	}
+	return toListResponse(list), nil
+}
+
+func (s *listServiceImpl) RenameList(ctx context.Context, userID uint, listID string, req *dto.RenameListRequest) (*dto.ListResponse, error) {
+	if listID == domain.SaveForLaterListID {
+		return nil, ErrDefaultList
+	}
+
+	list, err := s.listRepo.Update(ctx, userID, listID, func(list *domain.List) error {
+		list.Name = req.Name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toListResponse(list), nil
+}
+
+func (s *listServiceImpl) DeleteList(ctx context.Context, userID uint, listID string) error {
+	if listID == domain.SaveForLaterListID {
+		return ErrDefaultList
+	}
+	return s.listRepo.Delete(ctx, userID, listID)
+}
+
+func (s *listServiceImpl) AddItem(ctx context.Context, userID uint, listID string, req *dto.AddListItemRequest) (*dto.ListResponse, error) {
+	product, err := s.productClient.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	// Out of stock products can be kept, to hear when they are back; discontinued ones can't
+	if !product.IsActive {
+		return nil, ErrProductUnavailable
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	list, err := s.listRepo.Update(ctx, userID, listID, addToList(domain.ListItem{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		ImageURL:    product.ImageURL,
+		Quantity:    quantity,
+		Price:       product.Price,
+		InStock:     product.Stock > 0,
+		AddedAt:     time.Now(),
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return toListResponse(list), nil
+}
+
+func (s *listServiceImpl) RemoveItem(ctx context.Context, userID uint, listID string, productID uint) (*dto.ListResponse, error) {
+	list, err := s.listRepo.Update(ctx, userID, listID, func(list *domain.List) error {
+		if !list.RemoveItem(productID) {
+			return ErrItemNotInList
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toListResponse(list), nil
+}
+
+func (s *listServiceImpl) MoveToCart(ctx context.Context, userID uint, listID string, productID uint, cartVersion int64) (*dto.MoveItemResponse, error) {
+	list, err := s.listRepo.Get(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	item, found := list.FindItem(productID)
+	if !found {
+		return nil, ErrItemNotInList
+	}
+
+	cart, err := s.cartService.AddToCart(ctx, domain.UserCart(userID), cartVersion, &dto.AddToCartRequest{
+		ProductID: productID,
+		Quantity:  item.Quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The item may have been removed from the list in the meantime, which is just as good
+	list, err = s.listRepo.Update(ctx, userID, listID, func(list *domain.List) error {
+		list.RemoveItem(productID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MoveItemResponse{Cart: cart, List: toListResponse(list)}, nil
+}
+
+func (s *listServiceImpl) MoveFromCart(ctx context.Context, userID uint, listID string, productID uint, cartVersion int64) (*dto.MoveItemResponse, error) {
+	owner := domain.UserCart(userID)
+	stored, err := s.cartRepo.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	var cartItem *domain.CartItem
+	for i := range stored.Items {
+		if stored.Items[i].ProductID == productID {
+			cartItem = &stored.Items[i]
+		}
+	}
+	if cartItem == nil {
+		return nil, ErrItemNotInCart
+	}
+	if _, err := s.listRepo.Get(ctx, userID, listID); err != nil {
+		return nil, err
+	}
+
+	cart, err := s.cartService.RemoveItem(ctx, owner, cartVersion, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.listRepo.Update(ctx, userID, listID, addToList(domain.ListItem{
+		ProductID:   cartItem.ProductID,
+		ProductName: cartItem.ProductName,
+		ImageURL:    cartItem.ImageURL,
+		Quantity:    cartItem.Quantity,
+		Price:       cartItem.Price,
+		InStock:     !cartItem.Unavailable,
+		AddedAt:     time.Now(),
+	}))
+	if err != nil {
+		// Put the line back rather than lose it
+		if _, restoreErr := s.cartRepo.Update(ctx, owner, domain.AnyVersion, func(cart *domain.Cart) error {
+			cart.AddItem(*cartItem)
+			return nil
+		}); restoreErr != nil {
+			return nil, errors.Join(err, restoreErr)
+		}
+		return nil, err
+	}
+	return &dto.MoveItemResponse{Cart: cart, List: toListResponse(list)}, nil
+}
+
+// addToList returns a list change adding item, refused when the list is full
+func addToList(item domain.ListItem) func(list *domain.List) error {
+	return func(list *domain.List) error {
+		if _, found := list.FindItem(item.ProductID); !found && len(list.Items) >= maxListItems {
+			return ErrListFull
+		}
+		list.AddItem(item)
+		return nil
+	}
+}
+
+// newListID returns a random id for a new list
+func newListID() (string, error) {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func toListResponse(list *domain.List) *dto.ListResponse {
+	items := make([]dto.ListItemResponse, len(list.Items))
+	for i, item := range list.Items {
+		items[i] = dto.ListItemResponse{
+			ProductID:    item.ProductID,
+			ProductName:  item.ProductName,
+			ImageURL:     item.ImageURL,
+			Quantity:     item.Quantity,
+			Price:        item.Price,
+			AddedPrice:   item.AddedPrice,
+			InStock:      item.InStock,
+			BackInStock:  item.BackInStock,
+			PriceDropped: item.PriceDropped,
+			AddedAt:      item.AddedAt,
+		}
+	}
+
+	return &dto.ListResponse{
+		ID:         list.ID,
+		UserID:     list.UserID,
+		Name:       list.Name,
+		Kind:       string(list.Kind),
+		Items:      items,
+		TotalItems: list.TotalItems(),
+		CreatedAt:  list.CreatedAt,
+		UpdatedAt:  list.UpdatedAt,
+	}
+}
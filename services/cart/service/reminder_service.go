@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/repository"
+)
+
+// ReminderStage is an abandoned cart reminder sent once a cart has been idle for After,
+// rendered by Notification Service from the template TemplateID
+type ReminderStage struct {
+	After      time.Duration
+	TemplateID string
+}
+
+// ReminderEmail is an abandoned cart reminder for Notification Service to render and send
+type ReminderEmail struct {
+	UserID     uint
+	To         string
+	TemplateID string
+	Variables  map[string]string
+}
+
+// UserInfo represents account data from Auth Service
+type UserInfo struct {
+	ID    uint
+	Email string
+	Name  string
+}
+
+// UserClient interface for looking up who to remind; it returns nil for unknown users
+type UserClient interface {
+	GetUser(ctx context.Context, userID uint) (*UserInfo, error)
+}
+
+// OrderHistory interface for telling carts that were turned into orders apart
+type OrderHistory interface {
+	// LastOrderAt returns when the user last placed an order, or nil if they never did
+	LastOrderAt(ctx context.Context, userID uint) (*time.Time, error)
+}
+
+// Notifier interface for sending reminders through Notification Service
+type Notifier interface {
+	SendEmail(ctx context.Context, email *ReminderEmail) error
+}
+
+// ReminderService defines the interface for abandoned cart reminders
+type ReminderService interface {
+	// SendReminders sends each stage's reminder to the carts idle past it but not yet past the
+	// next stage. Empty, converted and opted-out carts are skipped, and a cart gets at most one
+	// reminder per stage. It returns how many reminders were sent.
+	SendReminders(ctx context.Context, now time.Time) (int, error)
+	// SetOptOut turns abandoned cart reminders off, or back on, for a user
+	SetOptOut(ctx context.Context, userID uint, optOut bool) error
+}
+
+type reminderServiceImpl struct {
+	reminderRepo repository.ReminderRepository
+	cartRepo     repository.CartRepository
+	orders       OrderHistory
+	users        UserClient
+	notifier     Notifier
+	stages       []ReminderStage
+	cartURL      string
+}
+
+// NewReminderService creates a new ReminderService. cartURL is linked from the reminders.
+func NewReminderService(reminderRepo repository.ReminderRepository, cartRepo repository.CartRepository, orders OrderHistory,
+	users UserClient, notifier Notifier, stages []ReminderStage, cartURL string) ReminderService {
+	sorted := append([]ReminderStage(nil), stages...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].After < sorted[j].After })
+
+	return &reminderServiceImpl{
+		reminderRepo: reminderRepo,
+		cartRepo:     cartRepo,
+		orders:       orders,
+		users:        users,
+		notifier:     notifier,
+		stages:       sorted,
+		cartURL:      cartURL,
+	}
+}
+
+func (s *reminderServiceImpl) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	if err := s.reminderRepo.PruneExpired(ctx, now); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for i, stage := range s.stages {
+		// A cart already past the next stage gets that one instead of a late reminder for this one
+		var from time.Time
+		if i+1 < len(s.stages) {
+			from = now.Add(-s.stages[i+1].After)
+		}
+		userIDs, err := s.reminderRepo.FindIdle(ctx, from, now.Add(-stage.After))
+		if err != nil {
+			return sent, err
+		}
+
+		for _, userID := range userIDs {
+			reminded, err := s.remind(ctx, userID, i+1, stage, now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("remind user %d: %w", userID, err))
+				continue
+			}
+			if reminded {
+				sent++
+			}
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// remind sends a stage's reminder for the user's cart if it is still due
+func (s *reminderServiceImpl) remind(ctx context.Context, userID uint, stageNumber int, stage ReminderStage, now time.Time) (bool, error) {
+	optedOut, err := s.reminderRepo.IsOptedOut(ctx, userID)
+	if err != nil || optedOut {
+		return false, err
+	}
+
+	cart, err := s.cartRepo.Get(ctx, domain.UserCart(userID))
+	if err != nil {
+		return false, err
+	}
+	items := availableItems(cart)
+	// Changed since it was found idle, or nothing left to come back for
+	if len(items) == 0 || cart.UpdatedAt.After(now.Add(-stage.After)) {
+		return false, nil
+	}
+
+	lastOrder, err := s.orders.LastOrderAt(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if lastOrder != nil && !lastOrder.Before(cart.StartedAt) {
+		return false, nil
+	}
+
+	user, err := s.users.GetUser(ctx, userID)
+	if err != nil || user == nil || user.Email == "" {
+		return false, err
+	}
+
+	marked, err := s.reminderRepo.MarkSent(ctx, userID, cart.StartedAt, stageNumber)
+	if err != nil || !marked {
+		return false, err
+	}
+	if err := s.notifier.SendEmail(ctx, s.reminderEmail(user, cart, items, stage)); err != nil {
+		if unmarkErr := s.reminderRepo.UnmarkSent(ctx, userID, cart.StartedAt, stageNumber); unmarkErr != nil {
+			return false, errors.Join(err, unmarkErr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *reminderServiceImpl) reminderEmail(user *UserInfo, cart *domain.Cart, items []domain.CartItem, stage ReminderStage) *ReminderEmail {
+	names := make([]string, len(items))
+	count := 0
+	for i, item := range items {
+		names[i] = fmt.Sprintf("%s × %d", item.ProductName, item.Quantity)
+		count += item.Quantity
+	}
+
+	return &ReminderEmail{
+		UserID:     user.ID,
+		To:         user.Email,
+		TemplateID: stage.TemplateID,
+		Variables: map[string]string{
+			"name":       user.Name,
+			"items":      strings.Join(names, ", "),
+			"item_count": strconv.Itoa(count),
+			"total":      currency.Base.Format(cart.TotalPrice),
+			"cart_url":   s.cartURL,
+		},
+	}
+}
+
+func (s *reminderServiceImpl) SetOptOut(ctx context.Context, userID uint, optOut bool) error {
+	return s.reminderRepo.SetOptOut(ctx, userID, optOut)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/cart/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOrderHistory map[uint]time.Time
+
+func (f fakeOrderHistory) LastOrderAt(ctx context.Context, userID uint) (*time.Time, error) {
+	if at, ok := f[userID]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+type fakeUsers struct{}
+
+func (fakeUsers) GetUser(ctx context.Context, userID uint) (*UserInfo, error) {
+	return &UserInfo{ID: userID, Email: "customer@example.com", Name: "Budi"}, nil
+}
+
+type fakeNotifier struct {
+	sent []*ReminderEmail
+	err  error
+}
+
+func (f *fakeNotifier) SendEmail(ctx context.Context, email *ReminderEmail) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, email)
+	return nil
+}
+
+type reminderFixture struct {
+	service   ReminderService
+	reminders repository.ReminderRepository
+	carts     repository.CartRepository
+	orders    fakeOrderHistory
+	notifier  *fakeNotifier
+}
+
+func newReminderFixture(t *testing.T) *reminderFixture {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	f := &reminderFixture{
+		reminders: repository.NewRedisReminderRepository(client),
+		carts:     repository.NewRedisCartRepository(client),
+		orders:    fakeOrderHistory{},
+		notifier:  &fakeNotifier{},
+	}
+	stages := []ReminderStage{
+		{After: time.Hour, TemplateID: "abandoned_cart_1"},
+		{After: 24 * time.Hour, TemplateID: "abandoned_cart_2"},
+	}
+	f.service = NewReminderService(f.reminders, f.carts, f.orders, fakeUsers{}, f.notifier, stages, "https://shop.example/cart")
+	return f
+}
+
+func (f *reminderFixture) fillCart(t *testing.T, userID uint) *domain.Cart {
+	cart, err := f.carts.Update(context.Background(), domain.UserCart(userID), domain.AnyVersion, func(cart *domain.Cart) error {
+		cart.AddItem(domain.CartItem{ProductID: 1, ProductName: "Kopi", Price: 50_000, Quantity: 2})
+		return nil
+	})
+	require.NoError(t, err)
+	return cart
+}
+
+func TestReminderService_SendReminders_OncePerStage(t *testing.T) {
+	// Arrange
+	f := newReminderFixture(t)
+	f.fillCart(t, 7)
+	ctx := context.Background()
+
+	// Act: two scans while the cart is in the first stage, then one in the second
+	first, err := f.service.SendReminders(ctx, time.Now().Add(90*time.Minute))
+	require.NoError(t, err)
+	repeat, err := f.service.SendReminders(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	second, err := f.service.SendReminders(ctx, time.Now().Add(25*time.Hour))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, []int{1, 0, 1}, []int{first, repeat, second})
+	require.Len(t, f.notifier.sent, 2)
+	assert.Equal(t, "abandoned_cart_1", f.notifier.sent[0].TemplateID)
+	assert.Equal(t, "abandoned_cart_2", f.notifier.sent[1].TemplateID)
+	assert.Equal(t, "customer@example.com", f.notifier.sent[0].To)
+	assert.Equal(t, "Kopi × 2", f.notifier.sent[0].Variables["items"])
+	assert.Equal(t, "2", f.notifier.sent[0].Variables["item_count"])
+}
+
+func TestReminderService_SendReminders_Skips(t *testing.T) {
+	// Arrange
+	f := newReminderFixture(t)
+	ctx := context.Background()
+
+	f.fillCart(t, 1)
+	require.NoError(t, f.service.SetOptOut(ctx, 1, true))
+
+	cart := f.fillCart(t, 2)
+	f.orders[2] = cart.StartedAt.Add(time.Minute) // checked out
+
+	f.fillCart(t, 3)
+	_, err := f.carts.Update(ctx, domain.UserCart(3), domain.AnyVersion, func(cart *domain.Cart) error {
+		cart.Clear()
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Act
+	sent, err := f.service.SendReminders(ctx, time.Now().Add(90*time.Minute))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Empty(t, f.notifier.sent)
+}
+
+func TestReminderService_SendReminders_RetriesFailedSend(t *testing.T) {
+	// Arrange
+	f := newReminderFixture(t)
+	f.fillCart(t, 7)
+	ctx := context.Background()
+	f.notifier.err = errors.New("notification service unavailable")
+
+	// Act
+	_, err := f.service.SendReminders(ctx, time.Now().Add(90*time.Minute))
+	require.Error(t, err)
+	f.notifier.err = nil
+	sent, err := f.service.SendReminders(ctx, time.Now().Add(2*time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
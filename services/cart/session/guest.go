@@ -0,0 +1,57 @@
+// Package session identifies anonymous shoppers by a signed guest session cookie.
+//
+// The cookie holds a random guest id and an HMAC of it, so a client can keep its own
+// guest cart but cannot guess or forge the id of another guest's cart.
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// CookieName is the cookie carrying the guest session
+const CookieName = "cart_session"
+
+// guestIDBytes is the length of random guest ids
+const guestIDBytes = 16
+
+// GuestSessions issues and verifies guest session tokens
+type GuestSessions struct {
+	secret []byte
+}
+
+// NewGuestSessions creates GuestSessions signing with secret
+func NewGuestSessions(secret string) *GuestSessions {
+	return &GuestSessions{secret: []byte(secret)}
+}
+
+// New returns a new guest id and the signed token to store in the cookie
+func (g *GuestSessions) New() (guestID, token string, err error) {
+	raw := make([]byte, guestIDBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	guestID = hex.EncodeToString(raw)
+	return guestID, guestID + "." + g.signature(guestID), nil
+}
+
+// Verify returns the guest id of a token if its signature is valid
+func (g *GuestSessions) Verify(token string) (string, bool) {
+	guestID, signature, found := strings.Cut(token, ".")
+	if !found || len(guestID) != 2*guestIDBytes {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(g.signature(guestID))) {
+		return "", false
+	}
+	return guestID, true
+}
+
+func (g *GuestSessions) signature(guestID string) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(guestID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestSessions_NewAndVerify(t *testing.T) {
+	// Arrange
+	sessions := NewGuestSessions("test-secret")
+
+	// Act
+	guestID, token, err := sessions.New()
+	require.NoError(t, err)
+	verified, ok := sessions.Verify(token)
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, guestID, verified)
+	assert.Len(t, guestID, 32)
+}
+
+func TestGuestSessions_Verify_RejectsForgedTokens(t *testing.T) {
+	sessions := NewGuestSessions("test-secret")
+	guestID, token, err := sessions.New()
+	require.NoError(t, err)
+	_, otherToken, err := NewGuestSessions("other-secret").New()
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"empty":          "",
+		"unsigned":       guestID,
+		"other guest id": "0123456789abcdef0123456789abcdef" + token[len(guestID):],
+		"other secret":   otherToken,
+		"truncated":      token[:len(token)-1],
+	}
+
+	for name, forged := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, ok := sessions.Verify(forged)
+			assert.False(t, ok)
+		})
+	}
+}
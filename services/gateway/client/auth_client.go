@@ -20,6 +20,7 @@ type UserInfo struct {
 	ID    uint
 	Email string
 	Name  string
+	Role  string
 }
 
 // NewAuthClient creates a new gRPC client connection to Auth Service
@@ -63,6 +64,7 @@ func (c *AuthClient) ValidateToken(ctx context.Context, token string) (*UserInfo
 		ID:    uint(resp.UserId),
 		Email: resp.Email,
 		Name:  "", // Name not available in ValidateToken response
+		Role:  resp.Role,
 	}, nil
 }
 
@@ -55,6 +55,7 @@ func AuthMiddleware(authClient *client.AuthClient) gin.HandlerFunc {
 		c.Set("user_id", user.ID)
 		c.Set("user_email", user.Email)
 		c.Set("user_name", user.Name)
+		c.Set("user_role", user.Role)
 
 		c.Next()
 	}
@@ -81,6 +82,7 @@ func OptionalAuthMiddleware(authClient *client.AuthClient) gin.HandlerFunc {
 			c.Set("user_id", user.ID)
 			c.Set("user_email", user.Email)
 			c.Set("user_name", user.Name)
+			c.Set("user_role", user.Role)
 		}
 
 		c.Next()
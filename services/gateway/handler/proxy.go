@@ -3,12 +3,15 @@ package handler
 import (
 	"io"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
 )
 
+// forwardingHeaders carry the client address through proxies; the gateway replaces them
+var forwardingHeaders = []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}
+
 // ServiceConfig holds configuration for a backend service
 type ServiceConfig struct {
 	Name    string
@@ -19,15 +22,17 @@ type ServiceConfig struct {
 type ProxyHandler struct {
 	httpClient *http.Client
 	services   map[string]*ServiceConfig
+	signer     *auth.Signer
 }
 
 // NewProxyHandler creates a new proxy handler
-func NewProxyHandler(services map[string]*ServiceConfig) *ProxyHandler {
+func NewProxyHandler(services map[string]*ServiceConfig, signer *auth.Signer) *ProxyHandler {
 	return &ProxyHandler{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 		services: services,
+		signer:   signer,
 	}
 }
 
@@ -72,12 +77,30 @@ func (p *ProxyHandler) Proxy(serviceName string) gin.HandlerFunc {
 			}
 		}
 
-		// Add user info headers if authenticated
-		if userID, exists := c.Get("user_id"); exists {
-			proxyReq.Header.Set("X-User-ID", formatUint(userID.(uint)))
+		// Clients can put any address in forwarding headers, so report the one the gateway saw
+		clientIP := c.ClientIP()
+		for _, name := range forwardingHeaders {
+			proxyReq.Header.Del(name)
 		}
-		if userEmail, exists := c.Get("user_email"); exists {
-			proxyReq.Header.Set("X-User-Email", userEmail.(string))
+		proxyReq.Header.Set("X-Forwarded-For", clientIP)
+
+		// Never forward identity headers sent by the client; only the gateway may assert identity
+		auth.StripIdentity(proxyReq.Header)
+		if userID, exists := c.Get("user_id"); exists {
+			err := p.signer.Sign(proxyReq, auth.Identity{
+				UserID:   userID.(uint),
+				Email:    c.GetString("user_email"),
+				Role:     c.GetString("user_role"),
+				ClientIP: clientIP,
+			})
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success": false,
+					"message": "Failed to read request body",
+					"error":   err.Error(),
+				})
+				return
+			}
 		}
 
 		// Add request ID
@@ -104,20 +127,10 @@ func (p *ProxyHandler) Proxy(serviceName string) gin.HandlerFunc {
 			}
 		}
 
-		// Copy response body
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"message": "Failed to read response",
-			})
-			return
+		// Stream the response body so large responses (e.g. CSV exports) are not buffered
+		c.Status(resp.StatusCode)
+		if _, err := io.Copy(c.Writer, resp.Body); err != nil {
+			_ = c.Error(err)
 		}
-
-		c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), body)
 	}
 }
-
-func formatUint(n uint) string {
-	return strconv.FormatUint(uint64(n), 10)
-}
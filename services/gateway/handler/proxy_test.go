@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyHandler_Proxy_ReportsTheClientAddress(t *testing.T) {
+	// Arrange: a backend that verifies the forwarded identity
+	signer := auth.NewSigner("test-secret")
+	var identity *auth.Identity
+	var forwardedFor, realIP string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		identity, err = signer.Verify(r)
+		require.NoError(t, err)
+		forwardedFor = r.Header.Get("X-Forwarded-For")
+		realIP = r.Header.Get("X-Real-IP")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(backend.Close)
+
+	proxy := NewProxyHandler(map[string]*ServiceConfig{"payment": {Name: "payment-service", BaseURL: backend.URL}}, signer)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	router.POST("/api/v1/payments", func(c *gin.Context) {
+		c.Set("user_id", uint(42))
+		c.Set("user_role", auth.RoleCustomer)
+	}, proxy.Proxy("payment"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	req.Header.Set("X-Real-IP", "10.0.0.2")
+	req.Header.Set(auth.HeaderClientIP, "10.0.0.3")
+	rec := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert: the addresses the client claimed are replaced by the one it connected from
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	require.NotNil(t, identity)
+	assert.Equal(t, "203.0.113.7", identity.ClientIP)
+	assert.Equal(t, "203.0.113.7", forwardedFor)
+	assert.Empty(t, realIP)
+}
@@ -11,7 +11,10 @@ const (
 	NotificationTypePush  NotificationType = "push"
 )
 
-// NotificationStatus represents the delivery status
+// NotificationStatus represents the delivery status.
+// Notifications are queued as pending and stay pending while they are retried. They become sent
+// once a provider accepts them and delivered when the provider confirms it, or failed once they
+// run out of attempts; failed notifications are dead letters that staff can queue again.
 type NotificationStatus string
 
 const (
@@ -26,34 +29,31 @@ type Notification struct {
 	ID         uint               `json:"id" gorm:"primaryKey"`
 	UserID     uint               `json:"user_id" gorm:"index"`
 	Type       NotificationType   `json:"type"`
-	Status     NotificationStatus `json:"status" gorm:"default:pending"`
+	Status     NotificationStatus `json:"status" gorm:"default:pending;index"`
 	Subject    string             `json:"subject"`
 	Content    string             `json:"content" gorm:"type:text"`
 	Recipient  string             `json:"recipient"` // email/phone/device_token
 	TemplateID string             `json:"template_id,omitempty"`
-	Metadata   string             `json:"metadata,omitempty" gorm:"type:text"` // JSON metadata
-	SentAt     *time.Time         `json:"sent_at"`
-	Error      string             `json:"error,omitempty"`
-	CreatedAt  time.Time          `json:"created_at"`
-	UpdatedAt  time.Time          `json:"updated_at"`
+	// TemplateLocale and TemplateVersion record which variant of the template was rendered
+	TemplateLocale  string     `json:"template_locale,omitempty"`
+	TemplateVersion int        `json:"template_version,omitempty"`
+	Metadata        string     `json:"metadata,omitempty" gorm:"type:text"` // JSON metadata
+	SentAt          *time.Time `json:"sent_at"`
+	Error           string     `json:"error,omitempty"` // Why the last attempt failed
+	// Attempts counts sends tried so far, out of MaxAttempts
+	Attempts    int `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int `json:"max_attempts" gorm:"not null;default:1"`
+	// NextAttemptAt is when a pending notification is due to be tried
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" gorm:"index"`
+	// LockedUntil is set while a worker is sending the notification, so no other worker picks it up
+	LockedUntil *time.Time `json:"-"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"` // When it was dead-lettered
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // TableName overrides the table name
 func (Notification) TableName() string {
 	return "notifications"
 }
-
-// NotificationTemplate represents email/sms templates
-type NotificationTemplate struct {
-	ID        string           `json:"id" gorm:"primaryKey"` // e.g., "order_confirmation"
-	Type      NotificationType `json:"type"`
-	Subject   string           `json:"subject"`
-	Body      string           `json:"body" gorm:"type:text"`
-	IsActive  bool             `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time        `json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
-}
-
-func (NotificationTemplate) TableName() string {
-	return "notification_templates"
-}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// NotificationTemplate is a message that can be sent by its ID, e.g. "order_confirmation".
+// Its content is kept per locale in TemplateVersions, and the latest version of each locale is the
+// one sent. Recipients whose locale has no variant get the template's default locale.
+type NotificationTemplate struct {
+	ID            string           `json:"id" gorm:"primaryKey;size:100"`
+	Type          NotificationType `json:"type"`
+	Description   string           `json:"description"`
+	DefaultLocale string           `json:"default_locale" gorm:"size:20;not null;default:en"`
+	IsActive      bool             `json:"is_active" gorm:"default:true"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
+
+// TemplateVersion is one revision of a template's content in one locale. Versions are never
+// changed; editing a template adds a version, and restoring an old one copies it as the newest.
+type TemplateVersion struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	TemplateID string `json:"template_id" gorm:"size:100;not null;uniqueIndex:idx_template_locale_version"`
+	Locale     string `json:"locale" gorm:"size:20;not null;uniqueIndex:idx_template_locale_version"` // e.g. "en", "id"
+	Version    int    `json:"version" gorm:"not null;uniqueIndex:idx_template_locale_version"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body" gorm:"type:text"`
+	// SampleData is a JSON object of variables to preview the template with
+	SampleData string    `json:"sample_data" gorm:"type:text"`
+	CreatedBy  uint      `json:"created_by"` // Staff user who saved it; 0 for built-in content
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (TemplateVersion) TableName() string {
+	return "notification_template_versions"
+}
@@ -4,12 +4,14 @@ import "github.com/herman-xphp/go-microservices-ecommerce/services/notification/
 
 // SendEmailRequest represents a request to send an email
 type SendEmailRequest struct {
-	UserID     uint              `json:"user_id" binding:"required"`
-	To         string            `json:"to" binding:"required,email"`
-	Subject    string            `json:"subject" binding:"required"`
-	Body       string            `json:"body"`
-	TemplateID string            `json:"template_id,omitempty"`
-	Variables  map[string]string `json:"variables,omitempty"`
+	UserID     uint   `json:"user_id" binding:"required"`
+	To         string `json:"to" binding:"required,email"`
+	Subject    string `json:"subject" binding:"required_without=TemplateID"` // Defaults to the template's
+	Body       string `json:"body"`
+	TemplateID string `json:"template_id,omitempty"`
+	// Locale picks the template variant, e.g. "id"; defaults to the template's default locale
+	Locale    string                 `json:"locale,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
 }
 
 // SendSMSRequest represents a request to send an SMS
@@ -38,9 +40,19 @@ type NotificationResponse struct {
 	Status    domain.NotificationStatus `json:"status"`
 	Subject   string                    `json:"subject"`
 	Recipient string                    `json:"recipient"`
-	SentAt    string                    `json:"sent_at,omitempty"`
-	Error     string                    `json:"error,omitempty"`
-	CreatedAt string                    `json:"created_at"`
+	Attempts  int                       `json:"attempts"`
+	// NextAttemptAt is when a pending notification is next tried
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+	SentAt        string `json:"sent_at,omitempty"`
+	DeliveredAt   string `json:"delivered_at,omitempty"`
+	FailedAt      string `json:"failed_at,omitempty"` // When it ran out of attempts
+	Error         string `json:"error,omitempty"`     // Why the last attempt failed
+	CreatedAt     string `json:"created_at"`
+}
+
+// RetryDeadLettersRequest picks the dead letters to queue again; leave IDs empty to retry them all
+type RetryDeadLettersRequest struct {
+	IDs []uint `json:"ids"`
 }
 
 // OrderConfirmationData represents data for order confirmation email
@@ -49,6 +61,8 @@ type OrderConfirmationData struct {
 	CustomerName string          `json:"customer_name"`
 	TotalAmount  float64         `json:"total_amount"`
 	OrderItems   []OrderItemData `json:"order_items"`
+	Currency     string          `json:"currency"` // Defaults to the base currency
+	Locale       string          `json:"locale"`
 }
 
 type OrderItemData struct {
@@ -64,4 +78,6 @@ type PaymentSuccessData struct {
 	Amount        float64 `json:"amount"`
 	PaymentMethod string  `json:"payment_method"`
 	TransactionID string  `json:"transaction_id"`
+	Currency      string  `json:"currency"` // Defaults to the base currency
+	Locale        string  `json:"locale"`
 }
@@ -0,0 +1,73 @@
+package dto
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+
+// CreateTemplateRequest creates a template with its first variant, in its default locale
+type CreateTemplateRequest struct {
+	ID            string                  `json:"id" binding:"required,max=100"`                 // e.g. "order_shipped"
+	Type          domain.NotificationType `json:"type" binding:"omitempty,oneof=email sms push"` // Defaults to email
+	Description   string                  `json:"description"`
+	DefaultLocale string                  `json:"default_locale"` // Defaults to "en"
+	IsActive      *bool                   `json:"is_active"`      // Defaults to true
+	Subject       string                  `json:"subject" binding:"required"`
+	Body          string                  `json:"body" binding:"required"`
+	// SampleData are variables to preview the template with; the content must render with them
+	SampleData map[string]interface{} `json:"sample_data"`
+}
+
+// UpdateTemplateRequest changes a template's settings; content is changed by saving a variant
+type UpdateTemplateRequest struct {
+	Description   *string `json:"description"`
+	DefaultLocale *string `json:"default_locale"` // Must have a variant
+	IsActive      *bool   `json:"is_active"`
+}
+
+// SaveTemplateVariantRequest saves a locale's content as its next version
+type SaveTemplateVariantRequest struct {
+	Subject    string                 `json:"subject" binding:"required"`
+	Body       string                 `json:"body" binding:"required"`
+	SampleData map[string]interface{} `json:"sample_data"`
+}
+
+// PreviewTemplateRequest renders a template without sending it. Everything is optional: by
+// default the latest version of the default locale is rendered with its sample data.
+type PreviewTemplateRequest struct {
+	Locale  string `json:"locale"`  // Falls back like sending does
+	Version int    `json:"version"` // A past version of the locale
+	// Subject and Body render unsaved content instead, e.g. while it is being edited
+	Subject   string                 `json:"subject"`
+	Body      string                 `json:"body"`
+	Variables map[string]interface{} `json:"variables"` // Defaults to the sample data
+}
+
+// TemplateResponse represents a template with the live version of each locale
+type TemplateResponse struct {
+	ID            string                    `json:"id"`
+	Type          domain.NotificationType   `json:"type"`
+	Description   string                    `json:"description"`
+	DefaultLocale string                    `json:"default_locale"`
+	IsActive      bool                      `json:"is_active"`
+	Variants      []TemplateVersionResponse `json:"variants"`
+	CreatedAt     string                    `json:"created_at"`
+	UpdatedAt     string                    `json:"updated_at"`
+}
+
+// TemplateVersionResponse represents a version of a template's locale
+type TemplateVersionResponse struct {
+	Locale     string                 `json:"locale"`
+	Version    int                    `json:"version"`
+	Subject    string                 `json:"subject"`
+	Body       string                 `json:"body"`
+	SampleData map[string]interface{} `json:"sample_data"`
+	CreatedBy  uint                   `json:"created_by,omitempty"`
+	CreatedAt  string                 `json:"created_at"`
+}
+
+// RenderedTemplateResponse is a rendered template; Version is left out for unsaved content
+type RenderedTemplateResponse struct {
+	TemplateID string `json:"template_id"`
+	Locale     string `json:"locale"`
+	Version    int    `json:"version,omitempty"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+}
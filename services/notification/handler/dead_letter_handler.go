@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
+)
+
+// DeadLetterHandler handles HTTP requests for notifications that ran out of attempts
+type DeadLetterHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewDeadLetterHandler creates a new DeadLetterHandler
+func NewDeadLetterHandler(notificationService service.NotificationService) *DeadLetterHandler {
+	return &DeadLetterHandler{notificationService: notificationService}
+}
+
+// RegisterRoutes registers dead letter routes
+func (h *DeadLetterHandler) RegisterRoutes(router *gin.RouterGroup) {
+	deadLetters := router.Group("/admin/notifications/dead-letters", auth.RequireStaff())
+	{
+		deadLetters.GET("", h.ListDeadLetters)
+		deadLetters.POST("/retry", h.RetryDeadLetters)
+	}
+}
+
+// ListDeadLetters lists notifications that ran out of attempts, newest first
+// GET /api/v1/admin/notifications/dead-letters
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	notifications, total, err := h.notificationService.GetDeadLetters(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"notifications": notifications,
+			"total":         total,
+			"page":          page,
+			"page_size":     pageSize,
+		},
+	})
+}
+
+// RetryDeadLetters queues dead letters for delivery again, the given ones or else all of them
+// POST /api/v1/admin/notifications/dead-letters/retry
+func (h *DeadLetterHandler) RetryDeadLetters(c *gin.Context) {
+	var req dto.RetryDeadLettersRequest
+	// The body is optional: no body retries every dead letter
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid request",
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	requeued, err := h.notificationService.RetryDeadLetters(req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Dead letters queued for delivery",
+		"data":    gin.H{"requeued": requeued},
+	})
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/dto"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
 )
@@ -23,14 +25,16 @@ func NewNotificationHandler(notificationService service.NotificationService) *No
 func (h *NotificationHandler) RegisterRoutes(router *gin.RouterGroup) {
 	notifications := router.Group("/notifications")
 	{
-		notifications.POST("/email", h.SendEmail)
-		notifications.POST("/sms", h.SendSMS)
-		notifications.POST("/push", h.SendPush)
+		// Sending is reserved for back-office staff and internal services
+		notifications.POST("/email", auth.RequireStaff(), h.SendEmail)
+		notifications.POST("/sms", auth.RequireStaff(), h.SendSMS)
+		notifications.POST("/push", auth.RequireStaff(), h.SendPush)
+		notifications.POST("/:id/delivered", auth.RequireStaff(), h.MarkDelivered)
 		notifications.GET("", h.GetUserNotifications)
 	}
 }
 
-// SendEmail sends an email notification
+// SendEmail queues an email notification
 // POST /api/v1/notifications/email
 func (h *NotificationHandler) SendEmail(c *gin.Context) {
 	var req dto.SendEmailRequest
@@ -45,25 +49,18 @@ func (h *NotificationHandler) SendEmail(c *gin.Context) {
 
 	notification, err := h.notificationService.SendEmail(&req)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrTemplateNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
+		respondTemplateError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusAccepted, gin.H{
 		"success": true,
-		"message": "Email sent successfully",
+		"message": "Email queued for delivery",
 		"data":    notification,
 	})
 }
 
-// SendSMS sends an SMS notification
+// SendSMS queues an SMS notification
 // POST /api/v1/notifications/sms
 func (h *NotificationHandler) SendSMS(c *gin.Context) {
 	var req dto.SendSMSRequest
@@ -85,14 +82,14 @@ func (h *NotificationHandler) SendSMS(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusAccepted, gin.H{
 		"success": true,
-		"message": "SMS sent successfully",
+		"message": "SMS queued for delivery",
 		"data":    notification,
 	})
 }
 
-// SendPush sends a push notification
+// SendPush queues a push notification
 // POST /api/v1/notifications/push
 func (h *NotificationHandler) SendPush(c *gin.Context) {
 	var req dto.SendPushRequest
@@ -114,25 +111,53 @@ func (h *NotificationHandler) SendPush(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusAccepted, gin.H{
 		"success": true,
-		"message": "Push notification sent successfully",
+		"message": "Push notification queued for delivery",
 		"data":    notification,
 	})
 }
 
-// GetUserNotifications retrieves notifications for a user
-// GET /api/v1/notifications
-func (h *NotificationHandler) GetUserNotifications(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	if userID == nil {
-		if uid := c.GetHeader("X-User-ID"); uid != "" {
-			id, _ := strconv.ParseUint(uid, 10, 32)
-			userID = uint(id)
+// MarkDelivered records a provider's delivery receipt for a sent notification
+// POST /api/v1/notifications/:id/delivered
+func (h *NotificationHandler) MarkDelivered(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid notification ID",
+		})
+		return
+	}
+
+	notification, err := h.notificationService.MarkDelivered(uint(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrNotificationNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrNotSent):
+			status = http.StatusConflict
 		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
 	}
 
-	if userID == nil || userID.(uint) == 0 {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification marked as delivered",
+		"data":    notification,
+	})
+}
+
+// GetUserNotifications retrieves notifications for the authenticated user (staff may pass user_id)
+// GET /api/v1/notifications
+func (h *NotificationHandler) GetUserNotifications(c *gin.Context) {
+	userID, ok := auth.TargetUserID(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"message": "User not authenticated",
@@ -143,7 +168,7 @@ func (h *NotificationHandler) GetUserNotifications(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	notifications, total, err := h.notificationService.GetUserNotifications(userID.(uint), page, pageSize)
+	notifications, total, err := h.notificationService.GetUserNotifications(userID, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
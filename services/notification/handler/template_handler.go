@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
+)
+
+// TemplateHandler handles HTTP requests for managing notification templates
+type TemplateHandler struct {
+	templateService service.TemplateService
+}
+
+// NewTemplateHandler creates a new TemplateHandler
+func NewTemplateHandler(templateService service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{templateService: templateService}
+}
+
+// RegisterRoutes registers template routes
+func (h *TemplateHandler) RegisterRoutes(router *gin.RouterGroup) {
+	templates := router.Group("/admin/notifications/templates", auth.RequireStaff())
+	{
+		templates.GET("", h.ListTemplates)
+		templates.POST("", h.CreateTemplate)
+		templates.GET("/:id", h.GetTemplate)
+		templates.PUT("/:id", h.UpdateTemplate)
+		templates.DELETE("/:id", h.DeleteTemplate)
+		templates.POST("/:id/preview", h.PreviewTemplate)
+		templates.PUT("/:id/locales/:locale", h.SaveVariant)
+		templates.GET("/:id/locales/:locale/versions", h.ListVersions)
+		templates.POST("/:id/locales/:locale/versions/:version/restore", h.RestoreVersion)
+	}
+}
+
+// ListTemplates lists templates with the live version of each locale
+// GET /api/v1/admin/notifications/templates
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	templates, total, err := h.templateService.ListTemplates(page, pageSize)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"templates": templates,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// CreateTemplate creates a template with the content of its default locale
+// POST /api/v1/admin/notifications/templates
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req dto.CreateTemplateRequest
+	if !bindTemplateRequest(c, &req) {
+		return
+	}
+
+	template, err := h.templateService.CreateTemplate(staffID(c), &req)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Template created",
+		"data":    template,
+	})
+}
+
+// GetTemplate retrieves a template with the live version of each locale
+// GET /api/v1/admin/notifications/templates/:id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	template, err := h.templateService.GetTemplate(c.Param("id"))
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// UpdateTemplate changes a template's description, default locale or active flag
+// PUT /api/v1/admin/notifications/templates/:id
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	var req dto.UpdateTemplateRequest
+	if !bindTemplateRequest(c, &req) {
+		return
+	}
+
+	template, err := h.templateService.UpdateTemplate(c.Param("id"), &req)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Template updated",
+		"data":    template,
+	})
+}
+
+// DeleteTemplate deletes a template and all of its versions
+// DELETE /api/v1/admin/notifications/templates/:id
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	if err := h.templateService.DeleteTemplate(c.Param("id")); err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Template deleted",
+	})
+}
+
+// PreviewTemplate renders a template with sample data, or with the variables given
+// POST /api/v1/admin/notifications/templates/:id/preview
+func (h *TemplateHandler) PreviewTemplate(c *gin.Context) {
+	var req dto.PreviewTemplateRequest
+	// The body is optional: no body previews the default locale with its sample data
+	if c.Request.ContentLength != 0 && !bindTemplateRequest(c, &req) {
+		return
+	}
+
+	rendered, err := h.templateService.Preview(c.Param("id"), &req)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rendered,
+	})
+}
+
+// SaveVariant saves a locale's content as its next version
+// PUT /api/v1/admin/notifications/templates/:id/locales/:locale
+func (h *TemplateHandler) SaveVariant(c *gin.Context) {
+	var req dto.SaveTemplateVariantRequest
+	if !bindTemplateRequest(c, &req) {
+		return
+	}
+
+	version, err := h.templateService.SaveVariant(staffID(c), c.Param("id"), c.Param("locale"), &req)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Template version saved",
+		"data":    version,
+	})
+}
+
+// ListVersions lists the versions of a template's locale, newest first
+// GET /api/v1/admin/notifications/templates/:id/locales/:locale/versions
+func (h *TemplateHandler) ListVersions(c *gin.Context) {
+	versions, err := h.templateService.ListVersions(c.Param("id"), c.Param("locale"))
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    versions,
+	})
+}
+
+// RestoreVersion makes an old version live again as the locale's next version
+// POST /api/v1/admin/notifications/templates/:id/locales/:locale/versions/:version/restore
+func (h *TemplateHandler) RestoreVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid version",
+		})
+		return
+	}
+
+	restored, err := h.templateService.RestoreVersion(staffID(c), c.Param("id"), c.Param("locale"), version)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Template version restored",
+		"data":    restored,
+	})
+}
+
+// bindTemplateRequest binds a JSON body, responding with 400 when it is invalid
+func bindTemplateRequest(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// staffID returns the user ID of the staff member making the request
+func staffID(c *gin.Context) uint {
+	identity, _ := auth.Current(c)
+	if identity == nil {
+		return 0
+	}
+	return identity.UserID
+}
+
+// respondTemplateError responds to errors from managing or rendering templates
+func respondTemplateError(c *gin.Context, err error) {
+	c.JSON(templateErrorStatus(err), gin.H{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound),
+		errors.Is(err, service.ErrVariantNotFound),
+		errors.Is(err, service.ErrVersionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrTemplateExists), errors.Is(err, service.ErrBuiltInTemplate):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidTemplateID),
+		errors.Is(err, service.ErrInvalidLocale),
+		errors.Is(err, service.ErrInvalidTemplate),
+		errors.Is(err, service.ErrTemplateVariables):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"gorm.io/gorm"
+)
+
+// MockNotificationRepository is a mock implementation for testing.
+// It stores copies, so changes to a returned notification are not visible until saved.
+// It is safe for concurrent use, like the database it stands in for.
+type MockNotificationRepository struct {
+	mu            sync.Mutex
+	notifications map[uint]domain.Notification
+	nextID        uint
+}
+
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return &MockNotificationRepository{
+		notifications: make(map[uint]domain.Notification),
+		nextID:        1,
+	}
+}
+
+func (m *MockNotificationRepository) Create(notification *domain.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	notification.ID = m.nextID
+	m.nextID++
+	m.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (m *MockNotificationRepository) Save(notification *domain.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (m *MockNotificationRepository) FindByID(id uint) (*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	notification, ok := m.notifications[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &notification, nil
+}
+
+func (m *MockNotificationRepository) FindByUserID(userID uint, page, pageSize int) ([]domain.Notification, int64, error) {
+	return m.find(func(n *domain.Notification) bool { return n.UserID == userID }, page, pageSize)
+}
+
+func (m *MockNotificationRepository) FindByStatus(status domain.NotificationStatus, page, pageSize int) ([]domain.Notification, int64, error) {
+	return m.find(func(n *domain.Notification) bool { return n.Status == status }, page, pageSize)
+}
+
+func (m *MockNotificationRepository) find(match func(*domain.Notification) bool, page, pageSize int) ([]domain.Notification, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []domain.Notification
+	for _, notification := range m.notifications {
+		if match(&notification) {
+			result = append(result, notification)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+
+	total := int64(len(result))
+	start := (page - 1) * pageSize
+	if start >= len(result) {
+		return nil, total, nil
+	}
+	end := start + pageSize
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[start:end], total, nil
+}
+
+func (m *MockNotificationRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []domain.Notification
+	for _, n := range m.notifications {
+		if n.Status == domain.NotificationStatusPending && n.NextAttemptAt != nil && !n.NextAttemptAt.After(now) &&
+			(n.LockedUntil == nil || !n.LockedUntil.After(now)) {
+			due = append(due, n)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	lockedUntil := now.Add(lease)
+	for i := range due {
+		due[i].LockedUntil = &lockedUntil
+		m.notifications[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (m *MockNotificationRepository) Requeue(ids []uint, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wanted := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var requeued int64
+	for id, n := range m.notifications {
+		if n.Status != domain.NotificationStatusFailed || (len(ids) > 0 && !wanted[id]) {
+			continue
+		}
+		n.Status = domain.NotificationStatusPending
+		n.Attempts = 0
+		n.NextAttemptAt = &now
+		n.LockedUntil = nil
+		n.FailedAt = nil
+		m.notifications[id] = n
+		requeued++
+	}
+	return requeued, nil
+}
+
+// MockTemplateRepository is a mock implementation of TemplateRepository for testing.
+// Like the database, it reports missing records with gorm.ErrRecordNotFound.
+type MockTemplateRepository struct {
+	mu        sync.Mutex
+	templates map[string]domain.NotificationTemplate
+	versions  []domain.TemplateVersion
+	nextID    uint
+}
+
+func NewMockTemplateRepository() *MockTemplateRepository {
+	return &MockTemplateRepository{
+		templates: make(map[string]domain.NotificationTemplate),
+		nextID:    1,
+	}
+}
+
+func (m *MockTemplateRepository) Create(template *domain.NotificationTemplate, versions []domain.TemplateVersion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.templates[template.ID]; ok {
+		return errors.New("duplicate key value violates unique constraint")
+	}
+	m.templates[template.ID] = *template
+	for i := range versions {
+		m.addVersion(&versions[i])
+	}
+	return nil
+}
+
+func (m *MockTemplateRepository) Update(template *domain.NotificationTemplate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.templates[template.ID] = *template
+	return nil
+}
+
+func (m *MockTemplateRepository) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.templates, id)
+	kept := m.versions[:0]
+	for _, v := range m.versions {
+		if v.TemplateID != id {
+			kept = append(kept, v)
+		}
+	}
+	m.versions = kept
+	return nil
+}
+
+func (m *MockTemplateRepository) FindByID(id string) (*domain.NotificationTemplate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	template, ok := m.templates[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &template, nil
+}
+
+func (m *MockTemplateRepository) FindAll(page, pageSize int) ([]domain.NotificationTemplate, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []domain.NotificationTemplate
+	for _, template := range m.templates {
+		result = append(result, template)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	total := int64(len(result))
+	start := (page - 1) * pageSize
+	if start >= len(result) {
+		return nil, total, nil
+	}
+	end := start + pageSize
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[start:end], total, nil
+}
+
+func (m *MockTemplateRepository) FindLatestVersions(templateIDs ...string) ([]domain.TemplateVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wanted := make(map[string]bool, len(templateIDs))
+	for _, id := range templateIDs {
+		wanted[id] = true
+	}
+	latest := make(map[[2]string]domain.TemplateVersion)
+	for _, v := range m.versions {
+		key := [2]string{v.TemplateID, v.Locale}
+		if wanted[v.TemplateID] && v.Version > latest[key].Version {
+			latest[key] = v
+		}
+	}
+
+	var result []domain.TemplateVersion
+	for _, v := range latest {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TemplateID != result[j].TemplateID {
+			return result[i].TemplateID < result[j].TemplateID
+		}
+		return result[i].Locale < result[j].Locale
+	})
+	return result, nil
+}
+
+func (m *MockTemplateRepository) FindVersions(templateID, locale string) ([]domain.TemplateVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []domain.TemplateVersion
+	for _, v := range m.versions {
+		if v.TemplateID == templateID && v.Locale == locale {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version > result[j].Version })
+	return result, nil
+}
+
+func (m *MockTemplateRepository) FindVersion(templateID, locale string, version int) (*domain.TemplateVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, v := range m.versions {
+		if v.TemplateID == templateID && v.Locale == locale && v.Version == version {
+			return &v, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockTemplateRepository) AddVersion(version *domain.TemplateVersion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.templates[version.TemplateID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	version.Version = 0
+	for _, v := range m.versions {
+		if v.TemplateID == version.TemplateID && v.Locale == version.Locale && v.Version > version.Version {
+			version.Version = v.Version
+		}
+	}
+	version.Version++
+	m.addVersion(version)
+	return nil
+}
+
+func (m *MockTemplateRepository) addVersion(version *domain.TemplateVersion) {
+	version.ID = m.nextID
+	m.nextID++
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+	m.versions = append(m.versions, *version)
+}
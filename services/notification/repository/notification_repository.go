@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+)
+
+// NotificationRepository defines the interface for notification data and the delivery queue
+type NotificationRepository interface {
+	Create(notification *domain.Notification) error
+	Save(notification *domain.Notification) error
+	FindByID(id uint) (*domain.Notification, error)
+	FindByUserID(userID uint, page, pageSize int) ([]domain.Notification, int64, error)
+	FindByStatus(status domain.NotificationStatus, page, pageSize int) ([]domain.Notification, int64, error)
+
+	// ClaimDue locks up to limit pending notifications that are due by now for the caller until
+	// now+lease, oldest due first. Notifications locked by another worker are left alone until
+	// their lock runs out, so a worker that dies mid-send doesn't strand them.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.Notification, error)
+	// Requeue moves failed notifications back to pending with a fresh set of attempts, due at now.
+	// With no ids, every failed notification is requeued. It returns how many were requeued.
+	Requeue(ids []uint, now time.Time) (int64, error)
+}
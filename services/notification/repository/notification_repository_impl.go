@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"gorm.io/gorm"
+)
+
+type notificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepositoryImpl{db: db}
+}
+
+func (r *notificationRepositoryImpl) Create(notification *domain.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *notificationRepositoryImpl) Save(notification *domain.Notification) error {
+	return r.db.Save(notification).Error
+}
+
+func (r *notificationRepositoryImpl) FindByID(id uint) (*domain.Notification, error) {
+	var notification domain.Notification
+	if err := r.db.First(&notification, id).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepositoryImpl) FindByUserID(userID uint, page, pageSize int) ([]domain.Notification, int64, error) {
+	return r.find(r.db.Where("user_id = ?", userID), page, pageSize)
+}
+
+func (r *notificationRepositoryImpl) FindByStatus(status domain.NotificationStatus, page, pageSize int) ([]domain.Notification, int64, error) {
+	return r.find(r.db.Where("status = ?", status), page, pageSize)
+}
+
+// find pages through the notifications matching query, newest first
+func (r *notificationRepositoryImpl) find(query *gorm.DB, page, pageSize int) ([]domain.Notification, int64, error) {
+	var notifications []domain.Notification
+	var total int64
+
+	if err := query.Model(&domain.Notification{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Offset(offset).
+		Limit(pageSize).
+		Order("created_at DESC").
+		Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *notificationRepositoryImpl) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+
+	// SKIP LOCKED lets replicas claim batches at the same time without waiting on each other
+	err := r.db.Raw(`
+		UPDATE notifications SET locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until <= ?)
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, domain.NotificationStatusPending, now, now, limit,
+	).Scan(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepositoryImpl) Requeue(ids []uint, now time.Time) (int64, error) {
+	query := r.db.Model(&domain.Notification{}).Where("status = ?", domain.NotificationStatusFailed)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"status":          domain.NotificationStatusPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"locked_until":    nil,
+		"failed_at":       nil,
+	})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+
+// TemplateRepository defines the interface for notification templates and their versions
+type TemplateRepository interface {
+	// Create stores a template together with its first versions
+	Create(template *domain.NotificationTemplate, versions []domain.TemplateVersion) error
+	Update(template *domain.NotificationTemplate) error
+	// Delete removes a template and all of its versions
+	Delete(id string) error
+	FindByID(id string) (*domain.NotificationTemplate, error)
+	FindAll(page, pageSize int) ([]domain.NotificationTemplate, int64, error)
+
+	// FindLatestVersions returns the latest version of each locale of the given templates
+	FindLatestVersions(templateIDs ...string) ([]domain.TemplateVersion, error)
+	// FindVersions lists the versions of a template's locale, newest first
+	FindVersions(templateID, locale string) ([]domain.TemplateVersion, error)
+	FindVersion(templateID, locale string, version int) (*domain.TemplateVersion, error)
+	// AddVersion stores content as the next version of its template's locale, setting its Version.
+	// Concurrent saves of the same locale get consecutive versions.
+	AddVersion(version *domain.TemplateVersion) error
+}
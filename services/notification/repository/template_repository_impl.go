@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type templateRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewTemplateRepository creates a new TemplateRepository
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &templateRepositoryImpl{db: db}
+}
+
+func (r *templateRepositoryImpl) Create(template *domain.NotificationTemplate, versions []domain.TemplateVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return nil
+		}
+		return tx.Create(&versions).Error
+	})
+}
+
+func (r *templateRepositoryImpl) Update(template *domain.NotificationTemplate) error {
+	return r.db.Save(template).Error
+}
+
+func (r *templateRepositoryImpl) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&domain.TemplateVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.NotificationTemplate{}, "id = ?", id).Error
+	})
+}
+
+func (r *templateRepositoryImpl) FindByID(id string) (*domain.NotificationTemplate, error) {
+	var template domain.NotificationTemplate
+	if err := r.db.First(&template, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *templateRepositoryImpl) FindAll(page, pageSize int) ([]domain.NotificationTemplate, int64, error) {
+	var templates []domain.NotificationTemplate
+	var total int64
+
+	if err := r.db.Model(&domain.NotificationTemplate{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := r.db.Order("id").Offset(offset).Limit(pageSize).Find(&templates).Error
+	return templates, total, err
+}
+
+func (r *templateRepositoryImpl) FindLatestVersions(templateIDs ...string) ([]domain.TemplateVersion, error) {
+	var versions []domain.TemplateVersion
+	if len(templateIDs) == 0 {
+		return versions, nil
+	}
+	err := r.db.Raw(`
+		SELECT DISTINCT ON (template_id, locale) * FROM notification_template_versions
+		WHERE template_id IN ?
+		ORDER BY template_id, locale, version DESC`,
+		templateIDs,
+	).Scan(&versions).Error
+	return versions, err
+}
+
+func (r *templateRepositoryImpl) FindVersions(templateID, locale string) ([]domain.TemplateVersion, error) {
+	var versions []domain.TemplateVersion
+	err := r.db.Where("template_id = ? AND locale = ?", templateID, locale).
+		Order("version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+func (r *templateRepositoryImpl) FindVersion(templateID, locale string, version int) (*domain.TemplateVersion, error) {
+	var v domain.TemplateVersion
+	err := r.db.Where("template_id = ? AND locale = ? AND version = ?", templateID, locale, version).First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *templateRepositoryImpl) AddVersion(version *domain.TemplateVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the template serialises version numbers across replicas
+		var template domain.NotificationTemplate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, "id = ?", version.TemplateID).Error; err != nil {
+			return err
+		}
+
+		var latest int
+		err := tx.Model(&domain.TemplateVersion{}).
+			Where("template_id = ? AND locale = ?", version.TemplateID, version.Locale).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		version.ID = 0
+		version.Version = latest + 1
+		return tx.Create(version).Error
+	})
+}
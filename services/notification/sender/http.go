@@ -0,0 +1,67 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPConfig configures a sender that calls a provider's HTTP API
+type HTTPConfig struct {
+	URL     string
+	APIKey  string // Sent as a bearer token
+	Timeout time.Duration
+}
+
+func (c HTTPConfig) client() *http.Client {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// providerError is a provider's answer other than success
+type providerError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *providerError) Error() string {
+	return fmt.Sprintf("%s: %d %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// postJSON sends payload to the provider and returns a *providerError for non-2xx answers
+func postJSON(client *http.Client, cfg HTTPConfig, provider string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Enough of the body to tell what went wrong, without storing a whole error page
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &providerError{Provider: provider, StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(message))}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package sender
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// providerStub answers every request with status, keeping the last request's auth header and JSON body
+type providerStub struct {
+	status int
+	auth   string
+	body   map[string]interface{}
+}
+
+func (p *providerStub) start(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.auth = r.Header.Get("Authorization")
+		p.body = nil
+		json.NewDecoder(r.Body).Decode(&p.body)
+		w.WriteHeader(p.status)
+		w.Write([]byte(`{"error":"stub answer"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPSMSSender_Send(t *testing.T) {
+	// Arrange
+	stub := &providerStub{status: http.StatusOK}
+	server := stub.start(t)
+	sender, err := NewHTTPSMSSender(HTTPConfig{URL: server.URL, APIKey: "sms-key"}, "GOSHOP")
+	require.NoError(t, err)
+
+	// Act
+	err = sender.Send("+628123456789", "Pesanan #42 telah dikirim")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer sms-key", stub.auth)
+	assert.Equal(t, map[string]interface{}{
+		"to": "+628123456789", "message": "Pesanan #42 telah dikirim", "sender_id": "GOSHOP",
+	}, stub.body)
+}
+
+func TestHTTPSMSSender_Send_Errors(t *testing.T) {
+	stub := &providerStub{}
+	server := stub.start(t)
+	sender, err := NewHTTPSMSSender(HTTPConfig{URL: server.URL}, "")
+	require.NoError(t, err)
+
+	// A rejected number is not worth retrying
+	stub.status = http.StatusUnprocessableEntity
+	err = sender.Send("12", "Halo")
+	assert.ErrorIs(t, err, service.ErrInvalidRecipient)
+
+	// An outage is
+	stub.status = http.StatusServiceUnavailable
+	err = sender.Send("+628123456789", "Halo")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, service.ErrInvalidRecipient)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestFCMPushSender_Send(t *testing.T) {
+	// Arrange
+	stub := &providerStub{status: http.StatusOK}
+	server := stub.start(t)
+	sender, err := NewFCMPushSender(HTTPConfig{URL: server.URL, APIKey: "access-token"})
+	require.NoError(t, err)
+
+	// Act
+	err = sender.Send("device-1", "Pesanan dikirim", "Pesanan #42 sedang dalam perjalanan", map[string]string{"order_id": "42"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer access-token", stub.auth)
+	assert.Equal(t, map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        "device-1",
+			"notification": map[string]interface{}{"title": "Pesanan dikirim", "body": "Pesanan #42 sedang dalam perjalanan"},
+			"data":         map[string]interface{}{"order_id": "42"},
+		},
+	}, stub.body)
+}
+
+func TestFCMPushSender_Send_UnregisteredToken(t *testing.T) {
+	stub := &providerStub{status: http.StatusNotFound}
+	server := stub.start(t)
+	sender, err := NewFCMPushSender(HTTPConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	err = sender.Send("stale-device", "Halo", "Halo", nil)
+
+	assert.ErrorIs(t, err, service.ErrInvalidRecipient)
+}
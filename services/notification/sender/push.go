@@ -0,0 +1,58 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
+)
+
+// FCMPushSender implements service.PushSender for Firebase Cloud Messaging's HTTP v1 API and
+// services that accept its payloads. It posts
+//
+//	{"message": {"token": "...", "notification": {"title": "...", "body": "..."}, "data": {...}}}
+//
+// to the send URL, e.g. https://fcm.googleapis.com/v1/projects/<project>/messages:send, with
+// the API key as an OAuth access token. 404 answers mean the device token is no longer registered.
+type FCMPushSender struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+// NewFCMPushSender creates an FCMPushSender
+func NewFCMPushSender(cfg HTTPConfig) (*FCMPushSender, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("push: URL is required")
+	}
+	return &FCMPushSender{cfg: cfg, client: cfg.client()}, nil
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+func (s *FCMPushSender) Send(deviceToken, title, body string, data map[string]string) error {
+	err := postJSON(s.client, s.cfg, "push service", fcmRequest{Message: fcmMessage{
+		Token:        deviceToken,
+		Notification: fcmNotification{Title: title, Body: body},
+		Data:         data,
+	}})
+
+	var providerErr *providerError
+	if errors.As(err, &providerErr) && providerErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", service.ErrInvalidRecipient, err)
+	}
+	return err
+}
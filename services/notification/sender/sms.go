@@ -0,0 +1,46 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
+)
+
+// HTTPSMSSender implements service.SMSSender for SMS gateways with a JSON API. It posts
+//
+//	{"to": "+628123456789", "message": "...", "sender_id": "GOSHOP"}
+//
+// and treats 400 and 422 answers as the gateway rejecting the number.
+type HTTPSMSSender struct {
+	cfg      HTTPConfig
+	senderID string
+	client   *http.Client
+}
+
+// NewHTTPSMSSender creates an HTTPSMSSender. senderID is the name shown to recipients, where
+// the gateway allows one.
+func NewHTTPSMSSender(cfg HTTPConfig, senderID string) (*HTTPSMSSender, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("sms: URL is required")
+	}
+	return &HTTPSMSSender{cfg: cfg, senderID: senderID, client: cfg.client()}, nil
+}
+
+type smsRequest struct {
+	To       string `json:"to"`
+	Message  string `json:"message"`
+	SenderID string `json:"sender_id,omitempty"`
+}
+
+func (s *HTTPSMSSender) Send(phoneNumber, message string) error {
+	err := postJSON(s.client, s.cfg, "sms gateway", smsRequest{To: phoneNumber, Message: message, SenderID: s.senderID})
+
+	var providerErr *providerError
+	if errors.As(err, &providerErr) &&
+		(providerErr.StatusCode == http.StatusBadRequest || providerErr.StatusCode == http.StatusUnprocessableEntity) {
+		return fmt.Errorf("%w: %v", service.ErrInvalidRecipient, err)
+	}
+	return err
+}
@@ -0,0 +1,257 @@
+// Package sender delivers notifications through external providers: email over SMTP, and SMS
+// and push notifications over the providers' HTTP APIs.
+package sender
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
+)
+
+// TLSMode is how an SMTP connection is secured
+type TLSMode string
+
+const (
+	// TLSModeStartTLS upgrades a plain connection with STARTTLS, usually on port 587
+	TLSModeStartTLS TLSMode = "starttls"
+	// TLSModeImplicit connects over TLS from the start, usually on port 465
+	TLSModeImplicit TLSMode = "tls"
+	// TLSModeNone sends in the clear; only for local mail sinks, as credentials are only sent to localhost
+	TLSModeNone TLSMode = "none"
+)
+
+// SMTPConfig configures an SMTPSender
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Leave empty for servers that don't need auth
+	Password string
+	From     string // e.g. "GoShop <no-reply@goshop.id>"
+	TLSMode  TLSMode
+	// TLSConfig overrides the TLS settings, e.g. to trust a private CA; the server name defaults to Host
+	TLSConfig *tls.Config
+	Timeout   time.Duration
+}
+
+// SMTPSender implements service.EmailSender over SMTP. Emails are sent as multipart messages
+// with the HTML body and a plain-text rendering of it.
+type SMTPSender struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+// NewSMTPSender creates an SMTPSender, checking the configuration
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp: host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("smtp: invalid from address %q: %w", cfg.From, err)
+	}
+	switch cfg.TLSMode {
+	case "":
+		cfg.TLSMode = TLSModeStartTLS
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, fmt.Errorf("smtp: unknown TLS mode %q", cfg.TLSMode)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{cfg: cfg, from: from}, nil
+}
+
+func (s *SMTPSender) Send(to, subject, body string) error {
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("%w: %v", service.ErrInvalidRecipient, err)
+	}
+	message, err := buildMessage(s.from, recipient, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		if isRejectedRecipient(err) {
+			return fmt.Errorf("%w: %v", service.ErrInvalidRecipient, err)
+		}
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects to the server and secures the connection as configured
+func (s *SMTPSender) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.TLSMode == TLSModeImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, s.tlsConfig())
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp dial %s: %w", addr, err)
+	}
+	// Bounds the whole conversation, so a stalled server doesn't hold a worker forever
+	conn.SetDeadline(time.Now().Add(s.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp: %w", err)
+	}
+	if s.cfg.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp: server does not support STARTTLS")
+		}
+		if err := client.StartTLS(s.tlsConfig()); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	return client, nil
+}
+
+func (s *SMTPSender) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if s.cfg.TLSConfig != nil {
+		cfg = s.cfg.TLSConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = s.cfg.Host
+	}
+	return cfg
+}
+
+// isRejectedRecipient reports whether the server refused a recipient for good, e.g. an unknown mailbox
+func isRejectedRecipient(err error) bool {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		return false
+	}
+	switch protoErr.Code {
+	case 501, 550, 551, 553:
+		return true
+	}
+	return false
+}
+
+// buildMessage formats an email with a plain-text and an HTML alternative
+func buildMessage(from, to *mail.Address, subject, htmlBody string, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", messageID(from))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	// Clients show the last alternative they understand, so HTML goes last
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", htmlToText(htmlBody)},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func messageID(from *mail.Address) string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain)
+}
+
+var (
+	htmlHeadPattern  = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>`)
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr)>`)
+	htmlLinkPattern  = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	blankLines       = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText renders an HTML email body as plain text, keeping paragraphs and link targets
+func htmlToText(body string) string {
+	text := htmlHeadPattern.ReplaceAllString(body, "")
+	text = htmlLinkPattern.ReplaceAllString(text, "$2 ($1)")
+	text = htmlBreakPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text) + "\n"
+}
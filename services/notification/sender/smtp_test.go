@@ -0,0 +1,248 @@
+package sender
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sinkMessage is an email received by smtpSink
+type sinkMessage struct {
+	From string
+	To   string
+	Data string
+	TLS  bool
+	Auth string // Decoded AUTH PLAIN credentials
+}
+
+// smtpSink is a local SMTP server that keeps what it receives. With a TLS config it offers STARTTLS.
+type smtpSink struct {
+	listener   net.Listener
+	tlsConfig  *tls.Config
+	rejectRcpt string
+	messages   chan sinkMessage
+}
+
+func startSMTPSink(t *testing.T, tlsConfig *tls.Config) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	sink := &smtpSink{listener: listener, tlsConfig: tlsConfig, messages: make(chan sinkMessage, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sink.serve(conn)
+		}
+	}()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	text := textproto.NewConn(conn)
+	var msg sinkMessage
+
+	text.PrintfLine("220 sink ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if s.tlsConfig != nil && !msg.TLS {
+				text.PrintfLine("250-sink")
+				text.PrintfLine("250-STARTTLS")
+			} else {
+				text.PrintfLine("250-sink")
+			}
+			text.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			text.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			msg.TLS = true
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			msg.Auth = string(credentials)
+			text.PrintfLine("235 Authenticated")
+		case "MAIL":
+			msg.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			msg.To = strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if msg.To == s.rejectRcpt {
+				text.PrintfLine("550 No such user")
+				continue
+			}
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.messages <- msg
+			text.PrintfLine("250 Queued")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// testCertificate borrows httptest's certificate for 127.0.0.1, returning a server config and a client pool trusting it
+func testCertificate(t *testing.T) (*tls.Config, *x509.CertPool) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return &tls.Config{Certificates: server.TLS.Certificates}, pool
+}
+
+const testEmailBody = `<!DOCTYPE html>
+<html>
+<head><style>body{font-family:Arial,sans-serif;}</style></head>
+<body>
+<p>Halo Budi,</p>
+<p>Terima kasih atas pesanan Anda &amp; pembayaran sebesar Rp&nbsp;150.000.</p>
+<p><a href="https://goshop.id/orders/42">Lihat pesanan</a></p>
+</body>
+</html>`
+
+func TestSMTPSender_Send_StartTLSWithAuth(t *testing.T) {
+	// Arrange
+	serverTLS, roots := testCertificate(t)
+	sink := startSMTPSink(t, serverTLS)
+	sender, err := NewSMTPSender(SMTPConfig{
+		Host:      "127.0.0.1",
+		Port:      sink.port(),
+		Username:  "mailer",
+		Password:  "secret",
+		From:      "GoShop <no-reply@goshop.id>",
+		TLSConfig: &tls.Config{RootCAs: roots},
+	})
+	require.NoError(t, err)
+
+	// Act
+	err = sender.Send("budi@example.com", "Pesanan #42 – dikonfirmasi", testEmailBody)
+
+	// Assert
+	require.NoError(t, err)
+	msg := <-sink.messages
+	assert.True(t, msg.TLS)
+	assert.Equal(t, "\x00mailer\x00secret", msg.Auth)
+	assert.Equal(t, "no-reply@goshop.id", msg.From)
+	assert.Equal(t, "budi@example.com", msg.To)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.Data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Pesanan #42 – dikonfirmasi", subject)
+	assert.Equal(t, `"GoShop" <no-reply@goshop.id>`, parsed.Header.Get("From"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+
+	plain, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", plain.Header.Get("Content-Type"))
+	plainBody, _ := io.ReadAll(plain)
+	assert.Equal(t, "Halo Budi,\n\nTerima kasih atas pesanan Anda & pembayaran sebesar Rp 150.000.\n\n"+
+		"Lihat pesanan (https://goshop.id/orders/42)\n", string(plainBody))
+
+	html, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", html.Header.Get("Content-Type"))
+	htmlBody, _ := io.ReadAll(html)
+	assert.Equal(t, testEmailBody, string(htmlBody))
+}
+
+func TestSMTPSender_Send_ImplicitTLS(t *testing.T) {
+	// Arrange: a sink behind a TLS listener
+	serverTLS, roots := testCertificate(t)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+	require.NoError(t, err)
+	sink := &smtpSink{listener: listener, messages: make(chan sinkMessage, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			sink.serve(conn)
+		}
+	}()
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: sink.port(), From: "no-reply@goshop.id",
+		TLSMode: TLSModeImplicit, TLSConfig: &tls.Config{RootCAs: roots}})
+	require.NoError(t, err)
+
+	// Act
+	err = sender.Send("budi@example.com", "Halo", "<p>Halo</p>")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, (<-sink.messages).Data, "Subject: Halo\n")
+}
+
+func TestSMTPSender_Send_RejectedRecipient(t *testing.T) {
+	sink := startSMTPSink(t, nil)
+	sink.rejectRcpt = "nobody@example.com"
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: sink.port(), From: "no-reply@goshop.id", TLSMode: TLSModeNone})
+	require.NoError(t, err)
+
+	err = sender.Send("nobody@example.com", "Halo", "<p>Halo</p>")
+
+	assert.ErrorIs(t, err, service.ErrInvalidRecipient)
+}
+
+func TestSMTPSender_Send_RequiresStartTLS(t *testing.T) {
+	sink := startSMTPSink(t, nil)
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: sink.port(), From: "no-reply@goshop.id"})
+	require.NoError(t, err)
+
+	err = sender.Send("budi@example.com", "Halo", "<p>Halo</p>")
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, service.ErrInvalidRecipient)
+	assert.Empty(t, sink.messages)
+}
+
+func TestNewSMTPSender_InvalidConfig(t *testing.T) {
+	_, err := NewSMTPSender(SMTPConfig{Host: "smtp.example.com", From: "not an address"})
+	assert.Error(t, err)
+
+	_, err = NewSMTPSender(SMTPConfig{Host: "smtp.example.com", From: "no-reply@goshop.id", TLSMode: "ssl"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/repository"
+	"github.com/rs/zerolog"
+)
+
+// ErrSenderNotConfigured is recorded on notifications of a type this service has no sender for
+var ErrSenderNotConfigured = errors.New("no sender configured")
+
+// claimLease is how long a worker holds a claimed notification before others may retry it.
+// It only comes into play if the worker dies mid-send.
+const claimLease = 5 * time.Minute
+
+// RetryPolicy decides how many times a notification is tried and how long to wait in between
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration // Before the first retry, doubling for each retry after it
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy tries a notification 5 times over about 8 minutes
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: time.Hour}
+}
+
+// Backoff returns how long to wait before the next attempt after the given number of failed ones
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Dispatcher sends queued notifications with a pool of workers. Replicas can run dispatchers
+// side by side: each notification is claimed before it is sent.
+type Dispatcher struct {
+	notificationRepo repository.NotificationRepository
+	emailSender      EmailSender
+	smsSender        SMSSender
+	pushSender       PushSender
+	policy           RetryPolicy
+	workers          int
+	log              zerolog.Logger
+}
+
+// NewDispatcher creates a Dispatcher sending with the given senders, workers at a time.
+// Notifications of a type without a sender fail.
+func NewDispatcher(notificationRepo repository.NotificationRepository, emailSender EmailSender, smsSender SMSSender,
+	pushSender PushSender, policy RetryPolicy, workers int, log zerolog.Logger) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Dispatcher{
+		notificationRepo: notificationRepo,
+		emailSender:      emailSender,
+		smsSender:        smsSender,
+		pushSender:       pushSender,
+		policy:           policy,
+		workers:          workers,
+		log:              log,
+	}
+}
+
+// Start sends due notifications every interval until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchDue(ctx, time.Now()); err != nil {
+			d.log.Error().Err(err).Msg("Failed to dispatch notifications")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends the notifications due by now, a batch at a time until none are left.
+// It returns how many were sent.
+func (d *Dispatcher) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	batchSize := 10 * d.workers
+	sent := 0
+	for ctx.Err() == nil {
+		batch, err := d.notificationRepo.ClaimDue(now, claimLease, batchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		batchSent, err := d.dispatch(batch, now)
+		sent += batchSent
+		if err != nil || len(batch) < batchSize {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// dispatch sends a batch of claimed notifications over the worker pool
+func (d *Dispatcher) dispatch(batch []domain.Notification, now time.Time) (int, error) {
+	jobs := make(chan *domain.Notification)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		sent int
+		errs []error
+	)
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for notification := range jobs {
+				ok, err := d.deliver(notification, now)
+				mu.Lock()
+				if ok {
+					sent++
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("notification %d: %w", notification.ID, err))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range batch {
+		jobs <- &batch[i]
+	}
+	close(jobs)
+	wg.Wait()
+	return sent, errors.Join(errs...)
+}
+
+// deliver makes one attempt at sending a notification and records the outcome. A failed send is
+// retried after a backoff, or dead-lettered once the notification runs out of attempts. The error
+// is only for failing to record the outcome; send failures are recorded on the notification.
+func (d *Dispatcher) deliver(notification *domain.Notification, now time.Time) (bool, error) {
+	sendErr := d.send(notification)
+
+	notification.Attempts++
+	notification.LockedUntil = nil
+	switch {
+	case sendErr == nil:
+		notification.Status = domain.NotificationStatusSent
+		notification.SentAt = &now
+		notification.NextAttemptAt = nil
+		notification.Error = ""
+	case notification.Attempts >= notification.MaxAttempts || errors.Is(sendErr, ErrInvalidRecipient):
+		notification.Status = domain.NotificationStatusFailed
+		notification.FailedAt = &now
+		notification.NextAttemptAt = nil
+		notification.Error = sendErr.Error()
+		d.log.Warn().Err(sendErr).Uint("notification_id", notification.ID).Int("attempts", notification.Attempts).
+			Msg("Notification dead-lettered")
+	default:
+		next := now.Add(d.policy.Backoff(notification.Attempts))
+		notification.NextAttemptAt = &next
+		notification.Error = sendErr.Error()
+	}
+
+	if err := d.notificationRepo.Save(notification); err != nil {
+		return false, err
+	}
+	return sendErr == nil, nil
+}
+
+func (d *Dispatcher) send(notification *domain.Notification) error {
+	switch notification.Type {
+	case domain.NotificationTypeEmail:
+		if d.emailSender == nil {
+			return fmt.Errorf("%w for %s", ErrSenderNotConfigured, notification.Type)
+		}
+		return d.emailSender.Send(notification.Recipient, notification.Subject, notification.Content)
+	case domain.NotificationTypeSMS:
+		if d.smsSender == nil {
+			return fmt.Errorf("%w for %s", ErrSenderNotConfigured, notification.Type)
+		}
+		return d.smsSender.Send(notification.Recipient, notification.Content)
+	case domain.NotificationTypePush:
+		if d.pushSender == nil {
+			return fmt.Errorf("%w for %s", ErrSenderNotConfigured, notification.Type)
+		}
+		var data map[string]string
+		if notification.Metadata != "" {
+			if err := json.Unmarshal([]byte(notification.Metadata), &data); err != nil {
+				return fmt.Errorf("invalid push data: %w", err)
+			}
+		}
+		return d.pushSender.Send(notification.Recipient, notification.Subject, notification.Content, data)
+	}
+	return fmt.Errorf("unknown notification type %q", notification.Type)
+}
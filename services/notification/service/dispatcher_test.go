@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/repository"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute}
+
+// fakeSender records what it sends, failing while err is set
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []string
+	err  error
+}
+
+func (f *fakeSender) record(to string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, to)
+	return nil
+}
+
+type fakeEmailSender struct{ fakeSender }
+
+func (f *fakeEmailSender) Send(to, subject, body string) error { return f.record(to) }
+
+type fakeSMSSender struct{ fakeSender }
+
+func (f *fakeSMSSender) Send(phoneNumber, message string) error { return f.record(phoneNumber) }
+
+type fakePushSender struct {
+	fakeSender
+	data map[string]string
+}
+
+func (f *fakePushSender) Send(deviceToken, title, body string, data map[string]string) error {
+	f.data = data
+	return f.record(deviceToken)
+}
+
+type dispatcherFixture struct {
+	repo       *repository.MockNotificationRepository
+	service    NotificationService
+	dispatcher *Dispatcher
+	email      *fakeEmailSender
+	sms        *fakeSMSSender
+	push       *fakePushSender
+}
+
+func newDispatcherFixture() *dispatcherFixture {
+	f := &dispatcherFixture{
+		repo:  repository.NewMockNotificationRepository(),
+		email: &fakeEmailSender{},
+		sms:   &fakeSMSSender{},
+		push:  &fakePushSender{},
+	}
+	f.service = NewNotificationService(f.repo, NewTemplateService(repository.NewMockTemplateRepository()), testPolicy)
+	f.dispatcher = NewDispatcher(f.repo, f.email, f.sms, f.push, testPolicy, 3, zerolog.Nop())
+	return f
+}
+
+func (f *dispatcherFixture) queueSMS(t *testing.T, phoneNumber string) uint {
+	resp, err := f.service.SendSMS(&dto.SendSMSRequest{UserID: 7, PhoneNumber: phoneNumber, Message: "Pesanan Anda dikirim"})
+	require.NoError(t, err)
+	return resp.ID
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, policy.Backoff(1))
+	assert.Equal(t, time.Minute, policy.Backoff(2))
+	assert.Equal(t, 4*time.Minute, policy.Backoff(4))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(5))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(40))
+}
+
+func TestDispatcher_DispatchDue_SendsEachTypeOnce(t *testing.T) {
+	// Arrange
+	f := newDispatcherFixture()
+	for _, phone := range []string{"+6281111", "+6282222", "+6283333", "+6284444"} {
+		f.queueSMS(t, phone)
+	}
+	_, err := f.service.SendPush(&dto.SendPushRequest{UserID: 7, DeviceToken: "device-1", Title: "Promo", Body: "Diskon 10%",
+		Data: map[string]string{"order_id": "42"}})
+	require.NoError(t, err)
+	now := time.Now().Add(time.Second)
+
+	// Act
+	sent, err := f.dispatcher.DispatchDue(context.Background(), now)
+	require.NoError(t, err)
+	again, err := f.dispatcher.DispatchDue(context.Background(), now)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 5, sent)
+	assert.Zero(t, again)
+	assert.ElementsMatch(t, []string{"+6281111", "+6282222", "+6283333", "+6284444"}, f.sms.sent)
+	assert.Equal(t, []string{"device-1"}, f.push.sent)
+	assert.Equal(t, map[string]string{"order_id": "42"}, f.push.data)
+
+	notification, err := f.repo.FindByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationStatusSent, notification.Status)
+	assert.Equal(t, 1, notification.Attempts)
+	assert.Nil(t, notification.LockedUntil)
+}
+
+func TestDispatcher_DispatchDue_BacksOffThenDeadLetters(t *testing.T) {
+	// Arrange
+	f := newDispatcherFixture()
+	f.sms.err = errors.New("gateway timeout")
+	id := f.queueSMS(t, "+6281111")
+	start := time.Now().Add(time.Second)
+	ctx := context.Background()
+
+	// Act: the first retry is due a minute later, the second two minutes after that
+	_, err := f.dispatcher.DispatchDue(ctx, start)
+	require.NoError(t, err)
+	early, err := f.dispatcher.DispatchDue(ctx, start.Add(30*time.Second))
+	require.NoError(t, err)
+	afterFirst, _ := f.repo.FindByID(id)
+	_, err = f.dispatcher.DispatchDue(ctx, start.Add(time.Minute))
+	require.NoError(t, err)
+	_, err = f.dispatcher.DispatchDue(ctx, start.Add(3*time.Minute))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Zero(t, early)
+	assert.Equal(t, domain.NotificationStatusPending, afterFirst.Status)
+	assert.Equal(t, start.Add(time.Minute), *afterFirst.NextAttemptAt)
+	assert.Equal(t, "gateway timeout", afterFirst.Error)
+
+	notification, _ := f.repo.FindByID(id)
+	assert.Equal(t, domain.NotificationStatusFailed, notification.Status)
+	assert.Equal(t, 3, notification.Attempts)
+	require.NotNil(t, notification.FailedAt)
+	assert.Nil(t, notification.NextAttemptAt)
+}
+
+func TestDispatcher_DispatchDue_DeadLettersWithoutSender(t *testing.T) {
+	// Arrange
+	f := newDispatcherFixture()
+	f.dispatcher = NewDispatcher(f.repo, nil, nil, nil, testPolicy, 1, zerolog.Nop())
+	id := f.queueSMS(t, "+6281111")
+	notification, _ := f.repo.FindByID(id)
+	notification.MaxAttempts = 1
+	require.NoError(t, f.repo.Save(notification))
+
+	// Act
+	sent, err := f.dispatcher.DispatchDue(context.Background(), time.Now().Add(time.Second))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	notification, _ = f.repo.FindByID(id)
+	assert.Equal(t, domain.NotificationStatusFailed, notification.Status)
+	assert.Contains(t, notification.Error, ErrSenderNotConfigured.Error())
+}
+
+func TestDispatcher_DispatchDue_InvalidRecipientIsNotRetried(t *testing.T) {
+	f := newDispatcherFixture()
+	f.sms.err = ErrInvalidRecipient
+	id := f.queueSMS(t, "not-a-number")
+
+	_, err := f.dispatcher.DispatchDue(context.Background(), time.Now().Add(time.Second))
+
+	require.NoError(t, err)
+	notification, _ := f.repo.FindByID(id)
+	assert.Equal(t, domain.NotificationStatusFailed, notification.Status)
+	assert.Equal(t, 1, notification.Attempts)
+}
+
+func TestNotificationService_RetryDeadLetters(t *testing.T) {
+	// Arrange: one dead letter
+	f := newDispatcherFixture()
+	f.sms.err = ErrInvalidRecipient
+	id := f.queueSMS(t, "+6281111")
+	_, err := f.dispatcher.DispatchDue(context.Background(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	deadLetters, total, err := f.service.GetDeadLetters(1, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	assert.Equal(t, id, deadLetters[0].ID)
+
+	// Act
+	f.sms.err = nil
+	requeued, err := f.service.RetryDeadLetters(nil)
+	require.NoError(t, err)
+	sent, err := f.dispatcher.DispatchDue(context.Background(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+
+	// Assert
+	assert.EqualValues(t, 1, requeued)
+	assert.Equal(t, 1, sent)
+	notification, _ := f.repo.FindByID(id)
+	assert.Equal(t, domain.NotificationStatusSent, notification.Status)
+	assert.Equal(t, 1, notification.Attempts)
+	assert.Nil(t, notification.FailedAt)
+}
+
+func TestNotificationService_MarkDelivered(t *testing.T) {
+	// Arrange
+	f := newDispatcherFixture()
+	id := f.queueSMS(t, "+6281111")
+
+	// Act & Assert: only sent notifications can be delivered, and receipts may repeat
+	_, err := f.service.MarkDelivered(id)
+	assert.ErrorIs(t, err, ErrNotSent)
+
+	_, err = f.dispatcher.DispatchDue(context.Background(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	resp, err := f.service.MarkDelivered(id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationStatusDelivered, resp.Status)
+	assert.NotEmpty(t, resp.DeliveredAt)
+
+	_, err = f.service.MarkDelivered(id)
+	assert.NoError(t, err)
+	_, err = f.service.MarkDelivered(99)
+	assert.ErrorIs(t, err, ErrNotificationNotFound)
+}
@@ -1,20 +1,24 @@
 package service
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
-	"html/template"
 	"time"
 
 	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/dto"
-	"gorm.io/gorm"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/repository"
 )
 
 var (
 	ErrTemplateNotFound = errors.New("template not found")
+	// ErrInvalidRecipient is returned by senders for recipients that can never be reached;
+	// such notifications are dead-lettered without retrying
 	ErrInvalidRecipient = errors.New("invalid recipient")
+	// ErrNotificationNotFound is returned for receipts and retries of unknown notifications
+	ErrNotificationNotFound = errors.New("notification not found")
+	// ErrNotSent is returned when confirming delivery of a notification that hasn't been sent
+	ErrNotSent = errors.New("notification has not been sent")
 )
 
 // EmailSender interface for sending emails
@@ -32,7 +36,8 @@ type PushSender interface {
 	Send(deviceToken, title, body string, data map[string]string) error
 }
 
-// NotificationService defines the interface for notification operations
+// NotificationService defines the interface for notification operations.
+// Notifications are queued and sent in the background by a Dispatcher.
 type NotificationService interface {
 	SendEmail(req *dto.SendEmailRequest) (*dto.NotificationResponse, error)
 	SendSMS(req *dto.SendSMSRequest) (*dto.NotificationResponse, error)
@@ -40,223 +45,181 @@ type NotificationService interface {
 	SendOrderConfirmation(userID uint, email string, data *dto.OrderConfirmationData) error
 	SendPaymentSuccess(userID uint, email string, data *dto.PaymentSuccessData) error
 	GetUserNotifications(userID uint, page, pageSize int) ([]dto.NotificationResponse, int64, error)
+
+	// MarkDelivered records a provider's delivery receipt for a sent notification
+	MarkDelivered(id uint) (*dto.NotificationResponse, error)
+	// GetDeadLetters lists notifications that ran out of attempts, newest first
+	GetDeadLetters(page, pageSize int) ([]dto.NotificationResponse, int64, error)
+	// RetryDeadLetters queues dead letters again with a fresh set of attempts; with no ids, all of them
+	RetryDeadLetters(ids []uint) (int64, error)
 }
 
 type notificationServiceImpl struct {
-	db          *gorm.DB
-	emailSender EmailSender
-	smsSender   SMSSender
-	pushSender  PushSender
+	notificationRepo repository.NotificationRepository
+	templates        TemplateService
+	policy           RetryPolicy
 }
 
-// NewNotificationService creates a new NotificationService
-func NewNotificationService(db *gorm.DB, emailSender EmailSender, smsSender SMSSender, pushSender PushSender) NotificationService {
+// NewNotificationService creates a new NotificationService. Emails sent by template ID are
+// rendered by templates, and policy sets how many attempts queued notifications get.
+func NewNotificationService(notificationRepo repository.NotificationRepository, templates TemplateService, policy RetryPolicy) NotificationService {
 	return &notificationServiceImpl{
-		db:          db,
-		emailSender: emailSender,
-		smsSender:   smsSender,
-		pushSender:  pushSender,
+		notificationRepo: notificationRepo,
+		templates:        templates,
+		policy:           policy,
 	}
 }
 
 func (s *notificationServiceImpl) SendEmail(req *dto.SendEmailRequest) (*dto.NotificationResponse, error) {
-	body := req.Body
-
-	// Use template if specified
-	if req.TemplateID != "" {
-		var tmpl domain.NotificationTemplate
-		if err := s.db.Where("id = ? AND type = ? AND is_active = true", req.TemplateID, domain.NotificationTypeEmail).First(&tmpl).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return nil, ErrTemplateNotFound
-			}
-			return nil, err
-		}
-
-		rendered, err := s.renderTemplate(tmpl.Body, req.Variables)
-		if err != nil {
-			return nil, err
-		}
-		body = rendered
-	}
-
-	// Create notification record
 	notification := &domain.Notification{
 		UserID:     req.UserID,
 		Type:       domain.NotificationTypeEmail,
-		Status:     domain.NotificationStatusPending,
 		Subject:    req.Subject,
-		Content:    body,
+		Content:    req.Body,
 		Recipient:  req.To,
 		TemplateID: req.TemplateID,
 	}
 
-	if err := s.db.Create(notification).Error; err != nil {
-		return nil, err
-	}
-
-	// Send email
-	if s.emailSender != nil {
-		if err := s.emailSender.Send(req.To, req.Subject, body); err != nil {
-			notification.Status = domain.NotificationStatusFailed
-			notification.Error = err.Error()
-		} else {
-			notification.Status = domain.NotificationStatusSent
-			now := time.Now()
-			notification.SentAt = &now
+	// Use template if specified
+	if req.TemplateID != "" {
+		rendered, err := s.templates.Render(req.TemplateID, domain.NotificationTypeEmail, req.Locale, req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		notification.Content = rendered.Body
+		if notification.Subject == "" {
+			notification.Subject = rendered.Subject
 		}
-		s.db.Save(notification)
-	} else {
-		// Mock: just mark as sent for demo
-		notification.Status = domain.NotificationStatusSent
-		now := time.Now()
-		notification.SentAt = &now
-		s.db.Save(notification)
+		notification.TemplateLocale = rendered.Locale
+		notification.TemplateVersion = rendered.Version
 	}
 
-	return s.toNotificationResponse(notification), nil
+	return s.enqueue(notification)
 }
 
 func (s *notificationServiceImpl) SendSMS(req *dto.SendSMSRequest) (*dto.NotificationResponse, error) {
-	notification := &domain.Notification{
+	return s.enqueue(&domain.Notification{
 		UserID:    req.UserID,
 		Type:      domain.NotificationTypeSMS,
-		Status:    domain.NotificationStatusPending,
 		Subject:   "SMS",
 		Content:   req.Message,
 		Recipient: req.PhoneNumber,
-	}
-
-	if err := s.db.Create(notification).Error; err != nil {
-		return nil, err
-	}
-
-	// Send SMS (mock for now)
-	notification.Status = domain.NotificationStatusSent
-	now := time.Now()
-	notification.SentAt = &now
-	s.db.Save(notification)
-
-	return s.toNotificationResponse(notification), nil
+	})
 }
 
 func (s *notificationServiceImpl) SendPush(req *dto.SendPushRequest) (*dto.NotificationResponse, error) {
 	metadata, _ := json.Marshal(req.Data)
 
-	notification := &domain.Notification{
+	return s.enqueue(&domain.Notification{
 		UserID:    req.UserID,
 		Type:      domain.NotificationTypePush,
-		Status:    domain.NotificationStatusPending,
 		Subject:   req.Title,
 		Content:   req.Body,
 		Recipient: req.DeviceToken,
 		Metadata:  string(metadata),
-	}
-
-	if err := s.db.Create(notification).Error; err != nil {
-		return nil, err
-	}
+	})
+}
 
-	// Send push (mock for now)
-	notification.Status = domain.NotificationStatusSent
+// enqueue stores a notification as pending and due now, for the dispatcher to send
+func (s *notificationServiceImpl) enqueue(notification *domain.Notification) (*dto.NotificationResponse, error) {
 	now := time.Now()
-	notification.SentAt = &now
-	s.db.Save(notification)
+	notification.Status = domain.NotificationStatusPending
+	notification.MaxAttempts = s.policy.MaxAttempts
+	notification.NextAttemptAt = &now
 
+	if err := s.notificationRepo.Create(notification); err != nil {
+		return nil, err
+	}
 	return s.toNotificationResponse(notification), nil
 }
 
 func (s *notificationServiceImpl) SendOrderConfirmation(userID uint, email string, data *dto.OrderConfirmationData) error {
-	body := s.buildOrderConfirmationEmail(data)
+	items := make([]map[string]interface{}, len(data.OrderItems))
+	for i, item := range data.OrderItems {
+		items[i] = map[string]interface{}{
+			"product_name": item.ProductName,
+			"quantity":     item.Quantity,
+			"price":        item.Price,
+			"subtotal":     item.Price * float64(item.Quantity),
+		}
+	}
 
 	_, err := s.SendEmail(&dto.SendEmailRequest{
-		UserID:  userID,
-		To:      email,
-		Subject: "Order Confirmation - #" + string(rune(data.OrderID+'0')),
-		Body:    body,
+		UserID:     userID,
+		To:         email,
+		TemplateID: TemplateOrderConfirmation,
+		Locale:     data.Locale,
+		Variables: map[string]interface{}{
+			"order_id":      data.OrderID,
+			"customer_name": data.CustomerName,
+			"total_amount":  data.TotalAmount,
+			"currency":      data.Currency,
+			"items":         items,
+		},
 	})
 	return err
 }
 
 func (s *notificationServiceImpl) SendPaymentSuccess(userID uint, email string, data *dto.PaymentSuccessData) error {
-	body := s.buildPaymentSuccessEmail(data)
-
 	_, err := s.SendEmail(&dto.SendEmailRequest{
-		UserID:  userID,
-		To:      email,
-		Subject: "Payment Successful - Transaction " + data.TransactionID,
-		Body:    body,
+		UserID:     userID,
+		To:         email,
+		TemplateID: TemplatePaymentSuccess,
+		Locale:     data.Locale,
+		Variables: map[string]interface{}{
+			"order_id":       data.OrderID,
+			"payment_id":     data.PaymentID,
+			"amount":         data.Amount,
+			"currency":       data.Currency,
+			"payment_method": data.PaymentMethod,
+			"transaction_id": data.TransactionID,
+		},
 	})
 	return err
 }
 
 func (s *notificationServiceImpl) GetUserNotifications(userID uint, page, pageSize int) ([]dto.NotificationResponse, int64, error) {
-	var notifications []domain.Notification
-	var total int64
-
-	s.db.Model(&domain.Notification{}).Where("user_id = ?", userID).Count(&total)
-
-	offset := (page - 1) * pageSize
-	if err := s.db.Where("user_id = ?", userID).
-		Offset(offset).
-		Limit(pageSize).
-		Order("created_at DESC").
-		Find(&notifications).Error; err != nil {
+	notifications, total, err := s.notificationRepo.FindByUserID(userID, page, pageSize)
+	if err != nil {
 		return nil, 0, err
 	}
-
-	responses := make([]dto.NotificationResponse, len(notifications))
-	for i, n := range notifications {
-		responses[i] = *s.toNotificationResponse(&n)
-	}
-
-	return responses, total, nil
+	return s.toNotificationResponses(notifications), total, nil
 }
 
-func (s *notificationServiceImpl) renderTemplate(tmpl string, variables map[string]string) (string, error) {
-	t, err := template.New("notification").Parse(tmpl)
+func (s *notificationServiceImpl) MarkDelivered(id uint) (*dto.NotificationResponse, error) {
+	notification, err := s.notificationRepo.FindByID(id)
 	if err != nil {
-		return "", err
+		return nil, ErrNotificationNotFound
 	}
 
-	var buf bytes.Buffer
-	if err := t.Execute(&buf, variables); err != nil {
-		return "", err
+	switch notification.Status {
+	case domain.NotificationStatusDelivered:
+		// Providers may send a receipt more than once
+		return s.toNotificationResponse(notification), nil
+	case domain.NotificationStatusSent:
+	default:
+		return nil, ErrNotSent
 	}
 
-	return buf.String(), nil
+	now := time.Now()
+	notification.Status = domain.NotificationStatusDelivered
+	notification.DeliveredAt = &now
+	if err := s.notificationRepo.Save(notification); err != nil {
+		return nil, err
+	}
+	return s.toNotificationResponse(notification), nil
 }
 
-func (s *notificationServiceImpl) buildOrderConfirmationEmail(data *dto.OrderConfirmationData) string {
-	return `
-<!DOCTYPE html>
-<html>
-<head><style>body{font-family:Arial,sans-serif;}</style></head>
-<body>
-<h2>Order Confirmation</h2>
-<p>Dear ` + data.CustomerName + `,</p>
-<p>Thank you for your order! Your order has been confirmed.</p>
-<p><strong>Order ID:</strong> #` + string(rune(data.OrderID+'0')) + `</p>
-<p><strong>Total Amount:</strong> Rp ` + formatPrice(data.TotalAmount) + `</p>
-<p>We will notify you once your order is shipped.</p>
-<p>Thank you for shopping with us!</p>
-</body>
-</html>`
+func (s *notificationServiceImpl) GetDeadLetters(page, pageSize int) ([]dto.NotificationResponse, int64, error) {
+	notifications, total, err := s.notificationRepo.FindByStatus(domain.NotificationStatusFailed, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.toNotificationResponses(notifications), total, nil
 }
 
-func (s *notificationServiceImpl) buildPaymentSuccessEmail(data *dto.PaymentSuccessData) string {
-	return `
-<!DOCTYPE html>
-<html>
-<head><style>body{font-family:Arial,sans-serif;}</style></head>
-<body>
-<h2>Payment Successful</h2>
-<p>Your payment has been processed successfully.</p>
-<p><strong>Transaction ID:</strong> ` + data.TransactionID + `</p>
-<p><strong>Amount:</strong> Rp ` + formatPrice(data.Amount) + `</p>
-<p><strong>Payment Method:</strong> ` + data.PaymentMethod + `</p>
-<p>Thank you for your purchase!</p>
-</body>
-</html>`
+func (s *notificationServiceImpl) RetryDeadLetters(ids []uint) (int64, error) {
+	return s.notificationRepo.Requeue(ids, time.Now())
 }
 
 func (s *notificationServiceImpl) toNotificationResponse(n *domain.Notification) *dto.NotificationResponse {
@@ -268,14 +231,28 @@ func (s *notificationServiceImpl) toNotificationResponse(n *domain.Notification)
 		Subject:   n.Subject,
 		Recipient: n.Recipient,
 		Error:     n.Error,
+		Attempts:  n.Attempts,
 		CreatedAt: n.CreatedAt.Format(time.RFC3339),
 	}
+	if n.Status == domain.NotificationStatusPending && n.NextAttemptAt != nil {
+		resp.NextAttemptAt = n.NextAttemptAt.Format(time.RFC3339)
+	}
 	if n.SentAt != nil {
 		resp.SentAt = n.SentAt.Format(time.RFC3339)
 	}
+	if n.DeliveredAt != nil {
+		resp.DeliveredAt = n.DeliveredAt.Format(time.RFC3339)
+	}
+	if n.FailedAt != nil {
+		resp.FailedAt = n.FailedAt.Format(time.RFC3339)
+	}
 	return resp
 }
 
-func formatPrice(price float64) string {
-	return string(rune(int(price)))
+func (s *notificationServiceImpl) toNotificationResponses(notifications []domain.Notification) []dto.NotificationResponse {
+	responses := make([]dto.NotificationResponse, len(notifications))
+	for i := range notifications {
+		responses[i] = *s.toNotificationResponse(&notifications[i])
+	}
+	return responses
 }
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+)
+
+// RenderedTemplate is a template variant rendered with a recipient's variables
+type RenderedTemplate struct {
+	TemplateID string
+	Locale     string
+	Version    int
+	Subject    string
+	Body       string
+}
+
+// numberFormat is how a language separates thousands and decimals
+type numberFormat struct {
+	thousands byte
+	decimal   byte
+}
+
+// numberFormats by language; other languages are formatted like English
+var numberFormats = map[string]numberFormat{
+	"en": {thousands: ',', decimal: '.'},
+	"id": {thousands: '.', decimal: ','},
+}
+
+// templateFuncs are the functions templates can call. Amounts may be given as numbers or as
+// numeric strings, as variables sent over the API are often strings.
+//
+//	{{formatNumber .quantity}}               1,250 (1.250 in Indonesian)
+//	{{formatCurrency .total .currency}}      Rp 1.250.000, or the base currency without a code
+func templateFuncs(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"formatNumber": func(value interface{}) (string, error) {
+			number, err := toFloat(value)
+			if err != nil {
+				return "", err
+			}
+			return formatNumber(number, locale), nil
+		},
+		"formatCurrency": func(value interface{}, code string) (string, error) {
+			amount, err := toFloat(value)
+			if err != nil {
+				return "", err
+			}
+			return currency.Code(strings.ToUpper(code)).Format(amount), nil
+		},
+	}
+}
+
+// formatNumber formats a number for a locale with at most two decimals, e.g. "1,234,567.5"
+func formatNumber(number float64, locale string) string {
+	format, ok := numberFormats[baseLanguage(locale)]
+	if !ok {
+		format = numberFormats["en"]
+	}
+
+	sign := ""
+	if number < 0 {
+		sign = "-"
+		number = -number
+	}
+	digits := strconv.FormatFloat(math.Round(number*100)/100, 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(format.thousands)
+		}
+		b.WriteRune(d)
+	}
+	if fraction != "" {
+		b.WriteByte(format.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+		return number, nil
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+// parsedVariant is a variant's subject, which is plain text, and its HTML body
+type parsedVariant struct {
+	subject *texttemplate.Template
+	body    *template.Template
+}
+
+// parseVariant parses a variant's content. Rendering fails on variables the data lacks, rather
+// than sending "<no value>" to customers.
+func parseVariant(locale, subject, body string) (*parsedVariant, error) {
+	funcs := templateFuncs(locale)
+	subjectTmpl, err := texttemplate.New("subject").Funcs(funcs).Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	bodyTmpl, err := template.New("body").Funcs(funcs).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return &parsedVariant{subject: subjectTmpl, body: bodyTmpl}, nil
+}
+
+func (p *parsedVariant) execute(data map[string]interface{}) (subject, body string, err error) {
+	var buf bytes.Buffer
+	if err := p.subject.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrTemplateVariables, err)
+	}
+	subject = buf.String()
+
+	buf.Reset()
+	if err := p.body.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrTemplateVariables, err)
+	}
+	return subject, buf.String(), nil
+}
+
+// renderVersion renders a stored version with data
+func renderVersion(version *domain.TemplateVersion, data map[string]interface{}) (*RenderedTemplate, error) {
+	parsed, err := parseVariant(version.Locale, version.Subject, version.Body)
+	if err != nil {
+		return nil, err
+	}
+	subject, body, err := parsed.execute(data)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderedTemplate{
+		TemplateID: version.TemplateID,
+		Locale:     version.Locale,
+		Version:    version.Version,
+		Subject:    subject,
+		Body:       body,
+	}, nil
+}
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// normalizeLocale returns a locale in the form variants are stored in, e.g. "pt-br" for "pt_BR"
+func normalizeLocale(locale string) (string, error) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+	if !localePattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocale, locale)
+	}
+	return normalized, nil
+}
+
+// baseLanguage returns the language of a locale, e.g. "id" for "id-id"
+func baseLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
+
+// localeCandidates lists the locales to look for a variant in, best first: the requested
+// locale, its language, then the template's default. Unusable requested locales are skipped.
+func localeCandidates(requested, defaultLocale string) []string {
+	var candidates []string
+	add := func(locale string) {
+		for _, c := range candidates {
+			if c == locale {
+				return
+			}
+		}
+		candidates = append(candidates, locale)
+	}
+	if locale, err := normalizeLocale(requested); err == nil {
+		add(locale)
+		add(baseLanguage(locale))
+	}
+	add(defaultLocale)
+	return candidates
+}
+
+// decodeSampleData decodes a version's stored sample data
+func decodeSampleData(sampleData string) map[string]interface{} {
+	data := map[string]interface{}{}
+	if sampleData != "" {
+		json.Unmarshal([]byte(sampleData), &data)
+	}
+	return data
+}
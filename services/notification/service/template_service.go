@@ -0,0 +1,432 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTemplateExists    = errors.New("template already exists")
+	ErrInvalidTemplateID = errors.New("template id may only contain lowercase letters, digits and underscores")
+	// ErrInvalidTemplate is returned for content that does not parse
+	ErrInvalidTemplate = errors.New("invalid template")
+	// ErrTemplateVariables is returned when content can't be rendered with the variables given,
+	// e.g. because one is missing
+	ErrTemplateVariables = errors.New("template variables do not match the template")
+	ErrInvalidLocale     = errors.New("invalid locale")
+	ErrVariantNotFound   = errors.New("template has no variant for the locale")
+	ErrVersionNotFound   = errors.New("template version not found")
+	ErrBuiltInTemplate   = errors.New("built-in templates cannot be deleted")
+)
+
+var templateIDPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// TemplateService defines the interface for managing and rendering notification templates.
+// Templates have a variant per locale, and every save of a variant is kept as a new version.
+type TemplateService interface {
+	CreateTemplate(staffID uint, req *dto.CreateTemplateRequest) (*dto.TemplateResponse, error)
+	GetTemplate(id string) (*dto.TemplateResponse, error)
+	ListTemplates(page, pageSize int) ([]dto.TemplateResponse, int64, error)
+	UpdateTemplate(id string, req *dto.UpdateTemplateRequest) (*dto.TemplateResponse, error)
+	DeleteTemplate(id string) error
+
+	// SaveVariant saves a locale's content as its next version, adding the locale if it is new
+	SaveVariant(staffID uint, id, locale string, req *dto.SaveTemplateVariantRequest) (*dto.TemplateVersionResponse, error)
+	// ListVersions lists the versions of a locale, newest first
+	ListVersions(id, locale string) ([]dto.TemplateVersionResponse, error)
+	// RestoreVersion makes an old version live again by saving a copy of it as the next version
+	RestoreVersion(staffID uint, id, locale string, version int) (*dto.TemplateVersionResponse, error)
+	// Preview renders a template, active or not, without sending it
+	Preview(id string, req *dto.PreviewTemplateRequest) (*dto.RenderedTemplateResponse, error)
+
+	// Render renders the live variant of an active template for a recipient's locale, falling back
+	// to the locale's language and then to the template's default locale
+	Render(id string, notificationType domain.NotificationType, locale string, variables map[string]interface{}) (*RenderedTemplate, error)
+}
+
+type templateServiceImpl struct {
+	templates repository.TemplateRepository
+}
+
+// NewTemplateService creates a new TemplateService
+func NewTemplateService(templates repository.TemplateRepository) TemplateService {
+	return &templateServiceImpl{templates: templates}
+}
+
+func (s *templateServiceImpl) CreateTemplate(staffID uint, req *dto.CreateTemplateRequest) (*dto.TemplateResponse, error) {
+	if !templateIDPattern.MatchString(req.ID) {
+		return nil, ErrInvalidTemplateID
+	}
+	defaultLocale := "en"
+	if req.DefaultLocale != "" {
+		locale, err := normalizeLocale(req.DefaultLocale)
+		if err != nil {
+			return nil, err
+		}
+		defaultLocale = locale
+	}
+
+	_, err := s.templates.FindByID(req.ID)
+	if err == nil {
+		return nil, ErrTemplateExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	template := &domain.NotificationTemplate{
+		ID:            req.ID,
+		Type:          req.Type,
+		Description:   req.Description,
+		DefaultLocale: defaultLocale,
+		IsActive:      true,
+	}
+	if template.Type == "" {
+		template.Type = domain.NotificationTypeEmail
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	version, err := newVersion(staffID, template.ID, defaultLocale, req.Subject, req.Body, req.SampleData)
+	if err != nil {
+		return nil, err
+	}
+	version.Version = 1
+
+	versions := []domain.TemplateVersion{*version}
+	if err := s.templates.Create(template, versions); err != nil {
+		return nil, err
+	}
+	return toTemplateResponse(template, versions), nil
+}
+
+func (s *templateServiceImpl) GetTemplate(id string) (*dto.TemplateResponse, error) {
+	template, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.templates.FindLatestVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	return toTemplateResponse(template, versions), nil
+}
+
+func (s *templateServiceImpl) ListTemplates(page, pageSize int) ([]dto.TemplateResponse, int64, error) {
+	templates, total, err := s.templates.FindAll(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]string, len(templates))
+	for i := range templates {
+		ids[i] = templates[i].ID
+	}
+	versions, err := s.templates.FindLatestVersions(ids...)
+	if err != nil {
+		return nil, 0, err
+	}
+	byTemplate := make(map[string][]domain.TemplateVersion)
+	for _, v := range versions {
+		byTemplate[v.TemplateID] = append(byTemplate[v.TemplateID], v)
+	}
+
+	responses := make([]dto.TemplateResponse, len(templates))
+	for i := range templates {
+		responses[i] = *toTemplateResponse(&templates[i], byTemplate[templates[i].ID])
+	}
+	return responses, total, nil
+}
+
+func (s *templateServiceImpl) UpdateTemplate(id string, req *dto.UpdateTemplateRequest) (*dto.TemplateResponse, error) {
+	template, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.templates.FindLatestVersions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+	if req.DefaultLocale != nil {
+		locale, err := normalizeLocale(*req.DefaultLocale)
+		if err != nil {
+			return nil, err
+		}
+		// Every recipient must be able to fall back to the default locale
+		if findLocale(versions, locale) == nil {
+			return nil, ErrVariantNotFound
+		}
+		template.DefaultLocale = locale
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	if err := s.templates.Update(template); err != nil {
+		return nil, err
+	}
+	return toTemplateResponse(template, versions), nil
+}
+
+func (s *templateServiceImpl) DeleteTemplate(id string) error {
+	// The service sends built-in templates itself; they can be deactivated instead
+	if isBuiltInTemplate(id) {
+		return ErrBuiltInTemplate
+	}
+	if _, err := s.find(id); err != nil {
+		return err
+	}
+	return s.templates.Delete(id)
+}
+
+func (s *templateServiceImpl) SaveVariant(staffID uint, id, locale string, req *dto.SaveTemplateVariantRequest) (*dto.TemplateVersionResponse, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := newVersion(staffID, id, locale, req.Subject, req.Body, req.SampleData)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.templates.AddVersion(version); err != nil {
+		return nil, err
+	}
+	return toTemplateVersionResponse(version), nil
+}
+
+func (s *templateServiceImpl) ListVersions(id, locale string) ([]dto.TemplateVersionResponse, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.templates.FindVersions(id, locale)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrVariantNotFound
+	}
+
+	responses := make([]dto.TemplateVersionResponse, len(versions))
+	for i := range versions {
+		responses[i] = *toTemplateVersionResponse(&versions[i])
+	}
+	return responses, nil
+}
+
+func (s *templateServiceImpl) RestoreVersion(staffID uint, id, locale string, version int) (*dto.TemplateVersionResponse, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+	old, err := s.findVersion(id, locale, version)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &domain.TemplateVersion{
+		TemplateID: old.TemplateID,
+		Locale:     old.Locale,
+		Subject:    old.Subject,
+		Body:       old.Body,
+		SampleData: old.SampleData,
+		CreatedBy:  staffID,
+	}
+	if err := s.templates.AddVersion(restored); err != nil {
+		return nil, err
+	}
+	return toTemplateVersionResponse(restored), nil
+}
+
+func (s *templateServiceImpl) Preview(id string, req *dto.PreviewTemplateRequest) (*dto.RenderedTemplateResponse, error) {
+	template, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var version *domain.TemplateVersion
+	switch {
+	case req.Subject != "" || req.Body != "":
+		// Unsaved content, previewed with the sample data of the variant it would replace
+		locale := template.DefaultLocale
+		if req.Locale != "" {
+			if locale, err = normalizeLocale(req.Locale); err != nil {
+				return nil, err
+			}
+		}
+		version = &domain.TemplateVersion{TemplateID: id, Locale: locale, Subject: req.Subject, Body: req.Body}
+		if saved, err := s.variant(template, locale); err == nil {
+			version.SampleData = saved.SampleData
+		}
+	case req.Version > 0:
+		locale := req.Locale
+		if locale == "" {
+			locale = template.DefaultLocale
+		}
+		if version, err = s.findVersion(id, locale, req.Version); err != nil {
+			return nil, err
+		}
+	default:
+		if version, err = s.variant(template, req.Locale); err != nil {
+			return nil, err
+		}
+	}
+
+	variables := req.Variables
+	if variables == nil {
+		variables = decodeSampleData(version.SampleData)
+	}
+	rendered, err := renderVersion(version, variables)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RenderedTemplateResponse{
+		TemplateID: rendered.TemplateID,
+		Locale:     rendered.Locale,
+		Version:    rendered.Version,
+		Subject:    rendered.Subject,
+		Body:       rendered.Body,
+	}, nil
+}
+
+func (s *templateServiceImpl) Render(id string, notificationType domain.NotificationType, locale string, variables map[string]interface{}) (*RenderedTemplate, error) {
+	template, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsActive || template.Type != notificationType {
+		return nil, ErrTemplateNotFound
+	}
+
+	version, err := s.variant(template, locale)
+	if err != nil {
+		return nil, err
+	}
+	return renderVersion(version, variables)
+}
+
+func (s *templateServiceImpl) find(id string) (*domain.NotificationTemplate, error) {
+	template, err := s.templates.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *templateServiceImpl) findVersion(id, locale string, version int) (*domain.TemplateVersion, error) {
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.templates.FindVersion(id, locale, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// variant returns the live version that recipients with locale get
+func (s *templateServiceImpl) variant(template *domain.NotificationTemplate, locale string) (*domain.TemplateVersion, error) {
+	versions, err := s.templates.FindLatestVersions(template.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range localeCandidates(locale, template.DefaultLocale) {
+		if version := findLocale(versions, candidate); version != nil {
+			return version, nil
+		}
+	}
+	return nil, ErrVariantNotFound
+}
+
+func findLocale(versions []domain.TemplateVersion, locale string) *domain.TemplateVersion {
+	for i := range versions {
+		if versions[i].Locale == locale {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+// newVersion checks content and returns it as an unsaved version. Content must parse, and
+// render with its sample data when it has some, so previews of it work.
+func newVersion(staffID uint, templateID, locale, subject, body string, sampleData map[string]interface{}) (*domain.TemplateVersion, error) {
+	parsed, err := parseVariant(locale, subject, body)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &domain.TemplateVersion{
+		TemplateID: templateID,
+		Locale:     locale,
+		Subject:    subject,
+		Body:       body,
+		CreatedBy:  staffID,
+	}
+	if len(sampleData) > 0 {
+		if _, _, err := parsed.execute(sampleData); err != nil {
+			return nil, fmt.Errorf("sample data: %w", err)
+		}
+		encoded, err := json.Marshal(sampleData)
+		if err != nil {
+			return nil, err
+		}
+		version.SampleData = string(encoded)
+	}
+	return version, nil
+}
+
+func toTemplateResponse(template *domain.NotificationTemplate, versions []domain.TemplateVersion) *dto.TemplateResponse {
+	variants := make([]dto.TemplateVersionResponse, len(versions))
+	for i := range versions {
+		variants[i] = *toTemplateVersionResponse(&versions[i])
+	}
+	return &dto.TemplateResponse{
+		ID:            template.ID,
+		Type:          template.Type,
+		Description:   template.Description,
+		DefaultLocale: template.DefaultLocale,
+		IsActive:      template.IsActive,
+		Variants:      variants,
+		CreatedAt:     template.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     template.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toTemplateVersionResponse(version *domain.TemplateVersion) *dto.TemplateVersionResponse {
+	return &dto.TemplateVersionResponse{
+		Locale:     version.Locale,
+		Version:    version.Version,
+		Subject:    version.Subject,
+		Body:       version.Body,
+		SampleData: decodeSampleData(version.SampleData),
+		CreatedBy:  version.CreatedBy,
+		CreatedAt:  version.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSeededTemplateService returns a TemplateService holding the built-in templates
+func newSeededTemplateService(t *testing.T) (TemplateService, *repository.MockTemplateRepository) {
+	repo := repository.NewMockTemplateRepository()
+	for _, seed := range defaultTemplates {
+		template := seed.template
+		require.NoError(t, repo.Create(&template, append([]domain.TemplateVersion(nil), seed.variants...)))
+	}
+	return NewTemplateService(repo), repo
+}
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "1,234,567.5", formatNumber(1234567.5, "en"))
+	assert.Equal(t, "1.234.567,5", formatNumber(1234567.5, "id-id"))
+	assert.Equal(t, "999", formatNumber(999, "id"))
+	assert.Equal(t, "-1,000.26", formatNumber(-1000.255, "fr"))
+}
+
+func TestTemplateFuncs_FormatCurrency(t *testing.T) {
+	formatCurrency := templateFuncs("en")["formatCurrency"].(func(interface{}, string) (string, error))
+
+	for _, tc := range []struct {
+		amount interface{}
+		code   string
+		want   string
+	}{
+		{1275000.0, "IDR", "Rp 1.275.000"},
+		{uint(1500), "", "Rp 1.500"},
+		{"99.5", "sgd", "S$ 99.50"},
+		{12, "MYR", "RM 12.00"},
+	} {
+		got, err := formatCurrency(tc.amount, tc.code)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+
+	_, err := formatCurrency("free", "IDR")
+	assert.Error(t, err)
+}
+
+func TestTemplateService_DefaultTemplatesRenderWithSampleData(t *testing.T) {
+	svc, _ := newSeededTemplateService(t)
+
+	for _, seed := range defaultTemplates {
+		for _, variant := range seed.variants {
+			rendered, err := svc.Preview(seed.template.ID, &dto.PreviewTemplateRequest{Locale: variant.Locale})
+			require.NoError(t, err, "%s (%s)", seed.template.ID, variant.Locale)
+			assert.Equal(t, variant.Locale, rendered.Locale)
+			assert.NotContains(t, rendered.Body, "<no value>")
+			// Every label reached its fmt verb
+			assert.NotContains(t, rendered.Body, "%!")
+		}
+	}
+}
+
+func TestNotificationService_SendOrderConfirmation(t *testing.T) {
+	// Arrange
+	templates, _ := newSeededTemplateService(t)
+	repo := repository.NewMockNotificationRepository()
+	svc := NewNotificationService(repo, templates, testPolicy)
+
+	// Act
+	err := svc.SendOrderConfirmation(7, "budi@example.com", &dto.OrderConfirmationData{
+		OrderID:      1042,
+		CustomerName: "Budi",
+		TotalAmount:  1275000,
+		OrderItems: []dto.OrderItemData{
+			{ProductName: "Kemeja Batik", Quantity: 2, Price: 350000},
+			{ProductName: "Sepatu Lari", Quantity: 1, Price: 575000},
+		},
+		Locale: "id-ID",
+	})
+
+	// Assert: the Indonesian variant, with the order number and amounts formatted in rupiah
+	require.NoError(t, err)
+	notification, err := repo.FindByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Konfirmasi Pesanan - #1042", notification.Subject)
+	assert.Contains(t, notification.Content, "<strong>ID Pesanan:</strong> #1042")
+	assert.Contains(t, notification.Content, "<td>Kemeja Batik</td><td>2</td><td>Rp 350.000</td><td>Rp 700.000</td>")
+	assert.Contains(t, notification.Content, "<strong>Total Pembayaran:</strong> Rp 1.275.000")
+	assert.Equal(t, TemplateOrderConfirmation, notification.TemplateID)
+	assert.Equal(t, "id", notification.TemplateLocale)
+	assert.Equal(t, 1, notification.TemplateVersion)
+}
+
+func TestNotificationService_SendPaymentSuccess(t *testing.T) {
+	// Arrange
+	templates, _ := newSeededTemplateService(t)
+	repo := repository.NewMockNotificationRepository()
+	svc := NewNotificationService(repo, templates, testPolicy)
+
+	// Act: a locale without a variant gets the default
+	err := svc.SendPaymentSuccess(7, "budi@example.com", &dto.PaymentSuccessData{
+		OrderID: 12, PaymentID: 5, Amount: 1520.5, Currency: "SGD", PaymentMethod: "credit_card",
+		TransactionID: "TXN-1", Locale: "fr",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	notification, err := repo.FindByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Payment Successful - Transaction TXN-1", notification.Subject)
+	assert.Contains(t, notification.Content, "<strong>Amount:</strong> S$ 1,520.50")
+	assert.Contains(t, notification.Content, "<strong>Order ID:</strong> #12")
+	assert.Equal(t, "en", notification.TemplateLocale)
+}
+
+func TestNotificationService_SendEmail_TemplateErrors(t *testing.T) {
+	templates, _ := newSeededTemplateService(t)
+	svc := NewNotificationService(repository.NewMockNotificationRepository(), templates, testPolicy)
+
+	_, err := svc.SendEmail(&dto.SendEmailRequest{UserID: 7, To: "budi@example.com", TemplateID: "missing"})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	// A missing variable fails the send instead of reaching the customer as "<no value>"
+	_, err = svc.SendEmail(&dto.SendEmailRequest{UserID: 7, To: "budi@example.com", TemplateID: "abandoned_cart_1",
+		Variables: map[string]interface{}{"name": "Budi"}})
+	assert.ErrorIs(t, err, ErrTemplateVariables)
+
+	_, err = templates.UpdateTemplate("abandoned_cart_1", &dto.UpdateTemplateRequest{IsActive: new(bool)})
+	require.NoError(t, err)
+	_, err = svc.SendEmail(&dto.SendEmailRequest{UserID: 7, To: "budi@example.com", TemplateID: "abandoned_cart_1"})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestTemplateService_CreateTemplate(t *testing.T) {
+	// Arrange
+	svc, _ := newSeededTemplateService(t)
+	req := &dto.CreateTemplateRequest{
+		ID:            "order_shipped",
+		Description:   "Sent when an order leaves the warehouse",
+		DefaultLocale: "id_ID",
+		Subject:       "Pesanan #{{.order_id}} dikirim",
+		Body:          "<p>Nomor resi: {{.tracking_number}}</p>",
+		SampleData:    map[string]interface{}{"order_id": 42, "tracking_number": "JNE123"},
+	}
+
+	// Act
+	template, err := svc.CreateTemplate(3, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationTypeEmail, template.Type)
+	assert.Equal(t, "id-id", template.DefaultLocale)
+	assert.True(t, template.IsActive)
+	require.Len(t, template.Variants, 1)
+	assert.Equal(t, 1, template.Variants[0].Version)
+	assert.EqualValues(t, 3, template.Variants[0].CreatedBy)
+
+	rendered, err := svc.Preview("order_shipped", &dto.PreviewTemplateRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "Pesanan #42 dikirim", rendered.Subject)
+	assert.Equal(t, "<p>Nomor resi: JNE123</p>", rendered.Body)
+
+	_, err = svc.CreateTemplate(3, req)
+	assert.ErrorIs(t, err, ErrTemplateExists)
+}
+
+func TestTemplateService_CreateTemplate_Invalid(t *testing.T) {
+	svc, _ := newSeededTemplateService(t)
+
+	_, err := svc.CreateTemplate(3, &dto.CreateTemplateRequest{ID: "Order Shipped", Subject: "Hi", Body: "Hi"})
+	assert.ErrorIs(t, err, ErrInvalidTemplateID)
+
+	_, err = svc.CreateTemplate(3, &dto.CreateTemplateRequest{ID: "order_shipped", Subject: "Hi", Body: "{{.name"})
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+
+	_, err = svc.CreateTemplate(3, &dto.CreateTemplateRequest{ID: "order_shipped", DefaultLocale: "english", Subject: "Hi", Body: "Hi"})
+	assert.ErrorIs(t, err, ErrInvalidLocale)
+
+	// Sample data must fit the content
+	_, err = svc.CreateTemplate(3, &dto.CreateTemplateRequest{ID: "order_shipped", Subject: "Hi", Body: "{{.name}} {{.total}}",
+		SampleData: map[string]interface{}{"name": "Budi"}})
+	assert.ErrorIs(t, err, ErrTemplateVariables)
+}
+
+func TestTemplateService_Versions(t *testing.T) {
+	// Arrange
+	svc, _ := newSeededTemplateService(t)
+	original, err := svc.Preview("abandoned_cart_1", &dto.PreviewTemplateRequest{})
+	require.NoError(t, err)
+
+	// Act: edit the English variant, then go back to the original
+	saved, err := svc.SaveVariant(3, "abandoned_cart_1", "en", &dto.SaveTemplateVariantRequest{
+		Subject: "Still thinking it over, {{.name}}?",
+		Body:    "<p>{{.items}}</p>",
+	})
+	require.NoError(t, err)
+	edited, err := svc.Preview("abandoned_cart_1", &dto.PreviewTemplateRequest{
+		Variables: map[string]interface{}{"name": "Budi", "items": "Kemeja Batik"},
+	})
+	require.NoError(t, err)
+	restored, err := svc.RestoreVersion(4, "abandoned_cart_1", "en", 1)
+	require.NoError(t, err)
+	versions, err := svc.ListVersions("abandoned_cart_1", "EN")
+	require.NoError(t, err)
+	live, err := svc.Preview("abandoned_cart_1", &dto.PreviewTemplateRequest{})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 2, saved.Version)
+	assert.Equal(t, "Still thinking it over, Budi?", edited.Subject)
+	assert.Equal(t, 3, restored.Version)
+	assert.EqualValues(t, 4, restored.CreatedBy)
+	require.Len(t, versions, 3)
+	assert.Equal(t, []int{3, 2, 1}, []int{versions[0].Version, versions[1].Version, versions[2].Version})
+	assert.Equal(t, original.Subject, live.Subject)
+	assert.Equal(t, original.Body, live.Body)
+	assert.Equal(t, 3, live.Version)
+
+	old, err := svc.Preview("abandoned_cart_1", &dto.PreviewTemplateRequest{Version: 2,
+		Variables: map[string]interface{}{"name": "Budi", "items": "Kemeja Batik"}})
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Kemeja Batik</p>", old.Body)
+
+	_, err = svc.RestoreVersion(4, "abandoned_cart_1", "en", 9)
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestTemplateService_Preview_UnsavedContent(t *testing.T) {
+	svc, _ := newSeededTemplateService(t)
+
+	// Unsaved content is rendered with the sample data of the variant it would replace
+	rendered, err := svc.Preview(TemplatePaymentSuccess, &dto.PreviewTemplateRequest{
+		Locale:  "id",
+		Subject: "Terima kasih",
+		Body:    "<p>{{formatCurrency .amount .currency}} untuk pesanan #{{.order_id}}</p>",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Rp 1.275.000 untuk pesanan #1042</p>", rendered.Body)
+	assert.Zero(t, rendered.Version)
+}
+
+func TestTemplateService_UpdateAndDelete(t *testing.T) {
+	svc, _ := newSeededTemplateService(t)
+	_, err := svc.CreateTemplate(3, &dto.CreateTemplateRequest{ID: "newsletter", Subject: "News", Body: "<p>News</p>"})
+	require.NoError(t, err)
+
+	// The default locale needs a variant
+	locale := "id"
+	_, err = svc.UpdateTemplate("newsletter", &dto.UpdateTemplateRequest{DefaultLocale: &locale})
+	assert.ErrorIs(t, err, ErrVariantNotFound)
+	_, err = svc.SaveVariant(3, "newsletter", "id", &dto.SaveTemplateVariantRequest{Subject: "Berita", Body: "<p>Berita</p>"})
+	require.NoError(t, err)
+	updated, err := svc.UpdateTemplate("newsletter", &dto.UpdateTemplateRequest{DefaultLocale: &locale})
+	require.NoError(t, err)
+	assert.Equal(t, "id", updated.DefaultLocale)
+	assert.Len(t, updated.Variants, 2)
+
+	// Built-in templates are sent by the service itself
+	assert.ErrorIs(t, svc.DeleteTemplate(TemplateOrderConfirmation), ErrBuiltInTemplate)
+	require.NoError(t, svc.DeleteTemplate("newsletter"))
+	_, err = svc.GetTemplate("newsletter")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/notification/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Built-in templates the service sends itself
+const (
+	TemplateOrderConfirmation = "order_confirmation"
+	TemplatePaymentSuccess    = "payment_success"
+)
+
+// abandonedCartBody is shared by the abandoned cart reminders, which differ in their opening line.
+// Cart Service provides name, items, item_count, total and cart_url.
+const abandonedCartBody = `
+<!DOCTYPE html>
+<html>
+<head><style>body{font-family:Arial,sans-serif;}</style></head>
+<body>
+<p>Hi {{.name}},</p>
+<p>%s</p>
+<p><strong>Your cart ({{.item_count}} items):</strong> {{.items}}</p>
+<p><strong>Total:</strong> {{.total}}</p>
+<p><a href="{{.cart_url}}">Return to your cart</a></p>
+<p style="color:#888;font-size:12px;">You can turn off cart reminders in your account settings.</p>
+</body>
+</html>`
+
+// orderConfirmationBody is laid out the same in every locale; its text comes from the arguments.
+// SendOrderConfirmation provides order_id, customer_name, total_amount, currency and items, each
+// with product_name, quantity, price and subtotal.
+const orderConfirmationBody = `
+<!DOCTYPE html>
+<html>
+<head><style>body{font-family:Arial,sans-serif;} th,td{padding:4px 8px;text-align:left;}</style></head>
+<body>
+<h2>%[1]s</h2>
+<p>%[2]s {{.customer_name}},</p>
+<p>%[3]s</p>
+<p><strong>%[4]s:</strong> #{{.order_id}}</p>
+{{if .items}}<table>
+<tr><th>%[5]s</th><th>%[6]s</th><th>%[7]s</th><th>%[11]s</th></tr>
+{{range .items}}<tr><td>{{.product_name}}</td><td>{{formatNumber .quantity}}</td><td>{{formatCurrency .price $.currency}}</td><td>{{formatCurrency .subtotal $.currency}}</td></tr>
+{{end}}</table>{{end}}
+<p><strong>%[8]s:</strong> {{formatCurrency .total_amount .currency}}</p>
+<p>%[9]s</p>
+<p>%[10]s</p>
+</body>
+</html>`
+
+// paymentSuccessBody is laid out the same in every locale; its text comes from the arguments.
+// SendPaymentSuccess provides order_id, payment_id, amount, currency, payment_method and transaction_id.
+const paymentSuccessBody = `
+<!DOCTYPE html>
+<html>
+<head><style>body{font-family:Arial,sans-serif;}</style></head>
+<body>
+<h2>%[1]s</h2>
+<p>%[2]s</p>
+<p><strong>%[3]s:</strong> #{{.order_id}}</p>
+<p><strong>%[4]s:</strong> {{.transaction_id}}</p>
+<p><strong>%[5]s:</strong> {{formatCurrency .amount .currency}}</p>
+<p><strong>%[6]s:</strong> {{.payment_method}}</p>
+<p>%[7]s</p>
+</body>
+</html>`
+
+var abandonedCartSample = sampleData(map[string]interface{}{
+	"name":       "Budi",
+	"items":      "Kemeja Batik, Sepatu Lari",
+	"item_count": "2",
+	"total":      "Rp 450.000",
+	"cart_url":   "https://goshop.local/cart",
+})
+
+var orderConfirmationSample = sampleData(map[string]interface{}{
+	"order_id":      1042,
+	"customer_name": "Budi Santoso",
+	"total_amount":  1275000,
+	"currency":      "IDR",
+	"items": []map[string]interface{}{
+		{"product_name": "Kemeja Batik", "quantity": 2, "price": 350000, "subtotal": 700000},
+		{"product_name": "Sepatu Lari", "quantity": 1, "price": 575000, "subtotal": 575000},
+	},
+})
+
+var paymentSuccessSample = sampleData(map[string]interface{}{
+	"order_id":       1042,
+	"payment_id":     311,
+	"amount":         1275000,
+	"currency":       "IDR",
+	"payment_method": "bank_transfer",
+	"transaction_id": "TXN-20240501-0042",
+})
+
+// templateSeed is a built-in template with the first version of each of its locales
+type templateSeed struct {
+	template domain.NotificationTemplate
+	variants []domain.TemplateVersion
+}
+
+// defaultTemplates are created when missing, so the service can send them on a fresh database
+var defaultTemplates = []templateSeed{
+	abandonedCartSeed("abandoned_cart_1", "First abandoned cart reminder",
+		"You left something in your cart",
+		"Your cart is saved and waiting for you."),
+	abandonedCartSeed("abandoned_cart_2", "Second abandoned cart reminder",
+		"Your cart is still waiting, {{.name}}",
+		"The items in your cart are still available, but stock can run out."),
+	abandonedCartSeed("abandoned_cart_3", "Last abandoned cart reminder",
+		"Last reminder: your cart expires soon",
+		"Your cart will be emptied in a few days. Complete your order before it expires."),
+	{
+		template: builtInTemplate(TemplateOrderConfirmation, "Sent when an order is placed"),
+		variants: []domain.TemplateVersion{
+			seedVersion(TemplateOrderConfirmation, "en", "Order Confirmation - #{{.order_id}}",
+				fmt.Sprintf(orderConfirmationBody, "Order Confirmation", "Dear",
+					"Thank you for your order! Your order has been confirmed.", "Order ID",
+					"Product", "Qty", "Price", "Total Amount",
+					"We will notify you once your order is shipped.", "Thank you for shopping with us!",
+					"Subtotal"),
+				orderConfirmationSample),
+			seedVersion(TemplateOrderConfirmation, "id", "Konfirmasi Pesanan - #{{.order_id}}",
+				fmt.Sprintf(orderConfirmationBody, "Konfirmasi Pesanan", "Yth.",
+					"Terima kasih atas pesanan Anda! Pesanan Anda telah dikonfirmasi.", "ID Pesanan",
+					"Produk", "Jumlah", "Harga", "Total Pembayaran",
+					"Kami akan memberi tahu Anda setelah pesanan dikirim.", "Terima kasih telah berbelanja bersama kami!",
+					"Jumlah Harga"),
+				orderConfirmationSample),
+		},
+	},
+	{
+		template: builtInTemplate(TemplatePaymentSuccess, "Sent when a payment succeeds"),
+		variants: []domain.TemplateVersion{
+			seedVersion(TemplatePaymentSuccess, "en", "Payment Successful - Transaction {{.transaction_id}}",
+				fmt.Sprintf(paymentSuccessBody, "Payment Successful",
+					"Your payment has been processed successfully.", "Order ID", "Transaction ID",
+					"Amount", "Payment Method", "Thank you for your purchase!"),
+				paymentSuccessSample),
+			seedVersion(TemplatePaymentSuccess, "id", "Pembayaran Berhasil - Transaksi {{.transaction_id}}",
+				fmt.Sprintf(paymentSuccessBody, "Pembayaran Berhasil",
+					"Pembayaran Anda telah berhasil diproses.", "ID Pesanan", "ID Transaksi",
+					"Jumlah", "Metode Pembayaran", "Terima kasih atas pembelian Anda!"),
+				paymentSuccessSample),
+		},
+	},
+}
+
+func abandonedCartSeed(id, description, subject, opening string) templateSeed {
+	return templateSeed{
+		template: builtInTemplate(id, description),
+		variants: []domain.TemplateVersion{
+			seedVersion(id, "en", subject, fmt.Sprintf(abandonedCartBody, opening), abandonedCartSample),
+		},
+	}
+}
+
+func builtInTemplate(id, description string) domain.NotificationTemplate {
+	return domain.NotificationTemplate{
+		ID:            id,
+		Type:          domain.NotificationTypeEmail,
+		Description:   description,
+		DefaultLocale: "en",
+		IsActive:      true,
+	}
+}
+
+func seedVersion(templateID, locale, subject, body, sample string) domain.TemplateVersion {
+	return domain.TemplateVersion{
+		TemplateID: templateID,
+		Locale:     locale,
+		Version:    1,
+		Subject:    subject,
+		Body:       body,
+		SampleData: sample,
+	}
+}
+
+func sampleData(data map[string]interface{}) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+	return string(encoded)
+}
+
+// isBuiltInTemplate reports whether a template is one of the seeded ones
+func isBuiltInTemplate(id string) bool {
+	for _, seed := range defaultTemplates {
+		if seed.template.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SeedTemplates creates the built-in templates and locales that don't exist yet, leaving edited
+// ones alone. Templates from before versioning are migrated to versions first.
+func SeedTemplates(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := migrateLegacyTemplates(tx); err != nil {
+			return err
+		}
+		for _, seed := range defaultTemplates {
+			template := seed.template
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&template).Error; err != nil {
+				return err
+			}
+			// Locales with a version 1 were seeded before, and may have been edited since
+			variants := append([]domain.TemplateVersion(nil), seed.variants...)
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&variants).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateLegacyTemplates moves the content of templates from before versioning, which was kept
+// on the template itself, to version 1 of their default locale
+func migrateLegacyTemplates(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasColumn(&domain.NotificationTemplate{}, "body") {
+		return nil
+	}
+
+	err := tx.Exec(`
+		INSERT INTO notification_template_versions (template_id, locale, version, subject, body, sample_data, created_by, created_at)
+		SELECT id, default_locale, 1, subject, body, '', 0, updated_at FROM notification_templates
+		ON CONFLICT DO NOTHING`).Error
+	if err != nil {
+		return err
+	}
+	if err := migrator.DropColumn(&domain.NotificationTemplate{}, "subject"); err != nil {
+		return err
+	}
+	return migrator.DropColumn(&domain.NotificationTemplate{}, "body")
+}
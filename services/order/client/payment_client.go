@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+)
+
+// PaymentClient calls the Payment Service HTTP API
+type PaymentClient struct {
+	baseURL    string
+	httpClient *http.Client
+	signer     *auth.Signer
+}
+
+// PaymentInfo represents payment data returned from Payment Service
+type PaymentInfo struct {
+	ID             uint    `json:"id"`
+	OrderID        uint    `json:"order_id"`
+	Amount         float64 `json:"amount"`
+	Status         string  `json:"status"`
+	TransactionID  string  `json:"transaction_id"`
+	RefundedAmount float64 `json:"refunded_amount"`
+}
+
+// paymentEnvelope mirrors the {success, message, data} response shape of Payment Service
+type paymentEnvelope struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewPaymentClient creates a new HTTP client for Payment Service.
+// Requests carry the caller's identity from the context, signed with signer.
+func NewPaymentClient(baseURL string, signer *auth.Signer) *PaymentClient {
+	return &PaymentClient{
+		baseURL: baseURL,
+		signer:  signer,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// GetPaymentByOrderID fetches the payment for an order, returning nil if none exists
+func (c *PaymentClient) GetPaymentByOrderID(ctx context.Context, orderID uint) (*PaymentInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/payments/order/%d", c.baseURL, orderID)
+
+	var payment PaymentInfo
+	status, err := c.do(ctx, http.MethodGet, url, nil, &payment)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetPaymentStatuses fetches payment statuses for a batch of orders.
+// Orders without a payment are absent from the returned map.
+func (c *PaymentClient) GetPaymentStatuses(ctx context.Context, orderIDs []uint) (map[uint]string, error) {
+	statuses := make(map[uint]string, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return statuses, nil
+	}
+
+	ids := make([]string, len(orderIDs))
+	for i, id := range orderIDs {
+		ids[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/payments/statuses?order_ids=%s", c.baseURL, url.QueryEscape(strings.Join(ids, ",")))
+
+	if _, err := c.do(ctx, http.MethodGet, endpoint, nil, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// RefundOrder requests a refund of part of the payment made for an order.
+// The reference makes retries safe: a repeated reference does not refund twice.
+func (c *PaymentClient) RefundOrder(ctx context.Context, orderID uint, amount float64, reason, reference string) (*PaymentInfo, error) {
+	payment, err := c.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("no payment found for order %d", orderID)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/payments/%d/refund", c.baseURL, payment.ID)
+	body := map[string]interface{}{
+		"amount":    amount,
+		"reason":    reason,
+		"reference": reference,
+	}
+	if _, err := c.do(ctx, http.MethodPost, url, body, nil); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (c *PaymentClient) do(ctx context.Context, method, url string, body interface{}, out interface{}) (int, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act on behalf of the user whose request we are serving, or as this service otherwise
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		identity = &auth.ServiceIdentity
+	}
+	if err := c.signer.Sign(req, *identity); err != nil {
+		return 0, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var envelope paymentEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return resp.StatusCode, fmt.Errorf("payment service: invalid response: %w", err)
+	}
+	if resp.StatusCode >= 400 || !envelope.Success {
+		return resp.StatusCode, fmt.Errorf("payment service: %s", envelope.Message)
+	}
+
+	if out != nil && len(envelope.Data) > 0 {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			return resp.StatusCode, err
+		}
+	}
+	return resp.StatusCode, nil
+}
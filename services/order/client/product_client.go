@@ -24,6 +24,9 @@ type ProductInfo struct {
 	Price       float64
 	Stock       int
 	IsActive    bool
+	WeightGrams int
+	CategoryID  uint
+	TaxCategory string
 }
 
 // NewProductClient creates a new gRPC client connection to Product Service
@@ -71,6 +74,9 @@ func (c *ProductClient) GetProduct(ctx context.Context, productID uint) (*Produc
 		Price:       resp.Price,
 		Stock:       int(resp.Stock),
 		IsActive:    resp.IsActive,
+		WeightGrams: int(resp.WeightGrams),
+		CategoryID:  uint(resp.CategoryId),
+		TaxCategory: resp.TaxCategory,
 	}, nil
 }
 
@@ -107,6 +113,23 @@ func (c *ProductClient) DecreaseStock(ctx context.Context, productID uint, quant
 	return int(resp.RemainingStock), nil
 }
 
+// IncreaseStock puts stock back for a product (called when items are returned)
+func (c *ProductClient) IncreaseStock(ctx context.Context, productID uint, quantity int) (int, error) {
+	resp, err := c.client.IncreaseStock(ctx, &pb.IncreaseStockRequest{
+		ProductId: uint64(productID),
+		Quantity:  int32(quantity),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if !resp.Success {
+		return 0, &StockError{Message: resp.ErrorMessage}
+	}
+
+	return int(resp.RemainingStock), nil
+}
+
 // StockError represents a stock-related error
 type StockError struct {
 	Message string
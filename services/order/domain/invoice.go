@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Invoice is the fiscal document issued for an order.
+// Seller, buyer and amounts are snapshots taken when the invoice is issued and never change afterwards.
+type Invoice struct {
+	ID               uint             `json:"id" gorm:"primaryKey"`
+	Number           string           `json:"number" gorm:"size:32;not null;uniqueIndex"`
+	Year             int              `json:"year" gorm:"not null;uniqueIndex:idx_invoices_year_sequence"`
+	Sequence         int              `json:"sequence" gorm:"not null;uniqueIndex:idx_invoices_year_sequence"`
+	OrderID          uint             `json:"order_id" gorm:"not null;uniqueIndex"`
+	UserID           uint             `json:"user_id" gorm:"not null;index"`
+	IssuedAt         time.Time        `json:"issued_at" gorm:"not null"`
+	Seller           InvoiceSeller    `json:"seller" gorm:"embedded;embeddedPrefix:seller_"`
+	Buyer            ShippingAddress  `json:"buyer" gorm:"embedded;embeddedPrefix:buyer_"`
+	Subtotal         float64          `json:"subtotal" gorm:"not null"`
+	ShippingCost     float64          `json:"shipping_cost" gorm:"not null"`
+	Discount         float64          `json:"discount" gorm:"not null;default:0"`
+	Total            float64          `json:"total" gorm:"not null"`
+	Currency         string           `json:"currency" gorm:"size:3;not null;default:IDR"`
+	PaymentReference string           `json:"payment_reference"`
+	Lines            []InvoiceLine    `json:"lines" gorm:"foreignKey:InvoiceID"`
+	TaxLines         []InvoiceTaxLine `json:"tax_lines" gorm:"foreignKey:InvoiceID"`
+	CreatedAt        time.Time        `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// InvoiceSeller holds the merchant details printed on an invoice
+type InvoiceSeller struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	TaxID   string `json:"tax_id"` // e.g., NPWP
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+}
+
+// InvoiceLine is one billed product line
+type InvoiceLine struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	InvoiceID   uint    `json:"invoice_id" gorm:"not null;index"`
+	ProductID   uint    `json:"product_id" gorm:"not null"`
+	Description string  `json:"description" gorm:"not null"`
+	Quantity    int     `json:"quantity" gorm:"not null"`
+	UnitPrice   float64 `json:"unit_price" gorm:"not null"`
+	Amount      float64 `json:"amount" gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (InvoiceLine) TableName() string {
+	return "invoice_lines"
+}
+
+// InvoiceTaxLine is one tax charged on the invoice
+type InvoiceTaxLine struct {
+	ID            uint    `json:"id" gorm:"primaryKey"`
+	InvoiceID     uint    `json:"invoice_id" gorm:"not null;index"`
+	Name          string  `json:"name" gorm:"not null"`
+	Rate          float64 `json:"rate" gorm:"not null"`
+	Inclusive     bool    `json:"inclusive"` // Already contained in the line prices
+	TaxableAmount float64 `json:"taxable_amount" gorm:"not null"`
+	Amount        float64 `json:"amount" gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (InvoiceTaxLine) TableName() string {
+	return "invoice_tax_lines"
+}
+
+// InvoiceSequence holds the last invoice number issued in a year
+type InvoiceSequence struct {
+	Year       int `gorm:"primaryKey;autoIncrement:false"`
+	LastNumber int `gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
+
+// FormatInvoiceNumber builds the printed invoice number, e.g. INV/2024/000042
+func FormatInvoiceNumber(year, sequence int) string {
+	return fmt.Sprintf("INV/%d/%06d", year, sequence)
+}
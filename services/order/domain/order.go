@@ -4,13 +4,32 @@ import "time"
 
 // Order represents an order in the system
 type Order struct {
-	ID          uint        `json:"id" gorm:"primaryKey"`
-	UserID      uint        `json:"user_id" gorm:"not null;index"`
-	Status      OrderStatus `json:"status" gorm:"default:pending"`
-	TotalAmount float64     `json:"total_amount" gorm:"not null"`
-	Items       []OrderItem `json:"items" gorm:"foreignKey:OrderID"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID              uint            `json:"id" gorm:"primaryKey"`
+	UserID          uint            `json:"user_id" gorm:"not null;index"`
+	Status          OrderStatus     `json:"status" gorm:"default:pending"`
+	Subtotal        float64         `json:"subtotal" gorm:"not null;default:0"`
+	ShippingCost    float64         `json:"shipping_cost" gorm:"not null;default:0"`
+	DiscountAmount  float64         `json:"discount_amount" gorm:"not null;default:0"` // Off the subtotal and shipping
+	CouponCode      string          `json:"coupon_code" gorm:"size:50"`
+	TaxAmount       float64         `json:"tax_amount" gorm:"not null;default:0"`
+	TaxInclusive    bool            `json:"tax_inclusive"`             // TaxAmount is contained in the prices rather than added to the total
+	TaxRegion       string          `json:"tax_region" gorm:"size:10"` // Where the order was taxed, from the shipping address
+	TotalAmount     float64         `json:"total_amount" gorm:"not null"`
+	Currency        string          `json:"currency" gorm:"size:3;not null;default:IDR"`
+	ExchangeRate    float64         `json:"exchange_rate" gorm:"not null;default:1"` // Base currency per unit of Currency when the order was priced
+	ShippingMethod  ShippingMethod  `json:"shipping_method"`
+	ShippingAddress ShippingAddress `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	Items           []OrderItem     `json:"items" gorm:"foreignKey:OrderID"`
+	Discounts       []OrderDiscount `json:"discounts" gorm:"foreignKey:OrderID"`
+	Taxes           []OrderTax      `json:"taxes" gorm:"foreignKey:OrderID"`
+	Shipments       []Shipment      `json:"shipments" gorm:"foreignKey:OrderID"`
+	PaymentRef      string          `json:"payment_ref"` // Transaction ID of the payment that paid the order
+	PaidAt          *time.Time      `json:"paid_at"`
+	CancelReason    string          `json:"cancel_reason"`
+	// ReleasePending is set when the order is cancelled and cleared once its coupon and stock are back
+	ReleasePending bool      `json:"-" gorm:"default:false;index"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // TableName overrides the table name
@@ -20,13 +39,23 @@ func (Order) TableName() string {
 
 // OrderItem represents a single item in an order
 type OrderItem struct {
-	ID        uint    `json:"id" gorm:"primaryKey"`
-	OrderID   uint    `json:"order_id" gorm:"not null;index"`
-	ProductID uint    `json:"product_id" gorm:"not null"`
-	Name      string  `json:"name" gorm:"not null"`
-	Price     float64 `json:"price" gorm:"not null"`
-	Quantity  int     `json:"quantity" gorm:"not null"`
-	Subtotal  float64 `json:"subtotal" gorm:"not null"`
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	OrderID     uint    `json:"order_id" gorm:"not null;index"`
+	ProductID   uint    `json:"product_id" gorm:"not null"`
+	Name        string  `json:"name" gorm:"not null"`
+	Price       float64 `json:"price" gorm:"not null"`
+	Quantity    int     `json:"quantity" gorm:"not null"`
+	Subtotal    float64 `json:"subtotal" gorm:"not null"`
+	WeightGrams int     `json:"weight_grams" gorm:"default:0"` // Per unit, copied from the product
+	CategoryID  uint    `json:"category_id" gorm:"default:0"`
+	TaxCategory string  `json:"tax_category" gorm:"size:20;not null;default:standard"`
+	// DiscountAmount is the line's share of the order's item discounts
+	DiscountAmount float64 `json:"discount_amount" gorm:"not null;default:0"`
+	// TaxAmount is the line's share of the order's tax, on its price net of discounts
+	TaxAmount float64 `json:"tax_amount" gorm:"not null;default:0"`
+	// StockReleased is set once a cancelled order's units are back in stock, so resuming
+	// an interrupted release does not add them twice
+	StockReleased bool `json:"-" gorm:"default:false"`
 }
 
 // TableName overrides the table name
@@ -34,6 +63,14 @@ func (OrderItem) TableName() string {
 	return "order_items"
 }
 
+// NetUnitPrice is what one unit cost after the line's share of discounts
+func (i *OrderItem) NetUnitPrice() float64 {
+	if i.Quantity == 0 {
+		return i.Price
+	}
+	return i.Price - i.DiscountAmount/float64(i.Quantity)
+}
+
 // OrderStatus represents the status of an order
 type OrderStatus string
 
@@ -45,3 +82,50 @@ const (
 	OrderStatusDelivered OrderStatus = "delivered"
 	OrderStatusCancelled OrderStatus = "cancelled"
 )
+
+// IsValid reports whether the status is one of the known order statuses
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusConfirmed, OrderStatusPaid,
+		OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsPayable reports whether an order in this status is still awaiting payment
+func (s OrderStatus) IsPayable() bool {
+	return s == OrderStatusPending || s == OrderStatusConfirmed
+}
+
+// IsInvoiceable reports whether an invoice can be issued for an order in this status
+func (s OrderStatus) IsInvoiceable() bool {
+	return s == OrderStatusPaid || s == OrderStatusShipped || s == OrderStatusDelivered
+}
+
+// staffTransitions lists the statuses staff may move an order to by hand, from each status.
+// Paying and shipping record a payment reference or a shipment, so they have their own paths.
+var staffTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending: {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusShipped: {OrderStatusDelivered},
+}
+
+// CanStaffSet reports whether staff may move an order from this status to next by hand
+func (s OrderStatus) CanStaffSet(next OrderStatus) bool {
+	for _, allowed := range staffTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaffSettable reports whether staff may move orders to this status by hand from any status
+func (s OrderStatus) IsStaffSettable() bool {
+	for from := range staffTransitions {
+		if from.CanStaffSet(s) {
+			return true
+		}
+	}
+	return false
+}
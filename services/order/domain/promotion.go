@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Promotion is a coupon customers apply by code. Amounts are in the base currency.
+type Promotion struct {
+	ID   uint          `json:"id" gorm:"primaryKey"`
+	Code string        `json:"code" gorm:"size:50;not null;uniqueIndex"` // Stored upper-case
+	Name string        `json:"name" gorm:"not null"`
+	Type PromotionType `json:"type" gorm:"size:20;not null"`
+	// Value is the percentage off for percentage coupons and the amount off for fixed ones
+	Value float64 `json:"value" gorm:"not null;default:0"`
+	// MaxDiscount caps the discount of percentage and free shipping coupons; 0 means no cap
+	MaxDiscount float64 `json:"max_discount" gorm:"not null;default:0"`
+	// BuyQuantity and GetQuantity define buy-X-get-Y coupons: of every BuyQuantity+GetQuantity
+	// eligible units, the GetQuantity cheapest are free
+	BuyQuantity int     `json:"buy_quantity" gorm:"not null;default:0"`
+	GetQuantity int     `json:"get_quantity" gorm:"not null;default:0"`
+	MinSpend    float64 `json:"min_spend" gorm:"not null;default:0"` // On the items subtotal
+	// UsageLimit caps redemptions across all customers and PerUserLimit per customer; 0 means no limit
+	UsageLimit   int        `json:"usage_limit" gorm:"not null;default:0"`
+	PerUserLimit int        `json:"per_user_limit" gorm:"not null;default:0"`
+	UsedCount    int        `json:"used_count" gorm:"not null;default:0"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	// ProductIDs and CategoryIDs limit the coupon to some items; with neither, every item is eligible
+	ProductIDs  []uint    `json:"product_ids" gorm:"serializer:json;type:text"`
+	CategoryIDs []uint    `json:"category_ids" gorm:"serializer:json;type:text"`
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// NormalizeCouponCode returns the form coupon codes are stored and looked up in
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsRunningAt reports whether the promotion can be redeemed at the given time
+func (p *Promotion) IsRunningAt(at time.Time) bool {
+	if !p.IsActive {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !at.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Targets reports whether an item of the product and category is eligible for the promotion
+func (p *Promotion) Targets(productID, categoryID uint) bool {
+	if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range p.ProductIDs {
+		if id == productID {
+			return true
+		}
+	}
+	for _, id := range p.CategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// PromotionType represents how a promotion discounts an order
+type PromotionType string
+
+const (
+	PromotionTypePercentage   PromotionType = "percentage"
+	PromotionTypeFixed        PromotionType = "fixed"
+	PromotionTypeBuyXGetY     PromotionType = "buy_x_get_y"
+	PromotionTypeFreeShipping PromotionType = "free_shipping"
+)
+
+// IsValid reports whether the type is one of the known promotion types
+func (t PromotionType) IsValid() bool {
+	switch t {
+	case PromotionTypePercentage, PromotionTypeFixed, PromotionTypeBuyXGetY, PromotionTypeFreeShipping:
+		return true
+	}
+	return false
+}
+
+// PromotionRedemption records a promotion used by an order. It counts towards the usage limits
+// until it is released, which happens when the order is cancelled.
+type PromotionRedemption struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	PromotionID uint       `json:"promotion_id" gorm:"not null;index:idx_redemptions_promotion_user"`
+	UserID      uint       `json:"user_id" gorm:"not null;index:idx_redemptions_promotion_user"`
+	OrderID     uint       `json:"order_id" gorm:"not null;uniqueIndex"`
+	Code        string     `json:"code" gorm:"size:50;not null"`
+	ReleasedAt  *time.Time `json:"released_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (PromotionRedemption) TableName() string {
+	return "promotion_redemptions"
+}
+
+// OrderDiscount is a discount applied to an order, kept for audit.
+// Amounts are in the order currency.
+type OrderDiscount struct {
+	ID             uint          `json:"id" gorm:"primaryKey"`
+	OrderID        uint          `json:"order_id" gorm:"not null;index"`
+	PromotionID    uint          `json:"promotion_id" gorm:"not null;index"`
+	Code           string        `json:"code" gorm:"size:50;not null"`
+	Type           PromotionType `json:"type" gorm:"size:20;not null"`
+	Description    string        `json:"description"`
+	ItemsAmount    float64       `json:"items_amount" gorm:"not null;default:0"`
+	ShippingAmount float64       `json:"shipping_amount" gorm:"not null;default:0"`
+	Amount         float64       `json:"amount" gorm:"not null"` // ItemsAmount + ShippingAmount
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (OrderDiscount) TableName() string {
+	return "order_discounts"
+}
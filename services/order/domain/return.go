@@ -0,0 +1,93 @@
+package domain
+
+import "time"
+
+// ReturnRequest represents a return merchandise authorization (RMA) for items of a delivered order
+type ReturnRequest struct {
+	ID           uint          `json:"id" gorm:"primaryKey"`
+	OrderID      uint          `json:"order_id" gorm:"not null;index"`
+	UserID       uint          `json:"user_id" gorm:"not null;index"`
+	Status       ReturnStatus  `json:"status" gorm:"default:requested"`
+	Reason       string        `json:"reason" gorm:"type:text;not null"`
+	StaffNote    string        `json:"staff_note" gorm:"type:text"`
+	RefundAmount float64       `json:"refund_amount" gorm:"not null"`
+	Restocked    bool          `json:"restocked" gorm:"default:false"`
+	Items        []ReturnItem  `json:"items" gorm:"foreignKey:ReturnRequestID"`
+	History      []ReturnEvent `json:"history" gorm:"foreignKey:ReturnRequestID"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ReturnRequest) TableName() string {
+	return "return_requests"
+}
+
+// ReturnItem represents a quantity of one order item being returned
+type ReturnItem struct {
+	ID              uint    `json:"id" gorm:"primaryKey"`
+	ReturnRequestID uint    `json:"return_request_id" gorm:"not null;index"`
+	OrderItemID     uint    `json:"order_item_id" gorm:"not null;index"`
+	ProductID       uint    `json:"product_id" gorm:"not null"`
+	Name            string  `json:"name"`
+	UnitPrice       float64 `json:"unit_price" gorm:"not null"`
+	Quantity        int     `json:"quantity" gorm:"not null"`
+	Subtotal        float64 `json:"subtotal" gorm:"not null"`
+	// Restocked is set once this item's quantity is back in stock, so a retry does not add it twice
+	Restocked bool `json:"restocked" gorm:"default:false"`
+}
+
+// TableName overrides the table name
+func (ReturnItem) TableName() string {
+	return "return_items"
+}
+
+// ReturnEvent records a status change of a return request
+type ReturnEvent struct {
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	ReturnRequestID uint         `json:"return_request_id" gorm:"not null;index"`
+	FromStatus      ReturnStatus `json:"from_status"`
+	ToStatus        ReturnStatus `json:"to_status"`
+	ActorID         uint         `json:"actor_id"`
+	Note            string       `json:"note" gorm:"type:text"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (ReturnEvent) TableName() string {
+	return "return_events"
+}
+
+// ReturnStatus represents the status of a return request
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusRejected  ReturnStatus = "rejected"
+	ReturnStatusCancelled ReturnStatus = "cancelled"
+	ReturnStatusReceived  ReturnStatus = "received"
+	ReturnStatusRefunded  ReturnStatus = "refunded"
+)
+
+// returnTransitions lists the statuses each status may move to
+var returnTransitions = map[ReturnStatus][]ReturnStatus{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected, ReturnStatusCancelled},
+	ReturnStatusApproved:  {ReturnStatusReceived},
+	ReturnStatusReceived:  {ReturnStatusRefunded},
+}
+
+// CanTransitionTo reports whether the return may move from its current status to next
+func (r *ReturnRequest) CanTransitionTo(next ReturnStatus) bool {
+	for _, allowed := range returnTransitions[r.Status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether the return still counts against the returnable quantity
+func (r *ReturnRequest) IsOpen() bool {
+	return r.Status != ReturnStatusRejected && r.Status != ReturnStatusCancelled
+}
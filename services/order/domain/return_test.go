@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturnRequest_CanTransitionTo(t *testing.T) {
+	ret := &ReturnRequest{Status: ReturnStatusRequested}
+	assert.True(t, ret.CanTransitionTo(ReturnStatusApproved))
+	assert.True(t, ret.CanTransitionTo(ReturnStatusRejected))
+	assert.False(t, ret.CanTransitionTo(ReturnStatusReceived))
+
+	ret.Status = ReturnStatusApproved
+	assert.True(t, ret.CanTransitionTo(ReturnStatusReceived))
+	assert.False(t, ret.CanTransitionTo(ReturnStatusCancelled))
+
+	ret.Status = ReturnStatusRefunded
+	assert.False(t, ret.CanTransitionTo(ReturnStatusReceived))
+}
+
+func TestReturnRequest_IsOpen(t *testing.T) {
+	assert.True(t, (&ReturnRequest{Status: ReturnStatusApproved}).IsOpen())
+	assert.False(t, (&ReturnRequest{Status: ReturnStatusRejected}).IsOpen())
+	assert.False(t, (&ReturnRequest{Status: ReturnStatusCancelled}).IsOpen())
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "time"
+
+// Address represents an entry in a user's address book
+type Address struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	Label         string    `json:"label"` // e.g., "Home", "Office"
+	RecipientName string    `json:"recipient_name" gorm:"not null"`
+	Phone         string    `json:"phone" gorm:"not null"`
+	Line1         string    `json:"line1" gorm:"not null"`
+	Line2         string    `json:"line2"`
+	City          string    `json:"city" gorm:"not null"`
+	Province      string    `json:"province"`
+	PostalCode    string    `json:"postal_code" gorm:"not null"`
+	Country       string    `json:"country" gorm:"default:ID"`
+	IsDefault     bool      `json:"is_default" gorm:"default:false"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Address) TableName() string {
+	return "addresses"
+}
+
+// Snapshot copies the address into an immutable ShippingAddress for an order
+func (a *Address) Snapshot() ShippingAddress {
+	return ShippingAddress{
+		RecipientName: a.RecipientName,
+		Phone:         a.Phone,
+		Line1:         a.Line1,
+		Line2:         a.Line2,
+		City:          a.City,
+		Province:      a.Province,
+		PostalCode:    a.PostalCode,
+		Country:       a.Country,
+	}
+}
+
+// ShippingAddress is the address snapshot stored on an order.
+// It is copied at checkout so later address book edits don't change past orders.
+type ShippingAddress struct {
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2,omitempty"`
+	City          string `json:"city"`
+	Province      string `json:"province,omitempty"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+}
+
+// ShippingMethod represents the delivery option chosen for an order
+type ShippingMethod string
+
+const (
+	ShippingMethodStandard ShippingMethod = "standard"
+	ShippingMethodExpress  ShippingMethod = "express"
+)
+
+// Shipment represents a parcel handed over to a carrier
+type Shipment struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	OrderID        uint           `json:"order_id" gorm:"not null;index"`
+	Carrier        string         `json:"carrier" gorm:"not null"`
+	TrackingNumber string         `json:"tracking_number" gorm:"not null;index"`
+	Status         ShipmentStatus `json:"status" gorm:"default:shipped"`
+	ShippedBy      uint           `json:"shipped_by"` // Staff user ID
+	ShippedAt      time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Shipment) TableName() string {
+	return "shipments"
+}
+
+// ShipmentStatus represents the delivery status of a shipment
+type ShipmentStatus string
+
+const (
+	ShipmentStatusShipped   ShipmentStatus = "shipped"
+	ShipmentStatusDelivered ShipmentStatus = "delivered"
+)
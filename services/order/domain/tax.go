@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// OrderTax is the total of one tax over an order, as charged when it was placed.
+// Amounts are in the order currency.
+type OrderTax struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	OrderID       uint      `json:"order_id" gorm:"not null;index"`
+	Name          string    `json:"name" gorm:"size:50;not null"` // e.g. "PPN"
+	Rate          float64   `json:"rate" gorm:"not null"`         // As a fraction, e.g. 0.11
+	Inclusive     bool      `json:"inclusive"`                    // Contained in the prices rather than added on top
+	TaxableAmount float64   `json:"taxable_amount" gorm:"not null"`
+	Amount        float64   `json:"amount" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (OrderTax) TableName() string {
+	return "order_taxes"
+}
@@ -0,0 +1,53 @@
+package dto
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// Payment status values reported for orders that have no payment status from Payment Service
+const (
+	PaymentStatusNone    = "none"    // No payment was created for the order
+	PaymentStatusUnknown = "unknown" // Payment Service could not be reached
+)
+
+// AdminOrderQuery represents the filters, sorting and pagination of a back-office order search
+type AdminOrderQuery struct {
+	Status    string   `form:"status"` // Comma-separated list of statuses
+	UserID    uint     `form:"user_id"`
+	ProductID uint     `form:"product_id"`
+	From      string   `form:"from"` // RFC3339 timestamp or YYYY-MM-DD, inclusive
+	To        string   `form:"to"`   // RFC3339 timestamp or YYYY-MM-DD, inclusive
+	MinTotal  *float64 `form:"min_total"`
+	MaxTotal  *float64 `form:"max_total"`
+	Sort      string   `form:"sort"` // created_at or total_amount, prefixed with "-" for descending
+	Cursor    string   `form:"cursor"`
+	Limit     int      `form:"limit"`
+}
+
+// AdminOrderResponse represents an order in back-office responses
+type AdminOrderResponse struct {
+	OrderResponse
+	PaymentStatus string `json:"payment_status"`
+}
+
+// AdminOrderListResponse represents a cursor-paginated back-office order list
+type AdminOrderListResponse struct {
+	Orders     []AdminOrderResponse `json:"orders"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+	Limit      int                  `json:"limit"`
+}
+
+// BulkStatusUpdateRequest represents the payload for updating the status of many orders at once
+type BulkStatusUpdateRequest struct {
+	OrderIDs []uint             `json:"order_ids" binding:"required,min=1,max=500"`
+	Status   domain.OrderStatus `json:"status" binding:"required"`
+}
+
+// BulkStatusUpdateResponse reports which orders were updated.
+// Rejected orders were in a status that cannot move to the requested one;
+// failed orders hit an error part way, e.g. releasing stock for a cancellation.
+type BulkStatusUpdateResponse struct {
+	Status   domain.OrderStatus `json:"status"`
+	Updated  []uint             `json:"updated"`
+	NotFound []uint             `json:"not_found"`
+	Rejected []uint             `json:"rejected"`
+	Failed   []uint             `json:"failed"`
+}
@@ -4,7 +4,11 @@ import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
 
 // CreateOrderRequest represents the payload for creating an order
 type CreateOrderRequest struct {
-	Items []OrderItemRequest `json:"items" binding:"required,min=1,dive"`
+	Items          []OrderItemRequest    `json:"items" binding:"required,min=1,dive"`
+	AddressID      uint                  `json:"address_id" binding:"required"`
+	ShippingMethod domain.ShippingMethod `json:"shipping_method"` // Defaults to standard
+	Currency       string                `json:"currency"`        // Defaults to IDR
+	CouponCode     string                `json:"coupon_code" binding:"max=50"`
 }
 
 // OrderItemRequest represents a single item in the order request
@@ -15,12 +19,28 @@ type OrderItemRequest struct {
 
 // OrderResponse represents an order in API responses
 type OrderResponse struct {
-	ID          uint                `json:"id"`
-	UserID      uint                `json:"user_id"`
-	Status      domain.OrderStatus  `json:"status"`
-	TotalAmount float64             `json:"total_amount"`
-	Items       []OrderItemResponse `json:"items"`
-	CreatedAt   string              `json:"created_at"`
+	ID              uint                    `json:"id"`
+	UserID          uint                    `json:"user_id"`
+	Status          domain.OrderStatus      `json:"status"`
+	Subtotal        float64                 `json:"subtotal"`
+	ShippingCost    float64                 `json:"shipping_cost"`
+	DiscountAmount  float64                 `json:"discount_amount"`
+	CouponCode      string                  `json:"coupon_code,omitempty"`
+	Discounts       []OrderDiscountResponse `json:"discounts,omitempty"`
+	TaxAmount       float64                 `json:"tax_amount"`
+	TaxInclusive    bool                    `json:"tax_inclusive"` // Tax is contained in the prices rather than added to the total
+	TaxRegion       string                  `json:"tax_region,omitempty"`
+	Taxes           []OrderTaxResponse      `json:"taxes,omitempty"`
+	TotalAmount     float64                 `json:"total_amount"`
+	Currency        string                  `json:"currency"`
+	ShippingMethod  domain.ShippingMethod   `json:"shipping_method"`
+	ShippingAddress domain.ShippingAddress  `json:"shipping_address"`
+	Items           []OrderItemResponse     `json:"items"`
+	Shipments       []ShipmentResponse      `json:"shipments"`
+	PaymentRef      string                  `json:"payment_ref,omitempty"`
+	PaidAt          string                  `json:"paid_at,omitempty"`
+	CancelReason    string                  `json:"cancel_reason,omitempty"`
+	CreatedAt       string                  `json:"created_at"`
 }
 
 // OrderItemResponse represents an order item in API responses
@@ -31,6 +51,29 @@ type OrderItemResponse struct {
 	Price     float64 `json:"price"`
 	Quantity  int     `json:"quantity"`
 	Subtotal  float64 `json:"subtotal"`
+	// DiscountAmount is the line's share of the order's item discounts
+	DiscountAmount float64 `json:"discount_amount"`
+	// TaxAmount is the line's share of the order's tax
+	TaxAmount float64 `json:"tax_amount"`
+}
+
+// OrderDiscountResponse represents a discount applied to an order
+type OrderDiscountResponse struct {
+	Code           string               `json:"code"`
+	Type           domain.PromotionType `json:"type"`
+	Description    string               `json:"description"`
+	ItemsAmount    float64              `json:"items_amount"`
+	ShippingAmount float64              `json:"shipping_amount"`
+	Amount         float64              `json:"amount"`
+}
+
+// OrderTaxResponse represents the total of one tax over an order
+type OrderTaxResponse struct {
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	Amount        float64 `json:"amount"`
 }
 
 // UpdateOrderStatusRequest represents the payload for updating order status
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+)
+
+// PromotionRequest represents the payload for creating or updating a promotion.
+// Amounts are in the base currency.
+type PromotionRequest struct {
+	Code         string               `json:"code" binding:"required,min=3,max=50"`
+	Name         string               `json:"name" binding:"required,max=200"`
+	Type         domain.PromotionType `json:"type" binding:"required"`
+	Value        float64              `json:"value" binding:"min=0"`
+	MaxDiscount  float64              `json:"max_discount" binding:"min=0"`
+	BuyQuantity  int                  `json:"buy_quantity" binding:"min=0"`
+	GetQuantity  int                  `json:"get_quantity" binding:"min=0"`
+	MinSpend     float64              `json:"min_spend" binding:"min=0"`
+	UsageLimit   int                  `json:"usage_limit" binding:"min=0"`
+	PerUserLimit int                  `json:"per_user_limit" binding:"min=0"`
+	StartsAt     *time.Time           `json:"starts_at"`
+	EndsAt       *time.Time           `json:"ends_at"`
+	ProductIDs   []uint               `json:"product_ids"`
+	CategoryIDs  []uint               `json:"category_ids"`
+	IsActive     *bool                `json:"is_active"` // Defaults to true
+}
+
+// PromotionResponse represents a promotion in API responses
+type PromotionResponse struct {
+	ID           uint                 `json:"id"`
+	Code         string               `json:"code"`
+	Name         string               `json:"name"`
+	Type         domain.PromotionType `json:"type"`
+	Value        float64              `json:"value"`
+	MaxDiscount  float64              `json:"max_discount"`
+	BuyQuantity  int                  `json:"buy_quantity"`
+	GetQuantity  int                  `json:"get_quantity"`
+	MinSpend     float64              `json:"min_spend"`
+	UsageLimit   int                  `json:"usage_limit"`
+	PerUserLimit int                  `json:"per_user_limit"`
+	UsedCount    int                  `json:"used_count"`
+	StartsAt     string               `json:"starts_at,omitempty"`
+	EndsAt       string               `json:"ends_at,omitempty"`
+	ProductIDs   []uint               `json:"product_ids"`
+	CategoryIDs  []uint               `json:"category_ids"`
+	IsActive     bool                 `json:"is_active"`
+	CreatedAt    string               `json:"created_at"`
+}
+
+// PromotionListResponse represents paginated promotion list
+type PromotionListResponse struct {
+	Promotions []PromotionResponse `json:"promotions"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+// DiscountPreviewResponse is what a coupon would take off a basket, in the base currency.
+// Free shipping is reported without an amount as shipping isn't priced until checkout.
+type DiscountPreviewResponse struct {
+	Code         string               `json:"code"`
+	Name         string               `json:"name"`
+	Type         domain.PromotionType `json:"type"`
+	ItemsAmount  float64              `json:"items_amount"`
+	FreeShipping bool                 `json:"free_shipping"`
+}
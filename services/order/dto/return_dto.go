@@ -0,0 +1,64 @@
+package dto
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// CreateReturnRequest represents the payload for requesting a return
+type CreateReturnRequest struct {
+	Items  []ReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+	Reason string              `json:"reason" binding:"required"`
+}
+
+// ReturnItemRequest represents a quantity of one order item to return
+type ReturnItemRequest struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,min=1"`
+}
+
+// ReviewReturnRequest represents the staff note sent with an approve, reject or receive action
+type ReviewReturnRequest struct {
+	Note string `json:"note"`
+}
+
+// ReturnResponse represents a return request in API responses
+type ReturnResponse struct {
+	ID           uint                  `json:"id"`
+	OrderID      uint                  `json:"order_id"`
+	UserID       uint                  `json:"user_id"`
+	Status       domain.ReturnStatus   `json:"status"`
+	Reason       string                `json:"reason"`
+	StaffNote    string                `json:"staff_note,omitempty"`
+	RefundAmount float64               `json:"refund_amount"`
+	Restocked    bool                  `json:"restocked"`
+	Items        []ReturnItemResponse  `json:"items"`
+	History      []ReturnEventResponse `json:"history,omitempty"`
+	CreatedAt    string                `json:"created_at"`
+}
+
+// ReturnItemResponse represents a returned item in API responses
+type ReturnItemResponse struct {
+	OrderItemID uint    `json:"order_item_id"`
+	ProductID   uint    `json:"product_id"`
+	Name        string  `json:"name"`
+	UnitPrice   float64 `json:"unit_price"`
+	Quantity    int     `json:"quantity"`
+	Subtotal    float64 `json:"subtotal"`
+	Restocked   bool    `json:"restocked"`
+}
+
+// ReturnEventResponse represents one entry of a return's history
+type ReturnEventResponse struct {
+	FromStatus domain.ReturnStatus `json:"from_status"`
+	ToStatus   domain.ReturnStatus `json:"to_status"`
+	ActorID    uint                `json:"actor_id"`
+	Note       string              `json:"note,omitempty"`
+	CreatedAt  string              `json:"created_at"`
+}
+
+// ReturnListResponse represents paginated return list
+type ReturnListResponse struct {
+	Returns    []ReturnResponse `json:"returns"`
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// AddressRequest represents the payload for creating or updating an address book entry
+type AddressRequest struct {
+	Label         string `json:"label"`
+	RecipientName string `json:"recipient_name" binding:"required"`
+	Phone         string `json:"phone" binding:"required"`
+	Line1         string `json:"line1" binding:"required"`
+	Line2         string `json:"line2"`
+	City          string `json:"city" binding:"required"`
+	Province      string `json:"province"`
+	PostalCode    string `json:"postal_code" binding:"required"`
+	Country       string `json:"country"`
+	IsDefault     bool   `json:"is_default"`
+}
+
+// AddressResponse represents an address book entry in API responses
+type AddressResponse struct {
+	ID            uint   `json:"id"`
+	Label         string `json:"label"`
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2,omitempty"`
+	City          string `json:"city"`
+	Province      string `json:"province,omitempty"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+	IsDefault     bool   `json:"is_default"`
+}
+
+// ShippingQuoteRequest represents the items to price shipping for
+type ShippingQuoteRequest struct {
+	Items []OrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// ShippingQuoteResponse represents the cost of one shipping method
+type ShippingQuoteResponse struct {
+	Method      domain.ShippingMethod `json:"method"`
+	Description string                `json:"description"`
+	Cost        float64               `json:"cost"`
+}
+
+// ShipOrderRequest represents the payload for handing an order over to a carrier
+type ShipOrderRequest struct {
+	Carrier        string `json:"carrier" binding:"required"`
+	TrackingNumber string `json:"tracking_number" binding:"required"`
+}
+
+// ShipmentResponse represents a shipment in API responses
+type ShipmentResponse struct {
+	ID             uint                  `json:"id"`
+	Carrier        string                `json:"carrier"`
+	TrackingNumber string                `json:"tracking_number"`
+	Status         domain.ShipmentStatus `json:"status"`
+	ShippedAt      string                `json:"shipped_at"`
+	DeliveredAt    string                `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/herman-xphp/go-microservices-ecommerce/proto/order"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+)
+
+// OrderGRPCServer implements the gRPC OrderService interface
+type OrderGRPCServer struct {
+	pb.UnimplementedOrderServiceServer
+	orderService service.OrderService
+}
+
+// NewOrderGRPCServer creates a new gRPC order server
+func NewOrderGRPCServer(orderService service.OrderService) *OrderGRPCServer {
+	return &OrderGRPCServer{orderService: orderService}
+}
+
+// GetOrder returns the owner, status and total of an order
+func (s *OrderGRPCServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	order, err := s.orderService.GetOrder(ctx, uint(req.OrderId))
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			return &pb.GetOrderResponse{Found: false}, nil
+		}
+		return nil, err
+	}
+
+	return &pb.GetOrderResponse{
+		Found:       true,
+		Id:          uint64(order.ID),
+		UserId:      uint64(order.UserID),
+		Status:      string(order.Status),
+		TotalAmount: order.TotalAmount,
+		Currency:    order.Currency,
+	}, nil
+}
+
+// MarkOrderPaid moves an order to paid after its payment succeeded
+func (s *OrderGRPCServer) MarkOrderPaid(ctx context.Context, req *pb.MarkOrderPaidRequest) (*pb.MarkOrderPaidResponse, error) {
+	order, err := s.orderService.MarkOrderPaid(ctx, uint(req.OrderId), req.TransactionId, req.Amount)
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) || errors.Is(err, service.ErrOrderNotPayable) ||
+			errors.Is(err, service.ErrPaymentShort) {
+			return &pb.MarkOrderPaidResponse{
+				Success:      false,
+				ErrorMessage: err.Error(),
+			}, nil
+		}
+		return nil, err
+	}
+
+	return &pb.MarkOrderPaidResponse{
+		Success: true,
+		Status:  string(order.Status),
+	}, nil
+}
+
+// CancelOrder cancels an unpaid order and releases its stock
+func (s *OrderGRPCServer) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	order, err := s.orderService.CancelUnpaidOrder(ctx, uint(req.OrderId), req.Reason)
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) || errors.Is(err, service.ErrOrderNotCancelable) {
+			return &pb.CancelOrderResponse{
+				Success:      false,
+				ErrorMessage: err.Error(),
+			}, nil
+		}
+		return nil, err
+	}
+
+	return &pb.CancelOrderResponse{
+		Success: true,
+		Status:  string(order.Status),
+	}, nil
+}
+
+// PreviewDiscount works out what a coupon takes off a basket. A coupon that can't be applied,
+// or a basket with products that can't be ordered, is reported as invalid with the reason.
+func (s *OrderGRPCServer) PreviewDiscount(ctx context.Context, req *pb.PreviewDiscountRequest) (*pb.PreviewDiscountResponse, error) {
+	items := make([]dto.OrderItemRequest, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = dto.OrderItemRequest{ProductID: uint(item.ProductId), Quantity: int(item.Quantity)}
+	}
+
+	preview, err := s.orderService.PreviewDiscount(ctx, uint(req.UserId), req.CouponCode, items)
+	if err != nil {
+		if errors.Is(err, service.ErrCouponInvalid) || errors.Is(err, service.ErrEmptyOrder) ||
+			errors.Is(err, service.ErrProductNotFound) || errors.Is(err, service.ErrProductUnavailable) ||
+			errors.Is(err, service.ErrInsufficientStock) {
+			return &pb.PreviewDiscountResponse{
+				Valid:        false,
+				ErrorMessage: err.Error(),
+			}, nil
+		}
+		return nil, err
+	}
+
+	return &pb.PreviewDiscountResponse{
+		Valid:        true,
+		Code:         preview.Code,
+		Name:         preview.Name,
+		Type:         string(preview.Type),
+		ItemsAmount:  preview.ItemsAmount,
+		FreeShipping: preview.FreeShipping,
+	}, nil
+}
+
+// GetLastOrderTime returns when a user last placed an order
+func (s *OrderGRPCServer) GetLastOrderTime(ctx context.Context, req *pb.GetLastOrderTimeRequest) (*pb.GetLastOrderTimeResponse, error) {
+	at, err := s.orderService.LastOrderAt(ctx, uint(req.UserId))
+	if err != nil {
+		return nil, err
+	}
+	if at == nil {
+		return &pb.GetLastOrderTimeResponse{Found: false}, nil
+	}
+
+	return &pb.GetLastOrderTimeResponse{
+		Found:     true,
+		CreatedAt: at.Unix(),
+	}, nil
+}
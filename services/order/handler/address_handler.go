@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/utils"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+)
+
+// AddressHandler handles HTTP requests for the user's address book
+type AddressHandler struct {
+	addressService service.AddressService
+}
+
+// NewAddressHandler creates a new instance of AddressHandler
+func NewAddressHandler(addressService service.AddressService) *AddressHandler {
+	return &AddressHandler{addressService: addressService}
+}
+
+// RegisterRoutes registers address book routes to the gin router
+func (h *AddressHandler) RegisterRoutes(router *gin.RouterGroup) {
+	addresses := router.Group("/addresses")
+	{
+		addresses.GET("", h.ListAddresses)
+		addresses.POST("", h.CreateAddress)
+		addresses.PUT("/:id", h.UpdateAddress)
+		addresses.DELETE("/:id", h.DeleteAddress)
+		addresses.POST("/:id/default", h.SetDefaultAddress)
+	}
+}
+
+// ListAddresses returns the authenticated user's addresses
+// GET /api/v1/addresses
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	addresses, err := h.addressService.ListAddresses(c.Request.Context(), userID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to get addresses", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Addresses retrieved successfully", addresses)
+}
+
+// CreateAddress adds an address to the user's address book
+// POST /api/v1/addresses
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	var req dto.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	address, err := h.addressService.CreateAddress(c.Request.Context(), userID, &req)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to create address", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusCreated, "Address created successfully", address)
+}
+
+// UpdateAddress updates an address book entry
+// PUT /api/v1/addresses/:id
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid address ID", nil)
+		return
+	}
+
+	var req dto.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(c.Request.Context(), userID, uint(id), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrAddressNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, "Address not found", nil)
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to update address", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Address updated successfully", address)
+}
+
+// DeleteAddress removes an address book entry
+// DELETE /api/v1/addresses/:id
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid address ID", nil)
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(c.Request.Context(), userID, uint(id)); err != nil {
+		if errors.Is(err, service.ErrAddressNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, "Address not found", nil)
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to delete address", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Address deleted successfully", nil)
+}
+
+// SetDefaultAddress marks an address as the user's default
+// POST /api/v1/addresses/:id/default
+func (h *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid address ID", nil)
+		return
+	}
+
+	if err := h.addressService.SetDefaultAddress(c.Request.Context(), userID, uint(id)); err != nil {
+		if errors.Is(err, service.ErrAddressNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, "Address not found", nil)
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to set default address", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Default address updated successfully", nil)
+}
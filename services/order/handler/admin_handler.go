@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/utils"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+)
+
+// AdminHandler handles back-office order management requests
+type AdminHandler struct {
+	adminService service.AdminOrderService
+}
+
+// NewAdminHandler creates a new instance of AdminHandler
+func NewAdminHandler(adminService service.AdminOrderService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// RegisterRoutes registers admin order routes to the gin router
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin/orders", auth.RequireStaff())
+	{
+		admin.GET("", h.ListOrders)
+		admin.GET("/export", h.ExportOrders)
+		admin.POST("/bulk-status", h.BulkUpdateStatus)
+	}
+}
+
+// ListOrders searches all orders with filters, sorting and cursor pagination
+// GET /api/v1/admin/orders?status=paid,shipped&from=2024-01-01&sort=-total_amount&limit=50&cursor=...
+func (h *AdminHandler) ListOrders(c *gin.Context) {
+	var query dto.AdminOrderQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	orders, err := h.adminService.ListOrders(c.Request.Context(), &query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOrderFilter) {
+			utils.ResponseError(c, http.StatusBadRequest, "Invalid order filter", err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to get orders", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Orders retrieved successfully", orders)
+}
+
+// ExportOrders streams all orders matching the filters as CSV
+// GET /api/v1/admin/orders/export?status=paid&from=2024-01-01
+func (h *AdminHandler) ExportOrders(c *gin.Context) {
+	var query dto.AdminOrderQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("orders-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	err := h.adminService.ExportOrders(c.Request.Context(), &query, c.Writer)
+	if err == nil {
+		return
+	}
+
+	// Once rows have been streamed the status line is gone; all we can do is cut the response short
+	if c.Writer.Written() {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	if errors.Is(err, service.ErrInvalidOrderFilter) {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid order filter", err.Error())
+		return
+	}
+	utils.ResponseError(c, http.StatusInternalServerError, "Failed to export orders", err.Error())
+}
+
+// BulkUpdateStatus sets the status of many orders at once
+// POST /api/v1/admin/orders/bulk-status
+func (h *AdminHandler) BulkUpdateStatus(c *gin.Context) {
+	var req dto.BulkStatusUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.adminService.BulkUpdateStatus(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOrderStatus) {
+			utils.ResponseError(c, http.StatusBadRequest, "Invalid order status", err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to update orders", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Order statuses updated successfully", result)
+}
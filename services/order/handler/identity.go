@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/utils"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+)
+
+// currentUserID returns the user ID of the gateway-verified caller
+func currentUserID(c *gin.Context) (uint, bool) {
+	identity, ok := auth.Current(c)
+	if !ok || identity.UserID == 0 {
+		return 0, false
+	}
+	return identity.UserID, true
+}
+
+// isStaff reports whether the caller has a back-office role
+func isStaff(c *gin.Context) bool {
+	identity, _ := auth.Current(c)
+	return identity.IsStaff()
+}
+
+// canAccess reports whether the caller owns a resource, or is staff
+func canAccess(c *gin.Context, ownerID uint) bool {
+	identity, _ := auth.Current(c)
+	return identity.CanAccess(ownerID)
+}
+
+// authorizeOrder loads an order the caller owns (or any order, for staff).
+// Orders belonging to other users are reported as not found so their existence is not leaked.
+func authorizeOrder(c *gin.Context, orderService service.OrderService, id uint) (*dto.OrderResponse, bool) {
+	if _, ok := auth.Current(c); !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return nil, false
+	}
+
+	order, err := orderService.GetOrder(c.Request.Context(), id)
+	if err == nil && !canAccess(c, order.UserID) {
+		err = service.ErrOrderNotFound
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
+			return nil, false
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to get order", err.Error())
+		return nil, false
+	}
+	return order, true
+}
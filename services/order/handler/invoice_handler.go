@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/utils"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/invoice"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+)
+
+// InvoiceHandler handles invoice downloads
+type InvoiceHandler struct {
+	orderService   service.OrderService
+	invoiceService service.InvoiceService
+}
+
+// NewInvoiceHandler creates a new instance of InvoiceHandler
+func NewInvoiceHandler(orderService service.OrderService, invoiceService service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		orderService:   orderService,
+		invoiceService: invoiceService,
+	}
+}
+
+// RegisterRoutes registers invoice routes to the gin router
+func (h *InvoiceHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/orders/:id/invoice", h.DownloadInvoice)
+}
+
+// DownloadInvoice renders the invoice of a paid order as PDF (default) or HTML
+// GET /api/v1/orders/:id/invoice?format=pdf|html
+func (h *InvoiceHandler) DownloadInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid order ID", nil)
+		return
+	}
+
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "html" {
+		utils.ResponseError(c, http.StatusBadRequest, "Unsupported invoice format", nil)
+		return
+	}
+
+	if _, ok := authorizeOrder(c, h.orderService, uint(id)); !ok {
+		return
+	}
+
+	inv, err := h.invoiceService.GetInvoice(c.Request.Context(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrderNotFound):
+			utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
+		case errors.Is(err, service.ErrOrderNotInvoiceable):
+			utils.ResponseError(c, http.StatusConflict, "Order is not invoiceable", err.Error())
+		case errors.Is(err, service.ErrInvoicePaymentLookup):
+			utils.ResponseError(c, http.StatusBadGateway, "Failed to issue invoice", err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "Failed to issue invoice", err.Error())
+		}
+		return
+	}
+
+	// Render into a buffer first so a rendering error can still be reported as JSON
+	var buf bytes.Buffer
+	contentType := "application/pdf"
+	disposition := "attachment"
+	if format == "html" {
+		err = invoice.RenderHTML(&buf, inv)
+		contentType = "text/html; charset=utf-8"
+		disposition = "inline"
+	} else {
+		err = invoice.RenderPDF(&buf, inv)
+	}
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to render invoice", err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("invoice-%s.%s", strings.ReplaceAll(inv.Number, "/", "-"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
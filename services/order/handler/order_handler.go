@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
 	"github.com/herman-xphp/go-microservices-ecommerce/pkg/utils"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
@@ -30,27 +31,22 @@ func (h *OrderHandler) RegisterRoutes(router *gin.RouterGroup) {
 		orders.GET("/:id", h.GetOrder)
 		orders.PUT("/:id/status", h.UpdateOrderStatus)
 		orders.POST("/:id/cancel", h.CancelOrder)
+		orders.POST("/:id/ship", h.ShipOrder)
+		orders.POST("/:id/deliver", h.DeliverOrder)
 	}
+
+	router.POST("/shipping/quote", h.QuoteShipping)
 }
 
 // CreateOrder creates a new order
 // POST /api/v1/orders
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
-	// Get user_id from context (set by auth middleware or from token)
-	userIDVal, exists := c.Get("user_id")
-	if !exists {
-		// For demo purposes, allow passing user_id as query param
-		userIDStr := c.Query("user_id")
-		if userIDStr == "" {
-			utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
-			return
-		}
-		userID, _ := strconv.ParseUint(userIDStr, 10, 32)
-		userIDVal = uint(userID)
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
 	}
 
-	userID := userIDVal.(uint)
-
 	var req dto.CreateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
@@ -66,6 +62,16 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 			utils.ResponseError(c, http.StatusBadRequest, "Insufficient stock", err.Error())
 		case errors.Is(err, service.ErrProductUnavailable):
 			utils.ResponseError(c, http.StatusBadRequest, "Product unavailable", err.Error())
+		case errors.Is(err, service.ErrAddressNotFound):
+			utils.ResponseError(c, http.StatusBadRequest, "Address not found", err.Error())
+		case errors.Is(err, service.ErrInvalidShipping):
+			utils.ResponseError(c, http.StatusBadRequest, "Invalid shipping method", err.Error())
+		case errors.Is(err, service.ErrUnsupportedCurrency):
+			utils.ResponseError(c, http.StatusBadRequest, "Unsupported currency", err.Error())
+		case errors.Is(err, service.ErrCouponInvalid):
+			utils.ResponseError(c, http.StatusUnprocessableEntity, "Coupon cannot be applied", err.Error())
+		case errors.Is(err, service.ErrExchangeRateUnavailable):
+			utils.ResponseError(c, http.StatusServiceUnavailable, "Exchange rate unavailable", err.Error())
 		default:
 			utils.ResponseError(c, http.StatusInternalServerError, "Failed to create order", err.Error())
 		}
@@ -84,34 +90,23 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.GetOrder(c.Request.Context(), uint(id))
-	if err != nil {
-		if errors.Is(err, service.ErrOrderNotFound) {
-			utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
-			return
-		}
-		utils.ResponseError(c, http.StatusInternalServerError, "Failed to get order", err.Error())
+	order, ok := authorizeOrder(c, h.orderService, uint(id))
+	if !ok {
 		return
 	}
 
 	utils.ResponseSuccess(c, http.StatusOK, "Order retrieved successfully", order)
 }
 
-// GetUserOrders returns orders for the authenticated user
+// GetUserOrders returns orders for the authenticated user (staff may pass user_id)
 // GET /api/v1/orders?page=1&page_size=10
 func (h *OrderHandler) GetUserOrders(c *gin.Context) {
-	userIDVal, exists := c.Get("user_id")
-	if !exists {
-		userIDStr := c.Query("user_id")
-		if userIDStr == "" {
-			utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
-			return
-		}
-		userID, _ := strconv.ParseUint(userIDStr, 10, 32)
-		userIDVal = uint(userID)
+	userID, ok := auth.TargetUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
 	}
 
-	userID := userIDVal.(uint)
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
@@ -124,9 +119,14 @@ func (h *OrderHandler) GetUserOrders(c *gin.Context) {
 	utils.ResponseSuccess(c, http.StatusOK, "Orders retrieved successfully", orders)
 }
 
-// UpdateOrderStatus updates the status of an order
+// UpdateOrderStatus confirms, cancels or delivers an order on behalf of staff
 // PUT /api/v1/orders/:id/status
 func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	if !isStaff(c) {
+		utils.ResponseError(c, http.StatusForbidden, "Staff role required", nil)
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		utils.ResponseError(c, http.StatusBadRequest, "Invalid order ID", nil)
@@ -141,11 +141,16 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 
 	err = h.orderService.UpdateOrderStatus(c.Request.Context(), uint(id), req.Status)
 	if err != nil {
-		if errors.Is(err, service.ErrOrderNotFound) {
+		switch {
+		case errors.Is(err, service.ErrOrderNotFound):
 			utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
-			return
+		case errors.Is(err, service.ErrInvalidOrderStatus):
+			utils.ResponseError(c, http.StatusBadRequest, "Invalid order status", err.Error())
+		case errors.Is(err, service.ErrStatusTransition):
+			utils.ResponseError(c, http.StatusConflict, "Order status cannot be changed", err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "Failed to update order status", err.Error())
 		}
-		utils.ResponseError(c, http.StatusInternalServerError, "Failed to update order status", err.Error())
 		return
 	}
 
@@ -161,15 +166,125 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizeOrder(c, h.orderService, uint(id)); !ok {
+		return
+	}
+
 	err = h.orderService.CancelOrder(c.Request.Context(), uint(id))
 	if err != nil {
 		if errors.Is(err, service.ErrOrderNotFound) {
 			utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
 			return
 		}
+		if errors.Is(err, service.ErrStockReleaseFailed) {
+			utils.ResponseError(c, http.StatusBadGateway, "Order cancelled, but its stock could not be released", err.Error())
+			return
+		}
 		utils.ResponseError(c, http.StatusBadRequest, "Failed to cancel order", err.Error())
 		return
 	}
 
 	utils.ResponseSuccess(c, http.StatusOK, "Order cancelled successfully", gin.H{"status": domain.OrderStatusCancelled})
 }
+
+// QuoteShipping returns the shipping cost of each method for a set of items
+// POST /api/v1/shipping/quote
+func (h *OrderHandler) QuoteShipping(c *gin.Context) {
+	var req dto.ShippingQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	quotes, err := h.orderService.QuoteShipping(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			utils.ResponseError(c, http.StatusBadRequest, "Product not found", err.Error())
+		case errors.Is(err, service.ErrInsufficientStock):
+			utils.ResponseError(c, http.StatusBadRequest, "Insufficient stock", err.Error())
+		case errors.Is(err, service.ErrProductUnavailable):
+			utils.ResponseError(c, http.StatusBadRequest, "Product unavailable", err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "Failed to quote shipping", err.Error())
+		}
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Shipping quote retrieved successfully", quotes)
+}
+
+// ShipOrder records a shipment and marks the order as shipped (staff only)
+// POST /api/v1/orders/:id/ship
+func (h *OrderHandler) ShipOrder(c *gin.Context) {
+	staffID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+	if !isStaff(c) {
+		utils.ResponseError(c, http.StatusForbidden, "Staff role required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid order ID", nil)
+		return
+	}
+
+	var req dto.ShipOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	order, err := h.orderService.ShipOrder(c.Request.Context(), uint(id), staffID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrderNotFound):
+			utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
+		case errors.Is(err, service.ErrOrderNotShippable):
+			utils.ResponseError(c, http.StatusConflict, "Order cannot be shipped", err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "Failed to ship order", err.Error())
+		}
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Order shipped successfully", order)
+}
+
+// DeliverOrder marks a shipped order as delivered (staff only)
+// POST /api/v1/orders/:id/deliver
+func (h *OrderHandler) DeliverOrder(c *gin.Context) {
+	if _, ok := currentUserID(c); !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+	if !isStaff(c) {
+		utils.ResponseError(c, http.StatusForbidden, "Staff role required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid order ID", nil)
+		return
+	}
+
+	order, err := h.orderService.DeliverOrder(c.Request.Context(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrderNotFound):
+			utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
+		case errors.Is(err, service.ErrOrderNotShipped):
+			utils.ResponseError(c, http.StatusConflict, "Order cannot be delivered", err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "Failed to deliver order", err.Error())
+		}
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Order delivered successfully", order)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeOrderRepository holds orders in memory and fails the test on any blind status write
+type fakeOrderRepository struct {
+	repository.OrderRepository
+	t      *testing.T
+	orders map[uint]*domain.Order
+}
+
+func (f *fakeOrderRepository) FindByID(id uint) (*domain.Order, error) {
+	order, ok := f.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (f *fakeOrderRepository) UpdateStatus(id uint, status domain.OrderStatus) error {
+	f.t.Errorf("unexpected status write: order %d to %q", id, status)
+	return nil
+}
+
+func (f *fakeOrderRepository) BulkUpdateStatus(ids []uint, from, to domain.OrderStatus) ([]uint, error) {
+	var updated []uint
+	for _, id := range ids {
+		if order, ok := f.orders[id]; ok && order.Status == from {
+			order.Status = to
+			updated = append(updated, id)
+		}
+	}
+	return updated, nil
+}
+
+func TestOrderHandler_UpdateOrderStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     domain.OrderStatus
+		body     string
+		wantCode int
+		want     domain.OrderStatus
+	}{
+		{"confirm", domain.OrderStatusPending, `{"status":"confirmed"}`, http.StatusOK, domain.OrderStatusConfirmed},
+		{"paid skips the payment", domain.OrderStatusPending, `{"status":"paid"}`, http.StatusBadRequest, domain.OrderStatusPending},
+		{"shipped skips the shipment", domain.OrderStatusPending, `{"status":"shipped"}`, http.StatusBadRequest, domain.OrderStatusPending},
+		{"unknown status", domain.OrderStatusPending, `{"status":"bogus"}`, http.StatusBadRequest, domain.OrderStatusPending},
+		{"cancel after shipping", domain.OrderStatusShipped, `{"status":"cancelled"}`, http.StatusConflict, domain.OrderStatusShipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &fakeOrderRepository{t: t, orders: map[uint]*domain.Order{7: {ID: 7, Status: tt.from}}}
+			orderService := service.NewOrderService(repo, nil, nil, nil, nil, nil, nil, nil)
+			signer := auth.NewSigner("test-secret")
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(auth.Middleware(signer))
+			NewOrderHandler(orderService).RegisterRoutes(router.Group("/api/v1"))
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/7/status", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			require.NoError(t, signer.Sign(req, auth.Identity{UserID: 1, Role: auth.RoleStaff}))
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.want, repo.orders[7].Status)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/utils"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+)
+
+// PromotionHandler handles back-office promotion management requests
+type PromotionHandler struct {
+	promotionService service.PromotionService
+}
+
+// NewPromotionHandler creates a new instance of PromotionHandler
+func NewPromotionHandler(promotionService service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+// RegisterRoutes registers promotion routes to the gin router
+func (h *PromotionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	promotions := router.Group("/admin/promotions", auth.RequireStaff())
+	{
+		promotions.POST("", h.CreatePromotion)
+		promotions.GET("", h.GetPromotions)
+		promotions.GET("/:id", h.GetPromotion)
+		promotions.PUT("/:id", h.UpdatePromotion)
+	}
+}
+
+// CreatePromotion creates a coupon
+// POST /api/v1/admin/promotions
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(&req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create promotion")
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusCreated, "Promotion created successfully", promotion)
+}
+
+// GetPromotions lists promotions, newest first
+// GET /api/v1/admin/promotions?page=1&page_size=20
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	promotions, err := h.promotionService.GetPromotions(page, pageSize)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to get promotions", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Promotions retrieved successfully", promotions)
+}
+
+// GetPromotion returns a promotion with its usage count
+// GET /api/v1/admin/promotions/:id
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid promotion ID", nil)
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(uint(id))
+	if err != nil {
+		h.respondError(c, err, "Failed to get promotion")
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Promotion retrieved successfully", promotion)
+}
+
+// UpdatePromotion replaces a promotion's settings; set is_active to false to withdraw it
+// PUT /api/v1/admin/promotions/:id
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid promotion ID", nil)
+		return
+	}
+
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(uint(id), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update promotion")
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Promotion updated successfully", promotion)
+}
+
+func (h *PromotionHandler) respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrPromotionNotFound):
+		utils.ResponseError(c, http.StatusNotFound, "Promotion not found", err.Error())
+	case errors.Is(err, service.ErrInvalidPromotion):
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid promotion", err.Error())
+	case errors.Is(err, service.ErrCouponCodeTaken):
+		utils.ResponseError(c, http.StatusConflict, "Coupon code already in use", err.Error())
+	default:
+		utils.ResponseError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/utils"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/service"
+)
+
+// ReturnHandler handles HTTP requests for the returns (RMA) workflow
+type ReturnHandler struct {
+	returnService service.ReturnService
+}
+
+// NewReturnHandler creates a new instance of ReturnHandler
+func NewReturnHandler(returnService service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService}
+}
+
+// RegisterRoutes registers return routes to the gin router
+func (h *ReturnHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/orders/:id/returns", h.RequestReturn)
+
+	returns := router.Group("/returns")
+	{
+		returns.GET("", h.ListReturns)
+		returns.GET("/:id", h.GetReturn)
+		returns.POST("/:id/cancel", h.CancelReturn)
+		returns.POST("/:id/approve", h.ApproveReturn)
+		returns.POST("/:id/reject", h.RejectReturn)
+		returns.POST("/:id/receive", h.ReceiveReturn)
+		returns.POST("/:id/refund", h.RetryRefund)
+	}
+}
+
+// RequestReturn opens a return request for items of a delivered order
+// POST /api/v1/orders/:id/returns
+func (h *ReturnHandler) RequestReturn(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid order ID", nil)
+		return
+	}
+
+	var req dto.CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	ret, err := h.returnService.RequestReturn(c.Request.Context(), userID, uint(orderID), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to request return")
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusCreated, "Return requested successfully", ret)
+}
+
+// ListReturns lists the caller's returns, or all returns for staff
+// GET /api/v1/returns?status=requested&page=1&page_size=10
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	var returns *dto.ReturnListResponse
+	var err error
+	if isStaff(c) {
+		returns, err = h.returnService.ListReturns(c.Request.Context(), domain.ReturnStatus(c.Query("status")), page, pageSize)
+	} else {
+		returns, err = h.returnService.GetUserReturns(c.Request.Context(), userID, page, pageSize)
+	}
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "Failed to get returns", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Returns retrieved successfully", returns)
+}
+
+// GetReturn returns a return request with its history
+// GET /api/v1/returns/:id
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	if _, ok := currentUserID(c); !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid return ID", nil)
+		return
+	}
+
+	ret, err := h.returnService.GetReturn(c.Request.Context(), uint(id))
+	if err == nil && !canAccess(c, ret.UserID) {
+		err = service.ErrReturnNotFound
+	}
+	if err != nil {
+		h.handleError(c, err, "Failed to get return")
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Return retrieved successfully", ret)
+}
+
+// CancelReturn withdraws a return request that has not been reviewed yet
+// POST /api/v1/returns/:id/cancel
+func (h *ReturnHandler) CancelReturn(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid return ID", nil)
+		return
+	}
+
+	ret, err := h.returnService.CancelReturn(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		h.handleError(c, err, "Failed to cancel return")
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, "Return cancelled successfully", ret)
+}
+
+// ApproveReturn approves a return request (staff only)
+// POST /api/v1/returns/:id/approve
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	h.staffAction(c, "Return approved successfully", h.returnService.ApproveReturn)
+}
+
+// RejectReturn rejects a return request (staff only)
+// POST /api/v1/returns/:id/reject
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	h.staffAction(c, "Return rejected successfully", h.returnService.RejectReturn)
+}
+
+// ReceiveReturn records the returned parcel, restocks the items and refunds them (staff only)
+// POST /api/v1/returns/:id/receive
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	h.staffAction(c, "Return received and refunded successfully", h.returnService.ReceiveReturn)
+}
+
+// RetryRefund retries restock and refund for a received return (staff only)
+// POST /api/v1/returns/:id/refund
+func (h *ReturnHandler) RetryRefund(c *gin.Context) {
+	h.staffAction(c, "Return refunded successfully", func(ctx context.Context, staffID, id uint, _ string) (*dto.ReturnResponse, error) {
+		return h.returnService.RetryRefund(ctx, staffID, id)
+	})
+}
+
+type returnAction func(ctx context.Context, staffID, id uint, note string) (*dto.ReturnResponse, error)
+
+func (h *ReturnHandler) staffAction(c *gin.Context, successMessage string, action returnAction) {
+	staffID, ok := currentUserID(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "User ID required", nil)
+		return
+	}
+	if !isStaff(c) {
+		utils.ResponseError(c, http.StatusForbidden, "Staff role required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "Invalid return ID", nil)
+		return
+	}
+
+	// The note is optional, so an empty body is fine
+	var req dto.ReviewReturnRequest
+	_ = c.ShouldBindJSON(&req)
+
+	ret, err := action(c.Request.Context(), staffID, uint(id), req.Note)
+	if err != nil {
+		h.handleError(c, err, "Failed to update return")
+		return
+	}
+
+	utils.ResponseSuccess(c, http.StatusOK, successMessage, ret)
+}
+
+func (h *ReturnHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrReturnNotFound):
+		utils.ResponseError(c, http.StatusNotFound, "Return not found", nil)
+	case errors.Is(err, service.ErrOrderNotFound):
+		utils.ResponseError(c, http.StatusNotFound, "Order not found", nil)
+	case errors.Is(err, service.ErrOrderNotReturnable),
+		errors.Is(err, service.ErrInvalidReturnItem),
+		errors.Is(err, service.ErrReturnQuantity):
+		utils.ResponseError(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, service.ErrInvalidReturnStatus):
+		utils.ResponseError(c, http.StatusConflict, message, err.Error())
+	case errors.Is(err, service.ErrReturnRestockFailed),
+		errors.Is(err, service.ErrReturnRefundFailed):
+		utils.ResponseError(c, http.StatusBadGateway, message, err.Error())
+	default:
+		utils.ResponseError(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,293 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+)
+
+// A4 page geometry in points
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	margin     = 50.0
+)
+
+// Table column positions; numeric columns are right-aligned on these edges
+const (
+	colDescription = margin
+	colQtyRight    = 360.0
+	colUnitRight   = 455.0
+	colAmountRight = pageWidth - margin
+	descriptionMax = 250.0
+	totalsLabel    = 340.0
+)
+
+type pdfFont string
+
+// Standard Type 1 fonts, available in every PDF reader without embedding
+const (
+	fontRegular pdfFont = "F1" // Helvetica
+	fontBold    pdfFont = "F2" // Helvetica-Bold
+)
+
+// RenderPDF writes the invoice as a PDF document
+func RenderPDF(w io.Writer, inv *domain.Invoice) error {
+	doc := newPDFDocument()
+
+	doc.text(margin, doc.y, fontBold, 20, "INVOICE")
+	meta := [][2]string{
+		{"Invoice number", inv.Number},
+		{"Issue date", formatDate(inv.IssuedAt)},
+		{"Order", fmt.Sprintf("#%d", inv.OrderID)},
+	}
+	if inv.PaymentReference != "" {
+		meta = append(meta, [2]string{"Payment reference", inv.PaymentReference})
+	}
+	y := doc.y
+	for _, row := range meta {
+		doc.text(330, y, fontRegular, 9, row[0])
+		doc.textRight(colAmountRight, y, fontBold, 9, row[1])
+		y -= 13
+	}
+
+	// Seller and buyer blocks side by side
+	doc.y = y - 20
+	seller := []string{inv.Seller.Name, inv.Seller.Address}
+	if inv.Seller.TaxID != "" {
+		seller = append(seller, "Tax ID: "+inv.Seller.TaxID)
+	}
+	seller = append(seller, inv.Seller.Email, inv.Seller.Phone)
+	sellerEnd := doc.block(margin, doc.y, "SELLER", seller)
+	buyerEnd := doc.block(320, doc.y, "BILL TO", buyerLines(inv.Buyer))
+	doc.y = min(sellerEnd, buyerEnd) - 20
+
+	doc.tableHeader()
+	for _, line := range inv.Lines {
+		if doc.y < margin+40 {
+			doc.newPage()
+			doc.tableHeader()
+		}
+		doc.text(colDescription, doc.y, fontRegular, 10, truncate(line.Description, 10, descriptionMax))
+		doc.textRight(colQtyRight, doc.y, fontRegular, 10, strconv.Itoa(line.Quantity))
+		doc.textRight(colUnitRight, doc.y, fontRegular, 10, formatMoney(inv.Currency, line.UnitPrice))
+		doc.textRight(colAmountRight, doc.y, fontRegular, 10, formatMoney(inv.Currency, line.Amount))
+		doc.y -= 6
+		doc.line(margin, doc.y, colAmountRight, doc.y, 0.25)
+		doc.y -= 12
+	}
+
+	// Totals are kept together on one page
+	totalRows := 4 + len(inv.TaxLines)
+	if doc.y-float64(totalRows)*16 < margin+30 {
+		doc.newPage()
+	}
+	doc.y -= 8
+	doc.totalRow("Subtotal", formatMoney(inv.Currency, inv.Subtotal), fontRegular)
+	doc.totalRow("Shipping", formatMoney(inv.Currency, inv.ShippingCost), fontRegular)
+	if inv.Discount > 0 {
+		doc.totalRow("Discount", "-"+formatMoney(inv.Currency, inv.Discount), fontRegular)
+	}
+	for _, tax := range inv.TaxLines {
+		doc.totalRow(taxLabel(tax), formatMoney(inv.Currency, tax.Amount), fontRegular)
+	}
+	doc.line(totalsLabel, doc.y+11, colAmountRight, doc.y+11, 1)
+	doc.totalRow("Total", formatMoney(inv.Currency, inv.Total), fontBold)
+	if hasInclusiveTax(inv.TaxLines) {
+		doc.text(margin, doc.y-10, fontRegular, 8, "Prices include tax.")
+	}
+
+	// Page footers are added last, once the page count is known
+	for i, page := range doc.pages {
+		footer := fmt.Sprintf("%s - page %d of %d", inv.Number, i+1, len(doc.pages))
+		doc.page = page
+		doc.textRight(colAmountRight, margin-20, fontRegular, 8, footer)
+	}
+
+	return doc.writeTo(w, "Invoice "+inv.Number)
+}
+
+// pdfDocument lays out text and rules on A4 pages and serialises them as a PDF 1.4 file
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // Baseline of the next row on the current page
+}
+
+func newPDFDocument() *pdfDocument {
+	doc := &pdfDocument{}
+	doc.newPage()
+	return doc
+}
+
+func (d *pdfDocument) newPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = pageHeight - margin - 20
+}
+
+func (d *pdfDocument) text(x, y float64, font pdfFont, size float64, s string) {
+	fmt.Fprintf(d.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(y), encodeText(s))
+}
+
+func (d *pdfDocument) textRight(right, y float64, font pdfFont, size float64, s string) {
+	d.text(right-textWidth(s, size), y, font, size, s)
+}
+
+func (d *pdfDocument) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// block prints a titled block of lines, skipping empty ones, and returns the y below it
+func (d *pdfDocument) block(x, y float64, title string, lines []string) float64 {
+	d.text(x, y, fontBold, 8, title)
+	y -= 14
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		d.text(x, y, fontRegular, 10, truncate(line, 10, 220))
+		y -= 13
+	}
+	return y
+}
+
+func (d *pdfDocument) tableHeader() {
+	d.text(colDescription, d.y, fontBold, 10, "Description")
+	d.textRight(colQtyRight, d.y, fontBold, 10, "Qty")
+	d.textRight(colUnitRight, d.y, fontBold, 10, "Unit price")
+	d.textRight(colAmountRight, d.y, fontBold, 10, "Amount")
+	d.y -= 6
+	d.line(margin, d.y, colAmountRight, d.y, 1)
+	d.y -= 14
+}
+
+func (d *pdfDocument) totalRow(label, amount string, font pdfFont) {
+	d.text(totalsLabel, d.y, font, 10, label)
+	d.textRight(colAmountRight, d.y, font, 10, amount)
+	d.y -= 16
+}
+
+func (d *pdfDocument) writeTo(w io.Writer, title string) error {
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-5 are fixed; each page then takes two objects (page, content stream)
+	const firstPage = 6
+	kids := ""
+	for i := range d.pages {
+		kids += fmt.Sprintf("%d 0 R ", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (GoShop) >>", encodeText(title)))
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(pageWidth), num(pageHeight), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := out.WriteTo(w)
+	return err
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// winAnsi maps the non-Latin-1 characters of WinAnsiEncoding that commonly appear in text
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// encodeText converts s to a WinAnsi PDF string literal body.
+// Characters outside the encoding become '?'; bytes above ASCII are written as octal escapes.
+func encodeText(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		var c byte
+		switch {
+		case r < 0x80:
+			c = byte(r)
+		case r >= 0xA0 && r <= 0xFF:
+			c = byte(r)
+		default:
+			mapped, ok := winAnsi[r]
+			if !ok {
+				mapped = '?'
+			}
+			c = mapped
+		}
+
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20:
+			b.WriteByte(' ')
+		case c >= 0x80:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths are the Helvetica glyph widths (per 1000 units of font size) for printable ASCII
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space - /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 - ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ - O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P - _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` - o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p - ~
+}
+
+// textWidth approximates the printed width of s, used to right-align and truncate columns.
+// Bold text is measured with the regular metrics, which is close enough for alignment.
+func textWidth(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			units += helveticaWidths[r-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// truncate shortens s with an ellipsis so it fits within maxWidth
+func truncate(s string, size, maxWidth float64) string {
+	if textWidth(s, size) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
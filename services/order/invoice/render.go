@@ -0,0 +1,88 @@
+// Package invoice renders order invoices as HTML and PDF.
+//
+// Both renderers are deterministic: the same invoice always produces byte-identical output,
+// which keeps stored copies and golden-file tests stable.
+package invoice
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+)
+
+//go:embed templates/invoice.html
+var templateFS embed.FS
+
+var htmlTemplate = template.Must(template.New("invoice.html").Funcs(template.FuncMap{
+	"money":           formatMoney,
+	"date":            formatDate,
+	"taxLabel":        taxLabel,
+	"buyerLines":      buyerLines,
+	"hasInclusiveTax": hasInclusiveTax,
+}).ParseFS(templateFS, "templates/invoice.html"))
+
+// RenderHTML writes the invoice as a standalone HTML document
+func RenderHTML(w io.Writer, inv *domain.Invoice) error {
+	return htmlTemplate.Execute(w, inv)
+}
+
+// formatMoney formats an amount in the invoice currency, e.g. "Rp 1.250.000" or "S$ 1,250.50".
+// Invoices issued before orders had a currency are in IDR.
+func formatMoney(code string, amount float64) string {
+	return currency.Code(code).Format(amount)
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2 January 2006")
+}
+
+// formatRate formats a tax rate fraction as a percentage, e.g. 0.11 as "11%"
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', -1, 64) + "%"
+}
+
+func taxLabel(line domain.InvoiceTaxLine) string {
+	label := fmt.Sprintf("%s %s", line.Name, formatRate(line.Rate))
+	if line.Inclusive {
+		label += " (included)"
+	}
+	return label
+}
+
+// buyerLines returns the buyer block, one printed line per entry
+func buyerLines(buyer domain.ShippingAddress) []string {
+	lines := []string{buyer.RecipientName, buyer.Line1}
+	if buyer.Line2 != "" {
+		lines = append(lines, buyer.Line2)
+	}
+
+	city := buyer.City
+	if buyer.Province != "" {
+		city += ", " + buyer.Province
+	}
+	lines = append(lines, strings.TrimSpace(city+" "+buyer.PostalCode))
+
+	if buyer.Country != "" {
+		lines = append(lines, buyer.Country)
+	}
+	if buyer.Phone != "" {
+		lines = append(lines, buyer.Phone)
+	}
+	return lines
+}
+
+func hasInclusiveTax(lines []domain.InvoiceTaxLine) bool {
+	for _, line := range lines {
+		if line.Inclusive {
+			return true
+		}
+	}
+	return false
+}
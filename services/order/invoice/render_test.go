@@ -0,0 +1,116 @@
+package invoice
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run `go test ./services/order/invoice -update` to regenerate the golden files after an intended change
+var update = flag.Bool("update", false, "update golden files")
+
+func sampleInvoice() *domain.Invoice {
+	return &domain.Invoice{
+		Number:   "INV/2024/000042",
+		Year:     2024,
+		Sequence: 42,
+		OrderID:  1001,
+		UserID:   7,
+		IssuedAt: time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+		Seller: domain.InvoiceSeller{
+			Name:    "GoShop Indonesia",
+			Address: "Jl. Sudirman No. 1, Jakarta",
+			TaxID:   "01.234.567.8-901.000",
+			Email:   "billing@goshop.id",
+			Phone:   "+62 21 555 0100",
+		},
+		Buyer: domain.ShippingAddress{
+			RecipientName: "Budi Santoso",
+			Phone:         "+62 812 3456 7890",
+			Line1:         "Jl. Merdeka No. 10",
+			Line2:         "RT 01/RW 02",
+			City:          "Bandung",
+			Province:      "Jawa Barat",
+			PostalCode:    "40111",
+			Country:       "ID",
+		},
+		Subtotal:         1_250_000,
+		ShippingCost:     15_000,
+		Total:            1_265_000,
+		PaymentReference: "TXN-20240315-ABC123",
+		Lines: []domain.InvoiceLine{
+			{ProductID: 1, Description: "Mechanical Keyboard (Brown switches)", Quantity: 1, UnitPrice: 850_000, Amount: 850_000},
+			{ProductID: 2, Description: "USB-C Cable 2m", Quantity: 4, UnitPrice: 100_000, Amount: 400_000},
+		},
+		TaxLines: []domain.InvoiceTaxLine{
+			{Name: "PPN", Rate: 0.11, Inclusive: true, TaxableAmount: 1_139_640, Amount: 125_360},
+		},
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "output differs from %s; rerun with -update if intended", path)
+}
+
+func TestRenderHTML_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderHTML(&buf, sampleInvoice()))
+
+	assertGolden(t, "invoice.html.golden", buf.Bytes())
+}
+
+func TestRenderPDF_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderPDF(&buf, sampleInvoice()))
+
+	assertGolden(t, "invoice.pdf.golden", buf.Bytes())
+}
+
+func TestRenderPDF_PaginatesLongInvoices(t *testing.T) {
+	// Arrange
+	inv := sampleInvoice()
+	inv.Lines = nil
+	for i := 1; i <= 80; i++ {
+		inv.Lines = append(inv.Lines, domain.InvoiceLine{
+			ProductID: uint(i), Description: fmt.Sprintf("Item %d", i), Quantity: 1, UnitPrice: 10_000, Amount: 10_000,
+		})
+	}
+
+	// Act
+	var buf bytes.Buffer
+	require.NoError(t, RenderPDF(&buf, inv))
+
+	// Assert
+	assert.Contains(t, buf.String(), "/Count 3")
+	assert.Contains(t, buf.String(), "(INV/2024/000042 - page 3 of 3)")
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "Rp 0", formatMoney("IDR", 0))
+	assert.Equal(t, "Rp 999", formatMoney("IDR", 999))
+	assert.Equal(t, "Rp 1.250.000", formatMoney("IDR", 1_250_000))
+	assert.Equal(t, "Rp 125.360", formatMoney("IDR", 125_359.64))
+	assert.Equal(t, "Rp 1.250.000", formatMoney("", 1_250_000))
+	assert.Equal(t, "S$ 1,250.50", formatMoney("SGD", 1250.5))
+}
+
+func TestEncodeText(t *testing.T) {
+	assert.Equal(t, `Caf\351 \(50%\) \\ ?`, encodeText("Café (50%) \\ 日"))
+}
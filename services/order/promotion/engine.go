@@ -0,0 +1,161 @@
+// Package promotion works out what a coupon takes off an order.
+//
+// Evaluate checks a promotion's conditions (validity window, usage limits, minimum spend and
+// product or category targeting) against a basket priced in the base currency, and returns the
+// discount split over the eligible lines so it can be stored on the order items for audit.
+package promotion
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+)
+
+var (
+	ErrCouponNotFound    = errors.New("coupon not found")
+	ErrCouponNotActive   = errors.New("coupon is not active")
+	ErrUsageLimitReached = errors.New("coupon usage limit reached")
+	ErrMinSpendNotMet    = errors.New("order total is below the coupon's minimum spend")
+	ErrNotApplicable     = errors.New("coupon does not apply to any item in the order")
+)
+
+// Line is a basket line priced in the base currency
+type Line struct {
+	ProductID  uint
+	CategoryID uint
+	UnitPrice  float64
+	Quantity   int
+}
+
+// Subtotal returns the price of the line before discounts
+func (l Line) Subtotal() float64 {
+	return l.UnitPrice * float64(l.Quantity)
+}
+
+// Basket is what a coupon is applied to. Amounts are in the base currency.
+type Basket struct {
+	Lines        []Line
+	ShippingCost float64 // Zero when not known yet, e.g. in the cart
+	At           time.Time
+}
+
+// Discount is what a promotion takes off a basket, in the base currency
+type Discount struct {
+	Promotion      *domain.Promotion
+	ItemsAmount    float64
+	ShippingAmount float64
+	// LineAmounts is the share of ItemsAmount of each basket line, in basket order
+	LineAmounts []float64
+}
+
+// Amount returns the total discount
+func (d *Discount) Amount() float64 {
+	return d.ItemsAmount + d.ShippingAmount
+}
+
+// Evaluate applies a promotion to a basket. userRedemptions is how many times the customer
+// has already used the promotion; pass 0 when the customer is not known, e.g. for guests.
+func Evaluate(p *domain.Promotion, basket *Basket, userRedemptions int64) (*Discount, error) {
+	if !p.IsRunningAt(basket.At) {
+		return nil, ErrCouponNotActive
+	}
+	if p.UsageLimit > 0 && p.UsedCount >= p.UsageLimit {
+		return nil, ErrUsageLimitReached
+	}
+	if p.PerUserLimit > 0 && userRedemptions >= int64(p.PerUserLimit) {
+		return nil, ErrUsageLimitReached
+	}
+
+	var subtotal, eligibleSubtotal float64
+	eligible := make([]bool, len(basket.Lines))
+	for i, line := range basket.Lines {
+		subtotal += line.Subtotal()
+		if p.Targets(line.ProductID, line.CategoryID) {
+			eligible[i] = true
+			eligibleSubtotal += line.Subtotal()
+		}
+	}
+	if subtotal < p.MinSpend {
+		return nil, ErrMinSpendNotMet
+	}
+	if eligibleSubtotal <= 0 {
+		return nil, ErrNotApplicable
+	}
+
+	discount := &Discount{Promotion: p, LineAmounts: make([]float64, len(basket.Lines))}
+	switch p.Type {
+	case domain.PromotionTypePercentage:
+		amount := eligibleSubtotal * p.Value / 100
+		if p.MaxDiscount > 0 {
+			amount = min(amount, p.MaxDiscount)
+		}
+		discount.allocate(basket.Lines, eligible, eligibleSubtotal, currency.Base.Round(amount))
+	case domain.PromotionTypeFixed:
+		discount.allocate(basket.Lines, eligible, eligibleSubtotal, currency.Base.Round(min(p.Value, eligibleSubtotal)))
+	case domain.PromotionTypeBuyXGetY:
+		discount.freeUnits(basket.Lines, eligible, p.BuyQuantity, p.GetQuantity)
+		if discount.ItemsAmount == 0 {
+			return nil, ErrNotApplicable
+		}
+	case domain.PromotionTypeFreeShipping:
+		amount := basket.ShippingCost
+		if p.MaxDiscount > 0 {
+			amount = min(amount, p.MaxDiscount)
+		}
+		discount.ShippingAmount = amount
+	default:
+		return nil, ErrNotApplicable
+	}
+	return discount, nil
+}
+
+// allocate splits amount over the eligible lines in proportion to their subtotals.
+// The last eligible line takes the rounding difference so the shares add up to amount.
+func (d *Discount) allocate(lines []Line, eligible []bool, eligibleSubtotal, amount float64) {
+	last := -1
+	allocated := 0.0
+	for i, line := range lines {
+		if !eligible[i] {
+			continue
+		}
+		d.LineAmounts[i] = currency.Base.Round(amount * line.Subtotal() / eligibleSubtotal)
+		allocated += d.LineAmounts[i]
+		last = i
+	}
+	d.LineAmounts[last] += amount - allocated
+	d.ItemsAmount = amount
+}
+
+// freeUnits makes the cheapest get units of every buy+get eligible units free
+func (d *Discount) freeUnits(lines []Line, eligible []bool, buy, get int) {
+	group := buy + get
+	if buy <= 0 || get <= 0 {
+		return
+	}
+
+	type unit struct {
+		line  int
+		price float64
+	}
+	var units []unit
+	for i, line := range lines {
+		if !eligible[i] {
+			continue
+		}
+		for n := 0; n < line.Quantity; n++ {
+			units = append(units, unit{line: i, price: line.UnitPrice})
+		}
+	}
+	// Most expensive first, so the last units of each group are its cheapest
+	sort.SliceStable(units, func(a, b int) bool { return units[a].price > units[b].price })
+
+	for start := 0; start+group <= len(units); start += group {
+		for _, free := range units[start+buy : start+group] {
+			d.LineAmounts[free.line] += free.price
+			d.ItemsAmount += free.price
+		}
+	}
+}
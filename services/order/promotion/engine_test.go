@@ -0,0 +1,138 @@
+package promotion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+// testBasket holds two coffees, three teas and a bag of sugar from another category
+func testBasket() *Basket {
+	return &Basket{
+		Lines: []Line{
+			{ProductID: 1, CategoryID: 10, UnitPrice: 50_000, Quantity: 2},
+			{ProductID: 2, CategoryID: 10, UnitPrice: 20_000, Quantity: 3},
+			{ProductID: 3, CategoryID: 20, UnitPrice: 15_000, Quantity: 1},
+		},
+		ShippingCost: 18_000,
+		At:           testNow,
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name         string
+		promotion    domain.Promotion
+		wantItems    float64
+		wantShipping float64
+		wantLines    []float64
+	}{
+		{
+			name:      "percentage",
+			promotion: domain.Promotion{Type: domain.PromotionTypePercentage, Value: 10},
+			wantItems: 17_500,
+			wantLines: []float64{10_000, 6_000, 1_500},
+		},
+		{
+			name:      "percentage capped",
+			promotion: domain.Promotion{Type: domain.PromotionTypePercentage, Value: 50, MaxDiscount: 35_000},
+			wantItems: 35_000,
+			wantLines: []float64{20_000, 12_000, 3_000},
+		},
+		{
+			name:      "fixed on a category",
+			promotion: domain.Promotion{Type: domain.PromotionTypeFixed, Value: 25_000, CategoryIDs: []uint{10}},
+			wantItems: 25_000,
+			wantLines: []float64{15_625, 9_375, 0},
+		},
+		{
+			name:      "fixed larger than the eligible items",
+			promotion: domain.Promotion{Type: domain.PromotionTypeFixed, Value: 100_000, ProductIDs: []uint{3}},
+			wantItems: 15_000,
+			wantLines: []float64{0, 0, 15_000},
+		},
+		{
+			name:      "buy two get one across a category",
+			promotion: domain.Promotion{Type: domain.PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1, CategoryIDs: []uint{10}},
+			// 50k 50k [20k] 20k 20k: one full group, its cheapest unit free
+			wantItems: 20_000,
+			wantLines: []float64{0, 20_000, 0},
+		},
+		{
+			name:         "free shipping",
+			promotion:    domain.Promotion{Type: domain.PromotionTypeFreeShipping},
+			wantShipping: 18_000,
+			wantLines:    []float64{0, 0, 0},
+		},
+		{
+			name:         "free shipping capped",
+			promotion:    domain.Promotion{Type: domain.PromotionTypeFreeShipping, MaxDiscount: 10_000},
+			wantShipping: 10_000,
+			wantLines:    []float64{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.promotion.IsActive = true
+
+			// Act
+			discount, err := Evaluate(&tt.promotion, testBasket(), 0)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantItems, discount.ItemsAmount)
+			assert.Equal(t, tt.wantShipping, discount.ShippingAmount)
+			assert.Equal(t, tt.wantLines, discount.LineAmounts)
+		})
+	}
+}
+
+func TestEvaluate_Conditions(t *testing.T) {
+	yesterday := testNow.AddDate(0, 0, -1)
+	tomorrow := testNow.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name            string
+		promotion       domain.Promotion
+		userRedemptions int64
+		wantErr         error
+	}{
+		{name: "inactive", promotion: domain.Promotion{}, wantErr: ErrCouponNotActive},
+		{name: "not started", promotion: domain.Promotion{IsActive: true, StartsAt: &tomorrow}, wantErr: ErrCouponNotActive},
+		{name: "ended", promotion: domain.Promotion{IsActive: true, EndsAt: &yesterday}, wantErr: ErrCouponNotActive},
+		{name: "used up", promotion: domain.Promotion{IsActive: true, UsageLimit: 100, UsedCount: 100}, wantErr: ErrUsageLimitReached},
+		{name: "used up by the customer", promotion: domain.Promotion{IsActive: true, PerUserLimit: 1}, userRedemptions: 1, wantErr: ErrUsageLimitReached},
+		{name: "below minimum spend", promotion: domain.Promotion{IsActive: true, MinSpend: 200_000}, wantErr: ErrMinSpendNotMet},
+		{name: "no targeted items", promotion: domain.Promotion{IsActive: true, ProductIDs: []uint{99}}, wantErr: ErrNotApplicable},
+		{name: "not enough units for a free one", promotion: domain.Promotion{IsActive: true, BuyQuantity: 1, GetQuantity: 1, ProductIDs: []uint{3}}, wantErr: ErrNotApplicable},
+		{name: "within every condition", promotion: domain.Promotion{IsActive: true, StartsAt: &yesterday, EndsAt: &tomorrow, MinSpend: 175_000, UsageLimit: 100, UsedCount: 99, PerUserLimit: 2}, userRedemptions: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.promotion.Type = domain.PromotionTypePercentage
+			tt.promotion.Value = 10
+			if tt.promotion.BuyQuantity > 0 {
+				tt.promotion.Type = domain.PromotionTypeBuyXGetY
+			}
+
+			// Act
+			_, err := Evaluate(&tt.promotion, testBasket(), tt.userRedemptions)
+
+			// Assert
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
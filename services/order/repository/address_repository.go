@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// AddressRepository defines the interface for address book data operations
+type AddressRepository interface {
+	Create(address *domain.Address) error
+	FindByID(id uint) (*domain.Address, error)
+	FindByUserID(userID uint) ([]domain.Address, error)
+	Update(address *domain.Address) error
+	Delete(id uint) error
+	SetDefault(userID, addressID uint) error
+}
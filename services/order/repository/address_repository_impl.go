@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"gorm.io/gorm"
+)
+
+type addressRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAddressRepository creates a new instance of AddressRepository
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepositoryImpl{db: db}
+}
+
+func (r *addressRepositoryImpl) Create(address *domain.Address) error {
+	return r.db.Create(address).Error
+}
+
+func (r *addressRepositoryImpl) FindByID(id uint) (*domain.Address, error) {
+	var address domain.Address
+	err := r.db.First(&address, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepositoryImpl) FindByUserID(userID uint) ([]domain.Address, error) {
+	var addresses []domain.Address
+	err := r.db.Where("user_id = ?", userID).
+		Order("is_default DESC, created_at DESC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *addressRepositoryImpl) Update(address *domain.Address) error {
+	return r.db.Save(address).Error
+}
+
+func (r *addressRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&domain.Address{}, id).Error
+}
+
+// SetDefault marks one address as default and clears the flag on the user's other addresses
+func (r *addressRepositoryImpl) SetDefault(userID, addressID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Address{}).
+			Where("user_id = ? AND id <> ?", userID, addressID).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Address{}).
+			Where("user_id = ? AND id = ?", userID, addressID).
+			Update("is_default", true).Error
+	})
+}
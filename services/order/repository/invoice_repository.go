@@ -0,0 +1,10 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// InvoiceRepository defines the interface for invoice data operations
+type InvoiceRepository interface {
+	// Create assigns the next number of the invoice's year and stores the invoice with its lines
+	Create(invoice *domain.Invoice) error
+	FindByOrderID(orderID uint) (*domain.Invoice, error)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"gorm.io/gorm"
+)
+
+type invoiceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewInvoiceRepository creates a new instance of InvoiceRepository
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepositoryImpl{db: db}
+}
+
+func (r *invoiceRepositoryImpl) Create(invoice *domain.Invoice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The upsert takes a row lock on the year's counter until commit, so numbers are
+		// gap-free: a failed insert below rolls the increment back with it
+		var next int
+		err := tx.Raw(`INSERT INTO invoice_sequences (year, last_number) VALUES (?, 1)
+			ON CONFLICT (year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+			RETURNING last_number`, invoice.Year).Scan(&next).Error
+		if err != nil {
+			return err
+		}
+
+		invoice.Sequence = next
+		invoice.Number = domain.FormatInvoiceNumber(invoice.Year, next)
+		return tx.Create(invoice).Error
+	})
+}
+
+func (r *invoiceRepositoryImpl) FindByOrderID(orderID uint) (*domain.Invoice, error) {
+	var invoice domain.Invoice
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("TaxLines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("order_id = ?", orderID).First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
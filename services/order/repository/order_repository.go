@@ -1,6 +1,10 @@
 package repository
 
-import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+)
 
 // OrderRepository defines the interface for order data operations
 type OrderRepository interface {
@@ -9,4 +13,64 @@ type OrderRepository interface {
 	FindByUserID(userID uint, page, pageSize int) ([]domain.Order, int64, error)
 	Update(order *domain.Order) error
 	UpdateStatus(id uint, status domain.OrderStatus) error
+	// MarkPaid moves a pending or confirmed order to paid. It reports false if the order was no longer payable.
+	MarkPaid(id uint, paymentRef string, paidAt time.Time) (bool, error)
+	// Cancel moves a pending order to cancelled and marks its release as pending.
+	// It reports false if the order was no longer pending.
+	Cancel(id uint, reason string) (bool, error)
+	// FindPendingReleases returns up to limit cancelled orders whose coupon and stock are not all back yet
+	FindPendingReleases(limit int) ([]domain.Order, error)
+	// ClaimItemRelease marks an item's stock as released before it is put back.
+	// It reports false if the item was already claimed.
+	ClaimItemRelease(itemID uint) (bool, error)
+	// UnclaimItemRelease undoes a claim whose stock could not be put back
+	UnclaimItemRelease(itemID uint) error
+	// FinishRelease records that everything a cancelled order held has been given back
+	FinishRelease(id uint) error
+	// Ship moves a paid or confirmed order to shipped and records its shipment in one transaction.
+	// It reports false, without recording the shipment, if the order was no longer shippable.
+	Ship(shipment *domain.Shipment) (bool, error)
+	// FindPendingBefore returns up to limit pending orders created before the given time, oldest first
+	FindPendingBefore(before time.Time, limit int) ([]domain.Order, error)
+
+	// Back-office queries
+	Search(filter OrderFilter) ([]domain.Order, error)
+	SearchInBatches(filter OrderFilter, batchSize int, fn func(orders []domain.Order) error) error
+	// FindByIDs returns the orders with the given ids, skipping ids that do not exist
+	FindByIDs(ids []uint) ([]domain.Order, error)
+	// BulkUpdateStatus moves the orders in ids that are still in status from to status to.
+	// It returns the ids that were updated.
+	BulkUpdateStatus(ids []uint, from, to domain.OrderStatus) ([]uint, error)
+}
+
+// OrderSortField is a column orders can be sorted by
+type OrderSortField string
+
+const (
+	OrderSortCreatedAt   OrderSortField = "created_at"
+	OrderSortTotalAmount OrderSortField = "total_amount"
+)
+
+// OrderFilter describes a back-office order search.
+// Zero values mean "no filter" for every field.
+type OrderFilter struct {
+	Statuses   []domain.OrderStatus
+	UserID     uint
+	ProductID  uint
+	From       *time.Time
+	To         *time.Time
+	MinTotal   *float64
+	MaxTotal   *float64
+	SortBy     OrderSortField
+	Descending bool
+	// After is the keyset cursor: only rows sorting strictly after this position are returned
+	After *OrderCursor
+	Limit int
+}
+
+// OrderCursor is a keyset pagination position: the sort column value and id of the last row seen
+type OrderCursor struct {
+	CreatedAt   time.Time `json:"c,omitempty"`
+	TotalAmount float64   `json:"t,omitempty"`
+	ID          uint      `json:"i"`
 }
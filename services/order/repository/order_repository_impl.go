@@ -1,10 +1,18 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errNotShippable rolls back a shipment for an order that has left paid and confirmed
+var errNotShippable = errors.New("order is no longer shippable")
+
 type orderRepositoryImpl struct {
 	db *gorm.DB
 }
@@ -20,7 +28,7 @@ func (r *orderRepositoryImpl) Create(order *domain.Order) error {
 
 func (r *orderRepositoryImpl) FindByID(id uint) (*domain.Order, error) {
 	var order domain.Order
-	err := r.db.Preload("Items").First(&order, id).Error
+	err := r.db.Preload("Items").Preload("Shipments").Preload("Discounts").Preload("Taxes").First(&order, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +42,7 @@ func (r *orderRepositoryImpl) FindByUserID(userID uint, page, pageSize int) ([]d
 	r.db.Model(&domain.Order{}).Where("user_id = ?", userID).Count(&total)
 
 	offset := (page - 1) * pageSize
-	err := r.db.Preload("Items").
+	err := r.db.Preload("Items").Preload("Shipments").Preload("Discounts").Preload("Taxes").
 		Where("user_id = ?", userID).
 		Offset(offset).
 		Limit(pageSize).
@@ -51,3 +59,193 @@ func (r *orderRepositoryImpl) Update(order *domain.Order) error {
 func (r *orderRepositoryImpl) UpdateStatus(id uint, status domain.OrderStatus) error {
 	return r.db.Model(&domain.Order{}).Where("id = ?", id).Update("status", status).Error
 }
+
+func (r *orderRepositoryImpl) MarkPaid(id uint, paymentRef string, paidAt time.Time) (bool, error) {
+	result := r.db.Model(&domain.Order{}).
+		Where("id = ? AND status IN ?", id, []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusConfirmed}).
+		Updates(map[string]interface{}{
+			"status":      domain.OrderStatusPaid,
+			"payment_ref": paymentRef,
+			"paid_at":     paidAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *orderRepositoryImpl) Cancel(id uint, reason string) (bool, error) {
+	result := r.db.Model(&domain.Order{}).
+		Where("id = ? AND status = ?", id, domain.OrderStatusPending).
+		Updates(map[string]interface{}{
+			"status":          domain.OrderStatusCancelled,
+			"cancel_reason":   reason,
+			"release_pending": true,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *orderRepositoryImpl) Ship(shipment *domain.Shipment) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Order{}).
+			Where("id = ? AND status IN ?", shipment.OrderID, []domain.OrderStatus{domain.OrderStatusPaid, domain.OrderStatusConfirmed}).
+			Update("status", domain.OrderStatusShipped)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNotShippable
+		}
+		return tx.Create(shipment).Error
+	})
+	if errors.Is(err, errNotShippable) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *orderRepositoryImpl) FindPendingReleases(limit int) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := r.db.Preload("Items").
+		Where("status = ? AND release_pending", domain.OrderStatusCancelled).
+		Order("updated_at").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepositoryImpl) ClaimItemRelease(itemID uint) (bool, error) {
+	result := r.db.Model(&domain.OrderItem{}).
+		Where("id = ? AND NOT stock_released", itemID).
+		Update("stock_released", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *orderRepositoryImpl) UnclaimItemRelease(itemID uint) error {
+	return r.db.Model(&domain.OrderItem{}).Where("id = ?", itemID).Update("stock_released", false).Error
+}
+
+func (r *orderRepositoryImpl) FinishRelease(id uint) error {
+	return r.db.Model(&domain.Order{}).Where("id = ?", id).Update("release_pending", false).Error
+}
+
+func (r *orderRepositoryImpl) FindPendingBefore(before time.Time, limit int) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := r.db.Preload("Items").
+		Where("status = ? AND created_at < ?", domain.OrderStatusPending, before).
+		Order("created_at").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepositoryImpl) Search(filter OrderFilter) ([]domain.Order, error) {
+	var orders []domain.Order
+	query := r.db.Preload("Items").Preload("Shipments").Preload("Discounts").Preload("Taxes").Scopes(orderFilterScope(filter))
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepositoryImpl) SearchInBatches(filter OrderFilter, batchSize int, fn func(orders []domain.Order) error) error {
+	// Walk the result set with the keyset cursor so each batch is an index range scan,
+	// rather than an ever-growing OFFSET
+	for {
+		filter.Limit = batchSize
+		orders, err := r.Search(filter)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+		if err := fn(orders); err != nil {
+			return err
+		}
+		if len(orders) < batchSize {
+			return nil
+		}
+		last := orders[len(orders)-1]
+		filter.After = &OrderCursor{CreatedAt: last.CreatedAt, TotalAmount: last.TotalAmount, ID: last.ID}
+	}
+}
+
+func (r *orderRepositoryImpl) FindByIDs(ids []uint) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := r.db.Preload("Items").Where("id IN ?", ids).Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepositoryImpl) BulkUpdateStatus(ids []uint, from, to domain.OrderStatus) ([]uint, error) {
+	var updated []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the rows so nothing else moves them between the lookup and the update
+		if err := tx.Model(&domain.Order{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ?", ids, from).
+			Pluck("id", &updated).Error; err != nil {
+			return err
+		}
+		if len(updated) == 0 {
+			return nil
+		}
+		return tx.Model(&domain.Order{}).Where("id IN ?", updated).Update("status", to).Error
+	})
+	return updated, err
+}
+
+func orderFilterScope(filter OrderFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(filter.Statuses) > 0 {
+			db = db.Where("status IN ?", filter.Statuses)
+		}
+		if filter.UserID != 0 {
+			db = db.Where("user_id = ?", filter.UserID)
+		}
+		if filter.ProductID != 0 {
+			db = db.Where("id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+				Model(&domain.OrderItem{}).Select("order_id").Where("product_id = ?", filter.ProductID))
+		}
+		if filter.From != nil {
+			db = db.Where("created_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("created_at < ?", *filter.To)
+		}
+		if filter.MinTotal != nil {
+			db = db.Where("total_amount >= ?", *filter.MinTotal)
+		}
+		if filter.MaxTotal != nil {
+			db = db.Where("total_amount <= ?", *filter.MaxTotal)
+		}
+
+		column := string(OrderSortCreatedAt)
+		if filter.SortBy == OrderSortTotalAmount {
+			column = string(OrderSortTotalAmount)
+		}
+		direction, cmp := "ASC", ">"
+		if filter.Descending {
+			direction, cmp = "DESC", "<"
+		}
+
+		if filter.After != nil {
+			var value interface{} = filter.After.CreatedAt
+			if column == string(OrderSortTotalAmount) {
+				value = filter.After.TotalAmount
+			}
+			// The id tie-breaker keeps the order total when several rows share a sort value
+			db = db.Where(fmt.Sprintf("(%s %s ?) OR (%s = ? AND id %s ?)", column, cmp, column, cmp),
+				value, value, filter.After.ID)
+		}
+
+		return db.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction))
+	}
+}
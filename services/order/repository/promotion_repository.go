@@ -0,0 +1,20 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// PromotionRepository defines the interface for promotion data operations
+type PromotionRepository interface {
+	Create(promotion *domain.Promotion) error
+	FindByID(id uint) (*domain.Promotion, error)
+	FindByCode(code string) (*domain.Promotion, error)
+	FindAll(page, pageSize int) ([]domain.Promotion, int64, error)
+	Update(promotion *domain.Promotion) error
+	// CountRedemptions counts a customer's redemptions of a promotion that have not been released
+	CountRedemptions(promotionID, userID uint) (int64, error)
+	// CreateOrder stores an order together with its redemption of a promotion. It reports false,
+	// storing nothing, when the promotion's global or per-customer limit has been reached meanwhile.
+	CreateOrder(order *domain.Order, redemption *domain.PromotionRedemption) (bool, error)
+	// Release frees the redemption made by an order so it no longer counts towards the limits.
+	// Releasing an order without a redemption, or one already released, does nothing.
+	Release(orderID uint) error
+}
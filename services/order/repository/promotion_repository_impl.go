@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"gorm.io/gorm"
+)
+
+// errLimitReached rolls back a redemption over a promotion's limits
+var errLimitReached = errors.New("promotion limit reached")
+
+type promotionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPromotionRepository creates a new instance of PromotionRepository
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepositoryImpl{db: db}
+}
+
+func (r *promotionRepositoryImpl) Create(promotion *domain.Promotion) error {
+	return r.db.Create(promotion).Error
+}
+
+func (r *promotionRepositoryImpl) FindByID(id uint) (*domain.Promotion, error) {
+	var promotion domain.Promotion
+	if err := r.db.First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepositoryImpl) FindByCode(code string) (*domain.Promotion, error) {
+	var promotion domain.Promotion
+	if err := r.db.Where("code = ?", code).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepositoryImpl) FindAll(page, pageSize int) ([]domain.Promotion, int64, error) {
+	var promotions []domain.Promotion
+	var total int64
+
+	if err := r.db.Model(&domain.Promotion{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := r.db.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&promotions).Error
+	return promotions, total, err
+}
+
+func (r *promotionRepositoryImpl) Update(promotion *domain.Promotion) error {
+	// used_count is only changed by redemptions
+	return r.db.Omit("used_count").Save(promotion).Error
+}
+
+func (r *promotionRepositoryImpl) CountRedemptions(promotionID, userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.PromotionRedemption{}).
+		Where("promotion_id = ? AND user_id = ? AND released_at IS NULL", promotionID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *promotionRepositoryImpl) CreateOrder(order *domain.Order, redemption *domain.PromotionRedemption) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Taking a use locks the promotion row until commit, which also serialises the
+		// per-customer check below between concurrent orders
+		result := tx.Model(&domain.Promotion{}).
+			Where("id = ? AND (usage_limit = 0 OR used_count < usage_limit)", redemption.PromotionID).
+			UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errLimitReached
+		}
+
+		var promotion domain.Promotion
+		if err := tx.First(&promotion, redemption.PromotionID).Error; err != nil {
+			return err
+		}
+		if promotion.PerUserLimit > 0 {
+			var used int64
+			err := tx.Model(&domain.PromotionRedemption{}).
+				Where("promotion_id = ? AND user_id = ? AND released_at IS NULL", redemption.PromotionID, redemption.UserID).
+				Count(&used).Error
+			if err != nil {
+				return err
+			}
+			if used >= int64(promotion.PerUserLimit) {
+				return errLimitReached
+			}
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		redemption.OrderID = order.ID
+		return tx.Create(redemption).Error
+	})
+	if errors.Is(err, errLimitReached) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *promotionRepositoryImpl) Release(orderID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var redemption domain.PromotionRedemption
+		result := tx.Model(&redemption).
+			Where("order_id = ? AND released_at IS NULL", orderID).
+			Update("released_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Where("order_id = ?", orderID).First(&redemption).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Promotion{}).
+			Where("id = ? AND used_count > 0", redemption.PromotionID).
+			UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+	})
+}
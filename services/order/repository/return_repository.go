@@ -0,0 +1,22 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// ReturnRepository defines the interface for return request data operations
+type ReturnRepository interface {
+	// Create stores a new return unless, together with the order's other open returns, it would
+	// return more of an item than was ordered. It reports false, storing nothing, in that case.
+	Create(ret *domain.ReturnRequest) (bool, error)
+	FindByID(id uint) (*domain.ReturnRequest, error)
+	FindByOrderID(orderID uint) ([]domain.ReturnRequest, error)
+	FindByUserID(userID uint, page, pageSize int) ([]domain.ReturnRequest, int64, error)
+	FindByStatus(status domain.ReturnStatus, page, pageSize int) ([]domain.ReturnRequest, int64, error)
+	// Transition saves the return and appends the event to its history atomically.
+	// It reports false, saving nothing, if the return is no longer in the event's from-status.
+	Transition(ret *domain.ReturnRequest, event *domain.ReturnEvent) (bool, error)
+	// ClaimItemRestock marks a returned item as restocked before its stock is put back.
+	// It reports false if the item was already claimed.
+	ClaimItemRestock(itemID uint) (bool, error)
+	// ReleaseItemRestock undoes a claim whose stock could not be put back
+	ReleaseItemRestock(itemID uint) error
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errReturnQuantity rolls back a return for more than is still returnable
+	errReturnQuantity = errors.New("return quantity exceeds the quantity still returnable")
+	// errStaleReturn rolls back a transition from a status the return has already left
+	errStaleReturn = errors.New("return status has changed")
+)
+
+type returnRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewReturnRepository creates a new instance of ReturnRepository
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepositoryImpl{db: db}
+}
+
+func (r *returnRepositoryImpl) Create(ret *domain.ReturnRequest) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the order so concurrent returns against it are checked one at a time
+		var order domain.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Items").
+			First(&order, ret.OrderID).Error; err != nil {
+			return err
+		}
+
+		returnable := make(map[uint]int, len(order.Items))
+		for _, item := range order.Items {
+			returnable[item.ID] = item.Quantity
+		}
+		var existing []domain.ReturnRequest
+		if err := tx.Preload("Items").Where("order_id = ?", ret.OrderID).Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, other := range existing {
+			if !other.IsOpen() {
+				continue
+			}
+			for _, item := range other.Items {
+				returnable[item.OrderItemID] -= item.Quantity
+			}
+		}
+		for _, item := range ret.Items {
+			returnable[item.OrderItemID] -= item.Quantity
+			if returnable[item.OrderItemID] < 0 {
+				return errReturnQuantity
+			}
+		}
+
+		return tx.Create(ret).Error
+	})
+	if errors.Is(err, errReturnQuantity) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *returnRepositoryImpl) FindByID(id uint) (*domain.ReturnRequest, error) {
+	var ret domain.ReturnRequest
+	err := r.db.Preload("Items").
+		Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&ret, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (r *returnRepositoryImpl) FindByOrderID(orderID uint) ([]domain.ReturnRequest, error) {
+	var returns []domain.ReturnRequest
+	err := r.db.Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Find(&returns).Error
+	return returns, err
+}
+
+func (r *returnRepositoryImpl) FindByUserID(userID uint, page, pageSize int) ([]domain.ReturnRequest, int64, error) {
+	return r.findPage(func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userID)
+	}, page, pageSize)
+}
+
+func (r *returnRepositoryImpl) FindByStatus(status domain.ReturnStatus, page, pageSize int) ([]domain.ReturnRequest, int64, error) {
+	return r.findPage(func(db *gorm.DB) *gorm.DB {
+		if status != "" {
+			return db.Where("status = ?", status)
+		}
+		return db
+	}, page, pageSize)
+}
+
+func (r *returnRepositoryImpl) Transition(ret *domain.ReturnRequest, event *domain.ReturnEvent) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ret.UpdatedAt = time.Now()
+		result := tx.Model(&domain.ReturnRequest{}).
+			Where("id = ? AND status = ?", ret.ID, event.FromStatus).
+			Updates(map[string]interface{}{
+				"status":     ret.Status,
+				"staff_note": ret.StaffNote,
+				"restocked":  ret.Restocked,
+				"updated_at": ret.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errStaleReturn
+		}
+		event.ReturnRequestID = ret.ID
+		return tx.Create(event).Error
+	})
+	if errors.Is(err, errStaleReturn) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *returnRepositoryImpl) ClaimItemRestock(itemID uint) (bool, error) {
+	result := r.db.Model(&domain.ReturnItem{}).
+		Where("id = ? AND NOT restocked", itemID).
+		Update("restocked", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *returnRepositoryImpl) ReleaseItemRestock(itemID uint) error {
+	return r.db.Model(&domain.ReturnItem{}).Where("id = ?", itemID).Update("restocked", false).Error
+}
+
+func (r *returnRepositoryImpl) findPage(filter func(*gorm.DB) *gorm.DB, page, pageSize int) ([]domain.ReturnRequest, int64, error) {
+	var returns []domain.ReturnRequest
+	var total int64
+
+	r.db.Model(&domain.ReturnRequest{}).Scopes(filter).Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := r.db.Scopes(filter).Preload("Items").
+		Offset(offset).
+		Limit(pageSize).
+		Order("created_at DESC").
+		Find(&returns).Error
+
+	return returns, total, err
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+
+// ShipmentRepository defines the interface for shipment data operations
+type ShipmentRepository interface {
+	Create(shipment *domain.Shipment) error
+	FindByOrderID(orderID uint) ([]domain.Shipment, error)
+	Update(shipment *domain.Shipment) error
+}
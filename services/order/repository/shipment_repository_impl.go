@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"gorm.io/gorm"
+)
+
+type shipmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewShipmentRepository creates a new instance of ShipmentRepository
+func NewShipmentRepository(db *gorm.DB) ShipmentRepository {
+	return &shipmentRepositoryImpl{db: db}
+}
+
+func (r *shipmentRepositoryImpl) Create(shipment *domain.Shipment) error {
+	return r.db.Create(shipment).Error
+}
+
+func (r *shipmentRepositoryImpl) FindByOrderID(orderID uint) ([]domain.Shipment, error) {
+	var shipments []domain.Shipment
+	err := r.db.Where("order_id = ?", orderID).Order("created_at ASC").Find(&shipments).Error
+	return shipments, err
+}
+
+func (r *shipmentRepositoryImpl) Update(shipment *domain.Shipment) error {
+	return r.db.Save(shipment).Error
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+// AddressService defines the interface for address book operations
+type AddressService interface {
+	ListAddresses(ctx context.Context, userID uint) ([]dto.AddressResponse, error)
+	CreateAddress(ctx context.Context, userID uint, req *dto.AddressRequest) (*dto.AddressResponse, error)
+	UpdateAddress(ctx context.Context, userID, id uint, req *dto.AddressRequest) (*dto.AddressResponse, error)
+	DeleteAddress(ctx context.Context, userID, id uint) error
+	SetDefaultAddress(ctx context.Context, userID, id uint) error
+}
+
+type addressServiceImpl struct {
+	addressRepo repository.AddressRepository
+}
+
+// NewAddressService creates a new instance of AddressService
+func NewAddressService(addressRepo repository.AddressRepository) AddressService {
+	return &addressServiceImpl{addressRepo: addressRepo}
+}
+
+func (s *addressServiceImpl) ListAddresses(ctx context.Context, userID uint) ([]dto.AddressResponse, error) {
+	addresses, err := s.addressRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AddressResponse, len(addresses))
+	for i, address := range addresses {
+		responses[i] = *toAddressResponse(&address)
+	}
+	return responses, nil
+}
+
+func (s *addressServiceImpl) CreateAddress(ctx context.Context, userID uint, req *dto.AddressRequest) (*dto.AddressResponse, error) {
+	existing, err := s.addressRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	address := &domain.Address{UserID: userID}
+	applyAddressRequest(address, req)
+
+	// The first address always becomes the default
+	if len(existing) == 0 {
+		address.IsDefault = true
+	}
+
+	if err := s.addressRepo.Create(address); err != nil {
+		return nil, err
+	}
+
+	if address.IsDefault && len(existing) > 0 {
+		if err := s.addressRepo.SetDefault(userID, address.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return toAddressResponse(address), nil
+}
+
+func (s *addressServiceImpl) UpdateAddress(ctx context.Context, userID, id uint, req *dto.AddressRequest) (*dto.AddressResponse, error) {
+	address, err := s.findOwnedAddress(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wasDefault := address.IsDefault
+	applyAddressRequest(address, req)
+	// Unsetting the default happens by choosing another address, not by clearing the flag
+	address.IsDefault = address.IsDefault || wasDefault
+
+	if err := s.addressRepo.Update(address); err != nil {
+		return nil, err
+	}
+
+	if address.IsDefault && !wasDefault {
+		if err := s.addressRepo.SetDefault(userID, address.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return toAddressResponse(address), nil
+}
+
+func (s *addressServiceImpl) DeleteAddress(ctx context.Context, userID, id uint) error {
+	if _, err := s.findOwnedAddress(userID, id); err != nil {
+		return err
+	}
+	return s.addressRepo.Delete(id)
+}
+
+func (s *addressServiceImpl) SetDefaultAddress(ctx context.Context, userID, id uint) error {
+	if _, err := s.findOwnedAddress(userID, id); err != nil {
+		return err
+	}
+	return s.addressRepo.SetDefault(userID, id)
+}
+
+func (s *addressServiceImpl) findOwnedAddress(userID, id uint) (*domain.Address, error) {
+	address, err := s.addressRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	// Don't reveal other users' addresses
+	if address.UserID != userID {
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
+
+func applyAddressRequest(address *domain.Address, req *dto.AddressRequest) {
+	address.Label = req.Label
+	address.RecipientName = req.RecipientName
+	address.Phone = req.Phone
+	address.Line1 = req.Line1
+	address.Line2 = req.Line2
+	address.City = req.City
+	address.Province = req.Province
+	address.PostalCode = req.PostalCode
+	address.Country = req.Country
+	if address.Country == "" {
+		address.Country = "ID"
+	}
+	address.IsDefault = req.IsDefault
+}
+
+func toAddressResponse(address *domain.Address) *dto.AddressResponse {
+	return &dto.AddressResponse{
+		ID:            address.ID,
+		Label:         address.Label,
+		RecipientName: address.RecipientName,
+		Phone:         address.Phone,
+		Line1:         address.Line1,
+		Line2:         address.Line2,
+		City:          address.City,
+		Province:      address.Province,
+		PostalCode:    address.PostalCode,
+		Country:       address.Country,
+		IsDefault:     address.IsDefault,
+	}
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/client"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+)
+
+var (
+	ErrInvalidOrderFilter = errors.New("invalid order filter")
+	ErrInvalidOrderStatus = errors.New("invalid order status")
+)
+
+const (
+	defaultAdminPageSize = 50
+	maxAdminPageSize     = 200
+	exportBatchSize      = 200
+)
+
+// exportHeader is the column layout of the order CSV export
+var exportHeader = []string{
+	"id", "user_id", "status", "payment_status", "subtotal", "shipping_cost", "total_amount",
+	"shipping_method", "item_count", "recipient_name", "city", "country", "created_at",
+}
+
+// AdminOrderService defines back-office order operations
+type AdminOrderService interface {
+	ListOrders(ctx context.Context, query *dto.AdminOrderQuery) (*dto.AdminOrderListResponse, error)
+	ExportOrders(ctx context.Context, query *dto.AdminOrderQuery, w io.Writer) error
+	BulkUpdateStatus(ctx context.Context, req *dto.BulkStatusUpdateRequest) (*dto.BulkStatusUpdateResponse, error)
+}
+
+type adminOrderServiceImpl struct {
+	orderRepo     repository.OrderRepository
+	orders        OrderService
+	paymentClient *client.PaymentClient
+}
+
+// NewAdminOrderService creates a new instance of AdminOrderService.
+// Bulk cancellations and deliveries go through orders so they release stock and update shipments.
+func NewAdminOrderService(orderRepo repository.OrderRepository, orders OrderService, paymentClient *client.PaymentClient) AdminOrderService {
+	return &adminOrderServiceImpl{
+		orderRepo:     orderRepo,
+		orders:        orders,
+		paymentClient: paymentClient,
+	}
+}
+
+// orderCursor is the decoded form of the opaque next_cursor token.
+// The sort is embedded so a cursor cannot be replayed against a different ordering.
+type orderCursor struct {
+	Sort string `json:"s"`
+	repository.OrderCursor
+}
+
+func (s *adminOrderServiceImpl) ListOrders(ctx context.Context, query *dto.AdminOrderQuery) (*dto.AdminOrderListResponse, error) {
+	filter, err := parseOrderFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit < 1 || limit > maxAdminPageSize {
+		limit = defaultAdminPageSize
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	filter.Limit = limit + 1
+	orders, err := s.orderRepo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AdminOrderListResponse{Limit: limit}
+	if len(orders) > limit {
+		orders = orders[:limit]
+		last := orders[limit-1]
+		response.NextCursor = encodeOrderCursor(orderCursor{
+			Sort:        sortKey(filter),
+			OrderCursor: repository.OrderCursor{CreatedAt: last.CreatedAt, TotalAmount: last.TotalAmount, ID: last.ID},
+		})
+	}
+
+	statuses := s.paymentStatuses(ctx, orders)
+	response.Orders = make([]dto.AdminOrderResponse, len(orders))
+	for i := range orders {
+		response.Orders[i] = dto.AdminOrderResponse{
+			OrderResponse: *toOrderResponse(&orders[i]),
+			PaymentStatus: statuses[orders[i].ID],
+		}
+	}
+	return response, nil
+}
+
+// ExportOrders writes every order matching the query to w as CSV, one batch at a time,
+// so large exports never have to be held in memory
+func (s *adminOrderServiceImpl) ExportOrders(ctx context.Context, query *dto.AdminOrderQuery, w io.Writer) error {
+	filter, err := parseOrderFilter(query)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader); err != nil {
+		return err
+	}
+
+	err = s.orderRepo.SearchInBatches(filter, exportBatchSize, func(orders []domain.Order) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		statuses := s.paymentStatuses(ctx, orders)
+		for _, order := range orders {
+			if err := writer.Write(exportRow(&order, statuses[order.ID])); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *adminOrderServiceImpl) BulkUpdateStatus(ctx context.Context, req *dto.BulkStatusUpdateRequest) (*dto.BulkStatusUpdateResponse, error) {
+	// Payment and shipping need a payment reference or shipment, so they are never set in bulk
+	if !req.Status.IsStaffSettable() {
+		return nil, ErrInvalidOrderStatus
+	}
+
+	// Drop duplicate ids while keeping the caller's order for the response
+	seen := make(map[uint]bool, len(req.OrderIDs))
+	ids := make([]uint, 0, len(req.OrderIDs))
+	for _, id := range req.OrderIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	orders, err := s.orderRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[uint]domain.OrderStatus, len(orders))
+	for _, order := range orders {
+		statuses[order.ID] = order.Status
+	}
+
+	outcomes := make(map[uint]bulkOutcome, len(ids))
+	var confirmable []uint
+	for _, id := range ids {
+		status, ok := statuses[id]
+		switch {
+		case !ok:
+			outcomes[id] = bulkNotFound
+		case !status.CanStaffSet(req.Status):
+			outcomes[id] = bulkRejected
+		case req.Status == domain.OrderStatusConfirmed:
+			confirmable = append(confirmable, id)
+		default:
+			outcomes[id] = s.changeStatus(ctx, id, req.Status)
+		}
+	}
+
+	if len(confirmable) > 0 {
+		confirmed, err := s.orderRepo.BulkUpdateStatus(confirmable, domain.OrderStatusPending, domain.OrderStatusConfirmed)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range confirmable {
+			outcomes[id] = bulkRejected // moved on since it was read
+		}
+		for _, id := range confirmed {
+			outcomes[id] = bulkUpdated
+		}
+	}
+
+	response := &dto.BulkStatusUpdateResponse{
+		Status:   req.Status,
+		Updated:  []uint{},
+		NotFound: []uint{},
+		Rejected: []uint{},
+		Failed:   []uint{},
+	}
+	for _, id := range ids {
+		switch outcomes[id] {
+		case bulkUpdated:
+			response.Updated = append(response.Updated, id)
+		case bulkNotFound:
+			response.NotFound = append(response.NotFound, id)
+		case bulkRejected:
+			response.Rejected = append(response.Rejected, id)
+		default:
+			response.Failed = append(response.Failed, id)
+		}
+	}
+	return response, nil
+}
+
+// bulkOutcome is what happened to one order in a bulk status update
+type bulkOutcome int
+
+const (
+	bulkFailed bulkOutcome = iota
+	bulkUpdated
+	bulkNotFound
+	bulkRejected
+)
+
+// changeStatus moves one order through the same path as the single-order endpoint
+func (s *adminOrderServiceImpl) changeStatus(ctx context.Context, id uint, status domain.OrderStatus) bulkOutcome {
+	err := s.orders.UpdateOrderStatus(ctx, id, status)
+	switch {
+	case err == nil:
+		return bulkUpdated
+	case errors.Is(err, ErrOrderNotFound):
+		return bulkNotFound
+	case errors.Is(err, ErrStatusTransition):
+		return bulkRejected
+	default:
+		return bulkFailed
+	}
+}
+
+// paymentStatuses looks up payment statuses for a page of orders.
+// The order list stays usable when Payment Service is down; affected rows report "unknown".
+func (s *adminOrderServiceImpl) paymentStatuses(ctx context.Context, orders []domain.Order) map[uint]string {
+	ids := make([]uint, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ID
+	}
+
+	result := make(map[uint]string, len(orders))
+	statuses, err := s.paymentClient.GetPaymentStatuses(ctx, ids)
+	for _, id := range ids {
+		switch {
+		case err != nil:
+			result[id] = dto.PaymentStatusUnknown
+		case statuses[id] == "":
+			result[id] = dto.PaymentStatusNone
+		default:
+			result[id] = statuses[id]
+		}
+	}
+	return result
+}
+
+// parseOrderFilter validates an admin query and converts it to a repository filter
+func parseOrderFilter(query *dto.AdminOrderQuery) (repository.OrderFilter, error) {
+	filter := repository.OrderFilter{
+		UserID:     query.UserID,
+		ProductID:  query.ProductID,
+		MinTotal:   query.MinTotal,
+		MaxTotal:   query.MaxTotal,
+		SortBy:     repository.OrderSortCreatedAt,
+		Descending: true,
+	}
+
+	if query.Status != "" {
+		for _, raw := range strings.Split(query.Status, ",") {
+			status := domain.OrderStatus(strings.TrimSpace(raw))
+			if !status.IsValid() {
+				return filter, fmt.Errorf("%w: unknown status %q", ErrInvalidOrderFilter, raw)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if query.From != "" {
+		from, _, err := parseFilterTime(query.From)
+		if err != nil {
+			return filter, fmt.Errorf("%w: from: %v", ErrInvalidOrderFilter, err)
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, dateOnly, err := parseFilterTime(query.To)
+		if err != nil {
+			return filter, fmt.Errorf("%w: to: %v", ErrInvalidOrderFilter, err)
+		}
+		// A bare date includes the whole day; a timestamp includes that instant
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		} else {
+			to = to.Add(time.Nanosecond)
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, fmt.Errorf("%w: from must be before to", ErrInvalidOrderFilter)
+	}
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		return filter, fmt.Errorf("%w: min_total must not exceed max_total", ErrInvalidOrderFilter)
+	}
+
+	if query.Sort != "" {
+		field := strings.TrimPrefix(query.Sort, "-")
+		switch repository.OrderSortField(field) {
+		case repository.OrderSortCreatedAt, repository.OrderSortTotalAmount:
+			filter.SortBy = repository.OrderSortField(field)
+			filter.Descending = strings.HasPrefix(query.Sort, "-")
+		default:
+			return filter, fmt.Errorf("%w: cannot sort by %q", ErrInvalidOrderFilter, field)
+		}
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeOrderCursor(query.Cursor)
+		if err != nil || cursor.Sort != sortKey(filter) {
+			return filter, fmt.Errorf("%w: invalid cursor", ErrInvalidOrderFilter)
+		}
+		filter.After = &cursor.OrderCursor
+	}
+
+	return filter, nil
+}
+
+// parseFilterTime accepts either a date (YYYY-MM-DD) or an RFC3339 timestamp
+func parseFilterTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+func sortKey(filter repository.OrderFilter) string {
+	if filter.Descending {
+		return "-" + string(filter.SortBy)
+	}
+	return string(filter.SortBy)
+}
+
+func encodeOrderCursor(cursor orderCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeOrderCursor(token string) (orderCursor, error) {
+	var cursor orderCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+func exportRow(order *domain.Order, paymentStatus string) []string {
+	itemCount := 0
+	for _, item := range order.Items {
+		itemCount += item.Quantity
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(order.ID), 10),
+		strconv.FormatUint(uint64(order.UserID), 10),
+		string(order.Status),
+		paymentStatus,
+		strconv.FormatFloat(order.Subtotal, 'f', 2, 64),
+		strconv.FormatFloat(order.ShippingCost, 'f', 2, 64),
+		strconv.FormatFloat(order.TotalAmount, 'f', 2, 64),
+		string(order.ShippingMethod),
+		strconv.Itoa(itemCount),
+		csvSafe(order.ShippingAddress.RecipientName),
+		csvSafe(order.ShippingAddress.City),
+		csvSafe(order.ShippingAddress.Country),
+		order.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// csvSafe neutralises customer-supplied values that spreadsheet software would evaluate as formulas
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
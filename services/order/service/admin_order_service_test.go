@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrderFilter_Defaults(t *testing.T) {
+	filter, err := parseOrderFilter(&dto.AdminOrderQuery{})
+
+	require.NoError(t, err)
+	assert.Equal(t, repository.OrderSortCreatedAt, filter.SortBy)
+	assert.True(t, filter.Descending)
+	assert.Nil(t, filter.After)
+}
+
+func TestParseOrderFilter_Filters(t *testing.T) {
+	filter, err := parseOrderFilter(&dto.AdminOrderQuery{
+		Status: "paid, shipped",
+		From:   "2024-03-01",
+		To:     "2024-03-31",
+		Sort:   "total_amount",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.OrderStatus{domain.OrderStatusPaid, domain.OrderStatusShipped}, filter.Statuses)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *filter.From)
+	// A bare "to" date covers that whole day
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), *filter.To)
+	assert.Equal(t, repository.OrderSortTotalAmount, filter.SortBy)
+	assert.False(t, filter.Descending)
+}
+
+func TestParseOrderFilter_Invalid(t *testing.T) {
+	minTotal, maxTotal := 500.0, 100.0
+	queries := []dto.AdminOrderQuery{
+		{Status: "lost"},
+		{From: "yesterday"},
+		{From: "2024-03-02", To: "2024-03-01"},
+		{MinTotal: &minTotal, MaxTotal: &maxTotal},
+		{Sort: "user_id"},
+		{Cursor: "not-a-cursor"},
+	}
+
+	for _, query := range queries {
+		_, err := parseOrderFilter(&query)
+		assert.ErrorIs(t, err, ErrInvalidOrderFilter, "query %+v", query)
+	}
+}
+
+func TestParseOrderFilter_CursorRoundTrip(t *testing.T) {
+	// Arrange
+	createdAt := time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC)
+	token := encodeOrderCursor(orderCursor{
+		Sort:        "-created_at",
+		OrderCursor: repository.OrderCursor{CreatedAt: createdAt, ID: 42},
+	})
+
+	// Act
+	filter, err := parseOrderFilter(&dto.AdminOrderQuery{Cursor: token})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, filter.After)
+	assert.Equal(t, uint(42), filter.After.ID)
+	assert.True(t, createdAt.Equal(filter.After.CreatedAt))
+
+	// The same cursor is rejected under a different sort
+	_, err = parseOrderFilter(&dto.AdminOrderQuery{Cursor: token, Sort: "total_amount"})
+	assert.ErrorIs(t, err, ErrInvalidOrderFilter)
+}
+
+func TestCSVSafe(t *testing.T) {
+	assert.Equal(t, "Jakarta", csvSafe("Jakarta"))
+	assert.Equal(t, "'=HYPERLINK(\"x\")", csvSafe("=HYPERLINK(\"x\")"))
+	assert.Equal(t, "", csvSafe(""))
+}
+
+// fakeOrderStore keeps orders in memory for the bulk status tests
+type fakeOrderStore struct {
+	repository.OrderRepository
+	orders map[uint]*domain.Order
+}
+
+func (f *fakeOrderStore) FindByIDs(ids []uint) ([]domain.Order, error) {
+	var orders []domain.Order
+	for _, id := range ids {
+		if order, ok := f.orders[id]; ok {
+			orders = append(orders, *order)
+		}
+	}
+	return orders, nil
+}
+
+func (f *fakeOrderStore) BulkUpdateStatus(ids []uint, from, to domain.OrderStatus) ([]uint, error) {
+	var updated []uint
+	for _, id := range ids {
+		if order, ok := f.orders[id]; ok && order.Status == from {
+			order.Status = to
+			updated = append(updated, id)
+		}
+	}
+	return updated, nil
+}
+
+// fakeOrderActions records the single-order status changes a bulk update goes through
+type fakeOrderActions struct {
+	OrderService
+	store     *fakeOrderStore
+	cancelled []uint
+	delivered []uint
+}
+
+func (f *fakeOrderActions) UpdateOrderStatus(ctx context.Context, id uint, status domain.OrderStatus) error {
+	switch status {
+	case domain.OrderStatusCancelled:
+		f.cancelled = append(f.cancelled, id)
+	case domain.OrderStatusDelivered:
+		f.delivered = append(f.delivered, id)
+	}
+	f.store.orders[id].Status = status
+	return nil
+}
+
+func newBulkFixture(statuses map[uint]domain.OrderStatus) (AdminOrderService, *fakeOrderStore, *fakeOrderActions) {
+	store := &fakeOrderStore{orders: make(map[uint]*domain.Order)}
+	for id, status := range statuses {
+		store.orders[id] = &domain.Order{ID: id, Status: status}
+	}
+	actions := &fakeOrderActions{store: store}
+	return NewAdminOrderService(store, actions, nil), store, actions
+}
+
+func TestAdminOrderService_BulkUpdateStatus_Confirm(t *testing.T) {
+	// Arrange
+	svc, store, _ := newBulkFixture(map[uint]domain.OrderStatus{
+		1: domain.OrderStatusPending,
+		2: domain.OrderStatusShipped,
+		3: domain.OrderStatusPending,
+	})
+
+	// Act
+	result, err := svc.BulkUpdateStatus(context.Background(), &dto.BulkStatusUpdateRequest{
+		OrderIDs: []uint{3, 2, 1, 3, 9},
+		Status:   domain.OrderStatusConfirmed,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3, 1}, result.Updated)
+	assert.Equal(t, []uint{2}, result.Rejected)
+	assert.Equal(t, []uint{9}, result.NotFound)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, domain.OrderStatusShipped, store.orders[2].Status)
+}
+
+func TestAdminOrderService_BulkUpdateStatus_CancelReleasesThroughOrderService(t *testing.T) {
+	// Arrange
+	svc, store, actions := newBulkFixture(map[uint]domain.OrderStatus{
+		1: domain.OrderStatusPending,
+		2: domain.OrderStatusPaid,
+		3: domain.OrderStatusCancelled,
+	})
+
+	// Act
+	result, err := svc.BulkUpdateStatus(context.Background(), &dto.BulkStatusUpdateRequest{
+		OrderIDs: []uint{1, 2, 3},
+		Status:   domain.OrderStatusCancelled,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1}, actions.cancelled)
+	assert.Equal(t, []uint{1}, result.Updated)
+	assert.Equal(t, []uint{2, 3}, result.Rejected)
+	assert.Equal(t, domain.OrderStatusPaid, store.orders[2].Status)
+}
+
+func TestAdminOrderService_BulkUpdateStatus_Deliver(t *testing.T) {
+	// Arrange
+	svc, _, actions := newBulkFixture(map[uint]domain.OrderStatus{
+		1: domain.OrderStatusShipped,
+		2: domain.OrderStatusPending,
+	})
+
+	// Act
+	result, err := svc.BulkUpdateStatus(context.Background(), &dto.BulkStatusUpdateRequest{
+		OrderIDs: []uint{1, 2},
+		Status:   domain.OrderStatusDelivered,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1}, actions.delivered)
+	assert.Equal(t, []uint{1}, result.Updated)
+	assert.Equal(t, []uint{2}, result.Rejected)
+}
+
+func TestAdminOrderService_BulkUpdateStatus_StatusNotSettableInBulk(t *testing.T) {
+	svc, store, _ := newBulkFixture(map[uint]domain.OrderStatus{1: domain.OrderStatusPending})
+
+	for _, status := range []domain.OrderStatus{domain.OrderStatusPaid, domain.OrderStatusShipped, domain.OrderStatusPending, "lost"} {
+		_, err := svc.BulkUpdateStatus(context.Background(), &dto.BulkStatusUpdateRequest{
+			OrderIDs: []uint{1},
+			Status:   status,
+		})
+		assert.ErrorIs(t, err, ErrInvalidOrderStatus, "status %q", status)
+	}
+	assert.Equal(t, domain.OrderStatusPending, store.orders[1].Status)
+}
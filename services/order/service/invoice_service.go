@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/client"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotInvoiceable  = errors.New("invoices are only issued for paid orders")
+	ErrInvoicePaymentLookup = errors.New("failed to look up the order payment")
+)
+
+// InvoiceSettings holds the merchant details and tax printed on every invoice
+type InvoiceSettings struct {
+	Seller domain.InvoiceSeller
+	// TaxName and TaxRate describe the tax contained in catalogue prices (e.g. PPN 11%) for
+	// orders placed before tax was recorded on them. A zero rate omits the tax line.
+	TaxName string
+	TaxRate float64
+}
+
+// InvoiceService defines the interface for invoice operations
+type InvoiceService interface {
+	// GetInvoice returns the invoice of an order, issuing it on first request
+	GetInvoice(ctx context.Context, orderID uint) (*domain.Invoice, error)
+}
+
+type invoiceServiceImpl struct {
+	invoiceRepo   repository.InvoiceRepository
+	orderRepo     repository.OrderRepository
+	paymentClient *client.PaymentClient
+	settings      InvoiceSettings
+	now           func() time.Time
+}
+
+// NewInvoiceService creates a new instance of InvoiceService
+func NewInvoiceService(
+	invoiceRepo repository.InvoiceRepository,
+	orderRepo repository.OrderRepository,
+	paymentClient *client.PaymentClient,
+	settings InvoiceSettings,
+) InvoiceService {
+	return &invoiceServiceImpl{
+		invoiceRepo:   invoiceRepo,
+		orderRepo:     orderRepo,
+		paymentClient: paymentClient,
+		settings:      settings,
+		now:           time.Now,
+	}
+}
+
+func (s *invoiceServiceImpl) GetInvoice(ctx context.Context, orderID uint) (*domain.Invoice, error) {
+	invoice, err := s.invoiceRepo.FindByOrderID(orderID)
+	if err == nil {
+		return invoice, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if !order.Status.IsInvoiceable() {
+		return nil, ErrOrderNotInvoiceable
+	}
+
+	// Invoices are immutable once issued, so don't issue one without its payment reference
+	payment, err := s.paymentClient.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvoicePaymentLookup, err)
+	}
+
+	invoice = s.buildInvoice(order)
+	if payment != nil {
+		invoice.PaymentReference = payment.TransactionID
+	}
+
+	if err := s.invoiceRepo.Create(invoice); err != nil {
+		// A concurrent request may have issued the invoice first
+		if existing, findErr := s.invoiceRepo.FindByOrderID(orderID); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *invoiceServiceImpl) buildInvoice(order *domain.Order) *domain.Invoice {
+	issuedAt := s.now()
+
+	lines := make([]domain.InvoiceLine, len(order.Items))
+	for i, item := range order.Items {
+		lines[i] = domain.InvoiceLine{
+			ProductID:   item.ProductID,
+			Description: item.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			Amount:      item.Subtotal,
+		}
+	}
+
+	invoice := &domain.Invoice{
+		Year:         issuedAt.Year(),
+		OrderID:      order.ID,
+		UserID:       order.UserID,
+		IssuedAt:     issuedAt,
+		Seller:       s.settings.Seller,
+		Buyer:        order.ShippingAddress,
+		Subtotal:     order.Subtotal,
+		ShippingCost: order.ShippingCost,
+		Discount:     order.DiscountAmount,
+		Total:        order.TotalAmount,
+		Currency:     order.Currency,
+		Lines:        lines,
+	}
+
+	for _, t := range order.Taxes {
+		invoice.TaxLines = append(invoice.TaxLines, domain.InvoiceTaxLine{
+			Name:          t.Name,
+			Rate:          t.Rate,
+			Inclusive:     t.Inclusive,
+			TaxableAmount: t.TaxableAmount,
+			Amount:        t.Amount,
+		})
+	}
+	// Orders taxed by the tax engine always have a region, even when they owe no tax, e.g. for
+	// exempt goods; only orders placed before tax was recorded are given the flat rate
+	if order.TaxRegion == "" && s.settings.TaxRate > 0 {
+		// Prices include tax, so the tax is carved out of the total rather than added to it
+		taxable := currency.Code(order.Currency).Round(order.TotalAmount / (1 + s.settings.TaxRate))
+		invoice.TaxLines = []domain.InvoiceTaxLine{{
+			Name:          s.settings.TaxName,
+			Rate:          s.settings.TaxRate,
+			Inclusive:     true,
+			TaxableAmount: taxable,
+			Amount:        order.TotalAmount - taxable,
+		}}
+	}
+
+	return invoice
+}
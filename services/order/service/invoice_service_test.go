@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInvoiceService() *invoiceServiceImpl {
+	return &invoiceServiceImpl{
+		settings: InvoiceSettings{TaxName: "PPN", TaxRate: 0.11},
+		now:      func() time.Time { return time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC) },
+	}
+}
+
+func TestInvoiceService_BuildInvoice_ExemptOrderHasNoTax(t *testing.T) {
+	// Arrange: taxed by the engine, but every item is exempt
+	order := &domain.Order{
+		ID: 7, Subtotal: 200000, TotalAmount: 200000, Currency: "IDR", TaxRegion: "ID", TaxInclusive: true,
+		Items: []domain.OrderItem{{ProductID: 1, Name: "Beras 5kg", Quantity: 2, Price: 100000, Subtotal: 200000, TaxCategory: "exempt"}},
+	}
+
+	// Act
+	invoice := newTestInvoiceService().buildInvoice(order)
+
+	// Assert
+	assert.Empty(t, invoice.TaxLines)
+	assert.Equal(t, 200000.0, invoice.Total)
+}
+
+func TestInvoiceService_BuildInvoice_LegacyOrderUsesFlatRate(t *testing.T) {
+	// Arrange: placed before tax was recorded on orders
+	order := &domain.Order{ID: 7, Subtotal: 111000, TotalAmount: 111000, Currency: "IDR"}
+
+	// Act
+	invoice := newTestInvoiceService().buildInvoice(order)
+
+	// Assert
+	require.Len(t, invoice.TaxLines, 1)
+	assert.Equal(t, "PPN", invoice.TaxLines[0].Name)
+	assert.True(t, invoice.TaxLines[0].Inclusive)
+	assert.Equal(t, 100000.0, invoice.TaxLines[0].TaxableAmount)
+	assert.Equal(t, 11000.0, invoice.TaxLines[0].Amount)
+}
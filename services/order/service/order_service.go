@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
 	"time"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/tax"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/client"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/promotion"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
 	"gorm.io/gorm"
 )
@@ -19,6 +23,20 @@ var (
 	ErrInsufficientStock  = errors.New("insufficient stock")
 	ErrProductUnavailable = errors.New("product is unavailable")
 	ErrEmptyOrder         = errors.New("order must have at least one item")
+	ErrInvalidShipping    = errors.New("unsupported shipping method")
+	ErrOrderNotShippable  = errors.New("only paid or confirmed orders can be shipped")
+	ErrOrderNotShipped    = errors.New("only shipped orders can be marked as delivered")
+	ErrOrderNotPayable    = errors.New("order is no longer awaiting payment")
+	ErrPaymentShort       = errors.New("payment amount is less than the order total")
+	ErrOrderNotCancelable = errors.New("only pending orders can be cancelled")
+	ErrStockReleaseFailed = errors.New("order cancelled but its stock could not be released yet")
+	ErrStatusTransition   = errors.New("order cannot move to this status from its current one")
+
+	// ErrCouponInvalid wraps the promotion error explaining why a coupon can't be applied
+	ErrCouponInvalid = errors.New("coupon cannot be applied")
+
+	ErrUnsupportedCurrency     = errors.New("unsupported currency")
+	ErrExchangeRateUnavailable = errors.New("exchange rate unavailable")
 )
 
 // OrderService defines the interface for order operations
@@ -26,20 +44,71 @@ type OrderService interface {
 	CreateOrder(ctx context.Context, userID uint, req *dto.CreateOrderRequest) (*dto.OrderResponse, error)
 	GetOrder(ctx context.Context, id uint) (*dto.OrderResponse, error)
 	GetUserOrders(ctx context.Context, userID uint, page, pageSize int) (*dto.OrderListResponse, error)
+	// LastOrderAt returns when the user last placed an order, or nil if they never did
+	LastOrderAt(ctx context.Context, userID uint) (*time.Time, error)
+	// UpdateOrderStatus moves an order to a status staff may set by hand, through the same
+	// path as the dedicated endpoints so cancelling releases stock and delivering updates shipments
 	UpdateOrderStatus(ctx context.Context, id uint, status domain.OrderStatus) error
 	CancelOrder(ctx context.Context, id uint) error
+	// CancelUnpaidOrder cancels a pending order on behalf of another service and releases its stock.
+	// Cancelling an order that is already cancelled succeeds without changes.
+	CancelUnpaidOrder(ctx context.Context, id uint, reason string) (*dto.OrderResponse, error)
+	// ExpireOrders cancels up to limit orders still pending since before the given time.
+	// It returns how many were cancelled. It first finishes giving back the coupon and stock
+	// of cancelled orders whose release was interrupted.
+	ExpireOrders(ctx context.Context, before time.Time, limit int) (int, error)
+	// MarkOrderPaid records a successful payment against an order and moves it to paid.
+	// Repeated calls for an order that has already been paid succeed without changes.
+	MarkOrderPaid(ctx context.Context, id uint, transactionID string, amount float64) (*dto.OrderResponse, error)
+
+	// Shipping & fulfillment
+	QuoteShipping(ctx context.Context, req *dto.ShippingQuoteRequest) ([]dto.ShippingQuoteResponse, error)
+
+	// PreviewDiscount works out what a coupon would take off the items, e.g. for the cart.
+	// userID is 0 for guests, whose per-customer limit is checked at checkout instead.
+	PreviewDiscount(ctx context.Context, userID uint, code string, items []dto.OrderItemRequest) (*dto.DiscountPreviewResponse, error)
+	ShipOrder(ctx context.Context, id uint, staffID uint, req *dto.ShipOrderRequest) (*dto.OrderResponse, error)
+	DeliverOrder(ctx context.Context, id uint) (*dto.OrderResponse, error)
+}
+
+// ProductCatalog is what orders need from Product Service (implemented by client.ProductClient)
+type ProductCatalog interface {
+	StockRestorer
+	GetProduct(ctx context.Context, productID uint) (*client.ProductInfo, error)
+	DecreaseStock(ctx context.Context, productID uint, quantity int) (int, error)
 }
 
 type orderServiceImpl struct {
 	orderRepo     repository.OrderRepository
-	productClient *client.ProductClient
+	addressRepo   repository.AddressRepository
+	shipmentRepo  repository.ShipmentRepository
+	promotions    repository.PromotionRepository
+	productClient ProductCatalog
+	shippingRates ShippingRates
+	rates         currency.RateProvider
+	taxes         tax.TaxCalculator
 }
 
 // NewOrderService creates a new instance of OrderService
-func NewOrderService(orderRepo repository.OrderRepository, productClient *client.ProductClient) OrderService {
+func NewOrderService(
+	orderRepo repository.OrderRepository,
+	addressRepo repository.AddressRepository,
+	shipmentRepo repository.ShipmentRepository,
+	promotions repository.PromotionRepository,
+	productClient ProductCatalog,
+	shippingRates ShippingRates,
+	rates currency.RateProvider,
+	taxes tax.TaxCalculator,
+) OrderService {
 	return &orderServiceImpl{
 		orderRepo:     orderRepo,
+		addressRepo:   addressRepo,
+		shipmentRepo:  shipmentRepo,
+		promotions:    promotions,
 		productClient: productClient,
+		shippingRates: shippingRates,
+		rates:         rates,
+		taxes:         taxes,
 	}
 }
 
@@ -48,48 +117,87 @@ func (s *orderServiceImpl) CreateOrder(ctx context.Context, userID uint, req *dt
 		return nil, ErrEmptyOrder
 	}
 
-	var orderItems []domain.OrderItem
-	var totalAmount float64
+	shippingMethod := req.ShippingMethod
+	if shippingMethod == "" {
+		shippingMethod = domain.ShippingMethodStandard
+	}
+	calculator, ok := s.shippingRates[shippingMethod]
+	if !ok {
+		return nil, ErrInvalidShipping
+	}
 
-	// Validate products and calculate totals by calling Product Service via gRPC
-	for _, item := range req.Items {
-		// Get product info from Product Service
-		product, err := s.productClient.GetProduct(ctx, item.ProductID)
+	orderCurrency, err := currency.Parse(req.Currency)
+	if err != nil {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	address, err := s.addressRepo.FindByID(req.AddressID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	if address.UserID != userID {
+		return nil, ErrAddressNotFound
+	}
+
+	orderItems, err := s.buildOrderItems(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Catalogue prices, shipping rates and promotions are in the base currency
+	shippingCost := calculator.Calculate(orderItems)
+	var discount *promotion.Discount
+	if req.CouponCode != "" {
+		discount, err = s.evaluateCoupon(userID, req.CouponCode, orderItems, shippingCost)
 		if err != nil {
 			return nil, err
 		}
-		if product == nil {
-			return nil, ErrProductNotFound
-		}
-		if !product.IsActive {
-			return nil, ErrProductUnavailable
+		for i := range orderItems {
+			orderItems[i].DiscountAmount = discount.LineAmounts[i]
 		}
+	}
 
-		// Check stock availability
-		if product.Stock < item.Quantity {
-			return nil, ErrInsufficientStock
-		}
+	rate, err := s.rates.Rate(ctx, orderCurrency, currency.Base)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeRateUnavailable, err)
+	}
+	shippingCost = convertOrderItems(orderItems, shippingCost, orderCurrency, rate.Value)
+	subtotal := orderCurrency.Round(itemsSubtotal(orderItems))
 
-		subtotal := product.Price * float64(item.Quantity)
-		orderItems = append(orderItems, domain.OrderItem{
-			ProductID: item.ProductID,
-			Name:      product.Name,
-			Price:     product.Price,
-			Quantity:  item.Quantity,
-			Subtotal:  subtotal,
-		})
-		totalAmount += subtotal
+	var discounts []domain.OrderDiscount
+	var discountAmount float64
+	if discount != nil {
+		applied := toOrderDiscount(discount, orderItems, orderCurrency, rate.Value)
+		discounts = append(discounts, applied)
+		discountAmount = applied.Amount
 	}
 
 	// Create order
 	order := &domain.Order{
-		UserID:      userID,
-		Status:      domain.OrderStatusPending,
-		TotalAmount: totalAmount,
-		Items:       orderItems,
+		UserID:          userID,
+		Status:          domain.OrderStatusPending,
+		Subtotal:        subtotal,
+		ShippingCost:    shippingCost,
+		DiscountAmount:  discountAmount,
+		TotalAmount:     orderCurrency.Round(subtotal + shippingCost - discountAmount),
+		Currency:        string(orderCurrency),
+		ExchangeRate:    rate.Value,
+		ShippingMethod:  shippingMethod,
+		ShippingAddress: address.Snapshot(),
+		Items:           orderItems,
+		Discounts:       discounts,
 	}
 
-	if err := s.orderRepo.Create(order); err != nil {
+	breakdown, err := s.taxes.Calculate(order.ShippingAddress.Country, orderTaxLines(order), orderCurrency)
+	if err != nil {
+		return nil, err
+	}
+	applyTax(order, breakdown, orderCurrency)
+
+	if err := s.createOrder(order, discount); err != nil {
 		return nil, err
 	}
 
@@ -104,7 +212,175 @@ func (s *orderServiceImpl) CreateOrder(ctx context.Context, userID uint, req *dt
 		}
 	}
 
-	return s.toOrderResponse(order), nil
+	return toOrderResponse(order), nil
+}
+
+// createOrder stores the order, redeeming its coupon if it has one
+func (s *orderServiceImpl) createOrder(order *domain.Order, discount *promotion.Discount) error {
+	if discount == nil {
+		return s.orderRepo.Create(order)
+	}
+
+	order.CouponCode = discount.Promotion.Code
+	redeemed, err := s.promotions.CreateOrder(order, &domain.PromotionRedemption{
+		PromotionID: discount.Promotion.ID,
+		UserID:      order.UserID,
+		Code:        discount.Promotion.Code,
+	})
+	if err != nil {
+		return err
+	}
+	if !redeemed {
+		// Another order took the last use since the coupon was evaluated
+		return fmt.Errorf("%w: %w", ErrCouponInvalid, promotion.ErrUsageLimitReached)
+	}
+	return nil
+}
+
+// evaluateCoupon applies the promotion with the given code to base-currency order items.
+// userID is 0 when the customer is not known.
+func (s *orderServiceImpl) evaluateCoupon(userID uint, code string, items []domain.OrderItem, shippingCost float64) (*promotion.Discount, error) {
+	p, err := s.promotions.FindByCode(domain.NormalizeCouponCode(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %w", ErrCouponInvalid, promotion.ErrCouponNotFound)
+		}
+		return nil, err
+	}
+
+	var used int64
+	if userID != 0 && p.PerUserLimit > 0 {
+		if used, err = s.promotions.CountRedemptions(p.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	basket := &promotion.Basket{ShippingCost: shippingCost, At: time.Now()}
+	for _, item := range items {
+		basket.Lines = append(basket.Lines, promotion.Line{
+			ProductID:  item.ProductID,
+			CategoryID: item.CategoryID,
+			UnitPrice:  item.Price,
+			Quantity:   item.Quantity,
+		})
+	}
+	discount, err := promotion.Evaluate(p, basket, used)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCouponInvalid, err)
+	}
+	return discount, nil
+}
+
+// toOrderDiscount records a discount on already converted order items. The items' shares are
+// summed rather than converting the base-currency total, so the audit lines add up exactly.
+func toOrderDiscount(discount *promotion.Discount, items []domain.OrderItem, code currency.Code, rate float64) domain.OrderDiscount {
+	var itemsAmount float64
+	for _, item := range items {
+		itemsAmount += item.DiscountAmount
+	}
+	itemsAmount = code.Round(itemsAmount)
+	shippingAmount := code.Round(discount.ShippingAmount / rate)
+
+	return domain.OrderDiscount{
+		PromotionID:    discount.Promotion.ID,
+		Code:           discount.Promotion.Code,
+		Type:           discount.Promotion.Type,
+		Description:    discount.Promotion.Name,
+		ItemsAmount:    itemsAmount,
+		ShippingAmount: shippingAmount,
+		Amount:         code.Round(itemsAmount + shippingAmount),
+	}
+}
+
+// orderTaxLines lists what is taxed on a priced order: each item net of its discounts, then
+// shipping net of shipping discounts at the standard rate
+func orderTaxLines(order *domain.Order) []tax.Line {
+	lines := make([]tax.Line, 0, len(order.Items)+1)
+	for _, item := range order.Items {
+		lines = append(lines, tax.Line{
+			Category: tax.Category(item.TaxCategory),
+			Amount:   item.Subtotal - item.DiscountAmount,
+		})
+	}
+
+	shipping := order.ShippingCost
+	for _, discount := range order.Discounts {
+		shipping -= discount.ShippingAmount
+	}
+	return append(lines, tax.Line{Category: tax.CategoryStandard, Amount: shipping})
+}
+
+// applyTax records a breakdown from orderTaxLines on the order. Tax that is not contained in the
+// prices is added to the total.
+func applyTax(order *domain.Order, breakdown *tax.Breakdown, code currency.Code) {
+	order.TaxRegion = breakdown.Region
+	order.TaxInclusive = breakdown.Inclusive
+	order.TaxAmount = code.Round(breakdown.Total)
+	for i := range order.Items {
+		order.Items[i].TaxAmount = breakdown.LineAmounts[i]
+	}
+	order.Taxes = nil
+	for _, line := range breakdown.Lines {
+		order.Taxes = append(order.Taxes, domain.OrderTax{
+			Name:          line.Name,
+			Rate:          line.Rate,
+			Inclusive:     line.Inclusive,
+			TaxableAmount: code.Round(line.TaxableAmount),
+			Amount:        code.Round(line.Amount),
+		})
+	}
+	if !breakdown.Inclusive {
+		order.TotalAmount = code.Round(order.TotalAmount + order.TaxAmount)
+	}
+}
+
+// buildOrderItems validates products and prices each line by calling Product Service via gRPC
+func (s *orderServiceImpl) buildOrderItems(ctx context.Context, items []dto.OrderItemRequest) ([]domain.OrderItem, error) {
+	var orderItems []domain.OrderItem
+
+	for _, item := range items {
+		// Get product info from Product Service
+		product, err := s.productClient.GetProduct(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if product == nil {
+			return nil, ErrProductNotFound
+		}
+		if !product.IsActive {
+			return nil, ErrProductUnavailable
+		}
+
+		// Check stock availability
+		if product.Stock < item.Quantity {
+			return nil, ErrInsufficientStock
+		}
+
+		orderItems = append(orderItems, domain.OrderItem{
+			ProductID:   item.ProductID,
+			Name:        product.Name,
+			Price:       product.Price,
+			Quantity:    item.Quantity,
+			Subtotal:    product.Price * float64(item.Quantity),
+			WeightGrams: product.WeightGrams,
+			CategoryID:  product.CategoryID,
+			TaxCategory: product.TaxCategory,
+		})
+	}
+
+	return orderItems, nil
+}
+
+// convertOrderItems reprices base-currency items in the order currency, given the value of one unit
+// of it in the base currency, and returns the converted shipping cost. Each amount is rounded to the
+// currency's smallest unit before it is summed, so totals add up to what the customer sees.
+func convertOrderItems(items []domain.OrderItem, shippingCost float64, code currency.Code, rate float64) float64 {
+	for i := range items {
+		items[i].Price = code.Round(items[i].Price / rate)
+		items[i].Subtotal = code.Round(items[i].Price * float64(items[i].Quantity))
+		items[i].DiscountAmount = code.Round(items[i].DiscountAmount / rate)
+	}
+	return code.Round(shippingCost / rate)
 }
 
 func (s *orderServiceImpl) GetOrder(ctx context.Context, id uint) (*dto.OrderResponse, error) {
@@ -115,7 +391,7 @@ func (s *orderServiceImpl) GetOrder(ctx context.Context, id uint) (*dto.OrderRes
 		}
 		return nil, err
 	}
-	return s.toOrderResponse(order), nil
+	return toOrderResponse(order), nil
 }
 
 func (s *orderServiceImpl) GetUserOrders(ctx context.Context, userID uint, page, pageSize int) (*dto.OrderListResponse, error) {
@@ -133,7 +409,7 @@ func (s *orderServiceImpl) GetUserOrders(ctx context.Context, userID uint, page,
 
 	orderResponses := make([]dto.OrderResponse, len(orders))
 	for i, order := range orders {
-		orderResponses[i] = *s.toOrderResponse(&order)
+		orderResponses[i] = *toOrderResponse(&order)
 	}
 
 	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
@@ -147,15 +423,57 @@ func (s *orderServiceImpl) GetUserOrders(ctx context.Context, userID uint, page,
 	}, nil
 }
 
+func (s *orderServiceImpl) LastOrderAt(ctx context.Context, userID uint) (*time.Time, error) {
+	orders, _, err := s.orderRepo.FindByUserID(userID, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	return &orders[0].CreatedAt, nil
+}
+
 func (s *orderServiceImpl) UpdateOrderStatus(ctx context.Context, id uint, status domain.OrderStatus) error {
-	_, err := s.orderRepo.FindByID(id)
+	// Payment and shipping need a payment reference or shipment, so staff cannot set them here
+	if !status.IsStaffSettable() {
+		return ErrInvalidOrderStatus
+	}
+
+	order, err := s.orderRepo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrOrderNotFound
 		}
 		return err
 	}
-	return s.orderRepo.UpdateStatus(id, status)
+	if !order.Status.CanStaffSet(status) {
+		return ErrStatusTransition
+	}
+
+	switch status {
+	case domain.OrderStatusCancelled:
+		err = s.cancel(ctx, order, "Cancelled by staff")
+		if errors.Is(err, ErrOrderNotCancelable) {
+			return ErrStatusTransition
+		}
+		return err
+	case domain.OrderStatusDelivered:
+		_, err = s.DeliverOrder(ctx, id)
+		if errors.Is(err, ErrOrderNotShipped) {
+			return ErrStatusTransition
+		}
+		return err
+	default:
+		updated, err := s.orderRepo.BulkUpdateStatus([]uint{id}, order.Status, status)
+		if err != nil {
+			return err
+		}
+		if len(updated) == 0 {
+			return ErrStatusTransition
+		}
+		return nil
+	}
 }
 
 func (s *orderServiceImpl) CancelOrder(ctx context.Context, id uint) error {
@@ -169,32 +487,368 @@ func (s *orderServiceImpl) CancelOrder(ctx context.Context, id uint) error {
 
 	// Only pending orders can be cancelled
 	if order.Status != domain.OrderStatusPending {
-		return errors.New("only pending orders can be cancelled")
+		return ErrOrderNotCancelable
 	}
 
-	return s.orderRepo.UpdateStatus(id, domain.OrderStatusCancelled)
+	return s.cancel(ctx, order, "Cancelled by customer")
+}
+
+func (s *orderServiceImpl) CancelUnpaidOrder(ctx context.Context, id uint, reason string) (*dto.OrderResponse, error) {
+	order, err := s.orderRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	if order.Status == domain.OrderStatusCancelled {
+		if order.ReleasePending {
+			if err := s.release(ctx, order); err != nil {
+				return nil, err
+			}
+		}
+		return toOrderResponse(order), nil
+	}
+	if order.Status != domain.OrderStatusPending {
+		return nil, ErrOrderNotCancelable
+	}
+
+	if err := s.cancel(ctx, order, reason); err != nil {
+		return nil, err
+	}
+	return toOrderResponse(order), nil
+}
+
+func (s *orderServiceImpl) ExpireOrders(ctx context.Context, before time.Time, limit int) (int, error) {
+	var errs []error
+	interrupted, err := s.orderRepo.FindPendingReleases(limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range interrupted {
+		if err := s.release(ctx, &interrupted[i]); err != nil {
+			errs = append(errs, fmt.Errorf("order %d: %w", interrupted[i].ID, err))
+		}
+	}
+
+	orders, err := s.orderRepo.FindPendingBefore(before, limit)
+	if err != nil {
+		return 0, errors.Join(append(errs, err)...)
+	}
+
+	expired := 0
+	for i := range orders {
+		if err := s.cancel(ctx, &orders[i], "Not paid in time"); err != nil {
+			// Keep going; one order shouldn't hold up the rest
+			errs = append(errs, fmt.Errorf("order %d: %w", orders[i].ID, err))
+			continue
+		}
+		expired++
+	}
+	return expired, errors.Join(errs...)
+}
+
+// cancel moves a pending order to cancelled and returns its items to stock.
+// Losing a race to another cancel or a payment is reported as ErrOrderNotCancelable.
+func (s *orderServiceImpl) cancel(ctx context.Context, order *domain.Order, reason string) error {
+	cancelled, err := s.orderRepo.Cancel(order.ID, reason)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrOrderNotCancelable
+	}
+	order.Status = domain.OrderStatusCancelled
+	order.CancelReason = reason
+	order.ReleasePending = true
+
+	return s.release(ctx, order)
+}
+
+// release gives back what a cancelled order held: its coupon use and its stock.
+// Each item is claimed before its stock goes back, so a release that was interrupted
+// can be run again and only puts back what is still outstanding.
+func (s *orderServiceImpl) release(ctx context.Context, order *domain.Order) error {
+	// The coupon can be used again; releasing it twice does nothing
+	if err := s.promotions.Release(order.ID); err != nil {
+		return fmt.Errorf("release coupon: %w", err)
+	}
+
+	// Stock was taken when the order was placed
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.StockReleased {
+			continue
+		}
+		claimed, err := s.orderRepo.ClaimItemRelease(item.ID)
+		if err != nil {
+			return fmt.Errorf("%w: product %d: %v", ErrStockReleaseFailed, item.ProductID, err)
+		}
+		if !claimed {
+			return fmt.Errorf("%w: product %d is being released by another request", ErrStockReleaseFailed, item.ProductID)
+		}
+		if _, err := s.productClient.IncreaseStock(ctx, item.ProductID, item.Quantity); err != nil {
+			if unclaimErr := s.orderRepo.UnclaimItemRelease(item.ID); unclaimErr != nil {
+				err = errors.Join(err, unclaimErr)
+			}
+			return fmt.Errorf("%w: product %d: %v", ErrStockReleaseFailed, item.ProductID, err)
+		}
+		item.StockReleased = true
+	}
+
+	if err := s.orderRepo.FinishRelease(order.ID); err != nil {
+		return err
+	}
+	order.ReleasePending = false
+	return nil
+}
+
+func (s *orderServiceImpl) MarkOrderPaid(ctx context.Context, id uint, transactionID string, amount float64) (*dto.OrderResponse, error) {
+	order, err := s.orderRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	if !order.Status.IsPayable() {
+		return resolvePaid(order, transactionID)
+	}
+	if amount < order.TotalAmount {
+		return nil, ErrPaymentShort
+	}
+
+	paidAt := time.Now()
+	paid, err := s.orderRepo.MarkPaid(order.ID, transactionID, paidAt)
+	if err != nil {
+		return nil, err
+	}
+	if !paid {
+		// The order changed since it was read, e.g. a concurrent cancel or notification.
+		// It has left the payable states, so read it once more to see which way it went.
+		current, err := s.orderRepo.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status.IsPayable() {
+			return nil, ErrOrderNotPayable
+		}
+		return resolvePaid(current, transactionID)
+	}
+
+	order.Status = domain.OrderStatusPaid
+	order.PaymentRef = transactionID
+	order.PaidAt = &paidAt
+	return toOrderResponse(order), nil
+}
+
+// resolvePaid answers a payment for an order that is no longer awaiting one.
+// Payment notifications are retried, so a repeat for the same payment is not an error.
+// Orders moved past payment by staff carry no payment reference.
+func resolvePaid(order *domain.Order, transactionID string) (*dto.OrderResponse, error) {
+	if order.Status != domain.OrderStatusCancelled && (order.PaymentRef == transactionID || order.PaymentRef == "") {
+		return toOrderResponse(order), nil
+	}
+	return nil, ErrOrderNotPayable
+}
+
+func (s *orderServiceImpl) QuoteShipping(ctx context.Context, req *dto.ShippingQuoteRequest) ([]dto.ShippingQuoteResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	orderItems, err := s.buildOrderItems(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]dto.ShippingQuoteResponse, 0, len(s.shippingRates))
+	for _, method := range []domain.ShippingMethod{domain.ShippingMethodStandard, domain.ShippingMethodExpress} {
+		calculator, ok := s.shippingRates[method]
+		if !ok {
+			continue
+		}
+		quotes = append(quotes, dto.ShippingQuoteResponse{
+			Method:      method,
+			Description: calculator.Description(),
+			Cost:        calculator.Calculate(orderItems),
+		})
+	}
+	return quotes, nil
+}
+
+func (s *orderServiceImpl) PreviewDiscount(ctx context.Context, userID uint, code string, items []dto.OrderItemRequest) (*dto.DiscountPreviewResponse, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	orderItems, err := s.buildOrderItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := s.evaluateCoupon(userID, code, orderItems, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DiscountPreviewResponse{
+		Code:         discount.Promotion.Code,
+		Name:         discount.Promotion.Name,
+		Type:         discount.Promotion.Type,
+		ItemsAmount:  discount.ItemsAmount,
+		FreeShipping: discount.Promotion.Type == domain.PromotionTypeFreeShipping,
+	}, nil
+}
+
+func (s *orderServiceImpl) ShipOrder(ctx context.Context, id uint, staffID uint, req *dto.ShipOrderRequest) (*dto.OrderResponse, error) {
+	order, err := s.orderRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	if order.Status != domain.OrderStatusPaid && order.Status != domain.OrderStatusConfirmed {
+		return nil, ErrOrderNotShippable
+	}
+
+	shipment := &domain.Shipment{
+		OrderID:        order.ID,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+		Status:         domain.ShipmentStatusShipped,
+		ShippedBy:      staffID,
+		ShippedAt:      time.Now(),
+	}
+	shipped, err := s.orderRepo.Ship(shipment)
+	if err != nil {
+		return nil, err
+	}
+	if !shipped {
+		// Cancelled or shipped by another request since it was read
+		return nil, ErrOrderNotShippable
+	}
+
+	order.Status = domain.OrderStatusShipped
+	order.Shipments = append(order.Shipments, *shipment)
+	return toOrderResponse(order), nil
+}
+
+func (s *orderServiceImpl) DeliverOrder(ctx context.Context, id uint) (*dto.OrderResponse, error) {
+	order, err := s.orderRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	if order.Status != domain.OrderStatusShipped {
+		return nil, ErrOrderNotShipped
+	}
+
+	now := time.Now()
+	for i := range order.Shipments {
+		shipment := &order.Shipments[i]
+		if shipment.Status == domain.ShipmentStatusDelivered {
+			continue
+		}
+		shipment.Status = domain.ShipmentStatusDelivered
+		shipment.DeliveredAt = &now
+		if err := s.shipmentRepo.Update(shipment); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.orderRepo.UpdateStatus(order.ID, domain.OrderStatusDelivered); err != nil {
+		return nil, err
+	}
+
+	order.Status = domain.OrderStatusDelivered
+	return toOrderResponse(order), nil
 }
 
 // Helper: convert domain.Order to dto.OrderResponse
-func (s *orderServiceImpl) toOrderResponse(order *domain.Order) *dto.OrderResponse {
+func toOrderResponse(order *domain.Order) *dto.OrderResponse {
 	items := make([]dto.OrderItemResponse, len(order.Items))
 	for i, item := range order.Items {
 		items[i] = dto.OrderItemResponse{
-			ID:        item.ID,
-			ProductID: item.ProductID,
-			Name:      item.Name,
-			Price:     item.Price,
-			Quantity:  item.Quantity,
-			Subtotal:  item.Subtotal,
+			ID:             item.ID,
+			ProductID:      item.ProductID,
+			Name:           item.Name,
+			Price:          item.Price,
+			Quantity:       item.Quantity,
+			Subtotal:       item.Subtotal,
+			DiscountAmount: item.DiscountAmount,
+			TaxAmount:      item.TaxAmount,
 		}
 	}
 
-	return &dto.OrderResponse{
-		ID:          order.ID,
-		UserID:      order.UserID,
-		Status:      order.Status,
-		TotalAmount: order.TotalAmount,
-		Items:       items,
-		CreatedAt:   order.CreatedAt.Format(time.RFC3339),
+	var discounts []dto.OrderDiscountResponse
+	for _, discount := range order.Discounts {
+		discounts = append(discounts, dto.OrderDiscountResponse{
+			Code:           discount.Code,
+			Type:           discount.Type,
+			Description:    discount.Description,
+			ItemsAmount:    discount.ItemsAmount,
+			ShippingAmount: discount.ShippingAmount,
+			Amount:         discount.Amount,
+		})
+	}
+
+	var taxes []dto.OrderTaxResponse
+	for _, t := range order.Taxes {
+		taxes = append(taxes, dto.OrderTaxResponse{
+			Name:          t.Name,
+			Rate:          t.Rate,
+			Inclusive:     t.Inclusive,
+			TaxableAmount: t.TaxableAmount,
+			Amount:        t.Amount,
+		})
+	}
+
+	shipments := make([]dto.ShipmentResponse, len(order.Shipments))
+	for i, shipment := range order.Shipments {
+		shipments[i] = dto.ShipmentResponse{
+			ID:             shipment.ID,
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			Status:         shipment.Status,
+			ShippedAt:      shipment.ShippedAt.Format(time.RFC3339),
+		}
+		if shipment.DeliveredAt != nil {
+			shipments[i].DeliveredAt = shipment.DeliveredAt.Format(time.RFC3339)
+		}
+	}
+
+	resp := &dto.OrderResponse{
+		ID:              order.ID,
+		UserID:          order.UserID,
+		Status:          order.Status,
+		Subtotal:        order.Subtotal,
+		ShippingCost:    order.ShippingCost,
+		DiscountAmount:  order.DiscountAmount,
+		CouponCode:      order.CouponCode,
+		Discounts:       discounts,
+		TaxAmount:       order.TaxAmount,
+		TaxInclusive:    order.TaxInclusive,
+		TaxRegion:       order.TaxRegion,
+		Taxes:           taxes,
+		TotalAmount:     order.TotalAmount,
+		Currency:        order.Currency,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingAddress: order.ShippingAddress,
+		Items:           items,
+		Shipments:       shipments,
+		PaymentRef:      order.PaymentRef,
+		CancelReason:    order.CancelReason,
+		CreatedAt:       order.CreatedAt.Format(time.RFC3339),
+	}
+	if order.PaidAt != nil {
+		resp.PaidAt = order.PaidAt.Format(time.RFC3339)
 	}
+	return resp
 }
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/tax"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertOrderItems(t *testing.T) {
+	// Arrange
+	items := []domain.OrderItem{
+		{Price: 850_000, Quantity: 1, Subtotal: 850_000},
+		{Price: 100_000, Quantity: 3, Subtotal: 300_000},
+	}
+
+	// Act: 1 SGD = 11,650 IDR
+	shipping := convertOrderItems(items, 15_000, currency.SGD, 11_650)
+
+	// Assert
+	assert.Equal(t, 72.96, items[0].Price)
+	assert.Equal(t, 72.96, items[0].Subtotal)
+	assert.Equal(t, 8.58, items[1].Price)
+	assert.Equal(t, 25.74, items[1].Subtotal)
+	assert.Equal(t, 1.29, shipping)
+}
+
+func TestConvertOrderItems_BaseCurrency(t *testing.T) {
+	items := []domain.OrderItem{{Price: 99_999.6, Quantity: 2}}
+
+	shipping := convertOrderItems(items, 15_000, currency.IDR, 1)
+
+	assert.Equal(t, 100_000.0, items[0].Price)
+	assert.Equal(t, 200_000.0, items[0].Subtotal)
+	assert.Equal(t, 15_000.0, shipping)
+}
+
+func testTaxTable(t *testing.T) *tax.RateTable {
+	table, err := tax.NewRateTable("ID",
+		tax.Region{Code: "ID", Names: []string{"Indonesia"}, Inclusive: true,
+			Rates: map[tax.Category][]tax.Rate{tax.CategoryStandard: {{Name: "PPN", Rate: 0.11}}}},
+		tax.Region{Code: "MY", Names: []string{"Malaysia"},
+			Rates: map[tax.Category][]tax.Rate{tax.CategoryStandard: {{Name: "SST", Rate: 0.10}}}},
+	)
+	require.NoError(t, err)
+	return table
+}
+
+func TestApplyTax_Inclusive(t *testing.T) {
+	// Arrange: 111.000 of goods after a 11.000 discount, exempt goods, and 22.200 shipping
+	order := &domain.Order{
+		Subtotal:     150_000,
+		ShippingCost: 22_200,
+		TotalAmount:  161_200,
+		Items: []domain.OrderItem{
+			{Quantity: 2, Subtotal: 122_000, DiscountAmount: 11_000},
+			{Quantity: 1, Subtotal: 28_000, TaxCategory: "exempt"},
+		},
+		Discounts:       []domain.OrderDiscount{{ItemsAmount: 11_000}},
+		ShippingAddress: domain.ShippingAddress{Country: "Indonesia"},
+	}
+
+	// Act
+	breakdown, err := testTaxTable(t).Calculate(order.ShippingAddress.Country, orderTaxLines(order), currency.IDR)
+	require.NoError(t, err)
+	applyTax(order, breakdown, currency.IDR)
+
+	// Assert: PPN is carved out of the prices, so the total is unchanged
+	assert.Equal(t, "ID", order.TaxRegion)
+	assert.True(t, order.TaxInclusive)
+	assert.Equal(t, 13_200.0, order.TaxAmount)
+	assert.Equal(t, 161_200.0, order.TotalAmount)
+	assert.Equal(t, 11_000.0, order.Items[0].TaxAmount)
+	assert.Zero(t, order.Items[1].TaxAmount)
+	assert.Equal(t, []domain.OrderTax{{Name: "PPN", Rate: 0.11, Inclusive: true, TaxableAmount: 120_000, Amount: 13_200}}, order.Taxes)
+}
+
+func TestApplyTax_Exclusive(t *testing.T) {
+	// Arrange: shipping is free with the coupon
+	order := &domain.Order{
+		Subtotal:     200,
+		ShippingCost: 15,
+		TotalAmount:  200,
+		Items:        []domain.OrderItem{{Quantity: 4, Subtotal: 200}},
+		Discounts:    []domain.OrderDiscount{{ShippingAmount: 15, Amount: 15}},
+	}
+
+	// Act
+	breakdown, err := testTaxTable(t).Calculate("MY", orderTaxLines(order), currency.MYR)
+	require.NoError(t, err)
+	applyTax(order, breakdown, currency.MYR)
+
+	// Assert: SST is added to the total
+	assert.False(t, order.TaxInclusive)
+	assert.Equal(t, 20.0, order.TaxAmount)
+	assert.Equal(t, 220.0, order.TotalAmount)
+	assert.Equal(t, 20.0, order.Items[0].TaxAmount)
+}
+
+// fakeReleaseStore keeps one order and its release claims in memory
+type fakeReleaseStore struct {
+	repository.OrderRepository
+	order *domain.Order
+}
+
+func (f *fakeReleaseStore) Cancel(id uint, reason string) (bool, error) {
+	if f.order.Status != domain.OrderStatusPending {
+		return false, nil
+	}
+	f.order.Status = domain.OrderStatusCancelled
+	f.order.CancelReason = reason
+	f.order.ReleasePending = true
+	return true, nil
+}
+
+func (f *fakeReleaseStore) FindPendingReleases(limit int) ([]domain.Order, error) {
+	if !f.order.ReleasePending {
+		return nil, nil
+	}
+	copied := *f.order
+	copied.Items = append([]domain.OrderItem(nil), f.order.Items...)
+	return []domain.Order{copied}, nil
+}
+
+func (f *fakeReleaseStore) FindPendingBefore(before time.Time, limit int) ([]domain.Order, error) {
+	return nil, nil
+}
+
+func (f *fakeReleaseStore) item(itemID uint) *domain.OrderItem {
+	for i := range f.order.Items {
+		if f.order.Items[i].ID == itemID {
+			return &f.order.Items[i]
+		}
+	}
+	return nil
+}
+
+func (f *fakeReleaseStore) ClaimItemRelease(itemID uint) (bool, error) {
+	item := f.item(itemID)
+	if item.StockReleased {
+		return false, nil
+	}
+	item.StockReleased = true
+	return true, nil
+}
+
+func (f *fakeReleaseStore) UnclaimItemRelease(itemID uint) error {
+	f.item(itemID).StockReleased = false
+	return nil
+}
+
+func (f *fakeReleaseStore) FinishRelease(id uint) error {
+	f.order.ReleasePending = false
+	return nil
+}
+
+// fakeCoupons counts coupon releases
+type fakeCoupons struct {
+	repository.PromotionRepository
+	released int
+}
+
+func (f *fakeCoupons) Release(orderID uint) error {
+	f.released++
+	return nil
+}
+
+// fakeCatalog puts stock back through fakeStock
+type fakeCatalog struct {
+	ProductCatalog
+	*fakeStock
+}
+
+func (f fakeCatalog) IncreaseStock(ctx context.Context, productID uint, quantity int) (int, error) {
+	return f.fakeStock.IncreaseStock(ctx, productID, quantity)
+}
+
+func TestOrderService_Cancel_ExpiryResumesInterruptedRelease(t *testing.T) {
+	// Arrange
+	store := &fakeReleaseStore{order: &domain.Order{
+		ID:     1,
+		Status: domain.OrderStatusPending,
+		Items: []domain.OrderItem{
+			{ID: 11, ProductID: 10, Quantity: 2},
+			{ID: 12, ProductID: 20, Quantity: 1},
+		},
+	}}
+	stock := &fakeStock{added: map[uint]int{}, failing: map[uint]bool{20: true}}
+	coupons := &fakeCoupons{}
+	svc := &orderServiceImpl{orderRepo: store, promotions: coupons, productClient: fakeCatalog{fakeStock: stock}}
+	ctx := context.Background()
+
+	// Act: the second item fails, then the product service recovers before the next expiry run
+	order := *store.order
+	order.Items = append([]domain.OrderItem(nil), store.order.Items...)
+	err := svc.cancel(ctx, &order, "Cancelled by customer")
+	require.ErrorIs(t, err, ErrStockReleaseFailed)
+	assert.Equal(t, domain.OrderStatusCancelled, store.order.Status)
+	assert.True(t, store.order.ReleasePending)
+
+	stock.failing = nil
+	_, err = svc.ExpireOrders(ctx, time.Now(), 10)
+
+	// Assert: each item went back exactly once and nothing is left to resume
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int{10: 2, 20: 1}, stock.added)
+	assert.False(t, store.order.ReleasePending)
+	assert.Equal(t, 2, coupons.released)
+
+	_, err = svc.ExpireOrders(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int{10: 2, 20: 1}, stock.added)
+}
+
+// racedPaymentStore loses every MarkPaid, as if another request changed the order first
+type racedPaymentStore struct {
+	repository.OrderRepository
+	reads     []*domain.Order
+	markCalls int
+}
+
+func (f *racedPaymentStore) FindByID(id uint) (*domain.Order, error) {
+	order := f.reads[0]
+	if len(f.reads) > 1 {
+		f.reads = f.reads[1:]
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (f *racedPaymentStore) MarkPaid(id uint, paymentRef string, paidAt time.Time) (bool, error) {
+	f.markCalls++
+	return false, nil
+}
+
+func TestOrderService_MarkOrderPaid_LosesConditionalUpdate(t *testing.T) {
+	pending := &domain.Order{ID: 1, Status: domain.OrderStatusPending, TotalAmount: 100}
+	tests := []struct {
+		name    string
+		after   *domain.Order
+		wantErr error
+	}{
+		{"same payment won", &domain.Order{ID: 1, Status: domain.OrderStatusPaid, PaymentRef: "txn-1"}, nil},
+		{"another payment won", &domain.Order{ID: 1, Status: domain.OrderStatusPaid, PaymentRef: "txn-2"}, ErrOrderNotPayable},
+		{"cancelled meanwhile", &domain.Order{ID: 1, Status: domain.OrderStatusCancelled}, ErrOrderNotPayable},
+		{"still payable", pending, ErrOrderNotPayable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			store := &racedPaymentStore{reads: []*domain.Order{pending, tt.after}}
+			svc := &orderServiceImpl{orderRepo: store}
+
+			// Act
+			resp, err := svc.MarkOrderPaid(context.Background(), 1, "txn-1", 100)
+
+			// Assert: one attempt, then the order is settled from a single re-read
+			assert.Equal(t, 1, store.markCalls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, domain.OrderStatusPaid, resp.Status)
+		})
+	}
+}
+
+// racedShipmentStore has the order cancelled between the read and the shipment
+type racedShipmentStore struct {
+	repository.OrderRepository
+}
+
+func (f *racedShipmentStore) FindByID(id uint) (*domain.Order, error) {
+	return &domain.Order{ID: id, Status: domain.OrderStatusPaid}, nil
+}
+
+func (f *racedShipmentStore) Ship(shipment *domain.Shipment) (bool, error) {
+	return false, nil
+}
+
+func TestOrderService_ShipOrder_LosesToConcurrentCancel(t *testing.T) {
+	// Arrange
+	store := &racedShipmentStore{}
+	svc := &orderServiceImpl{orderRepo: store}
+
+	// Act
+	_, err := svc.ShipOrder(context.Background(), 1, 5, &dto.ShipOrderRequest{Carrier: "JNE", TrackingNumber: "JNE123"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrOrderNotShippable)
+}
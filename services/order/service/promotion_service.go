@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPromotionNotFound = errors.New("promotion not found")
+	ErrInvalidPromotion  = errors.New("invalid promotion")
+	ErrCouponCodeTaken   = errors.New("coupon code already in use")
+)
+
+// PromotionService defines the interface for managing promotions
+type PromotionService interface {
+	CreatePromotion(req *dto.PromotionRequest) (*dto.PromotionResponse, error)
+	GetPromotion(id uint) (*dto.PromotionResponse, error)
+	GetPromotions(page, pageSize int) (*dto.PromotionListResponse, error)
+	UpdatePromotion(id uint, req *dto.PromotionRequest) (*dto.PromotionResponse, error)
+}
+
+type promotionServiceImpl struct {
+	promotions repository.PromotionRepository
+}
+
+// NewPromotionService creates a new instance of PromotionService
+func NewPromotionService(promotions repository.PromotionRepository) PromotionService {
+	return &promotionServiceImpl{promotions: promotions}
+}
+
+func (s *promotionServiceImpl) CreatePromotion(req *dto.PromotionRequest) (*dto.PromotionResponse, error) {
+	promotion := &domain.Promotion{IsActive: true}
+	if err := applyPromotionRequest(promotion, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeFree(promotion.Code, 0); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotions.Create(promotion); err != nil {
+		return nil, err
+	}
+	return toPromotionResponse(promotion), nil
+}
+
+func (s *promotionServiceImpl) GetPromotion(id uint) (*dto.PromotionResponse, error) {
+	promotion, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return toPromotionResponse(promotion), nil
+}
+
+func (s *promotionServiceImpl) GetPromotions(page, pageSize int) (*dto.PromotionListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	promotions, total, err := s.promotions.FindAll(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PromotionResponse, len(promotions))
+	for i := range promotions {
+		responses[i] = *toPromotionResponse(&promotions[i])
+	}
+	return &dto.PromotionListResponse{
+		Promotions: responses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *promotionServiceImpl) UpdatePromotion(id uint, req *dto.PromotionRequest) (*dto.PromotionResponse, error) {
+	promotion, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPromotionRequest(promotion, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeFree(promotion.Code, promotion.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotions.Update(promotion); err != nil {
+		return nil, err
+	}
+	return toPromotionResponse(promotion), nil
+}
+
+func (s *promotionServiceImpl) find(id uint) (*domain.Promotion, error) {
+	promotion, err := s.promotions.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// checkCodeFree fails if another promotion than the one with id uses code
+func (s *promotionServiceImpl) checkCodeFree(code string, id uint) error {
+	existing, err := s.promotions.FindByCode(code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != id {
+		return ErrCouponCodeTaken
+	}
+	return nil
+}
+
+// applyPromotionRequest validates the request and copies it onto the promotion
+func applyPromotionRequest(promotion *domain.Promotion, req *dto.PromotionRequest) error {
+	switch req.Type {
+	case domain.PromotionTypePercentage:
+		if req.Value <= 0 || req.Value > 100 {
+			return fmt.Errorf("%w: percentage must be above 0 and at most 100", ErrInvalidPromotion)
+		}
+	case domain.PromotionTypeFixed:
+		if req.Value <= 0 {
+			return fmt.Errorf("%w: fixed amount must be above 0", ErrInvalidPromotion)
+		}
+	case domain.PromotionTypeBuyXGetY:
+		if req.BuyQuantity < 1 || req.GetQuantity < 1 {
+			return fmt.Errorf("%w: buy and get quantities must be at least 1", ErrInvalidPromotion)
+		}
+	case domain.PromotionTypeFreeShipping:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidPromotion, req.Type)
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+
+	promotion.Code = domain.NormalizeCouponCode(req.Code)
+	promotion.Name = req.Name
+	promotion.Type = req.Type
+	promotion.Value = req.Value
+	promotion.MaxDiscount = req.MaxDiscount
+	promotion.BuyQuantity = req.BuyQuantity
+	promotion.GetQuantity = req.GetQuantity
+	promotion.MinSpend = req.MinSpend
+	promotion.UsageLimit = req.UsageLimit
+	promotion.PerUserLimit = req.PerUserLimit
+	promotion.StartsAt = req.StartsAt
+	promotion.EndsAt = req.EndsAt
+	promotion.ProductIDs = req.ProductIDs
+	promotion.CategoryIDs = req.CategoryIDs
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+func toPromotionResponse(p *domain.Promotion) *dto.PromotionResponse {
+	resp := &dto.PromotionResponse{
+		ID:           p.ID,
+		Code:         p.Code,
+		Name:         p.Name,
+		Type:         p.Type,
+		Value:        p.Value,
+		MaxDiscount:  p.MaxDiscount,
+		BuyQuantity:  p.BuyQuantity,
+		GetQuantity:  p.GetQuantity,
+		MinSpend:     p.MinSpend,
+		UsageLimit:   p.UsageLimit,
+		PerUserLimit: p.PerUserLimit,
+		UsedCount:    p.UsedCount,
+		ProductIDs:   p.ProductIDs,
+		CategoryIDs:  p.CategoryIDs,
+		IsActive:     p.IsActive,
+		CreatedAt:    p.CreatedAt.Format(time.RFC3339),
+	}
+	if p.StartsAt != nil {
+		resp.StartsAt = p.StartsAt.Format(time.RFC3339)
+	}
+	if p.EndsAt != nil {
+		resp.EndsAt = p.EndsAt.Format(time.RFC3339)
+	}
+	return resp
+}
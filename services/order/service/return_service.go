@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/client"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReturnNotFound      = errors.New("return request not found")
+	ErrOrderNotReturnable  = errors.New("only delivered orders can be returned")
+	ErrInvalidReturnItem   = errors.New("item does not belong to this order")
+	ErrReturnQuantity      = errors.New("return quantity exceeds the quantity still returnable")
+	ErrInvalidReturnStatus = errors.New("invalid return status transition")
+	ErrReturnRestockFailed = errors.New("failed to restock returned items")
+	ErrReturnRefundFailed  = errors.New("failed to refund returned items")
+)
+
+// ReturnService defines the interface for the returns (RMA) workflow
+type ReturnService interface {
+	RequestReturn(ctx context.Context, userID, orderID uint, req *dto.CreateReturnRequest) (*dto.ReturnResponse, error)
+	GetReturn(ctx context.Context, id uint) (*dto.ReturnResponse, error)
+	GetUserReturns(ctx context.Context, userID uint, page, pageSize int) (*dto.ReturnListResponse, error)
+	ListReturns(ctx context.Context, status domain.ReturnStatus, page, pageSize int) (*dto.ReturnListResponse, error)
+	CancelReturn(ctx context.Context, userID, id uint) (*dto.ReturnResponse, error)
+
+	// Staff actions
+	ApproveReturn(ctx context.Context, staffID, id uint, note string) (*dto.ReturnResponse, error)
+	RejectReturn(ctx context.Context, staffID, id uint, note string) (*dto.ReturnResponse, error)
+	ReceiveReturn(ctx context.Context, staffID, id uint, note string) (*dto.ReturnResponse, error)
+	RetryRefund(ctx context.Context, staffID, id uint) (*dto.ReturnResponse, error)
+}
+
+// StockRestorer puts returned items back into stock (implemented by client.ProductClient)
+type StockRestorer interface {
+	IncreaseStock(ctx context.Context, productID uint, quantity int) (int, error)
+}
+
+type returnServiceImpl struct {
+	returnRepo    repository.ReturnRepository
+	orderRepo     repository.OrderRepository
+	stock         StockRestorer
+	paymentClient *client.PaymentClient
+}
+
+// NewReturnService creates a new instance of ReturnService
+func NewReturnService(
+	returnRepo repository.ReturnRepository,
+	orderRepo repository.OrderRepository,
+	stock StockRestorer,
+	paymentClient *client.PaymentClient,
+) ReturnService {
+	return &returnServiceImpl{
+		returnRepo:    returnRepo,
+		orderRepo:     orderRepo,
+		stock:         stock,
+		paymentClient: paymentClient,
+	}
+}
+
+func (s *returnServiceImpl) RequestReturn(ctx context.Context, userID, orderID uint, req *dto.CreateReturnRequest) (*dto.ReturnResponse, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	if order.Status != domain.OrderStatusDelivered {
+		return nil, ErrOrderNotReturnable
+	}
+
+	returnable, err := s.returnableQuantities(order)
+	if err != nil {
+		return nil, err
+	}
+
+	orderItems := make(map[uint]domain.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		orderItems[item.ID] = item
+	}
+
+	var items []domain.ReturnItem
+	var refundAmount float64
+	for _, reqItem := range req.Items {
+		orderItem, ok := orderItems[reqItem.OrderItemID]
+		if !ok {
+			return nil, ErrInvalidReturnItem
+		}
+		if reqItem.Quantity > returnable[orderItem.ID] {
+			return nil, ErrReturnQuantity
+		}
+		// Guard against the same item listed twice in one request
+		returnable[orderItem.ID] -= reqItem.Quantity
+
+		// Refund what was paid for the units, net of the coupon discount they got and with any
+		// tax that was added on top
+		netUnitPrice := orderItem.NetUnitPrice()
+		if !order.TaxInclusive && orderItem.Quantity > 0 {
+			netUnitPrice += orderItem.TaxAmount / float64(orderItem.Quantity)
+		}
+		unitPrice := currency.Code(order.Currency).Round(netUnitPrice)
+		subtotal := unitPrice * float64(reqItem.Quantity)
+		items = append(items, domain.ReturnItem{
+			OrderItemID: orderItem.ID,
+			ProductID:   orderItem.ProductID,
+			Name:        orderItem.Name,
+			UnitPrice:   unitPrice,
+			Quantity:    reqItem.Quantity,
+			Subtotal:    subtotal,
+		})
+		refundAmount += subtotal
+	}
+
+	ret := &domain.ReturnRequest{
+		OrderID:      order.ID,
+		UserID:       userID,
+		Status:       domain.ReturnStatusRequested,
+		Reason:       req.Reason,
+		RefundAmount: refundAmount,
+		Items:        items,
+		History: []domain.ReturnEvent{{
+			ToStatus: domain.ReturnStatusRequested,
+			ActorID:  userID,
+			Note:     req.Reason,
+		}},
+	}
+
+	// The check above is repeated under a lock on the order, in case another return got in first
+	created, err := s.returnRepo.Create(ret)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrReturnQuantity
+	}
+
+	return toReturnResponse(ret), nil
+}
+
+func (s *returnServiceImpl) GetReturn(ctx context.Context, id uint) (*dto.ReturnResponse, error) {
+	ret, err := s.findReturn(id)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnResponse(ret), nil
+}
+
+func (s *returnServiceImpl) GetUserReturns(ctx context.Context, userID uint, page, pageSize int) (*dto.ReturnListResponse, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	returns, total, err := s.returnRepo.FindByUserID(userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnListResponse(returns, total, page, pageSize), nil
+}
+
+func (s *returnServiceImpl) ListReturns(ctx context.Context, status domain.ReturnStatus, page, pageSize int) (*dto.ReturnListResponse, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	returns, total, err := s.returnRepo.FindByStatus(status, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return toReturnListResponse(returns, total, page, pageSize), nil
+}
+
+func (s *returnServiceImpl) CancelReturn(ctx context.Context, userID, id uint) (*dto.ReturnResponse, error) {
+	ret, err := s.findReturn(id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.UserID != userID {
+		return nil, ErrReturnNotFound
+	}
+	if err := s.transition(ret, domain.ReturnStatusCancelled, userID, "Cancelled by customer"); err != nil {
+		return nil, err
+	}
+	return toReturnResponse(ret), nil
+}
+
+func (s *returnServiceImpl) ApproveReturn(ctx context.Context, staffID, id uint, note string) (*dto.ReturnResponse, error) {
+	return s.review(id, domain.ReturnStatusApproved, staffID, note)
+}
+
+func (s *returnServiceImpl) RejectReturn(ctx context.Context, staffID, id uint, note string) (*dto.ReturnResponse, error) {
+	return s.review(id, domain.ReturnStatusRejected, staffID, note)
+}
+
+// ReceiveReturn marks the parcel as received, puts the items back in stock and refunds them
+func (s *returnServiceImpl) ReceiveReturn(ctx context.Context, staffID, id uint, note string) (*dto.ReturnResponse, error) {
+	ret, err := s.findReturn(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(ret, domain.ReturnStatusReceived, staffID, note); err != nil {
+		return nil, err
+	}
+
+	if err := s.restock(ctx, ret, staffID); err != nil {
+		return toReturnResponse(ret), err
+	}
+	if err := s.refund(ctx, ret, staffID); err != nil {
+		return toReturnResponse(ret), err
+	}
+	return toReturnResponse(ret), nil
+}
+
+// RetryRefund re-runs the restock and refund steps for a received return that failed halfway
+func (s *returnServiceImpl) RetryRefund(ctx context.Context, staffID, id uint) (*dto.ReturnResponse, error) {
+	ret, err := s.findReturn(id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status != domain.ReturnStatusReceived {
+		return nil, ErrInvalidReturnStatus
+	}
+
+	if err := s.restock(ctx, ret, staffID); err != nil {
+		return toReturnResponse(ret), err
+	}
+	if err := s.refund(ctx, ret, staffID); err != nil {
+		return toReturnResponse(ret), err
+	}
+	return toReturnResponse(ret), nil
+}
+
+func (s *returnServiceImpl) review(id uint, next domain.ReturnStatus, staffID uint, note string) (*dto.ReturnResponse, error) {
+	ret, err := s.findReturn(id)
+	if err != nil {
+		return nil, err
+	}
+	ret.StaffNote = note
+	if err := s.transition(ret, next, staffID, note); err != nil {
+		return nil, err
+	}
+	return toReturnResponse(ret), nil
+}
+
+func (s *returnServiceImpl) restock(ctx context.Context, ret *domain.ReturnRequest, actorID uint) error {
+	if ret.Restocked {
+		return nil
+	}
+	// Each item is claimed before its stock is put back, so neither a retry after a failure nor
+	// a concurrent call restocks it twice
+	for i := range ret.Items {
+		item := &ret.Items[i]
+		if item.Restocked {
+			continue
+		}
+		claimed, err := s.returnRepo.ClaimItemRestock(item.ID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrReturnRestockFailed, err)
+		}
+		if !claimed {
+			return fmt.Errorf("%w: product %d is being restocked by another request", ErrReturnRestockFailed, item.ProductID)
+		}
+		if _, err := s.stock.IncreaseStock(ctx, item.ProductID, item.Quantity); err != nil {
+			if releaseErr := s.returnRepo.ReleaseItemRestock(item.ID); releaseErr != nil {
+				err = errors.Join(err, releaseErr)
+			}
+			s.recordNote(ret, actorID, fmt.Sprintf("Restock failed for product %d: %v", item.ProductID, err))
+			return fmt.Errorf("%w: %v", ErrReturnRestockFailed, err)
+		}
+		item.Restocked = true
+	}
+	ret.Restocked = true
+	s.recordNote(ret, actorID, "Items restocked")
+	return nil
+}
+
+func (s *returnServiceImpl) refund(ctx context.Context, ret *domain.ReturnRequest, actorID uint) error {
+	reason := fmt.Sprintf("Return #%d", ret.ID)
+	reference := fmt.Sprintf("return-%d", ret.ID)
+	if _, err := s.paymentClient.RefundOrder(ctx, ret.OrderID, ret.RefundAmount, reason, reference); err != nil {
+		s.recordNote(ret, actorID, "Refund failed: "+err.Error())
+		return fmt.Errorf("%w: %v", ErrReturnRefundFailed, err)
+	}
+	return s.transition(ret, domain.ReturnStatusRefunded, actorID, fmt.Sprintf("Refund of %.2f requested (%s)", ret.RefundAmount, reference))
+}
+
+// transition validates and persists a status change together with its history entry
+func (s *returnServiceImpl) transition(ret *domain.ReturnRequest, next domain.ReturnStatus, actorID uint, note string) error {
+	if !ret.CanTransitionTo(next) {
+		return ErrInvalidReturnStatus
+	}
+
+	event := &domain.ReturnEvent{
+		FromStatus: ret.Status,
+		ToStatus:   next,
+		ActorID:    actorID,
+		Note:       note,
+		CreatedAt:  time.Now(),
+	}
+	ret.Status = next
+	saved, err := s.returnRepo.Transition(ret, event)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		// Someone else moved the return on since it was read
+		ret.Status = event.FromStatus
+		return ErrInvalidReturnStatus
+	}
+	ret.History = append(ret.History, *event)
+	return nil
+}
+
+// recordNote appends a history entry without changing the status.
+// Failures here are not fatal; the note is informational.
+func (s *returnServiceImpl) recordNote(ret *domain.ReturnRequest, actorID uint, note string) {
+	event := &domain.ReturnEvent{
+		FromStatus: ret.Status,
+		ToStatus:   ret.Status,
+		ActorID:    actorID,
+		Note:       note,
+		CreatedAt:  time.Now(),
+	}
+	if saved, err := s.returnRepo.Transition(ret, event); err == nil && saved {
+		ret.History = append(ret.History, *event)
+	}
+}
+
+// returnableQuantities returns, per order item, how many units are not yet covered by an open return
+func (s *returnServiceImpl) returnableQuantities(order *domain.Order) (map[uint]int, error) {
+	returnable := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		returnable[item.ID] = item.Quantity
+	}
+
+	existing, err := s.returnRepo.FindByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ret := range existing {
+		if !ret.IsOpen() {
+			continue
+		}
+		for _, item := range ret.Items {
+			returnable[item.OrderItemID] -= item.Quantity
+		}
+	}
+	return returnable, nil
+}
+
+func (s *returnServiceImpl) findReturn(id uint) (*domain.ReturnRequest, error) {
+	ret, err := s.returnRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return ret, nil
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
+
+func toReturnListResponse(returns []domain.ReturnRequest, total int64, page, pageSize int) *dto.ReturnListResponse {
+	responses := make([]dto.ReturnResponse, len(returns))
+	for i, ret := range returns {
+		responses[i] = *toReturnResponse(&ret)
+	}
+
+	return &dto.ReturnListResponse{
+		Returns:    responses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}
+}
+
+func toReturnResponse(ret *domain.ReturnRequest) *dto.ReturnResponse {
+	items := make([]dto.ReturnItemResponse, len(ret.Items))
+	for i, item := range ret.Items {
+		items[i] = dto.ReturnItemResponse{
+			OrderItemID: item.OrderItemID,
+			ProductID:   item.ProductID,
+			Name:        item.Name,
+			UnitPrice:   item.UnitPrice,
+			Quantity:    item.Quantity,
+			Subtotal:    item.Subtotal,
+			Restocked:   item.Restocked,
+		}
+	}
+
+	history := make([]dto.ReturnEventResponse, len(ret.History))
+	for i, event := range ret.History {
+		history[i] = dto.ReturnEventResponse{
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			ActorID:    event.ActorID,
+			Note:       event.Note,
+			CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &dto.ReturnResponse{
+		ID:           ret.ID,
+		OrderID:      ret.OrderID,
+		UserID:       ret.UserID,
+		Status:       ret.Status,
+		Reason:       ret.Reason,
+		StaffNote:    ret.StaffNote,
+		RefundAmount: ret.RefundAmount,
+		Restocked:    ret.Restocked,
+		Items:        items,
+		History:      history,
+		CreatedAt:    ret.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReturnStore keeps the restock claims and status of one return in memory
+type fakeReturnStore struct {
+	repository.ReturnRepository
+	status  domain.ReturnStatus
+	claimed map[uint]bool
+}
+
+func newFakeReturnStore(status domain.ReturnStatus) *fakeReturnStore {
+	return &fakeReturnStore{status: status, claimed: map[uint]bool{}}
+}
+
+func (f *fakeReturnStore) ClaimItemRestock(itemID uint) (bool, error) {
+	if f.claimed[itemID] {
+		return false, nil
+	}
+	f.claimed[itemID] = true
+	return true, nil
+}
+
+func (f *fakeReturnStore) ReleaseItemRestock(itemID uint) error {
+	delete(f.claimed, itemID)
+	return nil
+}
+
+func (f *fakeReturnStore) Transition(ret *domain.ReturnRequest, event *domain.ReturnEvent) (bool, error) {
+	if f.status != event.FromStatus {
+		return false, nil
+	}
+	f.status = ret.Status
+	return true, nil
+}
+
+// fakeStock adds stock per product and fails for products listed in failing
+type fakeStock struct {
+	added   map[uint]int
+	failing map[uint]bool
+}
+
+func (f *fakeStock) IncreaseStock(ctx context.Context, productID uint, quantity int) (int, error) {
+	if f.failing[productID] {
+		return 0, errors.New("product service unavailable")
+	}
+	f.added[productID] += quantity
+	return f.added[productID], nil
+}
+
+func TestReturnService_Restock_RetryOnlyAddsOutstandingItems(t *testing.T) {
+	// Arrange
+	store := newFakeReturnStore(domain.ReturnStatusReceived)
+	stock := &fakeStock{added: map[uint]int{}, failing: map[uint]bool{20: true}}
+	svc := &returnServiceImpl{returnRepo: store, stock: stock}
+	ret := &domain.ReturnRequest{
+		ID:     1,
+		Status: domain.ReturnStatusReceived,
+		Items: []domain.ReturnItem{
+			{ID: 11, ProductID: 10, Quantity: 2},
+			{ID: 12, ProductID: 20, Quantity: 1},
+		},
+	}
+	ctx := context.Background()
+
+	// Act: the second item fails, then the product service recovers
+	err := svc.restock(ctx, ret, 5)
+	require.ErrorIs(t, err, ErrReturnRestockFailed)
+	assert.False(t, ret.Restocked)
+
+	stock.failing = nil
+	err = svc.restock(ctx, ret, 5)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, ret.Restocked)
+	assert.Equal(t, map[uint]int{10: 2, 20: 1}, stock.added)
+	assert.Equal(t, map[uint]bool{11: true, 12: true}, store.claimed)
+}
+
+func TestReturnService_Restock_LeavesItemsClaimedElsewhere(t *testing.T) {
+	// Arrange: a concurrent call has already claimed the item, though this copy was read before
+	store := newFakeReturnStore(domain.ReturnStatusReceived)
+	store.claimed[11] = true
+	stock := &fakeStock{added: map[uint]int{}}
+	svc := &returnServiceImpl{returnRepo: store, stock: stock}
+	ret := &domain.ReturnRequest{
+		ID:     1,
+		Status: domain.ReturnStatusReceived,
+		Items:  []domain.ReturnItem{{ID: 11, ProductID: 10, Quantity: 2}},
+	}
+
+	// Act
+	err := svc.restock(context.Background(), ret, 5)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrReturnRestockFailed)
+	assert.Empty(t, stock.added)
+}
+
+func TestReturnService_Transition_LosesToConcurrentChange(t *testing.T) {
+	// Arrange: two staff members load the same approved return
+	store := newFakeReturnStore(domain.ReturnStatusApproved)
+	svc := &returnServiceImpl{returnRepo: store}
+	first := &domain.ReturnRequest{ID: 1, Status: domain.ReturnStatusApproved}
+	second := &domain.ReturnRequest{ID: 1, Status: domain.ReturnStatusApproved}
+
+	// Act
+	firstErr := svc.transition(first, domain.ReturnStatusReceived, 5, "")
+	secondErr := svc.transition(second, domain.ReturnStatusReceived, 6, "")
+
+	// Assert: only the first receives it, so only the first goes on to restock
+	require.NoError(t, firstErr)
+	assert.ErrorIs(t, secondErr, ErrInvalidReturnStatus)
+	assert.Equal(t, domain.ReturnStatusApproved, second.Status)
+}
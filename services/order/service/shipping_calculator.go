@@ -0,0 +1,74 @@
+package service
+
+import (
+	"math"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+)
+
+// ShippingCalculator computes the shipping cost for a set of order items
+type ShippingCalculator interface {
+	Calculate(items []domain.OrderItem) float64
+	Description() string
+}
+
+// ShippingRates maps each shipping method to the calculator that prices it
+type ShippingRates map[domain.ShippingMethod]ShippingCalculator
+
+// DefaultShippingRates returns the built-in rate table
+func DefaultShippingRates() ShippingRates {
+	return ShippingRates{
+		domain.ShippingMethodStandard: &FlatRateCalculator{Rate: 15000, FreeOver: 500000},
+		domain.ShippingMethodExpress:  &WeightBasedCalculator{BaseRate: 10000, PerKgRate: 12000},
+	}
+}
+
+// FlatRateCalculator charges a fixed amount per order,
+// optionally waived when the item subtotal reaches FreeOver
+type FlatRateCalculator struct {
+	Rate     float64
+	FreeOver float64 // 0 disables free shipping
+}
+
+func (c *FlatRateCalculator) Calculate(items []domain.OrderItem) float64 {
+	if c.FreeOver > 0 && itemsSubtotal(items) >= c.FreeOver {
+		return 0
+	}
+	return c.Rate
+}
+
+func (c *FlatRateCalculator) Description() string {
+	return "Flat rate"
+}
+
+// WeightBasedCalculator charges a base rate plus a per-kilogram rate.
+// Weight is rounded up to the next whole kilogram with a minimum of 1 kg, like most couriers do.
+type WeightBasedCalculator struct {
+	BaseRate  float64
+	PerKgRate float64
+}
+
+func (c *WeightBasedCalculator) Calculate(items []domain.OrderItem) float64 {
+	grams := 0
+	for _, item := range items {
+		grams += item.WeightGrams * item.Quantity
+	}
+
+	kg := math.Ceil(float64(grams) / 1000)
+	if kg < 1 {
+		kg = 1
+	}
+	return c.BaseRate + c.PerKgRate*kg
+}
+
+func (c *WeightBasedCalculator) Description() string {
+	return "Weight based"
+}
+
+func itemsSubtotal(items []domain.OrderItem) float64 {
+	var subtotal float64
+	for _, item := range items {
+		subtotal += item.Subtotal
+	}
+	return subtotal
+}
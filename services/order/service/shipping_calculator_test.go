@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/order/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatRateCalculator_Calculate(t *testing.T) {
+	calc := &FlatRateCalculator{Rate: 15000, FreeOver: 500000}
+
+	items := []domain.OrderItem{{Price: 100000, Quantity: 2, Subtotal: 200000}}
+	assert.Equal(t, 15000.0, calc.Calculate(items))
+
+	items = []domain.OrderItem{{Price: 250000, Quantity: 2, Subtotal: 500000}}
+	assert.Equal(t, 0.0, calc.Calculate(items))
+}
+
+func TestWeightBasedCalculator_Calculate(t *testing.T) {
+	calc := &WeightBasedCalculator{BaseRate: 10000, PerKgRate: 12000}
+
+	// 2 x 700g = 1.4kg, rounded up to 2kg
+	items := []domain.OrderItem{{Quantity: 2, WeightGrams: 700}}
+	assert.Equal(t, 34000.0, calc.Calculate(items))
+
+	// Weightless items are still charged the 1kg minimum
+	items = []domain.OrderItem{{Quantity: 1, WeightGrams: 0}}
+	assert.Equal(t, 22000.0, calc.Calculate(items))
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "github.com/herman-xphp/go-microservices-ecommerce/proto/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// AuthClient wraps the gRPC client for Auth Service
+type AuthClient struct {
+	conn   *grpc.ClientConn
+	client pb.AuthServiceClient
+}
+
+// UserInfo represents account data returned from Auth Service
+type UserInfo struct {
+	ID        uint
+	Email     string
+	Role      string
+	CreatedAt time.Time
+}
+
+// NewAuthClient creates a new gRPC client connection to Auth Service
+func NewAuthClient(address string) (*AuthClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Connected to Auth Service at %s", address)
+	return &AuthClient{
+		conn:   conn,
+		client: pb.NewAuthServiceClient(conn),
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (c *AuthClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetUser fetches a user by ID. It returns nil if the user does not exist.
+func (c *AuthClient) GetUser(ctx context.Context, userID uint) (*UserInfo, error) {
+	resp, err := c.client.GetUserById(ctx, &pb.GetUserByIdRequest{
+		UserId: uint64(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Found {
+		return nil, nil
+	}
+
+	return &UserInfo{
+		ID:        uint(resp.UserId),
+		Email:     resp.Email,
+		Role:      resp.Role,
+		CreatedAt: time.Unix(resp.CreatedAt, 0),
+	}, nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/herman-xphp/go-microservices-ecommerce/proto/order"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ErrOrderRejected is returned when Order Service refuses a change to an order
+var ErrOrderRejected = errors.New("order service rejected the request")
+
+// OrderClient wraps the gRPC client for Order Service
+type OrderClient struct {
+	conn   *grpc.ClientConn
+	client pb.OrderServiceClient
+}
+
+// OrderInfo represents order data returned from Order Service
+type OrderInfo struct {
+	ID          uint
+	UserID      uint
+	Status      string
+	TotalAmount float64
+	Currency    string
+}
+
+// IsPayable reports whether the order is still awaiting payment
+func (o *OrderInfo) IsPayable() bool {
+	return o.Status == "pending" || o.Status == "confirmed"
+}
+
+// NewOrderClient creates a new gRPC client connection to Order Service
+func NewOrderClient(address string) (*OrderClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Connected to Order Service at %s", address)
+	return &OrderClient{
+		conn:   conn,
+		client: pb.NewOrderServiceClient(conn),
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (c *OrderClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetOrder fetches an order by ID. It returns nil if the order does not exist.
+func (c *OrderClient) GetOrder(ctx context.Context, orderID uint) (*OrderInfo, error) {
+	resp, err := c.client.GetOrder(ctx, &pb.GetOrderRequest{
+		OrderId: uint64(orderID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Found {
+		return nil, nil
+	}
+
+	return &OrderInfo{
+		ID:          uint(resp.Id),
+		UserID:      uint(resp.UserId),
+		Status:      resp.Status,
+		TotalAmount: resp.TotalAmount,
+		Currency:    resp.Currency,
+	}, nil
+}
+
+// MarkOrderPaid tells Order Service that the order has been paid by the given transaction
+func (c *OrderClient) MarkOrderPaid(ctx context.Context, orderID uint, transactionID string, amount float64) error {
+	resp, err := c.client.MarkOrderPaid(ctx, &pb.MarkOrderPaidRequest{
+		OrderId:       uint64(orderID),
+		TransactionId: transactionID,
+		Amount:        amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("%w: %s", ErrOrderRejected, resp.ErrorMessage)
+	}
+	return nil
+}
+
+// CancelOrder cancels an unpaid order, releasing its stock
+func (c *OrderClient) CancelOrder(ctx context.Context, orderID uint, reason string) error {
+	resp, err := c.client.CancelOrder(ctx, &pb.CancelOrderRequest{
+		OrderId: uint64(orderID),
+		Reason:  reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("%w: %s", ErrOrderRejected, resp.ErrorMessage)
+	}
+	return nil
+}
@@ -1,22 +1,39 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+)
 
 // Payment represents a payment transaction
 type Payment struct {
-	ID            uint          `json:"id" gorm:"primaryKey"`
-	OrderID       uint          `json:"order_id" gorm:"not null;index"`
-	UserID        uint          `json:"user_id" gorm:"not null;index"`
-	Amount        float64       `json:"amount" gorm:"not null"`
-	Currency      string        `json:"currency" gorm:"default:IDR"`
-	Method        PaymentMethod `json:"method" gorm:"not null"`
-	Status        PaymentStatus `json:"status" gorm:"default:pending"`
-	TransactionID string        `json:"transaction_id" gorm:"uniqueIndex"`
-	ProviderRef   string        `json:"provider_ref"` // Reference from payment provider
-	FailureReason string        `json:"failure_reason"`
-	PaidAt        *time.Time    `json:"paid_at"`
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at"`
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	OrderID  uint    `json:"order_id" gorm:"not null;index"`
+	UserID   uint    `json:"user_id" gorm:"not null;index"`
+	Amount   float64 `json:"amount" gorm:"not null"`
+	Currency string  `json:"currency" gorm:"default:IDR"`
+	// Exchange rate snapshot taken when the payment was created, so it can be reported in the base currency
+	BaseCurrency   string        `json:"base_currency" gorm:"size:3;default:IDR"`
+	BaseAmount     float64       `json:"base_amount"`
+	ExchangeRate   float64       `json:"exchange_rate" gorm:"default:1"` // Base currency per unit of Currency
+	RateSource     string        `json:"rate_source"`
+	RateAsOf       *time.Time    `json:"rate_as_of"`
+	Method         PaymentMethod `json:"method" gorm:"not null"`
+	SavedMethodID  *uint         `json:"saved_method_id"` // Saved payment method charged, if any
+	RiskDecision   RiskDecision  `json:"risk_decision"`
+	Status         PaymentStatus `json:"status" gorm:"default:pending"`
+	TransactionID  string        `json:"transaction_id" gorm:"uniqueIndex"`
+	Provider       string        `json:"provider"`     // Name of the provider that holds the charge
+	ProviderRef    string        `json:"provider_ref"` // Reference from payment provider
+	PaymentCode    string        `json:"payment_code"` // Virtual account number or QRIS payload
+	RedirectURL    string        `json:"redirect_url"` // Where the customer completes an e-wallet or card payment
+	ExpiresAt      *time.Time    `json:"expires_at" gorm:"index"`
+	FailureReason  string        `json:"failure_reason"`
+	RefundedAmount float64       `json:"refunded_amount" gorm:"default:0"` // Sum of successful refunds
+	PaidAt         *time.Time    `json:"paid_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
 }
 
 // TableName overrides the table name
@@ -24,6 +41,38 @@ func (Payment) TableName() string {
 	return "payments"
 }
 
+// IsHeld reports whether the payment is waiting for manual risk review before it is charged
+func (p *Payment) IsHeld() bool {
+	return p.RiskDecision == RiskDecisionReview && p.Status == PaymentStatusPending && p.ProviderRef == ""
+}
+
+// CapturedAmount is the amount collected from the customer
+func (p *Payment) CapturedAmount() float64 {
+	if p.Status == PaymentStatusSuccess || p.Status == PaymentStatusRefunded {
+		return p.Amount
+	}
+	return 0
+}
+
+// FitRefund works out the amount of a new refund, given the refunds already committed against
+// the payment; an amount of 0 takes everything left. Amounts are compared in the currency's
+// smallest unit, so float drift across partial refunds cannot refuse the last exact one.
+// It reports false if the refund does not fit.
+func (p *Payment) FitRefund(amount, committed float64) (float64, bool) {
+	code := currency.Code(p.Currency)
+	remaining := code.Round(p.CapturedAmount() - committed)
+	if amount == 0 {
+		amount = remaining
+	}
+	amount = code.Round(amount)
+	return amount, amount > 0 && amount <= remaining
+}
+
+// NetAmount is the captured amount the merchant keeps after refunds
+func (p *Payment) NetAmount() float64 {
+	return p.CapturedAmount() - p.RefundedAmount
+}
+
 // PaymentStatus represents the status of a payment
 type PaymentStatus string
 
@@ -34,8 +83,33 @@ const (
 	PaymentStatusFailed     PaymentStatus = "failed"
 	PaymentStatusRefunded   PaymentStatus = "refunded"
 	PaymentStatusCancelled  PaymentStatus = "cancelled"
+	PaymentStatusExpired    PaymentStatus = "expired" // Not paid before its payment window closed
 )
 
+// EndedUnpaid reports whether an attempt in this status finished without taking the money,
+// so the order may be paid by a new attempt
+func (s PaymentStatus) EndedUnpaid() bool {
+	return s == PaymentStatusFailed || s == PaymentStatusCancelled || s == PaymentStatusExpired
+}
+
+// paymentTransitions lists the statuses each status may move to.
+// Failed, cancelled, expired and refunded payments are final.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:    {PaymentStatusProcessing, PaymentStatusSuccess, PaymentStatusFailed, PaymentStatusCancelled, PaymentStatusExpired},
+	PaymentStatusProcessing: {PaymentStatusSuccess, PaymentStatusFailed},
+	PaymentStatusSuccess:    {PaymentStatusRefunded},
+}
+
+// CanTransitionTo reports whether the payment may move from its current status to next
+func (p *Payment) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[p.Status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // PaymentMethod represents supported payment methods
 type PaymentMethod string
 
@@ -46,3 +120,36 @@ const (
 	PaymentMethodVA           PaymentMethod = "virtual_account"
 	PaymentMethodQRIS         PaymentMethod = "qris"
 )
+
+// PaymentEvent records an attempt against a payment or a change of its status.
+// Events where FromStatus differs from ToStatus are status changes.
+type PaymentEvent struct {
+	ID         uint             `json:"id" gorm:"primaryKey"`
+	PaymentID  uint             `json:"payment_id" gorm:"not null;index"`
+	Type       PaymentEventType `json:"type" gorm:"not null"`
+	FromStatus PaymentStatus    `json:"from_status"`
+	ToStatus   PaymentStatus    `json:"to_status"`
+	Provider   string           `json:"provider"`
+	Note       string           `json:"note" gorm:"type:text"`
+	Error      string           `json:"error" gorm:"type:text"`   // Set when the attempt failed
+	Payload    string           `json:"payload" gorm:"type:text"` // Provider request or response, as JSON
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (PaymentEvent) TableName() string {
+	return "payment_events"
+}
+
+// PaymentEventType represents what happened to a payment
+type PaymentEventType string
+
+const (
+	PaymentEventCreated      PaymentEventType = "created"
+	PaymentEventCharge       PaymentEventType = "charge"       // Charge requested from the provider
+	PaymentEventNotification PaymentEventType = "notification" // Status reported by the provider
+	PaymentEventCancel       PaymentEventType = "cancel"
+	PaymentEventRefund       PaymentEventType = "refund"
+	PaymentEventExpire       PaymentEventType = "expire"
+	PaymentEventReview       PaymentEventType = "review" // Risk review decision on a held payment
+)
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayment_CanTransitionTo(t *testing.T) {
+	payment := &Payment{Status: PaymentStatusPending}
+	assert.True(t, payment.CanTransitionTo(PaymentStatusSuccess))
+	assert.True(t, payment.CanTransitionTo(PaymentStatusCancelled))
+	assert.True(t, payment.CanTransitionTo(PaymentStatusExpired))
+	assert.False(t, payment.CanTransitionTo(PaymentStatusRefunded))
+
+	payment.Status = PaymentStatusSuccess
+	assert.True(t, payment.CanTransitionTo(PaymentStatusRefunded))
+	assert.False(t, payment.CanTransitionTo(PaymentStatusFailed))
+	assert.False(t, payment.CanTransitionTo(PaymentStatusPending))
+
+	payment.Status = PaymentStatusRefunded
+	assert.False(t, payment.CanTransitionTo(PaymentStatusSuccess))
+
+	payment.Status = PaymentStatusExpired
+	assert.False(t, payment.CanTransitionTo(PaymentStatusSuccess))
+
+	payment.Status = PaymentStatusFailed
+	assert.False(t, payment.CanTransitionTo(PaymentStatusSuccess))
+	assert.False(t, payment.CanTransitionTo("bogus"))
+}
+
+func TestPayment_NetAmount(t *testing.T) {
+	payment := &Payment{Amount: 100_000, RefundedAmount: 30_000, Status: PaymentStatusSuccess}
+	assert.Equal(t, 100_000.0, payment.CapturedAmount())
+	assert.Equal(t, 70_000.0, payment.NetAmount())
+
+	payment = &Payment{Amount: 100_000, Status: PaymentStatusPending}
+	assert.Equal(t, 0.0, payment.CapturedAmount())
+}
+
+func TestContainsCardNumber(t *testing.T) {
+	assert.True(t, ContainsCardNumber("4242424242424242"))
+	assert.True(t, ContainsCardNumber("card 4242 4242 4242 4242 exp 12/34"))
+	assert.True(t, ContainsCardNumber("5555-5555-5555-4444"))
+	assert.False(t, ContainsCardNumber("4242424242424241")) // Fails the Luhn check
+	assert.False(t, ContainsCardNumber("tok_visa"))
+	assert.False(t, ContainsCardNumber("4242"))
+	assert.False(t, ContainsCardNumber(""))
+}
+
+func TestSavedPaymentMethod_IsExpired(t *testing.T) {
+	method := &SavedPaymentMethod{ExpMonth: 12, ExpYear: 2034}
+	assert.False(t, method.IsExpired(time.Date(2034, 12, 31, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, method.IsExpired(time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	wallet := &SavedPaymentMethod{}
+	assert.False(t, wallet.IsExpired(time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
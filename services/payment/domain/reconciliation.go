@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// Reconciliation is the result of matching a provider's settlement statement against our payments
+type Reconciliation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Provider    string    `json:"provider" gorm:"not null;index"`
+	FileName    string    `json:"file_name"`
+	PeriodStart time.Time `json:"period_start"` // Payments paid in [PeriodStart, PeriodEnd) are expected in the statement
+	PeriodEnd   time.Time `json:"period_end"`
+	Rows        int       `json:"rows"`
+	// Entry counts by status
+	Matched           int                   `json:"matched"`
+	MissingPayment    int                   `json:"missing_payment"`
+	MissingSettlement int                   `json:"missing_settlement"`
+	Duplicate         int                   `json:"duplicate"`
+	AmountMismatch    int                   `json:"amount_mismatch"`
+	StatusMismatch    int                   `json:"status_mismatch"`
+	StatementTotal    float64               `json:"statement_total"`
+	Entries           []ReconciliationEntry `json:"entries,omitempty" gorm:"foreignKey:ReconciliationID"`
+	CreatedAt         time.Time             `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Reconciliation) TableName() string {
+	return "reconciliations"
+}
+
+// Discrepancies is the number of entries that need attention
+func (r *Reconciliation) Discrepancies() int {
+	return r.MissingPayment + r.MissingSettlement + r.Duplicate + r.AmountMismatch + r.StatusMismatch
+}
+
+// Count adds an entry to the count for its status
+func (r *Reconciliation) Count(status ReconciliationStatus) {
+	switch status {
+	case ReconciliationMatched:
+		r.Matched++
+	case ReconciliationMissingPayment:
+		r.MissingPayment++
+	case ReconciliationMissingSettlement:
+		r.MissingSettlement++
+	case ReconciliationDuplicate:
+		r.Duplicate++
+	case ReconciliationAmountMismatch:
+		r.AmountMismatch++
+	case ReconciliationStatusMismatch:
+		r.StatusMismatch++
+	}
+}
+
+// ReconciliationEntry is the outcome for one statement row, or for one of our payments the statement lacks
+type ReconciliationEntry struct {
+	ID               uint                 `json:"id" gorm:"primaryKey"`
+	ReconciliationID uint                 `json:"reconciliation_id" gorm:"not null;index"`
+	Line             int                  `json:"line"` // Line in the statement; 0 for payments missing from it
+	Status           ReconciliationStatus `json:"status" gorm:"not null;index"`
+	TransactionID    string               `json:"transaction_id"`
+	ProviderRef      string               `json:"provider_ref"`
+	PaymentID        *uint                `json:"payment_id"`
+	StatementAmount  float64              `json:"statement_amount"`
+	PaymentAmount    float64              `json:"payment_amount"`
+	Note             string               `json:"note" gorm:"type:text"`
+}
+
+// TableName overrides the table name
+func (ReconciliationEntry) TableName() string {
+	return "reconciliation_entries"
+}
+
+// ReconciliationStatus is how a statement row or payment compared
+type ReconciliationStatus string
+
+const (
+	ReconciliationMatched           ReconciliationStatus = "matched"
+	ReconciliationMissingPayment    ReconciliationStatus = "missing_payment"    // Settled by the provider, unknown to us
+	ReconciliationMissingSettlement ReconciliationStatus = "missing_settlement" // Paid according to us, absent from the statement
+	ReconciliationDuplicate         ReconciliationStatus = "duplicate"          // Payment settled by more than one row
+	ReconciliationAmountMismatch    ReconciliationStatus = "amount_mismatch"
+	ReconciliationStatusMismatch    ReconciliationStatus = "status_mismatch" // Settled by the provider but not paid according to us
+)
+
+// IsValid reports whether the status is one of the known reconciliation statuses
+func (s ReconciliationStatus) IsValid() bool {
+	switch s {
+	case ReconciliationMatched, ReconciliationMissingPayment, ReconciliationMissingSettlement,
+		ReconciliationDuplicate, ReconciliationAmountMismatch, ReconciliationStatusMismatch:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Refund represents money returned to the customer from a captured payment.
+// A payment may have several refunds; together they never exceed the captured amount.
+type Refund struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	PaymentID     uint         `json:"payment_id" gorm:"not null;index;uniqueIndex:idx_refunds_payment_reference"`
+	Reference     string       `json:"reference" gorm:"not null;uniqueIndex:idx_refunds_payment_reference"` // Idempotency key, also sent to the provider
+	Amount        float64      `json:"amount" gorm:"not null"`
+	Reason        string       `json:"reason" gorm:"type:text;not null"`
+	Status        RefundStatus `json:"status" gorm:"default:pending"`
+	ProviderRef   string       `json:"provider_ref"`
+	FailureReason string       `json:"failure_reason" gorm:"type:text"`
+	RequestedBy   uint         `json:"requested_by"`
+	CompletedAt   *time.Time   `json:"completed_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// RefundStatus represents the status of a refund
+type RefundStatus string
+
+const (
+	RefundStatusPending    RefundStatus = "pending"    // Reserved, not yet accepted by the provider
+	RefundStatusProcessing RefundStatus = "processing" // Accepted by the provider, awaiting its outcome
+	RefundStatusSuccess    RefundStatus = "success"
+	RefundStatusFailed     RefundStatus = "failed"
+)
+
+// IsFinal reports whether the refund has a known outcome
+func (s RefundStatus) IsFinal() bool {
+	return s == RefundStatusSuccess || s == RefundStatusFailed
+}
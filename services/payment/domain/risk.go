@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// RiskDecision is the outcome of a risk evaluation of a payment attempt
+type RiskDecision string
+
+const (
+	RiskDecisionAllow  RiskDecision = "allow"
+	RiskDecisionReview RiskDecision = "review" // Held until staff approve or reject it
+	RiskDecisionDeny   RiskDecision = "deny"
+)
+
+// ReviewStatus tracks a held payment through manual review
+type ReviewStatus string
+
+const (
+	ReviewStatusNone     ReviewStatus = ""
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+// IsValid reports whether the status is one reviews can be listed by
+func (s ReviewStatus) IsValid() bool {
+	switch s {
+	case ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected:
+		return true
+	}
+	return false
+}
+
+// RiskAssessment records the risk evaluation of one payment attempt. Every attempt is recorded,
+// including denied ones that never became a payment, so attempts can be counted for velocity checks.
+type RiskAssessment struct {
+	ID         uint          `json:"id" gorm:"primaryKey"`
+	UserID     uint          `json:"user_id" gorm:"not null;index"`
+	OrderID    uint          `json:"order_id" gorm:"not null;index"`
+	PaymentID  *uint         `json:"payment_id" gorm:"index"` // Unset for denied attempts
+	ClientIP   string        `json:"client_ip" gorm:"size:45;index"`
+	Method     PaymentMethod `json:"method"`
+	Amount     float64       `json:"amount"`
+	Currency   string        `json:"currency" gorm:"size:3"`
+	BaseAmount float64       `json:"base_amount"` // Amount in the base currency, which thresholds are set in
+	Score      int           `json:"score"`
+	Decision   RiskDecision  `json:"decision" gorm:"not null;index"`
+	Reasons    []string      `json:"reasons" gorm:"serializer:json;type:text"`
+	// Manual review of held payments
+	ReviewStatus ReviewStatus `json:"review_status" gorm:"index"`
+	ReviewedBy   uint         `json:"reviewed_by"`
+	ReviewedAt   *time.Time   `json:"reviewed_at"`
+	ReviewNote   string       `json:"review_note" gorm:"type:text"`
+	CreatedAt    time.Time    `json:"created_at" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (RiskAssessment) TableName() string {
+	return "risk_assessments"
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// SavedPaymentMethod is a payment method a customer keeps on file with a provider.
+// Only the provider's token and masked display details are stored: card numbers are
+// collected by the provider's own form and never reach this service.
+type SavedPaymentMethod struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	UserID      uint          `json:"user_id" gorm:"not null;index"`
+	Provider    string        `json:"provider" gorm:"not null"`
+	Method      PaymentMethod `json:"method" gorm:"not null"`
+	Token       string        `json:"-" gorm:"not null"`
+	Brand       string        `json:"brand"`
+	Last4       string        `json:"last4" gorm:"size:4"`
+	ExpMonth    int           `json:"exp_month"`
+	ExpYear     int           `json:"exp_year"`
+	Fingerprint string        `json:"-" gorm:"index"` // Identifies the card or account across tokens
+	IsDefault   bool          `json:"is_default" gorm:"not null;default:false"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (SavedPaymentMethod) TableName() string {
+	return "saved_payment_methods"
+}
+
+// IsExpired reports whether the method can no longer be charged.
+// Cards are valid through the last day of their expiry month; methods without an expiry never expire.
+func (m *SavedPaymentMethod) IsExpired(now time.Time) bool {
+	if m.ExpYear == 0 {
+		return false
+	}
+	return !now.Before(time.Date(m.ExpYear, time.Month(m.ExpMonth)+1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// ContainsCardNumber reports whether a value holds something that looks like a full card number:
+// a run of 13 to 19 digits, optionally separated by spaces or dashes, that passes the Luhn check.
+// It guards against card numbers ending up in stored fields by mistake.
+func ContainsCardNumber(value string) bool {
+	var digits []int
+	for _, r := range value + "|" {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, int(r-'0'))
+			continue
+		case (r == ' ' || r == '-') && len(digits) > 0:
+			continue
+		}
+		if len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits) {
+			return true
+		}
+		digits = digits[:0]
+	}
+	return false
+}
+
+func luhnValid(digits []int) bool {
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
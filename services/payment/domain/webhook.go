@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// WebhookLog is the audit record of one webhook call.
+// It is written before the signature is checked, so rejected calls are kept as well.
+type WebhookLog struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Provider   string         `json:"provider" gorm:"not null;index"`
+	EventID    string         `json:"event_id" gorm:"index"`
+	Timestamp  string         `json:"timestamp"`
+	Signature  string         `json:"signature"`
+	RemoteAddr string         `json:"remote_addr"`
+	Payload    string         `json:"payload" gorm:"type:text"` // Raw request body; only the start of it unless Verified
+	Verified   bool           `json:"verified" gorm:"default:false"`
+	Outcome    WebhookOutcome `json:"outcome" gorm:"default:received"`
+	Error      string         `json:"error" gorm:"type:text"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (WebhookLog) TableName() string {
+	return "webhook_logs"
+}
+
+// WebhookOutcome represents what happened to a webhook call
+type WebhookOutcome string
+
+const (
+	WebhookOutcomeReceived  WebhookOutcome = "received"
+	WebhookOutcomeRejected  WebhookOutcome = "rejected"  // Signature or timestamp check failed, or signed by another provider
+	WebhookOutcomeInvalid   WebhookOutcome = "invalid"   // Verified, but the payload could not be used
+	WebhookOutcomeDuplicate WebhookOutcome = "duplicate" // Event already processed
+	WebhookOutcomeProcessed WebhookOutcome = "processed"
+	WebhookOutcomeFailed    WebhookOutcome = "failed"
+)
+
+// WebhookEvent records a verified provider event so that retries and replays are applied at most once
+type WebhookEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Provider   string    `json:"provider" gorm:"not null;uniqueIndex:idx_webhook_events_provider_event"`
+	EventID    string    `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_events_provider_event"`
+	ReceivedAt time.Time `json:"received_at" gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (WebhookEvent) TableName() string {
+	return "webhook_events"
+}
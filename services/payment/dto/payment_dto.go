@@ -1,36 +1,76 @@
 package dto
 
-import "github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+import (
+	"encoding/json"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+)
 
 // CreatePaymentRequest represents the payload for creating a payment
 type CreatePaymentRequest struct {
-	OrderID uint                 `json:"order_id" binding:"required"`
-	Amount  float64              `json:"amount" binding:"required,gt=0"`
-	Method  domain.PaymentMethod `json:"method" binding:"required"`
+	OrderID       uint                 `json:"order_id" binding:"required"`
+	Amount        float64              `json:"amount" binding:"omitempty,gt=0"` // Optional; must equal the order total if sent
+	Currency      string               `json:"currency"`                        // Optional; must equal the order currency if sent
+	Method        domain.PaymentMethod `json:"method" binding:"required_without=SavedMethodID"`
+	SavedMethodID *uint                `json:"saved_method_id"` // Charges a saved payment method; method may then be omitted
+	ClientIP      string               `json:"-"`               // Set by the handler for risk checks
 }
 
 // PaymentResponse represents a payment in API responses
 type PaymentResponse struct {
-	ID            uint                 `json:"id"`
-	OrderID       uint                 `json:"order_id"`
-	UserID        uint                 `json:"user_id"`
-	Amount        float64              `json:"amount"`
-	Currency      string               `json:"currency"`
-	Method        domain.PaymentMethod `json:"method"`
-	Status        domain.PaymentStatus `json:"status"`
-	TransactionID string               `json:"transaction_id"`
-	ProviderRef   string               `json:"provider_ref,omitempty"`
-	FailureReason string               `json:"failure_reason,omitempty"`
-	PaidAt        string               `json:"paid_at,omitempty"`
-	CreatedAt     string               `json:"created_at"`
-}
-
-// ProcessPaymentRequest represents a payment processing webhook/callback
+	ID             uint                 `json:"id"`
+	OrderID        uint                 `json:"order_id"`
+	UserID         uint                 `json:"user_id"`
+	Amount         float64              `json:"amount"`
+	Currency       string               `json:"currency"`
+	BaseCurrency   string               `json:"base_currency"`
+	BaseAmount     float64              `json:"base_amount"`
+	ExchangeRate   float64              `json:"exchange_rate"`
+	RateSource     string               `json:"rate_source,omitempty"`
+	RateAsOf       string               `json:"rate_as_of,omitempty"`
+	Method         domain.PaymentMethod `json:"method"`
+	SavedMethodID  *uint                `json:"saved_method_id,omitempty"`
+	Status         domain.PaymentStatus `json:"status"`
+	TransactionID  string               `json:"transaction_id"`
+	Provider       string               `json:"provider,omitempty"`
+	ProviderRef    string               `json:"provider_ref,omitempty"`
+	PaymentCode    string               `json:"payment_code,omitempty"`
+	RedirectURL    string               `json:"redirect_url,omitempty"`
+	ExpiresAt      string               `json:"expires_at,omitempty"`
+	FailureReason  string               `json:"failure_reason,omitempty"`
+	UnderReview    bool                 `json:"under_review,omitempty"` // Held for manual review before it is charged
+	RefundedAmount float64              `json:"refunded_amount"`
+	NetAmount      float64              `json:"net_amount"` // Captured amount less successful refunds
+	PaidAt         string               `json:"paid_at,omitempty"`
+	CreatedAt      string               `json:"created_at"`
+	// Timeline is only included when a single payment is fetched
+	Timeline []PaymentEventResponse `json:"timeline,omitempty"`
+}
+
+// PaymentEventResponse represents an entry of a payment's timeline
+type PaymentEventResponse struct {
+	ID         uint                    `json:"id"`
+	Type       domain.PaymentEventType `json:"type"`
+	FromStatus domain.PaymentStatus    `json:"from_status"`
+	ToStatus   domain.PaymentStatus    `json:"to_status"`
+	Provider   string                  `json:"provider,omitempty"`
+	Note       string                  `json:"note,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	Payload    json.RawMessage         `json:"payload,omitempty"` // Only shown to staff
+	CreatedAt  string                  `json:"created_at"`
+}
+
+// ProcessPaymentRequest represents a payment processing webhook/callback.
+// Notifications carrying a refund reference report the outcome of that refund instead of the payment.
 type ProcessPaymentRequest struct {
-	TransactionID string               `json:"transaction_id" binding:"required"`
-	Status        domain.PaymentStatus `json:"status" binding:"required"`
-	ProviderRef   string               `json:"provider_ref"`
-	FailureReason string               `json:"failure_reason,omitempty"`
+	EventID         string               `json:"event_id"` // Provider's unique id for this notification
+	TransactionID   string               `json:"transaction_id" binding:"required"`
+	RefundReference string               `json:"refund_reference,omitempty"`
+	Status          domain.PaymentStatus `json:"status" binding:"required"`
+	ProviderRef     string               `json:"provider_ref"`
+	FailureReason   string               `json:"failure_reason,omitempty"`
+	// Provider is the provider whose webhook secret verified the notification; never read from the payload
+	Provider string `json:"-"`
 }
 
 // PaymentListResponse represents paginated payment list
@@ -44,5 +84,125 @@ type PaymentListResponse struct {
 
 // RefundRequest represents a refund request
 type RefundRequest struct {
-	Reason string `json:"reason" binding:"required"`
+	Reason string  `json:"reason" binding:"required"`
+	Amount float64 `json:"amount" binding:"omitempty,gt=0"` // Omit to refund the remaining balance
+	// Reference makes the request idempotent: repeating it returns the existing refund
+	Reference string `json:"reference" binding:"omitempty,max=64"`
+}
+
+// RefundResponse represents a refund in API responses
+type RefundResponse struct {
+	ID            uint                `json:"id"`
+	PaymentID     uint                `json:"payment_id"`
+	Reference     string              `json:"reference"`
+	Amount        float64             `json:"amount"`
+	Reason        string              `json:"reason"`
+	Status        domain.RefundStatus `json:"status"`
+	ProviderRef   string              `json:"provider_ref,omitempty"`
+	FailureReason string              `json:"failure_reason,omitempty"`
+	RequestedBy   uint                `json:"requested_by,omitempty"`
+	CompletedAt   string              `json:"completed_at,omitempty"`
+	CreatedAt     string              `json:"created_at"`
+}
+
+// WebhookResult reports how a payment webhook was handled
+type WebhookResult struct {
+	EventID   string           `json:"event_id"`
+	Duplicate bool             `json:"duplicate"`
+	Payment   *PaymentResponse `json:"payment,omitempty"`
+	Refund    *RefundResponse  `json:"refund,omitempty"`
+}
+
+// ReconciliationResponse represents a settlement reconciliation report in API responses
+type ReconciliationResponse struct {
+	ID             uint                          `json:"id"`
+	Provider       string                        `json:"provider"`
+	FileName       string                        `json:"file_name"`
+	PeriodStart    string                        `json:"period_start"`
+	PeriodEnd      string                        `json:"period_end"`
+	Rows           int                           `json:"rows"`
+	StatementTotal float64                       `json:"statement_total"`
+	Summary        map[string]int                `json:"summary"` // Entry count per status
+	Discrepancies  int                           `json:"discrepancies"`
+	CreatedAt      string                        `json:"created_at"`
+	Entries        []ReconciliationEntryResponse `json:"entries,omitempty"` // Only included when a single report is fetched
+}
+
+// ReconciliationEntryResponse represents one line of a reconciliation report
+type ReconciliationEntryResponse struct {
+	Line            int                         `json:"line,omitempty"`
+	Status          domain.ReconciliationStatus `json:"status"`
+	TransactionID   string                      `json:"transaction_id,omitempty"`
+	ProviderRef     string                      `json:"provider_ref,omitempty"`
+	PaymentID       *uint                       `json:"payment_id,omitempty"`
+	StatementAmount float64                     `json:"statement_amount"`
+	PaymentAmount   float64                     `json:"payment_amount"`
+	Note            string                      `json:"note,omitempty"`
+}
+
+// ReconciliationListResponse represents a paginated list of reconciliation reports
+type ReconciliationListResponse struct {
+	Reconciliations []ReconciliationResponse `json:"reconciliations"`
+	Total           int64                    `json:"total"`
+	Page            int                      `json:"page"`
+	PageSize        int                      `json:"page_size"`
+	TotalPages      int                      `json:"total_pages"`
+}
+
+// SavePaymentMethodRequest represents the payload for saving a payment method.
+// The token comes from the provider's checkout form; card numbers are refused.
+type SavePaymentMethodRequest struct {
+	Method  domain.PaymentMethod `json:"method" binding:"required"`
+	Token   string               `json:"token" binding:"required,max=255"`
+	Default bool                 `json:"default"` // Make it the default method
+}
+
+// SavedMethodResponse represents a saved payment method in API responses
+type SavedMethodResponse struct {
+	ID        uint                 `json:"id"`
+	Provider  string               `json:"provider"`
+	Method    domain.PaymentMethod `json:"method"`
+	Brand     string               `json:"brand"`
+	Last4     string               `json:"last4"`
+	ExpMonth  int                  `json:"exp_month,omitempty"`
+	ExpYear   int                  `json:"exp_year,omitempty"`
+	IsDefault bool                 `json:"is_default"`
+	Expired   bool                 `json:"expired"`
+	CreatedAt string               `json:"created_at"`
+}
+
+// RiskReviewResponse represents a risk assessment of a payment attempt in the manual review queue
+type RiskReviewResponse struct {
+	ID           uint                 `json:"id"`
+	UserID       uint                 `json:"user_id"`
+	OrderID      uint                 `json:"order_id"`
+	PaymentID    *uint                `json:"payment_id,omitempty"`
+	ClientIP     string               `json:"client_ip,omitempty"`
+	Method       domain.PaymentMethod `json:"method"`
+	Amount       float64              `json:"amount"`
+	Currency     string               `json:"currency"`
+	BaseAmount   float64              `json:"base_amount"`
+	Score        int                  `json:"score"`
+	Decision     domain.RiskDecision  `json:"decision"`
+	Reasons      []string             `json:"reasons"`
+	ReviewStatus domain.ReviewStatus  `json:"review_status,omitempty"`
+	ReviewedBy   uint                 `json:"reviewed_by,omitempty"`
+	ReviewedAt   string               `json:"reviewed_at,omitempty"`
+	ReviewNote   string               `json:"review_note,omitempty"`
+	CreatedAt    string               `json:"created_at"`
+	Payment      *PaymentResponse     `json:"payment,omitempty"` // Only included when a single review is fetched or resolved
+}
+
+// RiskReviewListResponse represents a paginated list of risk reviews
+type RiskReviewListResponse struct {
+	Reviews    []RiskReviewResponse `json:"reviews"`
+	Total      int64                `json:"total"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	TotalPages int                  `json:"total_pages"`
+}
+
+// ResolveReviewRequest represents the payload for approving or rejecting a held payment
+type ResolveReviewRequest struct {
+	Note string `json:"note" binding:"max=1000"`
 }
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
 )
@@ -29,26 +32,16 @@ func (h *PaymentHandler) RegisterRoutes(router *gin.RouterGroup) {
 		payments.GET("", h.GetUserPayments)
 		payments.GET("/:id", h.GetPayment)
 		payments.GET("/order/:order_id", h.GetPaymentByOrderID)
-		payments.POST("/webhook", h.ProcessPaymentWebhook)
+		payments.GET("/statuses", auth.RequireStaff(), h.GetPaymentStatuses)
 		payments.POST("/:id/cancel", h.CancelPayment)
-		payments.POST("/:id/refund", h.RefundPayment)
 	}
 }
 
 // CreatePayment creates a new payment
 // POST /api/v1/payments
 func (h *PaymentHandler) CreatePayment(c *gin.Context) {
-	// Get user_id from context (set by auth middleware) or header
-	userID, _ := c.Get("user_id")
-	if userID == nil {
-		// Fallback: try from header (for demo/testing)
-		if uid := c.GetHeader("X-User-ID"); uid != "" {
-			id, _ := strconv.ParseUint(uid, 10, 32)
-			userID = uint(id)
-		}
-	}
-
-	if userID == nil || userID.(uint) == 0 {
+	identity, ok := auth.Current(c)
+	if !ok || identity.UserID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"message": "User not authenticated",
@@ -65,12 +58,32 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		})
 		return
 	}
+	// Behind the gateway c.ClientIP() is the gateway's address; it signs the shopper's instead
+	req.ClientIP = identity.ClientIP
+	if req.ClientIP == "" {
+		req.ClientIP = c.ClientIP()
+	}
 
-	payment, err := h.paymentService.CreatePayment(userID.(uint), &req)
+	payment, err := h.paymentService.CreatePayment(identity.UserID, &req)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err == service.ErrPaymentExists {
 			status = http.StatusConflict
+		} else if err == service.ErrMethodUnsupported || err == service.ErrAmountMismatch ||
+			err == service.ErrCurrencyMismatch || err == service.ErrUnsupportedCurrency ||
+			err == service.ErrSavedMethodMismatch || err == service.ErrSavedMethodExpired {
+			status = http.StatusBadRequest
+		} else if err == service.ErrOrderNotFound || err == service.ErrSavedMethodNotFound {
+			status = http.StatusNotFound
+		} else if err == service.ErrOrderNotPayable {
+			status = http.StatusConflict
+		} else if err == service.ErrPaymentDenied {
+			// Risk reasons are for staff; they would help fraudsters tune their attempts
+			status = http.StatusForbidden
+		} else if errors.Is(err, service.ErrProviderFailure) || errors.Is(err, service.ErrOrderLookup) {
+			status = http.StatusBadGateway
+		} else if errors.Is(err, service.ErrExchangeRateUnavailable) {
+			status = http.StatusServiceUnavailable
 		}
 		c.JSON(status, gin.H{
 			"success": false,
@@ -79,6 +92,15 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		return
 	}
 
+	if payment.UnderReview {
+		c.JSON(http.StatusAccepted, gin.H{
+			"success": true,
+			"message": "Payment is under review",
+			"data":    payment,
+		})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Payment created successfully",
@@ -98,15 +120,20 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.paymentService.GetPayment(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
+	payment, ok := authorizePayment(c, func() (*dto.PaymentResponse, error) {
+		return h.paymentService.GetPayment(uint(id))
+	})
+	if !ok {
 		return
 	}
 
+	// Raw provider payloads are for staff investigating a payment
+	if identity, _ := auth.Current(c); !identity.IsStaff() {
+		for i := range payment.Timeline {
+			payment.Timeline[i].Payload = nil
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    payment,
@@ -125,12 +152,10 @@ func (h *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.paymentService.GetPaymentByOrderID(uint(orderID))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
+	payment, ok := authorizePayment(c, func() (*dto.PaymentResponse, error) {
+		return h.paymentService.GetPaymentByOrderID(uint(orderID))
+	})
+	if !ok {
 		return
 	}
 
@@ -140,29 +165,37 @@ func (h *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
 	})
 }
 
-// GetUserPayments retrieves payments for the authenticated user
-// GET /api/v1/payments
-func (h *PaymentHandler) GetUserPayments(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	if userID == nil {
-		if uid := c.GetHeader("X-User-ID"); uid != "" {
-			id, _ := strconv.ParseUint(uid, 10, 32)
-			userID = uint(id)
+// maxStatusLookup caps how many orders a single status lookup may ask for
+const maxStatusLookup = 500
+
+// GetPaymentStatuses retrieves payment statuses for a batch of orders
+// GET /api/v1/payments/statuses?order_ids=1,2,3
+func (h *PaymentHandler) GetPaymentStatuses(c *gin.Context) {
+	var orderIDs []uint
+	for _, raw := range strings.Split(c.Query("order_ids"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid order ID: " + raw,
+			})
+			return
 		}
+		orderIDs = append(orderIDs, uint(id))
 	}
 
-	if userID == nil || userID.(uint) == 0 {
-		c.JSON(http.StatusUnauthorized, gin.H{
+	if len(orderIDs) > maxStatusLookup {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"message": "User not authenticated",
+			"message": "Too many order IDs",
 		})
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	payments, err := h.paymentService.GetUserPayments(userID.(uint), page, pageSize)
+	statuses, err := h.paymentService.GetPaymentStatuses(orderIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -173,30 +206,28 @@ func (h *PaymentHandler) GetUserPayments(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    payments,
+		"data":    statuses,
 	})
 }
 
-// ProcessPaymentWebhook handles payment provider webhooks
-// POST /api/v1/payments/webhook
-func (h *PaymentHandler) ProcessPaymentWebhook(c *gin.Context) {
-	var req dto.ProcessPaymentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+// GetUserPayments retrieves payments for the authenticated user (staff may pass user_id)
+// GET /api/v1/payments
+func (h *PaymentHandler) GetUserPayments(c *gin.Context) {
+	userID, ok := auth.TargetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
-			"message": "Invalid request",
-			"error":   err.Error(),
+			"message": "User not authenticated",
 		})
 		return
 	}
 
-	payment, err := h.paymentService.ProcessPayment(&req)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	payments, err := h.paymentService.GetUserPayments(userID, page, pageSize)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrPaymentNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{
+		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
@@ -205,8 +236,7 @@ func (h *PaymentHandler) ProcessPaymentWebhook(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Payment processed successfully",
-		"data":    payment,
+		"data":    payments,
 	})
 }
 
@@ -222,12 +252,20 @@ func (h *PaymentHandler) CancelPayment(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizePayment(c, func() (*dto.PaymentResponse, error) {
+		return h.paymentService.GetPayment(uint(id))
+	}); !ok {
+		return
+	}
+
 	if err := h.paymentService.CancelPayment(uint(id)); err != nil {
 		status := http.StatusInternalServerError
 		if err == service.ErrPaymentNotFound {
 			status = http.StatusNotFound
 		} else if err == service.ErrPaymentNotPending {
 			status = http.StatusBadRequest
+		} else if errors.Is(err, service.ErrProviderFailure) {
+			status = http.StatusBadGateway
 		}
 		c.JSON(status, gin.H{
 			"success": false,
@@ -242,44 +280,28 @@ func (h *PaymentHandler) CancelPayment(c *gin.Context) {
 	})
 }
 
-// RefundPayment processes a refund for a successful payment
-// POST /api/v1/payments/:id/refund
-func (h *PaymentHandler) RefundPayment(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+// authorizePayment loads a payment the caller owns, or any payment for staff.
+// Payments of other users are reported as not found so their existence is not leaked.
+func authorizePayment(c *gin.Context, load func() (*dto.PaymentResponse, error)) (*dto.PaymentResponse, bool) {
+	identity, ok := auth.Current(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
-			"message": "Invalid payment ID",
+			"message": "User not authenticated",
 		})
-		return
+		return nil, false
 	}
 
-	var req dto.RefundRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request",
-			"error":   err.Error(),
-		})
-		return
+	payment, err := load()
+	if err == nil && !identity.CanAccess(payment.UserID) {
+		err = service.ErrPaymentNotFound
 	}
-
-	if err := h.paymentService.RefundPayment(uint(id), req.Reason); err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrPaymentNotFound {
-			status = http.StatusNotFound
-		} else if err == service.ErrPaymentNotSuccess {
-			status = http.StatusBadRequest
-		}
-		c.JSON(status, gin.H{
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
-		return
+		return nil, false
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Payment refunded successfully",
-	})
+	return payment, true
 }
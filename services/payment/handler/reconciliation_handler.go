@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
+)
+
+// ReconciliationHandler handles HTTP requests for settlement reconciliation reports.
+// Reports are produced by the payment-reconcile command.
+type ReconciliationHandler struct {
+	reconciliationService service.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new ReconciliationHandler
+func NewReconciliationHandler(reconciliationService service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// RegisterRoutes registers reconciliation routes
+func (h *ReconciliationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	reconciliations := router.Group("/admin/payments/reconciliations", auth.RequireStaff())
+	{
+		reconciliations.GET("", h.ListReconciliations)
+		reconciliations.GET("/:id", h.GetReconciliation)
+	}
+}
+
+// ListReconciliations lists reconciliation reports, newest first
+// GET /api/v1/admin/payments/reconciliations
+func (h *ReconciliationHandler) ListReconciliations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	reconciliations, err := h.reconciliationService.GetReconciliations(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reconciliations,
+	})
+}
+
+// GetReconciliation returns a reconciliation report with its entries.
+// Use ?status=amount_mismatch (or another entry status) to list only those entries.
+// GET /api/v1/admin/payments/reconciliations/:id
+func (h *ReconciliationHandler) GetReconciliation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid reconciliation ID",
+		})
+		return
+	}
+
+	status := domain.ReconciliationStatus(c.Query("status"))
+	if status != "" && !status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid entry status",
+		})
+		return
+	}
+
+	reconciliation, err := h.reconciliationService.GetReconciliation(uint(id), status)
+	if err != nil {
+		httpStatus := http.StatusInternalServerError
+		if errors.Is(err, service.ErrReconciliationNotFound) {
+			httpStatus = http.StatusNotFound
+		}
+		c.JSON(httpStatus, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reconciliation,
+	})
+}
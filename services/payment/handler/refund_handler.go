@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
+)
+
+// RefundHandler handles HTTP requests for refunds
+type RefundHandler struct {
+	paymentService service.PaymentService
+	refundService  service.RefundService
+}
+
+// NewRefundHandler creates a new RefundHandler
+func NewRefundHandler(paymentService service.PaymentService, refundService service.RefundService) *RefundHandler {
+	return &RefundHandler{
+		paymentService: paymentService,
+		refundService:  refundService,
+	}
+}
+
+// RegisterRoutes registers refund routes
+func (h *RefundHandler) RegisterRoutes(router *gin.RouterGroup) {
+	payments := router.Group("/payments")
+	{
+		payments.POST("/:id/refund", auth.RequireStaff(), h.RefundPayment)
+		payments.GET("/:id/refunds", h.GetRefunds)
+	}
+}
+
+// RefundPayment requests a full or partial refund of a successful payment.
+// The refund is processed by the provider in the background.
+// POST /api/v1/payments/:id/refund
+func (h *RefundHandler) RefundPayment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid payment ID",
+		})
+		return
+	}
+
+	var req dto.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	identity, _ := auth.Current(c)
+	refund, err := h.refundService.RequestRefund(uint(id), identity.UserID, &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPaymentNotSuccess), errors.Is(err, service.ErrRefundTooLarge):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrRefundReferenceConflict):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Refund requested",
+		"data":    refund,
+	})
+}
+
+// GetRefunds lists the refunds of a payment
+// GET /api/v1/payments/:id/refunds
+func (h *RefundHandler) GetRefunds(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid payment ID",
+		})
+		return
+	}
+
+	if _, ok := authorizePayment(c, func() (*dto.PaymentResponse, error) {
+		return h.paymentService.GetPayment(uint(id))
+	}); !ok {
+		return
+	}
+
+	refunds, err := h.refundService.GetRefunds(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    refunds,
+	})
+}
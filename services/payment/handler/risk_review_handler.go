@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
+)
+
+// RiskReviewHandler handles HTTP requests for the manual review queue of held payments
+type RiskReviewHandler struct {
+	riskReviewService service.RiskReviewService
+}
+
+// NewRiskReviewHandler creates a new RiskReviewHandler
+func NewRiskReviewHandler(riskReviewService service.RiskReviewService) *RiskReviewHandler {
+	return &RiskReviewHandler{
+		riskReviewService: riskReviewService,
+	}
+}
+
+// RegisterRoutes registers risk review routes
+func (h *RiskReviewHandler) RegisterRoutes(router *gin.RouterGroup) {
+	reviews := router.Group("/admin/payments/reviews", auth.RequireStaff())
+	{
+		reviews.GET("", h.ListReviews)
+		reviews.GET("/:id", h.GetReview)
+		reviews.POST("/:id/approve", h.ApproveReview)
+		reviews.POST("/:id/reject", h.RejectReview)
+	}
+}
+
+// ListReviews lists held payments, pending ones oldest first.
+// Use ?status=approved or ?status=rejected to list resolved reviews.
+// GET /api/v1/admin/payments/reviews
+func (h *RiskReviewHandler) ListReviews(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	reviews, err := h.riskReviewService.GetReviews(domain.ReviewStatus(c.Query("status")), page, pageSize)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reviews,
+	})
+}
+
+// GetReview returns a review with the risk reasons and the held payment
+// GET /api/v1/admin/payments/reviews/:id
+func (h *RiskReviewHandler) GetReview(c *gin.Context) {
+	id, ok := reviewID(c)
+	if !ok {
+		return
+	}
+
+	review, err := h.riskReviewService.GetReview(id)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    review,
+	})
+}
+
+// ApproveReview releases a held payment for charging
+// POST /api/v1/admin/payments/reviews/:id/approve
+func (h *RiskReviewHandler) ApproveReview(c *gin.Context) {
+	h.resolve(c, h.riskReviewService.Approve, "Payment approved")
+}
+
+// RejectReview cancels a held payment and its order
+// POST /api/v1/admin/payments/reviews/:id/reject
+func (h *RiskReviewHandler) RejectReview(c *gin.Context) {
+	h.resolve(c, h.riskReviewService.Reject, "Payment rejected")
+}
+
+func (h *RiskReviewHandler) resolve(c *gin.Context, decide func(id, reviewerID uint, note string) (*dto.RiskReviewResponse, error), message string) {
+	reviewerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := reviewID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ResolveReviewRequest
+	// The note is optional, and so is the body
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid request",
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	review, err := decide(id, reviewerID, req.Note)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    review,
+	})
+}
+
+func (h *RiskReviewHandler) respondError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidReviewStatus):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrReviewResolved), errors.Is(err, service.ErrPaymentNotHeld),
+		errors.Is(err, service.ErrOrderNotPayable):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrProviderFailure), errors.Is(err, service.ErrOrderLookup):
+		status = http.StatusBadGateway
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+// reviewID parses the review ID path parameter, answering 400 if it is invalid
+func reviewID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid review ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/auth"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
+)
+
+// SavedMethodHandler handles HTTP requests for saved payment methods
+type SavedMethodHandler struct {
+	savedMethodService service.SavedMethodService
+}
+
+// NewSavedMethodHandler creates a new SavedMethodHandler
+func NewSavedMethodHandler(savedMethodService service.SavedMethodService) *SavedMethodHandler {
+	return &SavedMethodHandler{
+		savedMethodService: savedMethodService,
+	}
+}
+
+// RegisterRoutes registers saved payment method routes
+func (h *SavedMethodHandler) RegisterRoutes(router *gin.RouterGroup) {
+	methods := router.Group("/payments/methods")
+	{
+		methods.GET("", h.GetMethods)
+		methods.POST("", h.SaveMethod)
+		methods.PUT("/:id/default", h.SetDefault)
+		methods.DELETE("/:id", h.DeleteMethod)
+	}
+}
+
+// GetMethods lists the current user's saved payment methods, the default first
+// GET /api/v1/payments/methods
+func (h *SavedMethodHandler) GetMethods(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	methods, err := h.savedMethodService.GetMethods(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    methods,
+	})
+}
+
+// SaveMethod keeps a provider token on file for the current user
+// POST /api/v1/payments/methods
+func (h *SavedMethodHandler) SaveMethod(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SavePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	method, err := h.savedMethodService.SaveMethod(userID, &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRawCardNumber), errors.Is(err, service.ErrInvalidToken),
+			errors.Is(err, service.ErrSavedMethodExpired), errors.Is(err, service.ErrMethodUnsupported),
+			errors.Is(err, service.ErrTokenizationUnsupported):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrProviderFailure):
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Payment method saved",
+		"data":    method,
+	})
+}
+
+// SetDefault makes one of the current user's saved methods their default
+// PUT /api/v1/payments/methods/:id/default
+func (h *SavedMethodHandler) SetDefault(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid payment method ID",
+		})
+		return
+	}
+
+	method, err := h.savedMethodService.SetDefault(userID, uint(id))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Default payment method updated",
+		"data":    method,
+	})
+}
+
+// DeleteMethod removes one of the current user's saved methods
+// DELETE /api/v1/payments/methods/:id
+func (h *SavedMethodHandler) DeleteMethod(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid payment method ID",
+		})
+		return
+	}
+
+	if err := h.savedMethodService.DeleteMethod(userID, uint(id)); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Payment method deleted",
+	})
+}
+
+func (h *SavedMethodHandler) respondError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrSavedMethodNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrSavedMethodExpired):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrProviderFailure):
+		status = http.StatusBadGateway
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+// currentUserID returns the authenticated user, answering 401 if there is none
+func currentUserID(c *gin.Context) (uint, bool) {
+	identity, ok := auth.Current(c)
+	if !ok || identity.UserID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "User not authenticated",
+		})
+		return 0, false
+	}
+	return identity.UserID, true
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/service"
+)
+
+// maxWebhookBody caps the size of a webhook payload
+const maxWebhookBody = 1 << 20
+
+// WebhookHandler handles payment provider webhooks
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// RegisterRoutes registers webhook routes
+func (h *WebhookHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/payments/webhooks/:provider", h.ProcessPaymentWebhook)
+}
+
+// ProcessPaymentWebhook verifies and applies a signed payment provider webhook
+// POST /api/v1/payments/webhooks/:provider
+func (h *WebhookHandler) ProcessPaymentWebhook(c *gin.Context) {
+	// The signature covers the exact bytes sent, so the body is read raw rather than bound
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"message": "Payload too large",
+		})
+		return
+	}
+
+	result, err := h.webhookService.HandleWebhook(&service.WebhookCall{
+		Provider:   c.Param("provider"),
+		Timestamp:  c.GetHeader(provider.HeaderWebhookTimestamp),
+		Signature:  c.GetHeader(provider.HeaderWebhookSignature),
+		RemoteAddr: c.ClientIP(),
+		Payload:    payload,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := err.Error()
+		switch {
+		case errors.Is(err, service.ErrWebhookUnverified):
+			// Don't tell the caller which check failed
+			status = http.StatusUnauthorized
+			message = "Webhook could not be verified"
+		case errors.Is(err, service.ErrProviderMismatch):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrInvalidWebhookPayload):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrPaymentNotFound), errors.Is(err, service.ErrRefundNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidStatus):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"message": message,
+		})
+		return
+	}
+
+	message := "Payment processed successfully"
+	if result.Duplicate {
+		message = "Event already processed"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    result,
+	})
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+)
+
+var (
+	ErrNoProviderForMethod = errors.New("no payment provider configured for this method")
+	ErrUnknownProvider     = errors.New("unknown payment provider")
+	ErrChargeNotFound      = errors.New("charge not found at provider")
+	ErrChargeNotCapturable = errors.New("charge cannot be captured in its current state")
+	ErrChargeNotVoidable   = errors.New("charge cannot be voided in its current state")
+	ErrInvalidToken        = errors.New("payment token is invalid or no longer usable")
+)
+
+// PaymentProvider is an external payment gateway. Charges are settled asynchronously:
+// the final outcome of a charge arrives later as a signed webhook.
+type PaymentProvider interface {
+	// Name identifies the provider in stored payments and webhook URLs
+	Name() string
+	CreateCharge(ctx context.Context, req *ChargeRequest) (*Charge, error)
+	Capture(ctx context.Context, providerRef string, amount float64) error
+	Void(ctx context.Context, providerRef string) error
+	// Refund asks for money back; the provider may settle it immediately or later by webhook
+	Refund(ctx context.Context, req *RefundRequest) (*Refund, error)
+	QueryStatus(ctx context.Context, providerRef string) (domain.PaymentStatus, error)
+}
+
+// Tokenizer is implemented by providers that can keep a customer's payment details on file.
+// The details are entered in the provider's own client-side form, which hands back a token;
+// full card numbers never reach our servers.
+type Tokenizer interface {
+	// DescribeToken returns the masked details behind a token
+	DescribeToken(ctx context.Context, token string) (*TokenDetails, error)
+	// DeleteToken tells the provider the token is no longer needed
+	DeleteToken(ctx context.Context, token string) error
+}
+
+// TokenDetails are the display details of a token, already masked by the provider
+type TokenDetails struct {
+	Method      domain.PaymentMethod
+	Brand       string // e.g. "visa" or "gopay"
+	Last4       string
+	ExpMonth    int // Zero for methods that don't expire
+	ExpYear     int
+	Fingerprint string // Same for every token of the same card or account
+}
+
+// ChargeRequest asks a provider to start collecting a payment
+type ChargeRequest struct {
+	TransactionID string // Our reference, echoed back in webhooks
+	OrderID       uint
+	UserID        uint
+	Amount        float64
+	Currency      string
+	Method        domain.PaymentMethod
+	ExpiresAt     *time.Time // When the customer's payment window closes, if it does
+	Token         string     `json:"-"` // Saved payment method to charge, if the customer picked one; kept out of event payloads
+}
+
+// Charge is a provider's answer to a charge request: what the customer needs to complete the payment
+type Charge struct {
+	ProviderRef string
+	Status      domain.PaymentStatus
+	PaymentCode string // Virtual account number or QRIS payload
+	RedirectURL string // E-wallet deeplink or card 3-D Secure page
+	ExpiresAt   *time.Time
+}
+
+// RefundRequest asks a provider to return money from a settled charge
+type RefundRequest struct {
+	ProviderRef   string
+	TransactionID string
+	Reference     string // Our refund reference, echoed back in webhooks
+	Amount        float64
+	Reason        string
+}
+
+// Refund is a provider's answer to a refund request. A pending refund is settled later by webhook.
+type Refund struct {
+	ProviderRef string
+	Status      domain.PaymentStatus
+}
+
+// Router picks the provider for each payment method
+type Router struct {
+	byMethod map[domain.PaymentMethod]PaymentProvider
+	byName   map[string]PaymentProvider
+}
+
+// NewRouter creates an empty Router
+func NewRouter() *Router {
+	return &Router{
+		byMethod: make(map[domain.PaymentMethod]PaymentProvider),
+		byName:   make(map[string]PaymentProvider),
+	}
+}
+
+// Route sends charges for the given methods to p
+func (r *Router) Route(p PaymentProvider, methods ...domain.PaymentMethod) {
+	r.byName[p.Name()] = p
+	for _, method := range methods {
+		r.byMethod[method] = p
+	}
+}
+
+// ForMethod returns the provider that handles new charges for method
+func (r *Router) ForMethod(method domain.PaymentMethod) (PaymentProvider, error) {
+	p, ok := r.byMethod[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoProviderForMethod, method)
+	}
+	return p, nil
+}
+
+// ByName returns a provider by name. Follow-up operations on a charge must go to
+// the provider that created it, even if the method has since been rerouted.
+func (r *Router) ByName(name string) (PaymentProvider, error) {
+	p, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return p, nil
+}
+
+// ParseRoutes parses "method:provider" pairs separated by commas, e.g. "qris:simulator,credit_card:simulator"
+func ParseRoutes(value string) (map[domain.PaymentMethod]string, error) {
+	routes := make(map[domain.PaymentMethod]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		method, name, ok := strings.Cut(pair, ":")
+		if !ok || method == "" || name == "" {
+			return nil, fmt.Errorf("invalid payment route %q, expected method:provider", pair)
+		}
+		routes[domain.PaymentMethod(method)] = name
+	}
+	return routes, nil
+}
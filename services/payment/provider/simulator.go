@@ -0,0 +1,328 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/rs/zerolog"
+)
+
+// SimulatorName is the provider name of the simulator, used as its webhook path segment
+const SimulatorName = "simulator"
+
+// Webhook signature headers, as expected by POST /payments/webhooks/:provider
+const (
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookSigner signs webhook bodies the way the receiving service verifies them
+type WebhookSigner interface {
+	Sign(provider string, body []byte) (timestamp, signature string, err error)
+}
+
+// SimulatorConfig controls how the simulator settles charges
+type SimulatorConfig struct {
+	// WebhookURL receives the settlement callbacks
+	WebhookURL string
+	// SuccessRate is the probability (0-1) that a charge settles successfully rather than failing
+	SuccessRate float64
+	// Latency is how long after creation a charge settles
+	Latency time.Duration
+	// FailureReason is reported for failed charges
+	FailureReason string
+	// ChargeTTL is how long the customer has to pay; zero means charges don't expire
+	ChargeTTL time.Duration
+	// MaxAttempts and RetryBackoff control redelivery of webhooks the receiver didn't acknowledge.
+	// The backoff doubles after each attempt.
+	MaxAttempts  int
+	RetryBackoff time.Duration
+}
+
+// DefaultSimulatorConfig returns a config where most charges succeed after a short delay
+func DefaultSimulatorConfig(webhookURL string) SimulatorConfig {
+	return SimulatorConfig{
+		WebhookURL:    webhookURL,
+		SuccessRate:   0.9,
+		Latency:       3 * time.Second,
+		FailureReason: "Declined by issuer (simulated)",
+		ChargeTTL:     24 * time.Hour,
+		MaxAttempts:   5,
+		RetryBackoff:  time.Second,
+	}
+}
+
+// Simulator is an in-memory PaymentProvider for local development and tests.
+// It settles every charge on its own after the configured latency and reports the
+// outcome through a signed webhook, like a real gateway would.
+type Simulator struct {
+	config     SimulatorConfig
+	signer     WebhookSigner
+	httpClient *http.Client
+	log        zerolog.Logger
+	random     func() float64
+
+	mu      sync.Mutex
+	charges map[string]*simulatedCharge
+}
+
+type simulatedCharge struct {
+	transactionID string
+	amount        float64
+	refunded      float64
+	status        domain.PaymentStatus
+	settle        *time.Timer
+}
+
+// NewSimulator creates a simulator that signs its webhooks with signer
+func NewSimulator(config SimulatorConfig, signer WebhookSigner, log zerolog.Logger) *Simulator {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	return &Simulator{
+		config:     config,
+		signer:     signer,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		log:        log,
+		random:     rand.Float64,
+		charges:    make(map[string]*simulatedCharge),
+	}
+}
+
+// simulatorTokens are the test tokens the simulator's checkout form hands out, like a sandbox gateway's
+var simulatorTokens = map[string]TokenDetails{
+	"tok_visa":         {Method: domain.PaymentMethodCreditCard, Brand: "visa", Last4: "4242", ExpMonth: 12, ExpYear: 2034, Fingerprint: "sim-fp-visa-4242"},
+	"tok_mastercard":   {Method: domain.PaymentMethodCreditCard, Brand: "mastercard", Last4: "4444", ExpMonth: 6, ExpYear: 2033, Fingerprint: "sim-fp-mastercard-4444"},
+	"tok_visa_expired": {Method: domain.PaymentMethodCreditCard, Brand: "visa", Last4: "0069", ExpMonth: 1, ExpYear: 2020, Fingerprint: "sim-fp-visa-0069"},
+	"tok_gopay":        {Method: domain.PaymentMethodEWallet, Brand: "gopay", Last4: "7890", Fingerprint: "sim-fp-gopay-7890"},
+}
+
+func (s *Simulator) Name() string {
+	return SimulatorName
+}
+
+// DescribeToken returns the details of one of the simulator's test tokens
+func (s *Simulator) DescribeToken(ctx context.Context, token string) (*TokenDetails, error) {
+	details, ok := simulatorTokens[token]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return &details, nil
+}
+
+// DeleteToken accepts any known token; the test tokens themselves are never removed
+func (s *Simulator) DeleteToken(ctx context.Context, token string) error {
+	if _, ok := simulatorTokens[token]; !ok {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func (s *Simulator) CreateCharge(ctx context.Context, req *ChargeRequest) (*Charge, error) {
+	ref := "SIM-" + uuid.New().String()
+	charge := &Charge{ProviderRef: ref, Status: domain.PaymentStatusPending}
+
+	if req.Token != "" {
+		details, ok := simulatorTokens[req.Token]
+		if !ok || details.Method != req.Method {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	switch req.Method {
+	case domain.PaymentMethodVA, domain.PaymentMethodBankTransfer:
+		charge.PaymentCode = fmt.Sprintf("8808%012d", req.OrderID)
+	case domain.PaymentMethodQRIS:
+		charge.PaymentCode = fmt.Sprintf("00020101021226SIMULATOR%s5303360540%.0f6304", ref, req.Amount)
+	case domain.PaymentMethodEWallet, domain.PaymentMethodCreditCard:
+		// Saved methods are charged without sending the customer through checkout again
+		if req.Token == "" {
+			charge.RedirectURL = "https://simulator.invalid/checkout/" + ref
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNoProviderForMethod, req.Method)
+	}
+	if req.ExpiresAt != nil {
+		charge.ExpiresAt = req.ExpiresAt
+	} else if s.config.ChargeTTL > 0 {
+		expiresAt := time.Now().Add(s.config.ChargeTTL)
+		charge.ExpiresAt = &expiresAt
+	}
+
+	sc := &simulatedCharge{
+		transactionID: req.TransactionID,
+		amount:        req.Amount,
+		status:        domain.PaymentStatusPending,
+	}
+	s.mu.Lock()
+	s.charges[ref] = sc
+	sc.settle = time.AfterFunc(s.config.Latency, func() { s.settle(ref) })
+	s.mu.Unlock()
+
+	return charge, nil
+}
+
+// Capture succeeds for settled charges; the simulator captures card payments automatically
+func (s *Simulator) Capture(ctx context.Context, providerRef string, amount float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, ok := s.charges[providerRef]
+	if !ok {
+		return ErrChargeNotFound
+	}
+	if sc.status != domain.PaymentStatusSuccess || amount > sc.amount {
+		return ErrChargeNotCapturable
+	}
+	return nil
+}
+
+// Void cancels a charge that hasn't settled yet. No webhook is sent for it.
+func (s *Simulator) Void(ctx context.Context, providerRef string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, ok := s.charges[providerRef]
+	if !ok {
+		return ErrChargeNotFound
+	}
+	if sc.status != domain.PaymentStatusPending {
+		return ErrChargeNotVoidable
+	}
+	sc.settle.Stop()
+	sc.status = domain.PaymentStatusCancelled
+	return nil
+}
+
+// Refund accepts the refund and settles it successfully after the configured latency
+func (s *Simulator) Refund(ctx context.Context, req *RefundRequest) (*Refund, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, ok := s.charges[req.ProviderRef]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	if sc.status != domain.PaymentStatusSuccess && sc.status != domain.PaymentStatusRefunded {
+		return nil, errors.New("simulator: only settled charges can be refunded")
+	}
+	if sc.refunded+req.Amount > sc.amount {
+		return nil, errors.New("simulator: refund exceeds the charged amount")
+	}
+
+	sc.refunded += req.Amount
+	if sc.refunded >= sc.amount {
+		sc.status = domain.PaymentStatusRefunded
+	}
+
+	ref := "SIM-RF-" + uuid.New().String()
+	event := dto.ProcessPaymentRequest{
+		EventID:         "sim-evt-" + uuid.New().String(),
+		TransactionID:   sc.transactionID,
+		RefundReference: req.Reference,
+		ProviderRef:     ref,
+		Status:          domain.PaymentStatusSuccess,
+	}
+	time.AfterFunc(s.config.Latency, func() { s.deliver(&event) })
+
+	return &Refund{ProviderRef: ref, Status: domain.PaymentStatusPending}, nil
+}
+
+func (s *Simulator) QueryStatus(ctx context.Context, providerRef string) (domain.PaymentStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, ok := s.charges[providerRef]
+	if !ok {
+		return "", ErrChargeNotFound
+	}
+	return sc.status, nil
+}
+
+// settle decides the outcome of a pending charge and notifies the webhook URL
+func (s *Simulator) settle(ref string) {
+	s.mu.Lock()
+	sc := s.charges[ref]
+	if sc.status != domain.PaymentStatusPending {
+		// Voided while the timer was firing
+		s.mu.Unlock()
+		return
+	}
+	event := dto.ProcessPaymentRequest{
+		EventID:       "sim-evt-" + uuid.New().String(),
+		TransactionID: sc.transactionID,
+		ProviderRef:   ref,
+		Status:        domain.PaymentStatusSuccess,
+	}
+	if s.random() >= s.config.SuccessRate {
+		event.Status = domain.PaymentStatusFailed
+		event.FailureReason = s.config.FailureReason
+	}
+	sc.status = event.Status
+	s.mu.Unlock()
+
+	s.deliver(&event)
+}
+
+// deliver posts a webhook, retrying with exponential backoff until the receiver acknowledges it
+func (s *Simulator) deliver(event *dto.ProcessPaymentRequest) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		s.log.Error().Err(err).Str("event_id", event.EventID).Msg("Failed to encode simulated webhook")
+		return
+	}
+
+	backoff := s.config.RetryBackoff
+	for attempt := 1; attempt <= s.config.MaxAttempts; attempt++ {
+		// Re-signed on each attempt so redeliveries stay within the receiver's timestamp tolerance
+		err = s.post(body)
+		if err == nil {
+			return
+		}
+		s.log.Warn().Err(err).
+			Str("event_id", event.EventID).
+			Int("attempt", attempt).
+			Msg("Simulated webhook delivery failed")
+		if attempt < s.config.MaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	s.log.Error().Str("event_id", event.EventID).Msg("Giving up on simulated webhook")
+}
+
+func (s *Simulator) post(body []byte) error {
+	timestamp, signature, err := s.signer.Sign(SimulatorName, body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, signature)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook receiver returned status %d", resp.StatusCode)
+	}
+	return nil
+}
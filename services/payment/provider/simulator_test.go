@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(provider string, body []byte) (string, string, error) {
+	return "1700000000", "sig-" + provider, nil
+}
+
+type receivedWebhook struct {
+	header http.Header
+	event  dto.ProcessPaymentRequest
+}
+
+// newWebhookSink starts a receiver that fails the first `failures` deliveries
+func newWebhookSink(t *testing.T, failures int) (*httptest.Server, <-chan receivedWebhook) {
+	received := make(chan receivedWebhook, 10)
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= int32(failures) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var event dto.ProcessPaymentRequest
+		require.NoError(t, json.Unmarshal(body, &event))
+		received <- receivedWebhook{header: r.Header, event: event}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func newTestSimulator(webhookURL string, roll float64) *Simulator {
+	config := DefaultSimulatorConfig(webhookURL)
+	config.Latency = 0
+	config.RetryBackoff = time.Millisecond
+	sim := NewSimulator(config, fakeSigner{}, zerolog.Nop())
+	sim.random = func() float64 { return roll }
+	return sim
+}
+
+func awaitWebhook(t *testing.T, received <-chan receivedWebhook) receivedWebhook {
+	t.Helper()
+	select {
+	case webhook := <-received:
+		return webhook
+	case <-time.After(2 * time.Second):
+		t.Fatal("no webhook received")
+		return receivedWebhook{}
+	}
+}
+
+func TestSimulator_SettlesChargeWithSignedWebhook(t *testing.T) {
+	// Arrange
+	server, received := newWebhookSink(t, 0)
+	sim := newTestSimulator(server.URL, 0.1)
+
+	// Act
+	charge, err := sim.CreateCharge(context.Background(), &ChargeRequest{
+		TransactionID: "TXN-1", OrderID: 42, Amount: 150_000, Method: domain.PaymentMethodVA,
+	})
+	require.NoError(t, err)
+	webhook := awaitWebhook(t, received)
+
+	// Assert
+	assert.Equal(t, domain.PaymentStatusPending, charge.Status)
+	assert.Equal(t, "8808000000000042", charge.PaymentCode)
+	assert.Equal(t, "1700000000", webhook.header.Get(HeaderWebhookTimestamp))
+	assert.Equal(t, "sig-simulator", webhook.header.Get(HeaderWebhookSignature))
+	assert.Equal(t, "TXN-1", webhook.event.TransactionID)
+	assert.Equal(t, charge.ProviderRef, webhook.event.ProviderRef)
+	assert.Equal(t, domain.PaymentStatusSuccess, webhook.event.Status)
+	assert.NotEmpty(t, webhook.event.EventID)
+
+	status, err := sim.QueryStatus(context.Background(), charge.ProviderRef)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusSuccess, status)
+}
+
+func TestSimulator_FailsChargesAboveSuccessRate(t *testing.T) {
+	server, received := newWebhookSink(t, 0)
+	sim := newTestSimulator(server.URL, 0.95)
+
+	_, err := sim.CreateCharge(context.Background(), &ChargeRequest{
+		TransactionID: "TXN-2", Amount: 10_000, Method: domain.PaymentMethodCreditCard,
+	})
+	require.NoError(t, err)
+	webhook := awaitWebhook(t, received)
+
+	assert.Equal(t, domain.PaymentStatusFailed, webhook.event.Status)
+	assert.Equal(t, sim.config.FailureReason, webhook.event.FailureReason)
+}
+
+func TestSimulator_RetriesUnacknowledgedWebhooks(t *testing.T) {
+	server, received := newWebhookSink(t, 2)
+	sim := newTestSimulator(server.URL, 0)
+
+	_, err := sim.CreateCharge(context.Background(), &ChargeRequest{
+		TransactionID: "TXN-3", Amount: 10_000, Method: domain.PaymentMethodQRIS,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "TXN-3", awaitWebhook(t, received).event.TransactionID)
+}
+
+func TestSimulator_VoidStopsSettlement(t *testing.T) {
+	// Arrange: a charge that would settle well after the test ends
+	server, received := newWebhookSink(t, 0)
+	sim := newTestSimulator(server.URL, 0)
+	sim.config.Latency = time.Hour
+	charge, err := sim.CreateCharge(context.Background(), &ChargeRequest{
+		TransactionID: "TXN-4", Amount: 10_000, Method: domain.PaymentMethodEWallet,
+	})
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, sim.Void(context.Background(), charge.ProviderRef))
+
+	// Assert
+	status, err := sim.QueryStatus(context.Background(), charge.ProviderRef)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusCancelled, status)
+	assert.ErrorIs(t, sim.Void(context.Background(), charge.ProviderRef), ErrChargeNotVoidable)
+	assert.Empty(t, received)
+}
+
+func TestSimulator_ChargesSavedTokens(t *testing.T) {
+	// Arrange
+	sim := newTestSimulator("http://unused", 0)
+	sim.config.Latency = time.Hour
+
+	// Act
+	charge, err := sim.CreateCharge(context.Background(), &ChargeRequest{
+		TransactionID: "TXN-5", Amount: 10_000, Method: domain.PaymentMethodCreditCard, Token: "tok_visa",
+	})
+	_, unknownErr := sim.CreateCharge(context.Background(), &ChargeRequest{
+		TransactionID: "TXN-6", Amount: 10_000, Method: domain.PaymentMethodCreditCard, Token: "tok_unknown",
+	})
+
+	// Assert: no checkout redirect for a card already on file
+	require.NoError(t, err)
+	assert.Empty(t, charge.RedirectURL)
+	assert.ErrorIs(t, unknownErr, ErrInvalidToken)
+	require.NoError(t, sim.Void(context.Background(), charge.ProviderRef))
+}
+
+func TestRouter_RoutesByMethodAndName(t *testing.T) {
+	sim := newTestSimulator("http://unused", 0)
+	router := NewRouter()
+	router.Route(sim, domain.PaymentMethodQRIS)
+
+	p, err := router.ForMethod(domain.PaymentMethodQRIS)
+	require.NoError(t, err)
+	assert.Same(t, sim, p)
+
+	_, err = router.ForMethod(domain.PaymentMethodCreditCard)
+	assert.ErrorIs(t, err, ErrNoProviderForMethod)
+
+	p, err = router.ByName(SimulatorName)
+	require.NoError(t, err)
+	assert.Same(t, sim, p)
+}
+
+func TestSimulator_SettlesRefundsByWebhook(t *testing.T) {
+	// Arrange: a settled charge
+	server, received := newWebhookSink(t, 0)
+	sim := newTestSimulator(server.URL, 0)
+	charge, err := sim.CreateCharge(context.Background(), &ChargeRequest{
+		TransactionID: "TXN-5", Amount: 10_000, Method: domain.PaymentMethodQRIS,
+	})
+	require.NoError(t, err)
+	awaitWebhook(t, received)
+
+	// Act
+	refund, err := sim.Refund(context.Background(), &RefundRequest{
+		ProviderRef: charge.ProviderRef, Reference: "return-1", Amount: 4_000,
+	})
+	require.NoError(t, err)
+	webhook := awaitWebhook(t, received)
+
+	// Assert
+	assert.Equal(t, domain.PaymentStatusPending, refund.Status)
+	assert.Equal(t, "TXN-5", webhook.event.TransactionID)
+	assert.Equal(t, "return-1", webhook.event.RefundReference)
+	assert.Equal(t, refund.ProviderRef, webhook.event.ProviderRef)
+	assert.Equal(t, domain.PaymentStatusSuccess, webhook.event.Status)
+
+	_, err = sim.Refund(context.Background(), &RefundRequest{ProviderRef: charge.ProviderRef, Amount: 7_000})
+	assert.Error(t, err)
+}
@@ -0,0 +1,534 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+)
+
+// MockPaymentRepository is a mock implementation for testing.
+// It stores copies, so changes to a returned payment are not visible until saved.
+type MockPaymentRepository struct {
+	payments map[uint]domain.Payment
+	events   []domain.PaymentEvent
+	nextID   uint
+}
+
+func NewMockPaymentRepository() *MockPaymentRepository {
+	return &MockPaymentRepository{
+		payments: make(map[uint]domain.Payment),
+		nextID:   1,
+	}
+}
+
+func (m *MockPaymentRepository) Create(payment *domain.Payment) error {
+	payment.ID = m.nextID
+	m.nextID++
+	m.payments[payment.ID] = *payment
+	return nil
+}
+
+func (m *MockPaymentRepository) FindByID(id uint) (*domain.Payment, error) {
+	payment, ok := m.payments[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &payment, nil
+}
+
+func (m *MockPaymentRepository) FindByOrderID(orderID uint) (*domain.Payment, error) {
+	var latest *domain.Payment
+	for _, payment := range m.payments {
+		if payment.OrderID == orderID && (latest == nil || payment.ID > latest.ID) {
+			latest = &payment
+		}
+	}
+	return latest, nil
+}
+
+func (m *MockPaymentRepository) FindByOrderIDs(orderIDs []uint) ([]domain.Payment, error) {
+	var result []domain.Payment
+	for _, id := range orderIDs {
+		if payment, _ := m.FindByOrderID(id); payment != nil {
+			result = append(result, *payment)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockPaymentRepository) FindExpired(now time.Time, limit int) ([]domain.Payment, error) {
+	var result []domain.Payment
+	for _, payment := range m.payments {
+		if payment.Status == domain.PaymentStatusPending && payment.ExpiresAt != nil && !payment.ExpiresAt.After(now) {
+			result = append(result, payment)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ExpiresAt.Before(*result[j].ExpiresAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockPaymentRepository) FindByTransactionID(transactionID string) (*domain.Payment, error) {
+	for _, payment := range m.payments {
+		if payment.TransactionID == transactionID {
+			return &payment, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockPaymentRepository) FindByTransactionIDs(transactionIDs []string) ([]domain.Payment, error) {
+	var result []domain.Payment
+	for _, id := range transactionIDs {
+		if payment, _ := m.FindByTransactionID(id); payment != nil {
+			result = append(result, *payment)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockPaymentRepository) FindByProviderRefs(provider string, providerRefs []string) ([]domain.Payment, error) {
+	var result []domain.Payment
+	for _, ref := range providerRefs {
+		for _, payment := range m.payments {
+			if payment.Provider == provider && payment.ProviderRef == ref {
+				result = append(result, payment)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *MockPaymentRepository) FindPaidBetween(provider string, from, to time.Time) ([]domain.Payment, error) {
+	var result []domain.Payment
+	for _, payment := range m.payments {
+		paid := payment.Status == domain.PaymentStatusSuccess || payment.Status == domain.PaymentStatusRefunded
+		if payment.Provider == provider && paid && payment.PaidAt != nil &&
+			!payment.PaidAt.Before(from) && payment.PaidAt.Before(to) {
+			result = append(result, payment)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PaidAt.Before(*result[j].PaidAt) })
+	return result, nil
+}
+
+func (m *MockPaymentRepository) FindByUserID(userID uint, page, pageSize int) ([]domain.Payment, int64, error) {
+	var result []domain.Payment
+	for _, payment := range m.payments {
+		if payment.UserID == userID {
+			result = append(result, payment)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *MockPaymentRepository) CountFailedSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	for _, payment := range m.payments {
+		if payment.UserID == userID && payment.Status == domain.PaymentStatusFailed && !payment.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockPaymentRepository) Update(payment *domain.Payment) error {
+	m.payments[payment.ID] = *payment
+	return nil
+}
+
+func (m *MockPaymentRepository) UpdateCharge(payment *domain.Payment) error {
+	stored := m.payments[payment.ID]
+	stored.Provider = payment.Provider
+	stored.ProviderRef = payment.ProviderRef
+	stored.PaymentCode = payment.PaymentCode
+	stored.RedirectURL = payment.RedirectURL
+	stored.ExpiresAt = payment.ExpiresAt
+	m.payments[payment.ID] = stored
+	return nil
+}
+
+func (m *MockPaymentRepository) UpdateStatus(id uint, status domain.PaymentStatus) error {
+	stored := m.payments[id]
+	stored.Status = status
+	m.payments[id] = stored
+	return nil
+}
+
+func (m *MockPaymentRepository) Transition(payment *domain.Payment, from domain.PaymentStatus, event *domain.PaymentEvent) (bool, error) {
+	if m.payments[payment.ID].Status != from {
+		return false, nil
+	}
+	m.payments[payment.ID] = *payment
+	event.PaymentID = payment.ID
+	return true, m.AddEvent(event)
+}
+
+func (m *MockPaymentRepository) AddEvent(event *domain.PaymentEvent) error {
+	event.ID = uint(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *MockPaymentRepository) FindEvents(paymentID uint) ([]domain.PaymentEvent, error) {
+	var result []domain.PaymentEvent
+	for _, event := range m.events {
+		if event.PaymentID == paymentID {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// MockRefundRepository is a mock implementation for testing, backed by a MockPaymentRepository
+type MockRefundRepository struct {
+	payments *MockPaymentRepository
+	refunds  map[uint]domain.Refund
+	nextID   uint
+}
+
+func NewMockRefundRepository(payments *MockPaymentRepository) *MockRefundRepository {
+	return &MockRefundRepository{
+		payments: payments,
+		refunds:  make(map[uint]domain.Refund),
+		nextID:   1,
+	}
+}
+
+func (m *MockRefundRepository) Reserve(refund *domain.Refund) (bool, error) {
+	payment := m.payments.payments[refund.PaymentID]
+	committed := 0.0
+	for _, existing := range m.refunds {
+		if existing.PaymentID == refund.PaymentID && existing.Status != domain.RefundStatusFailed {
+			committed += existing.Amount
+		}
+	}
+
+	amount, fits := payment.FitRefund(refund.Amount, committed)
+	if !fits {
+		return false, nil
+	}
+	refund.Amount = amount
+	refund.ID = m.nextID
+	m.nextID++
+	m.refunds[refund.ID] = *refund
+	return true, nil
+}
+
+func (m *MockRefundRepository) Reopen(refund *domain.Refund) (bool, error) {
+	stored := m.refunds[refund.ID]
+	if stored.Status != domain.RefundStatusFailed {
+		return false, nil
+	}
+	payment := m.payments.payments[refund.PaymentID]
+	committed := 0.0
+	for _, existing := range m.refunds {
+		if existing.PaymentID == refund.PaymentID && existing.Status != domain.RefundStatusFailed {
+			committed += existing.Amount
+		}
+	}
+	if _, fits := payment.FitRefund(refund.Amount, committed); !fits {
+		return false, nil
+	}
+
+	stored.Status = domain.RefundStatusPending
+	stored.ProviderRef = ""
+	stored.FailureReason = ""
+	stored.CompletedAt = nil
+	m.refunds[refund.ID] = stored
+	*refund = stored
+	return true, nil
+}
+
+func (m *MockRefundRepository) FindByReference(paymentID uint, reference string) (*domain.Refund, error) {
+	for _, refund := range m.refunds {
+		if refund.PaymentID == paymentID && refund.Reference == reference {
+			return &refund, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockRefundRepository) FindByPaymentID(paymentID uint) ([]domain.Refund, error) {
+	var result []domain.Refund
+	for id := uint(1); id < m.nextID; id++ {
+		if refund, ok := m.refunds[id]; ok && refund.PaymentID == paymentID {
+			result = append(result, refund)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockRefundRepository) MarkSubmitted(refund *domain.Refund) error {
+	stored := m.refunds[refund.ID]
+	if stored.Status == domain.RefundStatusPending {
+		stored.Status = domain.RefundStatusProcessing
+		stored.ProviderRef = refund.ProviderRef
+		m.refunds[refund.ID] = stored
+	}
+	return nil
+}
+
+func (m *MockRefundRepository) Settle(refund *domain.Refund, update func(payment *domain.Payment, refundedTotal float64) *domain.PaymentEvent) (bool, error) {
+	if m.refunds[refund.ID].Status.IsFinal() {
+		return false, nil
+	}
+	m.refunds[refund.ID] = *refund
+
+	refundedTotal := 0.0
+	for _, existing := range m.refunds {
+		if existing.PaymentID == refund.PaymentID && existing.Status == domain.RefundStatusSuccess {
+			refundedTotal += existing.Amount
+		}
+	}
+
+	payment := m.payments.payments[refund.PaymentID]
+	event := update(&payment, refundedTotal)
+	m.payments.payments[payment.ID] = payment
+	if event != nil {
+		event.PaymentID = payment.ID
+		return true, m.payments.AddEvent(event)
+	}
+	return true, nil
+}
+
+// MockReconciliationRepository is a mock implementation for testing
+type MockReconciliationRepository struct {
+	reconciliations []domain.Reconciliation
+}
+
+func NewMockReconciliationRepository() *MockReconciliationRepository {
+	return &MockReconciliationRepository{}
+}
+
+func (m *MockReconciliationRepository) Create(reconciliation *domain.Reconciliation) error {
+	reconciliation.ID = uint(len(m.reconciliations) + 1)
+	for i := range reconciliation.Entries {
+		reconciliation.Entries[i].ID = uint(i + 1)
+		reconciliation.Entries[i].ReconciliationID = reconciliation.ID
+	}
+	m.reconciliations = append(m.reconciliations, *reconciliation)
+	return nil
+}
+
+func (m *MockReconciliationRepository) FindByID(id uint) (*domain.Reconciliation, error) {
+	if id == 0 || int(id) > len(m.reconciliations) {
+		return nil, errors.New("record not found")
+	}
+	reconciliation := m.reconciliations[id-1]
+	reconciliation.Entries = nil
+	return &reconciliation, nil
+}
+
+func (m *MockReconciliationRepository) FindAll(page, pageSize int) ([]domain.Reconciliation, int64, error) {
+	var result []domain.Reconciliation
+	for i := len(m.reconciliations) - 1; i >= 0; i-- {
+		reconciliation := m.reconciliations[i]
+		reconciliation.Entries = nil
+		result = append(result, reconciliation)
+	}
+	total := int64(len(result))
+
+	start := (page - 1) * pageSize
+	if start > len(result) {
+		start = len(result)
+	}
+	end := start + pageSize
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[start:end], total, nil
+}
+
+func (m *MockReconciliationRepository) FindEntries(reconciliationID uint, status domain.ReconciliationStatus) ([]domain.ReconciliationEntry, error) {
+	if reconciliationID == 0 || int(reconciliationID) > len(m.reconciliations) {
+		return nil, nil
+	}
+	var result []domain.ReconciliationEntry
+	for _, entry := range m.reconciliations[reconciliationID-1].Entries {
+		if status == "" || entry.Status == status {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// MockSavedMethodRepository is a mock implementation for testing
+type MockSavedMethodRepository struct {
+	methods []domain.SavedPaymentMethod
+	nextID  uint
+}
+
+func NewMockSavedMethodRepository() *MockSavedMethodRepository {
+	return &MockSavedMethodRepository{nextID: 1}
+}
+
+// All returns every stored method, as persisted
+func (m *MockSavedMethodRepository) All() []domain.SavedPaymentMethod {
+	return append([]domain.SavedPaymentMethod(nil), m.methods...)
+}
+
+func (m *MockSavedMethodRepository) Create(method *domain.SavedPaymentMethod) error {
+	method.ID = m.nextID
+	m.nextID++
+	method.IsDefault = true
+	for _, existing := range m.methods {
+		if existing.UserID == method.UserID {
+			method.IsDefault = false
+		}
+	}
+	m.methods = append(m.methods, *method)
+	return nil
+}
+
+func (m *MockSavedMethodRepository) FindByID(id uint) (*domain.SavedPaymentMethod, error) {
+	for _, method := range m.methods {
+		if method.ID == id {
+			return &method, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *MockSavedMethodRepository) FindByUserID(userID uint) ([]domain.SavedPaymentMethod, error) {
+	var result []domain.SavedPaymentMethod
+	for i := len(m.methods) - 1; i >= 0; i-- {
+		if m.methods[i].UserID == userID {
+			result = append(result, m.methods[i])
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].IsDefault && !result[j].IsDefault
+	})
+	return result, nil
+}
+
+func (m *MockSavedMethodRepository) FindByFingerprint(userID uint, provider, fingerprint string) (*domain.SavedPaymentMethod, error) {
+	if fingerprint == "" {
+		return nil, nil
+	}
+	for _, method := range m.methods {
+		if method.UserID == userID && method.Provider == provider && method.Fingerprint == fingerprint {
+			return &method, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockSavedMethodRepository) SetDefault(userID, id uint) error {
+	for i := range m.methods {
+		if m.methods[i].UserID == userID {
+			m.methods[i].IsDefault = m.methods[i].ID == id
+		}
+	}
+	return nil
+}
+
+func (m *MockSavedMethodRepository) Delete(method *domain.SavedPaymentMethod) error {
+	var newest uint
+	for i := len(m.methods) - 1; i >= 0; i-- {
+		if m.methods[i].ID == method.ID {
+			m.methods = append(m.methods[:i], m.methods[i+1:]...)
+			continue
+		}
+		if m.methods[i].UserID == method.UserID && newest == 0 {
+			newest = m.methods[i].ID
+		}
+	}
+	if method.IsDefault && newest != 0 {
+		return m.SetDefault(method.UserID, newest)
+	}
+	return nil
+}
+
+// MockRiskRepository is a mock implementation for testing.
+// Payments let through by an assessment are stored in the given payment repository.
+type MockRiskRepository struct {
+	assessments []domain.RiskAssessment
+	payments    *MockPaymentRepository
+}
+
+func NewMockRiskRepository(payments *MockPaymentRepository) *MockRiskRepository {
+	return &MockRiskRepository{payments: payments}
+}
+
+// All returns every stored assessment, oldest first
+func (m *MockRiskRepository) All() []domain.RiskAssessment {
+	return append([]domain.RiskAssessment(nil), m.assessments...)
+}
+
+func (m *MockRiskRepository) Create(assessment *domain.RiskAssessment, payment *domain.Payment) error {
+	if payment != nil {
+		if err := m.payments.Create(payment); err != nil {
+			return err
+		}
+		assessment.PaymentID = &payment.ID
+	}
+	assessment.ID = uint(len(m.assessments) + 1)
+	m.assessments = append(m.assessments, *assessment)
+	return nil
+}
+
+func (m *MockRiskRepository) FindByID(id uint) (*domain.RiskAssessment, error) {
+	if id == 0 || int(id) > len(m.assessments) {
+		return nil, errors.New("record not found")
+	}
+	assessment := m.assessments[id-1]
+	return &assessment, nil
+}
+
+func (m *MockRiskRepository) FindReviews(status domain.ReviewStatus, page, pageSize int) ([]domain.RiskAssessment, int64, error) {
+	var result []domain.RiskAssessment
+	for _, assessment := range m.assessments {
+		if assessment.ReviewStatus == status {
+			result = append(result, assessment)
+		}
+	}
+	if status != domain.ReviewStatusPending {
+		sort.SliceStable(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	}
+	total := int64(len(result))
+
+	start := min((page-1)*pageSize, len(result))
+	end := min(start+pageSize, len(result))
+	return result[start:end], total, nil
+}
+
+func (m *MockRiskRepository) Resolve(assessment *domain.RiskAssessment) (bool, error) {
+	if assessment.ID == 0 || int(assessment.ID) > len(m.assessments) {
+		return false, nil
+	}
+	stored := &m.assessments[assessment.ID-1]
+	if stored.ReviewStatus != domain.ReviewStatusPending {
+		return false, nil
+	}
+	stored.ReviewStatus = assessment.ReviewStatus
+	stored.ReviewedBy = assessment.ReviewedBy
+	stored.ReviewedAt = assessment.ReviewedAt
+	stored.ReviewNote = assessment.ReviewNote
+	return true, nil
+}
+
+func (m *MockRiskRepository) CountByUser(userID uint, since time.Time) (int64, error) {
+	var count int64
+	for _, assessment := range m.assessments {
+		if assessment.UserID == userID && !assessment.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockRiskRepository) CountByIP(clientIP string, since time.Time) (int64, error) {
+	var count int64
+	for _, assessment := range m.assessments {
+		if assessment.ClientIP == clientIP && !assessment.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
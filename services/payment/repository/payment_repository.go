@@ -1,14 +1,36 @@
 package repository
 
-import "github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+)
 
 // PaymentRepository defines the interface for payment data operations
 type PaymentRepository interface {
 	Create(payment *domain.Payment) error
 	FindByID(id uint) (*domain.Payment, error)
+	// FindByOrderID returns the order's latest payment attempt, or nil if it has none
 	FindByOrderID(orderID uint) (*domain.Payment, error)
+	// FindByOrderIDs returns the latest payment attempt of each order that has one
+	FindByOrderIDs(orderIDs []uint) ([]domain.Payment, error)
 	FindByTransactionID(transactionID string) (*domain.Payment, error)
+	FindByTransactionIDs(transactionIDs []string) ([]domain.Payment, error)
+	FindByProviderRefs(provider string, providerRefs []string) ([]domain.Payment, error)
+	// FindPaidBetween returns the provider's payments that were paid in [from, to), including since refunded ones
+	FindPaidBetween(provider string, from, to time.Time) ([]domain.Payment, error)
 	FindByUserID(userID uint, page, pageSize int) ([]domain.Payment, int64, error)
+	// CountFailedSince counts the user's payments created since a time that failed
+	CountFailedSince(userID uint, since time.Time) (int64, error)
+	// FindExpired returns up to limit pending payments whose payment window closed at or before now, oldest first
+	FindExpired(now time.Time, limit int) ([]domain.Payment, error)
 	Update(payment *domain.Payment) error
+	// UpdateCharge saves only the provider charge details, leaving the status to webhooks
+	UpdateCharge(payment *domain.Payment) error
 	UpdateStatus(id uint, status domain.PaymentStatus) error
+	// Transition saves the payment together with its event, provided the stored status is still from.
+	// It reports false if the status was changed concurrently.
+	Transition(payment *domain.Payment, from domain.PaymentStatus, event *domain.PaymentEvent) (bool, error)
+	AddEvent(event *domain.PaymentEvent) error
+	FindEvents(paymentID uint) ([]domain.PaymentEvent, error)
 }
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
 	"gorm.io/gorm"
 )
@@ -29,7 +31,7 @@ func (r *paymentRepositoryImpl) FindByID(id uint) (*domain.Payment, error) {
 
 func (r *paymentRepositoryImpl) FindByOrderID(orderID uint) (*domain.Payment, error) {
 	var payment domain.Payment
-	err := r.db.Where("order_id = ?", orderID).First(&payment).Error
+	err := r.db.Where("order_id = ?", orderID).Order("created_at DESC, id DESC").First(&payment).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -39,6 +41,26 @@ func (r *paymentRepositoryImpl) FindByOrderID(orderID uint) (*domain.Payment, er
 	return &payment, nil
 }
 
+func (r *paymentRepositoryImpl) FindByOrderIDs(orderIDs []uint) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Raw(`
+		SELECT DISTINCT ON (order_id) * FROM payments
+		WHERE order_id IN ?
+		ORDER BY order_id, created_at DESC, id DESC`,
+		orderIDs,
+	).Scan(&payments).Error
+	return payments, err
+}
+
+func (r *paymentRepositoryImpl) FindExpired(now time.Time, limit int) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("status = ? AND expires_at <= ?", domain.PaymentStatusPending, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
 func (r *paymentRepositoryImpl) FindByTransactionID(transactionID string) (*domain.Payment, error) {
 	var payment domain.Payment
 	err := r.db.Where("transaction_id = ?", transactionID).First(&payment).Error
@@ -51,6 +73,27 @@ func (r *paymentRepositoryImpl) FindByTransactionID(transactionID string) (*doma
 	return &payment, nil
 }
 
+func (r *paymentRepositoryImpl) FindByTransactionIDs(transactionIDs []string) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("transaction_id IN ?", transactionIDs).Find(&payments).Error
+	return payments, err
+}
+
+func (r *paymentRepositoryImpl) FindByProviderRefs(provider string, providerRefs []string) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("provider = ? AND provider_ref IN ?", provider, providerRefs).Find(&payments).Error
+	return payments, err
+}
+
+func (r *paymentRepositoryImpl) FindPaidBetween(provider string, from, to time.Time) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("provider = ? AND status IN ? AND paid_at >= ? AND paid_at < ?",
+		provider, []domain.PaymentStatus{domain.PaymentStatusSuccess, domain.PaymentStatusRefunded}, from, to).
+		Order("paid_at").
+		Find(&payments).Error
+	return payments, err
+}
+
 func (r *paymentRepositoryImpl) FindByUserID(userID uint, page, pageSize int) ([]domain.Payment, int64, error) {
 	var payments []domain.Payment
 	var total int64
@@ -67,10 +110,51 @@ func (r *paymentRepositoryImpl) FindByUserID(userID uint, page, pageSize int) ([
 	return payments, total, err
 }
 
+func (r *paymentRepositoryImpl) CountFailedSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Payment{}).
+		Where("user_id = ? AND status = ? AND created_at >= ?", userID, domain.PaymentStatusFailed, since).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *paymentRepositoryImpl) Update(payment *domain.Payment) error {
 	return r.db.Save(payment).Error
 }
 
+func (r *paymentRepositoryImpl) UpdateCharge(payment *domain.Payment) error {
+	return r.db.Model(payment).
+		Select("provider", "provider_ref", "payment_code", "redirect_url", "expires_at").
+		Updates(payment).Error
+}
+
 func (r *paymentRepositoryImpl) UpdateStatus(id uint, status domain.PaymentStatus) error {
 	return r.db.Model(&domain.Payment{}).Where("id = ?", id).Update("status", status).Error
 }
+
+func (r *paymentRepositoryImpl) Transition(payment *domain.Payment, from domain.PaymentStatus, event *domain.PaymentEvent) (bool, error) {
+	applied := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(payment).Where("status = ?", from).Select("*").Omit("id", "created_at").Updates(payment)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		applied = true
+		event.PaymentID = payment.ID
+		return tx.Create(event).Error
+	})
+	return applied, err
+}
+
+func (r *paymentRepositoryImpl) AddEvent(event *domain.PaymentEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *paymentRepositoryImpl) FindEvents(paymentID uint) ([]domain.PaymentEvent, error) {
+	var events []domain.PaymentEvent
+	err := r.db.Where("payment_id = ?", paymentID).Order("created_at ASC, id ASC").Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+
+// ReconciliationRepository defines the interface for settlement reconciliation reports
+type ReconciliationRepository interface {
+	// Create stores a report together with its entries
+	Create(reconciliation *domain.Reconciliation) error
+	// FindByID returns a report without its entries
+	FindByID(id uint) (*domain.Reconciliation, error)
+	// FindAll returns reports newest first, without their entries
+	FindAll(page, pageSize int) ([]domain.Reconciliation, int64, error)
+	// FindEntries returns the entries of a report in statement order, optionally only those with a status
+	FindEntries(reconciliationID uint, status domain.ReconciliationStatus) ([]domain.ReconciliationEntry, error)
+}
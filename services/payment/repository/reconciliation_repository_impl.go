@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"gorm.io/gorm"
+)
+
+// entryBatchSize keeps inserts of large statements below the driver's parameter limit
+const entryBatchSize = 500
+
+type reconciliationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewReconciliationRepository creates a new instance of ReconciliationRepository
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepositoryImpl{db: db}
+}
+
+func (r *reconciliationRepositoryImpl) Create(reconciliation *domain.Reconciliation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Entries").Create(reconciliation).Error; err != nil {
+			return err
+		}
+		if len(reconciliation.Entries) == 0 {
+			return nil
+		}
+		for i := range reconciliation.Entries {
+			reconciliation.Entries[i].ReconciliationID = reconciliation.ID
+		}
+		return tx.CreateInBatches(reconciliation.Entries, entryBatchSize).Error
+	})
+}
+
+func (r *reconciliationRepositoryImpl) FindByID(id uint) (*domain.Reconciliation, error) {
+	var reconciliation domain.Reconciliation
+	err := r.db.First(&reconciliation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reconciliation, nil
+}
+
+func (r *reconciliationRepositoryImpl) FindAll(page, pageSize int) ([]domain.Reconciliation, int64, error) {
+	var reconciliations []domain.Reconciliation
+	var total int64
+
+	r.db.Model(&domain.Reconciliation{}).Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := r.db.Offset(offset).
+		Limit(pageSize).
+		Order("created_at DESC, id DESC").
+		Find(&reconciliations).Error
+
+	return reconciliations, total, err
+}
+
+func (r *reconciliationRepositoryImpl) FindEntries(reconciliationID uint, status domain.ReconciliationStatus) ([]domain.ReconciliationEntry, error) {
+	query := r.db.Where("reconciliation_id = ?", reconciliationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var entries []domain.ReconciliationEntry
+	// Payments missing from the statement have line 0 and come last
+	err := query.Order("line = 0, line, id").Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,22 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+
+// RefundRepository defines the interface for refund data operations
+type RefundRepository interface {
+	// Reserve creates a pending refund if it fits within what is left of the captured amount,
+	// counting refunds that are still in flight. An amount of 0 reserves everything left.
+	// It reports false if there is not enough left.
+	Reserve(refund *domain.Refund) (bool, error)
+	// Reopen puts a failed refund back to pending so it can be submitted again, if its amount
+	// still fits within what is left. It reports false if it does not, or is no longer failed.
+	Reopen(refund *domain.Refund) (bool, error)
+	FindByReference(paymentID uint, reference string) (*domain.Refund, error)
+	FindByPaymentID(paymentID uint) ([]domain.Refund, error)
+	// MarkSubmitted records that the provider accepted a pending refund
+	MarkSubmitted(refund *domain.Refund) error
+	// Settle stores the outcome of a refund that is not yet final, then lets update adjust the
+	// locked payment given the total of its successful refunds. The returned event, if any, is
+	// saved in the same transaction. It reports false if the refund was already final.
+	Settle(refund *domain.Refund, update func(payment *domain.Payment, refundedTotal float64) *domain.PaymentEvent) (bool, error)
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type refundRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewRefundRepository creates a new instance of RefundRepository
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepositoryImpl{db: db}
+}
+
+func (r *refundRepositoryImpl) Reserve(refund *domain.Refund) (bool, error) {
+	reserved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the payment serialises concurrent refunds of the same payment
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		committed, err := committedRefunds(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+		amount, fits := payment.FitRefund(refund.Amount, committed)
+		if !fits {
+			return nil
+		}
+		refund.Amount = amount
+		reserved = true
+		return tx.Create(refund).Error
+	})
+	return reserved, err
+}
+
+func (r *refundRepositoryImpl) Reopen(refund *domain.Refund) (bool, error) {
+	reopened := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		// Failed refunds are not counted, so this one is not counted against itself
+		committed, err := committedRefunds(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+		if _, fits := payment.FitRefund(refund.Amount, committed); !fits {
+			return nil
+		}
+
+		result := tx.Model(refund).
+			Where("status = ?", domain.RefundStatusFailed).
+			Updates(map[string]interface{}{
+				"status":         domain.RefundStatusPending,
+				"provider_ref":   "",
+				"failure_reason": "",
+				"completed_at":   nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		reopened = result.RowsAffected == 1
+		return nil
+	})
+	if reopened {
+		refund.Status = domain.RefundStatusPending
+		refund.ProviderRef = ""
+		refund.FailureReason = ""
+		refund.CompletedAt = nil
+	}
+	return reopened, err
+}
+
+func (r *refundRepositoryImpl) FindByReference(paymentID uint, reference string) (*domain.Refund, error) {
+	var refund domain.Refund
+	err := r.db.Where("payment_id = ? AND reference = ?", paymentID, reference).First(&refund).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *refundRepositoryImpl) FindByPaymentID(paymentID uint) ([]domain.Refund, error) {
+	var refunds []domain.Refund
+	err := r.db.Where("payment_id = ?", paymentID).Order("created_at ASC, id ASC").Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *refundRepositoryImpl) MarkSubmitted(refund *domain.Refund) error {
+	// The provider's webhook may already have settled the refund
+	return r.db.Model(refund).
+		Where("status = ?", domain.RefundStatusPending).
+		Updates(map[string]interface{}{
+			"status":       domain.RefundStatusProcessing,
+			"provider_ref": refund.ProviderRef,
+		}).Error
+}
+
+func (r *refundRepositoryImpl) Settle(refund *domain.Refund, update func(payment *domain.Payment, refundedTotal float64) *domain.PaymentEvent) (bool, error) {
+	settled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(refund).
+			Where("status IN ?", []domain.RefundStatus{domain.RefundStatusPending, domain.RefundStatusProcessing}).
+			Select("status", "provider_ref", "failure_reason", "completed_at").
+			Updates(refund)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		settled = true
+
+		var refundedTotal float64
+		if err := tx.Model(&domain.Refund{}).
+			Where("payment_id = ? AND status = ?", refund.PaymentID, domain.RefundStatusSuccess).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&refundedTotal).Error; err != nil {
+			return err
+		}
+
+		event := update(payment, refundedTotal)
+		if err := tx.Model(payment).Select("status", "refunded_amount").Updates(payment).Error; err != nil {
+			return err
+		}
+		if event == nil {
+			return nil
+		}
+		event.PaymentID = payment.ID
+		return tx.Create(event).Error
+	})
+	return settled, err
+}
+
+// committedRefunds sums the refunds of a payment that have not failed, including those in flight
+func committedRefunds(tx *gorm.DB, paymentID uint) (float64, error) {
+	var committed float64
+	err := tx.Model(&domain.Refund{}).
+		Where("payment_id = ? AND status <> ?", paymentID, domain.RefundStatusFailed).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&committed).Error
+	return committed, err
+}
+
+func lockPayment(tx *gorm.DB, id uint) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+)
+
+// RiskRepository defines the interface for risk assessment data operations
+type RiskRepository interface {
+	// Create stores an assessment together with the payment it let through, if any, in one transaction
+	Create(assessment *domain.RiskAssessment, payment *domain.Payment) error
+	FindByID(id uint) (*domain.RiskAssessment, error)
+	// FindReviews lists assessments by review status. Pending reviews come oldest first, as a queue;
+	// resolved ones newest first.
+	FindReviews(status domain.ReviewStatus, page, pageSize int) ([]domain.RiskAssessment, int64, error)
+	// Resolve records a review decision, provided the review is still pending.
+	// It reports false if the review was resolved concurrently.
+	Resolve(assessment *domain.RiskAssessment) (bool, error)
+	// CountByUser and CountByIP count payment attempts made since a time, including denied ones
+	CountByUser(userID uint, since time.Time) (int64, error)
+	CountByIP(clientIP string, since time.Time) (int64, error)
+}
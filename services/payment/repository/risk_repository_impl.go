@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"gorm.io/gorm"
+)
+
+type riskRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewRiskRepository creates a new instance of RiskRepository
+func NewRiskRepository(db *gorm.DB) RiskRepository {
+	return &riskRepositoryImpl{db: db}
+}
+
+func (r *riskRepositoryImpl) Create(assessment *domain.RiskAssessment, payment *domain.Payment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if payment != nil {
+			if err := tx.Create(payment).Error; err != nil {
+				return err
+			}
+			assessment.PaymentID = &payment.ID
+		}
+		return tx.Create(assessment).Error
+	})
+}
+
+func (r *riskRepositoryImpl) FindByID(id uint) (*domain.RiskAssessment, error) {
+	var assessment domain.RiskAssessment
+	err := r.db.First(&assessment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assessment, nil
+}
+
+func (r *riskRepositoryImpl) FindReviews(status domain.ReviewStatus, page, pageSize int) ([]domain.RiskAssessment, int64, error) {
+	var assessments []domain.RiskAssessment
+	var total int64
+
+	r.db.Model(&domain.RiskAssessment{}).Where("review_status = ?", status).Count(&total)
+
+	order := "created_at DESC, id DESC"
+	if status == domain.ReviewStatusPending {
+		order = "created_at, id"
+	}
+	offset := (page - 1) * pageSize
+	err := r.db.Where("review_status = ?", status).
+		Offset(offset).
+		Limit(pageSize).
+		Order(order).
+		Find(&assessments).Error
+
+	return assessments, total, err
+}
+
+func (r *riskRepositoryImpl) Resolve(assessment *domain.RiskAssessment) (bool, error) {
+	result := r.db.Model(assessment).
+		Where("review_status = ?", domain.ReviewStatusPending).
+		Select("review_status", "reviewed_by", "reviewed_at", "review_note").
+		Updates(assessment)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *riskRepositoryImpl) CountByUser(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.RiskAssessment{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *riskRepositoryImpl) CountByIP(clientIP string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.RiskAssessment{}).
+		Where("client_ip = ? AND created_at >= ?", clientIP, since).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+
+// SavedMethodRepository defines the interface for saved payment method data operations
+type SavedMethodRepository interface {
+	// Create stores a method. The user's first method becomes their default.
+	Create(method *domain.SavedPaymentMethod) error
+	FindByID(id uint) (*domain.SavedPaymentMethod, error)
+	// FindByUserID lists a user's methods, the default first and then newest first
+	FindByUserID(userID uint) ([]domain.SavedPaymentMethod, error)
+	// FindByFingerprint returns the user's method for the same card or account, or nil if there is none.
+	// An empty fingerprint never matches.
+	FindByFingerprint(userID uint, provider, fingerprint string) (*domain.SavedPaymentMethod, error)
+	// SetDefault makes a method the user's only default
+	SetDefault(userID, id uint) error
+	// Delete removes a method. If it was the default, the user's newest remaining method takes its place.
+	Delete(method *domain.SavedPaymentMethod) error
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"gorm.io/gorm"
+)
+
+type savedMethodRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewSavedMethodRepository creates a new instance of SavedMethodRepository
+func NewSavedMethodRepository(db *gorm.DB) SavedMethodRepository {
+	return &savedMethodRepositoryImpl{db: db}
+}
+
+func (r *savedMethodRepositoryImpl) Create(method *domain.SavedPaymentMethod) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&domain.SavedPaymentMethod{}).Where("user_id = ?", method.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		method.IsDefault = count == 0
+		return tx.Create(method).Error
+	})
+}
+
+func (r *savedMethodRepositoryImpl) FindByID(id uint) (*domain.SavedPaymentMethod, error) {
+	var method domain.SavedPaymentMethod
+	if err := r.db.First(&method, id).Error; err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+func (r *savedMethodRepositoryImpl) FindByUserID(userID uint) ([]domain.SavedPaymentMethod, error) {
+	var methods []domain.SavedPaymentMethod
+	err := r.db.Where("user_id = ?", userID).Order("is_default DESC, created_at DESC, id DESC").Find(&methods).Error
+	return methods, err
+}
+
+func (r *savedMethodRepositoryImpl) FindByFingerprint(userID uint, provider, fingerprint string) (*domain.SavedPaymentMethod, error) {
+	if fingerprint == "" {
+		return nil, nil
+	}
+	var method domain.SavedPaymentMethod
+	err := r.db.Where("user_id = ? AND provider = ? AND fingerprint = ?", userID, provider, fingerprint).First(&method).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &method, nil
+}
+
+func (r *savedMethodRepositoryImpl) SetDefault(userID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.SavedPaymentMethod{}).
+			Where("user_id = ? AND id <> ? AND is_default", userID, id).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.SavedPaymentMethod{}).
+			Where("user_id = ? AND id = ?", userID, id).
+			Update("is_default", true).Error
+	})
+}
+
+func (r *savedMethodRepositoryImpl) Delete(method *domain.SavedPaymentMethod) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.SavedPaymentMethod{}, method.ID).Error; err != nil {
+			return err
+		}
+		if !method.IsDefault {
+			return nil
+		}
+
+		var next domain.SavedPaymentMethod
+		err := tx.Where("user_id = ?", method.UserID).Order("created_at DESC, id DESC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
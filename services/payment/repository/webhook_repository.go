@@ -0,0 +1,14 @@
+package repository
+
+import "github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+
+// WebhookRepository defines the interface for webhook audit and replay data
+type WebhookRepository interface {
+	CreateLog(log *domain.WebhookLog) error
+	UpdateLog(log *domain.WebhookLog) error
+
+	// RecordEvent stores a provider event id, returning false if it was already recorded
+	RecordEvent(event *domain.WebhookEvent) (bool, error)
+	// ForgetEvent removes a recorded event so that a failed event can be retried by the provider
+	ForgetEvent(provider, eventID string) error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepositoryImpl{db: db}
+}
+
+func (r *webhookRepositoryImpl) CreateLog(log *domain.WebhookLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r *webhookRepositoryImpl) UpdateLog(log *domain.WebhookLog) error {
+	return r.db.Save(log).Error
+}
+
+func (r *webhookRepositoryImpl) RecordEvent(event *domain.WebhookEvent) (bool, error) {
+	// The unique (provider, event_id) index makes this safe against concurrent deliveries
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *webhookRepositoryImpl) ForgetEvent(provider, eventID string) error {
+	return r.db.Where("provider = ? AND event_id = ?", provider, eventID).Delete(&domain.WebhookEvent{}).Error
+}
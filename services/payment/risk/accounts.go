@@ -0,0 +1,31 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/client"
+)
+
+type authAccounts struct {
+	auth *client.AuthClient
+}
+
+// NewAuthAccounts looks up account ages in Auth Service
+func NewAuthAccounts(auth *client.AuthClient) Accounts {
+	return &authAccounts{auth: auth}
+}
+
+func (a *authAccounts) AccountCreatedAt(ctx context.Context, userID uint) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	user, err := a.auth.GetUser(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user == nil {
+		return time.Time{}, ErrAccountNotFound
+	}
+	return user.CreatedAt, nil
+}
@@ -0,0 +1,89 @@
+// Package risk scores payment attempts before they are charged.
+//
+// The RuleEngine adds up the scores of independent rules (velocity, amount, failed attempts,
+// account age) and maps the total to a decision: allow, hold for manual review, or deny.
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+)
+
+// Attempt is a payment about to be charged
+type Attempt struct {
+	UserID     uint
+	OrderID    uint
+	ClientIP   string
+	Method     domain.PaymentMethod
+	Amount     float64
+	Currency   string
+	BaseAmount float64 // Amount in the base currency
+	At         time.Time
+}
+
+// Assessment is the verdict on an attempt
+type Assessment struct {
+	Decision domain.RiskDecision
+	Score    int
+	Reasons  []string
+}
+
+// Evaluator decides whether a payment attempt may go ahead
+type Evaluator interface {
+	Evaluate(ctx context.Context, attempt *Attempt) (*Assessment, error)
+}
+
+// Rule scores one aspect of an attempt. A zero score means the rule found nothing suspicious;
+// otherwise the reason explains the score to reviewers.
+type Rule interface {
+	Name() string
+	Score(ctx context.Context, attempt *Attempt) (score int, reason string, err error)
+}
+
+// RuleEngine is an Evaluator that sums rule scores.
+// Attempts scoring at least ReviewScore are held for review; at least DenyScore are denied.
+type RuleEngine struct {
+	rules       []Rule
+	reviewScore int
+	denyScore   int
+}
+
+// NewRuleEngine creates an engine from rules and decision thresholds
+func NewRuleEngine(reviewScore, denyScore int, rules ...Rule) *RuleEngine {
+	return &RuleEngine{
+		rules:       rules,
+		reviewScore: reviewScore,
+		denyScore:   denyScore,
+	}
+}
+
+// Evaluate scores the attempt with every rule. A rule that cannot decide, e.g. because a service
+// it depends on is down, holds the attempt for review rather than blocking or waving it through.
+func (e *RuleEngine) Evaluate(ctx context.Context, attempt *Attempt) (*Assessment, error) {
+	assessment := &Assessment{}
+	for _, rule := range e.rules {
+		score, reason, err := rule.Score(ctx, attempt)
+		if err != nil {
+			score = max(score, e.reviewScore)
+			reason = fmt.Sprintf("%s check unavailable: %v", rule.Name(), err)
+		}
+		if score <= 0 {
+			continue
+		}
+		assessment.Score += score
+		assessment.Reasons = append(assessment.Reasons, reason)
+	}
+
+	switch {
+	case assessment.Score >= e.denyScore:
+		assessment.Decision = domain.RiskDecisionDeny
+	case assessment.Score >= e.reviewScore:
+		assessment.Decision = domain.RiskDecisionReview
+	default:
+		assessment.Decision = domain.RiskDecisionAllow
+	}
+	return assessment, nil
+}
@@ -0,0 +1,95 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+type stubHistory struct {
+	byUser, byIP, failed int64
+	err                  error
+}
+
+func (h *stubHistory) CountAttemptsByUser(userID uint, since time.Time) (int64, error) {
+	return h.byUser, h.err
+}
+
+func (h *stubHistory) CountAttemptsByIP(clientIP string, since time.Time) (int64, error) {
+	return h.byIP, h.err
+}
+
+func (h *stubHistory) CountFailedPayments(userID uint, since time.Time) (int64, error) {
+	return h.failed, h.err
+}
+
+type stubAccounts struct {
+	createdAt time.Time
+	err       error
+}
+
+func (a *stubAccounts) AccountCreatedAt(ctx context.Context, userID uint) (time.Time, error) {
+	return a.createdAt, a.err
+}
+
+func TestDefaultEngine_Evaluate(t *testing.T) {
+	established := &stubAccounts{createdAt: testNow.AddDate(-1, 0, 0)}
+
+	tests := []struct {
+		name        string
+		history     *stubHistory
+		accounts    *stubAccounts
+		baseAmount  float64
+		wantScore   int
+		wantResult  domain.RiskDecision
+		wantReasons int
+	}{
+		{name: "ordinary payment", history: &stubHistory{}, accounts: established, baseAmount: 250_000, wantResult: domain.RiskDecisionAllow},
+		{name: "new account alone", history: &stubHistory{}, accounts: &stubAccounts{createdAt: testNow.Add(-time.Hour)}, baseAmount: 250_000, wantScore: 30, wantResult: domain.RiskDecisionAllow, wantReasons: 1},
+		{name: "large amount", history: &stubHistory{}, accounts: established, baseAmount: 5_000_000, wantScore: 50, wantResult: domain.RiskDecisionReview, wantReasons: 1},
+		{name: "amount over the limit", history: &stubHistory{}, accounts: established, baseAmount: 50_000_000, wantScore: 100, wantResult: domain.RiskDecisionDeny, wantReasons: 1},
+		{name: "user velocity", history: &stubHistory{byUser: 5}, accounts: established, baseAmount: 250_000, wantScore: 60, wantResult: domain.RiskDecisionReview, wantReasons: 1},
+		{name: "IP velocity", history: &stubHistory{byIP: 10}, accounts: established, baseAmount: 250_000, wantScore: 60, wantResult: domain.RiskDecisionReview, wantReasons: 1},
+		{name: "card testing from a new account", history: &stubHistory{failed: 3}, accounts: &stubAccounts{createdAt: testNow.Add(-time.Hour)}, baseAmount: 250_000, wantScore: 90, wantResult: domain.RiskDecisionReview, wantReasons: 2},
+		{name: "velocity and failures", history: &stubHistory{byUser: 6, failed: 4}, accounts: established, baseAmount: 250_000, wantScore: 120, wantResult: domain.RiskDecisionDeny, wantReasons: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			engine := NewDefaultEngine(DefaultConfig(), tt.history, tt.accounts)
+
+			// Act
+			assessment, err := engine.Evaluate(context.Background(), &Attempt{
+				UserID: 7, ClientIP: "203.0.113.9", BaseAmount: tt.baseAmount, At: testNow,
+			})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, assessment.Decision)
+			assert.Equal(t, tt.wantScore, assessment.Score)
+			assert.Len(t, assessment.Reasons, tt.wantReasons)
+		})
+	}
+}
+
+func TestDefaultEngine_Evaluate_HoldsWhenChecksUnavailable(t *testing.T) {
+	// Arrange: Auth Service is down
+	engine := NewDefaultEngine(DefaultConfig(), &stubHistory{}, &stubAccounts{err: errors.New("connection refused")})
+
+	// Act
+	assessment, err := engine.Evaluate(context.Background(), &Attempt{UserID: 7, BaseAmount: 250_000, At: testNow})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.RiskDecisionReview, assessment.Decision)
+	require.Len(t, assessment.Reasons, 1)
+	assert.Contains(t, assessment.Reasons[0], "account age check unavailable")
+}
@@ -0,0 +1,29 @@
+package risk
+
+import (
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+)
+
+type repositoryHistory struct {
+	risks    repository.RiskRepository
+	payments repository.PaymentRepository
+}
+
+// NewRepositoryHistory counts attempts from recorded assessments and failures from payments
+func NewRepositoryHistory(risks repository.RiskRepository, payments repository.PaymentRepository) History {
+	return &repositoryHistory{risks: risks, payments: payments}
+}
+
+func (h *repositoryHistory) CountAttemptsByUser(userID uint, since time.Time) (int64, error) {
+	return h.risks.CountByUser(userID, since)
+}
+
+func (h *repositoryHistory) CountAttemptsByIP(clientIP string, since time.Time) (int64, error) {
+	return h.risks.CountByIP(clientIP, since)
+}
+
+func (h *repositoryHistory) CountFailedPayments(userID uint, since time.Time) (int64, error) {
+	return h.payments.CountFailedSince(userID, since)
+}
@@ -0,0 +1,175 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+)
+
+// ErrAccountNotFound is returned by Accounts when the paying user does not exist
+var ErrAccountNotFound = errors.New("account not found")
+
+// History counts earlier payment attempts
+type History interface {
+	CountAttemptsByUser(userID uint, since time.Time) (int64, error)
+	CountAttemptsByIP(clientIP string, since time.Time) (int64, error)
+	CountFailedPayments(userID uint, since time.Time) (int64, error)
+}
+
+// Accounts looks up when a user's account was created
+type Accounts interface {
+	AccountCreatedAt(ctx context.Context, userID uint) (time.Time, error)
+}
+
+// Config holds the thresholds of the default rules. Amounts are in the base currency.
+type Config struct {
+	VelocityWindow     time.Duration
+	MaxAttemptsPerUser int64
+	MaxAttemptsPerIP   int64
+	FailedWindow       time.Duration
+	MaxFailedAttempts  int64
+	ReviewAmount       float64
+	DenyAmount         float64
+	NewAccountAge      time.Duration
+	ReviewScore        int
+	DenyScore          int
+}
+
+// DefaultConfig returns the thresholds used unless overridden
+func DefaultConfig() Config {
+	return Config{
+		VelocityWindow:     time.Hour,
+		MaxAttemptsPerUser: 5,
+		MaxAttemptsPerIP:   10,
+		FailedWindow:       24 * time.Hour,
+		MaxFailedAttempts:  3,
+		ReviewAmount:       5_000_000,
+		DenyAmount:         50_000_000,
+		NewAccountAge:      24 * time.Hour,
+		ReviewScore:        50,
+		DenyScore:          100,
+	}
+}
+
+// NewDefaultEngine creates an engine with the standard rule set
+func NewDefaultEngine(cfg Config, history History, accounts Accounts) *RuleEngine {
+	return NewRuleEngine(cfg.ReviewScore, cfg.DenyScore,
+		&UserVelocityRule{History: history, Window: cfg.VelocityWindow, Max: cfg.MaxAttemptsPerUser, Points: 60},
+		&IPVelocityRule{History: history, Window: cfg.VelocityWindow, Max: cfg.MaxAttemptsPerIP, Points: 60},
+		&AmountRule{ReviewAmount: cfg.ReviewAmount, DenyAmount: cfg.DenyAmount, ReviewPoints: cfg.ReviewScore, DenyPoints: cfg.DenyScore},
+		&FailedAttemptsRule{History: history, Window: cfg.FailedWindow, Max: cfg.MaxFailedAttempts, Points: 60},
+		&AccountAgeRule{Accounts: accounts, MinAge: cfg.NewAccountAge, Points: 30},
+	)
+}
+
+// UserVelocityRule scores users making many payment attempts in a short time
+type UserVelocityRule struct {
+	History History
+	Window  time.Duration
+	Max     int64
+	Points  int
+}
+
+func (r *UserVelocityRule) Name() string { return "user velocity" }
+
+func (r *UserVelocityRule) Score(ctx context.Context, attempt *Attempt) (int, string, error) {
+	count, err := r.History.CountAttemptsByUser(attempt.UserID, attempt.At.Add(-r.Window))
+	if err != nil {
+		return 0, "", err
+	}
+	if count < r.Max {
+		return 0, "", nil
+	}
+	return r.Points, fmt.Sprintf("%d payment attempts by the user in the last %s", count, r.Window), nil
+}
+
+// IPVelocityRule scores addresses making many payment attempts in a short time, across users
+type IPVelocityRule struct {
+	History History
+	Window  time.Duration
+	Max     int64
+	Points  int
+}
+
+func (r *IPVelocityRule) Name() string { return "IP velocity" }
+
+func (r *IPVelocityRule) Score(ctx context.Context, attempt *Attempt) (int, string, error) {
+	if attempt.ClientIP == "" {
+		return 0, "", nil
+	}
+	count, err := r.History.CountAttemptsByIP(attempt.ClientIP, attempt.At.Add(-r.Window))
+	if err != nil {
+		return 0, "", err
+	}
+	if count < r.Max {
+		return 0, "", nil
+	}
+	return r.Points, fmt.Sprintf("%d payment attempts from %s in the last %s", count, attempt.ClientIP, r.Window), nil
+}
+
+// AmountRule scores large payments. Thresholds are compared with the amount in the base currency.
+type AmountRule struct {
+	ReviewAmount float64
+	DenyAmount   float64
+	ReviewPoints int
+	DenyPoints   int
+}
+
+func (r *AmountRule) Name() string { return "amount" }
+
+func (r *AmountRule) Score(ctx context.Context, attempt *Attempt) (int, string, error) {
+	switch {
+	case r.DenyAmount > 0 && attempt.BaseAmount >= r.DenyAmount:
+		return r.DenyPoints, fmt.Sprintf("amount %s is at or above the limit of %s",
+			currency.Base.Format(attempt.BaseAmount), currency.Base.Format(r.DenyAmount)), nil
+	case r.ReviewAmount > 0 && attempt.BaseAmount >= r.ReviewAmount:
+		return r.ReviewPoints, fmt.Sprintf("amount %s is at or above the review threshold of %s",
+			currency.Base.Format(attempt.BaseAmount), currency.Base.Format(r.ReviewAmount)), nil
+	}
+	return 0, "", nil
+}
+
+// FailedAttemptsRule scores users whose recent payments keep failing, e.g. card testing
+type FailedAttemptsRule struct {
+	History History
+	Window  time.Duration
+	Max     int64
+	Points  int
+}
+
+func (r *FailedAttemptsRule) Name() string { return "failed attempts" }
+
+func (r *FailedAttemptsRule) Score(ctx context.Context, attempt *Attempt) (int, string, error) {
+	count, err := r.History.CountFailedPayments(attempt.UserID, attempt.At.Add(-r.Window))
+	if err != nil {
+		return 0, "", err
+	}
+	if count < r.Max {
+		return 0, "", nil
+	}
+	return r.Points, fmt.Sprintf("%d failed payments by the user in the last %s", count, r.Window), nil
+}
+
+// AccountAgeRule scores payments from accounts created very recently
+type AccountAgeRule struct {
+	Accounts Accounts
+	MinAge   time.Duration
+	Points   int
+}
+
+func (r *AccountAgeRule) Name() string { return "account age" }
+
+func (r *AccountAgeRule) Score(ctx context.Context, attempt *Attempt) (int, string, error) {
+	createdAt, err := r.Accounts.AccountCreatedAt(ctx, attempt.UserID)
+	if err != nil {
+		return 0, "", err
+	}
+	age := attempt.At.Sub(createdAt)
+	if age >= r.MinAge {
+		return 0, "", nil
+	}
+	return r.Points, fmt.Sprintf("account created %s ago", age.Truncate(time.Minute)), nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+)
+
+// ExpiryPolicy is how long customers have to complete a payment, per method.
+// Methods without an entry never expire.
+type ExpiryPolicy map[domain.PaymentMethod]time.Duration
+
+// DefaultExpiryPolicy gives transfers a day and interactive methods a short window
+func DefaultExpiryPolicy() ExpiryPolicy {
+	return ExpiryPolicy{
+		domain.PaymentMethodVA:           24 * time.Hour,
+		domain.PaymentMethodBankTransfer: 24 * time.Hour,
+		domain.PaymentMethodQRIS:         15 * time.Minute,
+		domain.PaymentMethodEWallet:      30 * time.Minute,
+		domain.PaymentMethodCreditCard:   time.Hour,
+	}
+}
+
+// ParseExpiryPolicy parses "method:duration" pairs separated by commas, e.g. "qris:15m,virtual_account:24h".
+// Methods not listed keep their default window.
+func ParseExpiryPolicy(value string) (ExpiryPolicy, error) {
+	policy := DefaultExpiryPolicy()
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		method, duration, ok := strings.Cut(pair, ":")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid payment expiry entry %q, expected method:duration", pair)
+		}
+		ttl, err := time.ParseDuration(duration)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid payment expiry duration in %q", pair)
+		}
+		policy[domain.PaymentMethod(method)] = ttl
+	}
+	return policy, nil
+}
+
+// expiresAt returns when a payment created at now with the given method expires, or nil if it doesn't
+func (p ExpiryPolicy) expiresAt(method domain.PaymentMethod, now time.Time) *time.Time {
+	ttl, ok := p[method]
+	if !ok {
+		return nil
+	}
+	expiresAt := now.Add(ttl)
+	return &expiresAt
+}
+
+// ExpirePayments expires up to limit pending payments whose window closed by now: the charge
+// is voided with the provider and the order is cancelled, releasing its stock.
+// It returns how many payments were expired.
+func (s *paymentServiceImpl) ExpirePayments(ctx context.Context, now time.Time, limit int) (int, error) {
+	payments, err := s.paymentRepo.FindExpired(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var errs []error
+	for i := range payments {
+		if err := s.expire(ctx, &payments[i]); err != nil {
+			// Keep going; one payment shouldn't hold up the rest
+			errs = append(errs, fmt.Errorf("payment %d: %w", payments[i].ID, err))
+			continue
+		}
+		expired++
+	}
+	return expired, errors.Join(errs...)
+}
+
+func (s *paymentServiceImpl) expire(ctx context.Context, payment *domain.Payment) error {
+	event := &domain.PaymentEvent{
+		Type:     domain.PaymentEventExpire,
+		Provider: payment.Provider,
+		Note:     "Payment window closed",
+	}
+
+	// Void first: if the customer paid at the last moment the void fails and the provider's webhook settles it
+	if payment.ProviderRef != "" {
+		gateway, err := s.providers.ByName(payment.Provider)
+		if err == nil {
+			err = gateway.Void(ctx, payment.ProviderRef)
+		}
+		if err != nil {
+			event.Error = err.Error()
+			s.recordEvent(payment, event)
+			return fmt.Errorf("%w: %v", ErrProviderFailure, err)
+		}
+	}
+
+	if err := s.transition(payment, domain.PaymentStatusExpired, event); err != nil {
+		return err
+	}
+
+	if err := s.orders.CancelOrder(ctx, payment.OrderID, "Payment expired"); err != nil {
+		// The order service expires unpaid orders on its own as well, so this is not retried here
+		s.recordEvent(payment, &domain.PaymentEvent{
+			Type:     domain.PaymentEventExpire,
+			Provider: payment.Provider,
+			Note:     "Order could not be cancelled",
+			Error:    err.Error(),
+		})
+		return fmt.Errorf("%w: %v", ErrOrderLookup, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentService_ExpirePayments(t *testing.T) {
+	// Arrange: a QRIS payment created at a fixed time
+	createdAt := time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)
+	router := provider.NewRouter()
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	orders := newStubOrders()
+	paymentService := newUncheckedPaymentService(repository.NewMockPaymentRepository(), repository.NewMockSavedMethodRepository(), router, orders)
+	paymentService.(*paymentServiceImpl).now = func() time.Time { return createdAt }
+
+	payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-15T09:15:00Z", payment.ExpiresAt)
+
+	// Act & Assert: nothing happens within the window
+	expired, err := paymentService.ExpirePayments(context.Background(), createdAt.Add(14*time.Minute), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 0, expired)
+
+	expired, err = paymentService.ExpirePayments(context.Background(), createdAt.Add(15*time.Minute), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	got, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusExpired, got.Status)
+	assert.Equal(t, domain.PaymentEventExpire, got.Timeline[len(got.Timeline)-1].Type)
+	assert.Equal(t, "cancelled", orders.orders[1].Status)
+
+	// A late success notification is refused
+	_, err = paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+		TransactionID: payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
+
+func TestParseExpiryPolicy(t *testing.T) {
+	policy, err := ParseExpiryPolicy(" qris:5m , virtual_account:48h")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, policy[domain.PaymentMethodQRIS])
+	assert.Equal(t, 48*time.Hour, policy[domain.PaymentMethodVA])
+	assert.Equal(t, time.Hour, policy[domain.PaymentMethodCreditCard])
+
+	_, err = ParseExpiryPolicy("qris")
+	assert.Error(t, err)
+	_, err = ParseExpiryPolicy("qris:soon")
+	assert.Error(t, err)
+	_, err = ParseExpiryPolicy("qris:-1m")
+	assert.Error(t, err)
+}
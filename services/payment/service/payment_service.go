@@ -1,34 +1,75 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/client"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/risk"
 )
 
 var (
 	ErrPaymentNotFound   = errors.New("payment not found")
-	ErrPaymentExists     = errors.New("payment already exists for this order")
+	ErrPaymentExists     = errors.New("order already has a pending or successful payment")
 	ErrInvalidStatus     = errors.New("invalid payment status transition")
 	ErrPaymentNotPending = errors.New("payment is not in pending status")
-	ErrPaymentNotSuccess = errors.New("only successful payments can be refunded")
+	ErrMethodUnsupported = errors.New("payment method is not supported")
+	ErrProviderFailure   = errors.New("payment provider rejected the request")
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrOrderNotPayable   = errors.New("order is not awaiting payment")
+	ErrAmountMismatch    = errors.New("payment amount does not match the order total")
+	ErrOrderLookup       = errors.New("failed to reach order service")
+	ErrProviderMismatch  = errors.New("notification is from a different provider than the payment")
+
+	ErrCurrencyMismatch        = errors.New("payment currency does not match the order currency")
+	ErrUnsupportedCurrency     = errors.New("unsupported currency")
+	ErrExchangeRateUnavailable = errors.New("exchange rate unavailable")
+
+	ErrSavedMethodMismatch = errors.New("saved payment method is not of the requested method")
+
+	ErrPaymentDenied  = errors.New("payment was declined")
+	ErrPaymentNotHeld = errors.New("payment is not held for review")
 )
 
+const (
+	// providerTimeout bounds each synchronous call to a payment provider
+	providerTimeout = 15 * time.Second
+	// orderTimeout bounds each call to Order Service
+	orderTimeout = 5 * time.Second
+)
+
+// OrderClient is the part of Order Service that payments depend on
+type OrderClient interface {
+	GetOrder(ctx context.Context, orderID uint) (*client.OrderInfo, error)
+	MarkOrderPaid(ctx context.Context, orderID uint, transactionID string, amount float64) error
+	CancelOrder(ctx context.Context, orderID uint, reason string) error
+}
+
 // PaymentService defines the interface for payment operations
 type PaymentService interface {
 	CreatePayment(userID uint, req *dto.CreatePaymentRequest) (*dto.PaymentResponse, error)
 	GetPayment(id uint) (*dto.PaymentResponse, error)
 	GetPaymentByOrderID(orderID uint) (*dto.PaymentResponse, error)
+	GetPaymentStatuses(orderIDs []uint) (map[uint]domain.PaymentStatus, error)
 	GetUserPayments(userID uint, page, pageSize int) (*dto.PaymentListResponse, error)
 	ProcessPayment(req *dto.ProcessPaymentRequest) (*dto.PaymentResponse, error)
 	CancelPayment(id uint) error
-	RefundPayment(id uint, reason string) error
+	// ReleaseHeldPayment charges a payment that was held for risk review
+	ReleaseHeldPayment(id uint) (*dto.PaymentResponse, error)
+	// CancelHeldPayment cancels a payment that was held for risk review, together with its order
+	CancelHeldPayment(id uint, reason string) error
+	ExpirePayments(ctx context.Context, now time.Time, limit int) (int, error)
 
 	// For gRPC
 	GetPaymentStatus(orderID uint) (domain.PaymentStatus, error)
@@ -36,49 +77,361 @@ type PaymentService interface {
 }
 
 type paymentServiceImpl struct {
-	paymentRepo repository.PaymentRepository
+	paymentRepo  repository.PaymentRepository
+	savedMethods repository.SavedMethodRepository
+	providers    *provider.Router
+	orders       OrderClient
+	expiry       ExpiryPolicy
+	rates        currency.RateProvider
+	risk         risk.Evaluator
+	risks        repository.RiskRepository
+	now          func() time.Time
 }
 
 // NewPaymentService creates a new instance of PaymentService
-func NewPaymentService(paymentRepo repository.PaymentRepository) PaymentService {
+func NewPaymentService(
+	paymentRepo repository.PaymentRepository,
+	savedMethods repository.SavedMethodRepository,
+	providers *provider.Router,
+	orders OrderClient,
+	expiry ExpiryPolicy,
+	rates currency.RateProvider,
+	evaluator risk.Evaluator,
+	risks repository.RiskRepository,
+) PaymentService {
 	return &paymentServiceImpl{
-		paymentRepo: paymentRepo,
+		paymentRepo:  paymentRepo,
+		savedMethods: savedMethods,
+		providers:    providers,
+		orders:       orders,
+		expiry:       expiry,
+		rates:        rates,
+		risk:         evaluator,
+		risks:        risks,
+		now:          time.Now,
 	}
 }
 
+// CreatePayment charges the amount due on one of the user's orders, in the order's currency.
+// The amount is taken from Order Service; an amount or currency sent by the client must match it.
+// Every attempt is risk-assessed first: risky ones are denied, or held uncharged for manual review.
 func (s *paymentServiceImpl) CreatePayment(userID uint, req *dto.CreatePaymentRequest) (*dto.PaymentResponse, error) {
-	// Check if payment already exists for this order
-	existing, _ := s.paymentRepo.FindByOrderID(req.OrderID)
-	if existing != nil {
+	// An order has one live attempt at a time: pending (including held for review) or paid.
+	// After a failed, cancelled or expired attempt the customer may try again.
+	existing, err := s.paymentRepo.FindByOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !existing.Status.EndedUnpaid() {
 		return nil, ErrPaymentExists
 	}
 
+	order, err := s.lookupOrder(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users' orders are reported as missing so their existence isn't revealed
+	if order == nil || order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	if !order.IsPayable() {
+		return nil, ErrOrderNotPayable
+	}
+	if req.Amount != 0 && math.Abs(req.Amount-order.TotalAmount) >= 0.01 {
+		return nil, ErrAmountMismatch
+	}
+	code, err := currency.Parse(order.Currency)
+	if err != nil {
+		return nil, ErrUnsupportedCurrency
+	}
+	if req.Currency != "" && !strings.EqualFold(req.Currency, string(code)) {
+		return nil, ErrCurrencyMismatch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	rate, err := s.rates.Rate(ctx, code, currency.Base)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeRateUnavailable, err)
+	}
+
 	// Generate unique transaction ID
-	transactionID := fmt.Sprintf("TXN-%d-%s", time.Now().UnixNano(), uuid.New().String()[:8])
+	now := s.now()
+
+	method, saved, err := s.resolveMethod(userID, req, now)
+	if err != nil {
+		return nil, err
+	}
+	var gateway provider.PaymentProvider
+	if saved != nil {
+		// Tokens are only valid at the provider that issued them
+		gateway, err = s.providers.ByName(saved.Provider)
+	} else {
+		gateway, err = s.providers.ForMethod(method)
+	}
+	if err != nil {
+		return nil, ErrMethodUnsupported
+	}
+
+	transactionID := fmt.Sprintf("TXN-%d-%s", now.UnixNano(), uuid.New().String()[:8])
 
 	payment := &domain.Payment{
 		OrderID:       req.OrderID,
 		UserID:        userID,
-		Amount:        req.Amount,
-		Currency:      "IDR",
-		Method:        req.Method,
+		Amount:        order.TotalAmount,
+		Currency:      string(code),
+		BaseCurrency:  string(rate.To),
+		BaseAmount:    rate.Convert(order.TotalAmount),
+		ExchangeRate:  rate.Value,
+		RateSource:    rate.Source,
+		RateAsOf:      &rate.AsOf,
+		Method:        method,
 		Status:        domain.PaymentStatusPending,
 		TransactionID: transactionID,
+		Provider:      gateway.Name(),
+		ExpiresAt:     s.expiry.expiresAt(method, now),
+	}
+	if saved != nil {
+		payment.SavedMethodID = &saved.ID
+	}
+
+	assessment := s.assess(ctx, payment, req.ClientIP, now)
+	if assessment.Decision == domain.RiskDecisionDeny {
+		if err := s.risks.Create(assessment, nil); err != nil {
+			return nil, err
+		}
+		return nil, ErrPaymentDenied
+	}
+	payment.RiskDecision = assessment.Decision
+	if assessment.Decision == domain.RiskDecisionReview {
+		// Held payments have no payment window until a reviewer releases them
+		payment.ExpiresAt = nil
+		assessment.ReviewStatus = domain.ReviewStatusPending
+	}
+
+	// The record must exist before the charge, as the provider's webhook may arrive at any time
+	if err := s.risks.Create(assessment, payment); err != nil {
+		return nil, err
+	}
+	s.recordEvent(payment, &domain.PaymentEvent{
+		Type: domain.PaymentEventCreated,
+		Note: fmt.Sprintf("%s by %s", currency.Code(payment.Currency).Format(payment.Amount), payment.Method),
+	})
+	if payment.IsHeld() {
+		s.recordEvent(payment, &domain.PaymentEvent{
+			Type: domain.PaymentEventReview,
+			Note: fmt.Sprintf("Held for review with risk score %d", assessment.Score),
+		})
+		return s.toPaymentResponse(payment), nil
+	}
+
+	token := ""
+	if saved != nil {
+		token = saved.Token
+	}
+	return s.charge(ctx, payment, gateway, token)
+}
+
+// assess evaluates the risk of a payment attempt. The evaluator failing holds the payment for review.
+func (s *paymentServiceImpl) assess(ctx context.Context, payment *domain.Payment, clientIP string, now time.Time) *domain.RiskAssessment {
+	result, err := s.risk.Evaluate(ctx, &risk.Attempt{
+		UserID:     payment.UserID,
+		OrderID:    payment.OrderID,
+		ClientIP:   clientIP,
+		Method:     payment.Method,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		BaseAmount: payment.BaseAmount,
+		At:         now,
+	})
+	if err != nil {
+		result = &risk.Assessment{
+			Decision: domain.RiskDecisionReview,
+			Reasons:  []string{fmt.Sprintf("risk evaluation failed: %v", err)},
+		}
+	}
+
+	return &domain.RiskAssessment{
+		UserID:     payment.UserID,
+		OrderID:    payment.OrderID,
+		ClientIP:   clientIP,
+		Method:     payment.Method,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		BaseAmount: payment.BaseAmount,
+		Score:      result.Score,
+		Decision:   result.Decision,
+		Reasons:    result.Reasons,
+		CreatedAt:  now,
 	}
+}
 
-	if err := s.paymentRepo.Create(payment); err != nil {
+// charge requests the charge of a stored pending payment from its provider
+func (s *paymentServiceImpl) charge(ctx context.Context, payment *domain.Payment, gateway provider.PaymentProvider, token string) (*dto.PaymentResponse, error) {
+	chargeReq := &provider.ChargeRequest{
+		TransactionID: payment.TransactionID,
+		OrderID:       payment.OrderID,
+		UserID:        payment.UserID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Method:        payment.Method,
+		ExpiresAt:     payment.ExpiresAt,
+		Token:         token,
+	}
+	charge, err := gateway.CreateCharge(ctx, chargeReq)
+	if err != nil {
+		payment.FailureReason = err.Error()
+		_ = s.transition(payment, domain.PaymentStatusFailed, &domain.PaymentEvent{
+			Type:     domain.PaymentEventCharge,
+			Provider: gateway.Name(),
+			Error:    err.Error(),
+			Payload:  payloadJSON(chargeReq),
+		})
+		return nil, fmt.Errorf("%w: %v", ErrProviderFailure, err)
+	}
+
+	payment.ProviderRef = charge.ProviderRef
+	payment.PaymentCode = charge.PaymentCode
+	payment.RedirectURL = charge.RedirectURL
+	if charge.ExpiresAt != nil {
+		// The provider's window is the one the customer sees
+		payment.ExpiresAt = charge.ExpiresAt
+	}
+	if err := s.paymentRepo.UpdateCharge(payment); err != nil {
 		return nil, err
 	}
+	s.recordEvent(payment, &domain.PaymentEvent{
+		Type:     domain.PaymentEventCharge,
+		Provider: gateway.Name(),
+		Payload:  payloadJSON(charge),
+	})
 
 	return s.toPaymentResponse(payment), nil
 }
 
+// ReleaseHeldPayment charges a payment approved in risk review. Its payment window starts now.
+// If the order can no longer be paid, the payment is cancelled instead.
+func (s *paymentServiceImpl) ReleaseHeldPayment(id uint) (*dto.PaymentResponse, error) {
+	payment, err := s.paymentRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	if !payment.IsHeld() {
+		return nil, ErrPaymentNotHeld
+	}
+
+	// Saved first, so the payment expires normally if the charge never goes out
+	payment.ExpiresAt = s.expiry.expiresAt(payment.Method, s.now())
+	if err := s.paymentRepo.UpdateCharge(payment); err != nil {
+		return nil, err
+	}
+
+	order, err := s.lookupOrder(payment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || !order.IsPayable() {
+		if err := s.transition(payment, domain.PaymentStatusCancelled, &domain.PaymentEvent{
+			Type: domain.PaymentEventReview,
+			Note: "Approved, but the order is no longer awaiting payment",
+		}); err != nil {
+			return nil, err
+		}
+		return nil, ErrOrderNotPayable
+	}
+
+	gateway, err := s.providers.ByName(payment.Provider)
+	if err != nil {
+		return nil, ErrMethodUnsupported
+	}
+	token := ""
+	if payment.SavedMethodID != nil {
+		saved, err := s.savedMethods.FindByID(*payment.SavedMethodID)
+		if err != nil {
+			return nil, ErrSavedMethodNotFound
+		}
+		token = saved.Token
+	}
+
+	s.recordEvent(payment, &domain.PaymentEvent{Type: domain.PaymentEventReview, Note: "Approved"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+	return s.charge(ctx, payment, gateway, token)
+}
+
+// CancelHeldPayment cancels a payment rejected in risk review and releases its order.
+// The order is cancelled on a best-effort basis; unpaid orders expire anyway.
+func (s *paymentServiceImpl) CancelHeldPayment(id uint, reason string) error {
+	payment, err := s.paymentRepo.FindByID(id)
+	if err != nil {
+		return ErrPaymentNotFound
+	}
+	if !payment.IsHeld() {
+		return ErrPaymentNotHeld
+	}
+
+	note := "Rejected"
+	if reason != "" {
+		note += ": " + reason
+	}
+	if err := s.transition(payment, domain.PaymentStatusCancelled, &domain.PaymentEvent{
+		Type: domain.PaymentEventReview,
+		Note: note,
+	}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), orderTimeout)
+	defer cancel()
+	if err := s.orders.CancelOrder(ctx, payment.OrderID, "Payment rejected in review"); err != nil {
+		s.recordEvent(payment, &domain.PaymentEvent{
+			Type:  domain.PaymentEventReview,
+			Note:  "Order could not be cancelled",
+			Error: err.Error(),
+		})
+	}
+	return nil
+}
+
+// resolveMethod returns the method to charge and, if the customer picked one, their saved payment method
+func (s *paymentServiceImpl) resolveMethod(userID uint, req *dto.CreatePaymentRequest, now time.Time) (domain.PaymentMethod, *domain.SavedPaymentMethod, error) {
+	if req.SavedMethodID == nil {
+		return req.Method, nil, nil
+	}
+
+	saved, err := s.savedMethods.FindByID(*req.SavedMethodID)
+	if err != nil || saved.UserID != userID {
+		return "", nil, ErrSavedMethodNotFound
+	}
+	if req.Method != "" && req.Method != saved.Method {
+		return "", nil, ErrSavedMethodMismatch
+	}
+	if saved.IsExpired(now) {
+		return "", nil, ErrSavedMethodExpired
+	}
+	return saved.Method, saved, nil
+}
+
+// GetPayment returns a payment with its full event timeline
 func (s *paymentServiceImpl) GetPayment(id uint) (*dto.PaymentResponse, error) {
 	payment, err := s.paymentRepo.FindByID(id)
 	if err != nil {
 		return nil, ErrPaymentNotFound
 	}
-	return s.toPaymentResponse(payment), nil
+
+	events, err := s.paymentRepo.FindEvents(payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := s.toPaymentResponse(payment)
+	resp.Timeline = make([]dto.PaymentEventResponse, len(events))
+	for i, event := range events {
+		resp.Timeline[i] = toPaymentEventResponse(&event)
+	}
+	return resp, nil
 }
 
 func (s *paymentServiceImpl) GetPaymentByOrderID(orderID uint) (*dto.PaymentResponse, error) {
@@ -92,6 +445,24 @@ func (s *paymentServiceImpl) GetPaymentByOrderID(orderID uint) (*dto.PaymentResp
 	return s.toPaymentResponse(payment), nil
 }
 
+// GetPaymentStatuses returns the payment status for each order that has a payment.
+// Orders without a payment are absent from the map.
+func (s *paymentServiceImpl) GetPaymentStatuses(orderIDs []uint) (map[uint]domain.PaymentStatus, error) {
+	statuses := make(map[uint]domain.PaymentStatus, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return statuses, nil
+	}
+
+	payments, err := s.paymentRepo.FindByOrderIDs(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range payments {
+		statuses[payment.OrderID] = payment.Status
+	}
+	return statuses, nil
+}
+
 func (s *paymentServiceImpl) GetUserPayments(userID uint, page, pageSize int) (*dto.PaymentListResponse, error) {
 	if page < 1 {
 		page = 1
@@ -121,6 +492,8 @@ func (s *paymentServiceImpl) GetUserPayments(userID uint, page, pageSize int) (*
 	}, nil
 }
 
+// ProcessPayment applies a status reported by the payment provider.
+// Every notification is recorded, including repeats and ones that would make an illegal transition.
 func (s *paymentServiceImpl) ProcessPayment(req *dto.ProcessPaymentRequest) (*dto.PaymentResponse, error) {
 	payment, err := s.paymentRepo.FindByTransactionID(req.TransactionID)
 	if err != nil {
@@ -129,25 +502,59 @@ func (s *paymentServiceImpl) ProcessPayment(req *dto.ProcessPaymentRequest) (*dt
 	if payment == nil {
 		return nil, ErrPaymentNotFound
 	}
+	if !notifiedBy(payment, req) {
+		return nil, ErrProviderMismatch
+	}
 
-	// Update payment based on status
-	payment.Status = req.Status
-	payment.ProviderRef = req.ProviderRef
+	event := &domain.PaymentEvent{
+		Type:     domain.PaymentEventNotification,
+		Provider: payment.Provider,
+		Payload:  payloadJSON(req),
+	}
+
+	// Providers may report the same outcome more than once
+	if req.Status == payment.Status {
+		event.Note = "Status already applied"
+		s.recordEvent(payment, event)
+		if payment.Status == domain.PaymentStatusSuccess {
+			// A previous attempt may have failed to reach Order Service
+			if err := s.markOrderPaid(payment); err != nil {
+				return nil, err
+			}
+		}
+		return s.toPaymentResponse(payment), nil
+	}
 
-	if req.Status == domain.PaymentStatusSuccess {
-		now := time.Now()
+	if req.ProviderRef != "" {
+		payment.ProviderRef = req.ProviderRef
+	}
+	switch req.Status {
+	case domain.PaymentStatusSuccess:
+		now := s.now()
 		payment.PaidAt = &now
-	} else if req.Status == domain.PaymentStatusFailed {
+	case domain.PaymentStatusFailed:
 		payment.FailureReason = req.FailureReason
 	}
 
-	if err := s.paymentRepo.Update(payment); err != nil {
+	if err := s.transition(payment, req.Status, event); err != nil {
 		return nil, err
 	}
 
+	if payment.Status == domain.PaymentStatusSuccess {
+		if err := s.markOrderPaid(payment); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.toPaymentResponse(payment), nil
 }
 
+// notifiedBy reports whether a notification may settle the payment: each provider's
+// webhook secret only vouches for payments made through that provider
+func notifiedBy(payment *domain.Payment, req *dto.ProcessPaymentRequest) bool {
+	return req.Provider == "" || req.Provider == payment.Provider
+}
+
 func (s *paymentServiceImpl) CancelPayment(id uint) error {
 	payment, err := s.paymentRepo.FindByID(id)
 	if err != nil {
@@ -158,23 +565,23 @@ func (s *paymentServiceImpl) CancelPayment(id uint) error {
 		return ErrPaymentNotPending
 	}
 
-	return s.paymentRepo.UpdateStatus(id, domain.PaymentStatusCancelled)
-}
-
-func (s *paymentServiceImpl) RefundPayment(id uint, reason string) error {
-	payment, err := s.paymentRepo.FindByID(id)
-	if err != nil {
-		return ErrPaymentNotFound
+	event := &domain.PaymentEvent{Type: domain.PaymentEventCancel, Provider: payment.Provider}
+	if payment.ProviderRef != "" {
+		gateway, err := s.providers.ByName(payment.Provider)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+		defer cancel()
+		// The customer may have paid in the meantime; the provider's webhook will settle it
+		if err := gateway.Void(ctx, payment.ProviderRef); err != nil {
+			event.Error = err.Error()
+			s.recordEvent(payment, event)
+			return fmt.Errorf("%w: %v", ErrProviderFailure, err)
+		}
 	}
 
-	if payment.Status != domain.PaymentStatusSuccess {
-		return ErrPaymentNotSuccess
-	}
-
-	payment.Status = domain.PaymentStatusRefunded
-	payment.FailureReason = "Refund: " + reason
-
-	return s.paymentRepo.Update(payment)
+	return s.transition(payment, domain.PaymentStatusCancelled, event)
 }
 
 func (s *paymentServiceImpl) GetPaymentStatus(orderID uint) (domain.PaymentStatus, error) {
@@ -199,25 +606,144 @@ func (s *paymentServiceImpl) VerifyPayment(orderID uint) (bool, error) {
 	return payment.Status == domain.PaymentStatusSuccess, nil
 }
 
+// lookupOrder fetches an order from Order Service, returning nil if it does not exist
+func (s *paymentServiceImpl) lookupOrder(orderID uint) (*client.OrderInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), orderTimeout)
+	defer cancel()
+
+	order, err := s.orders.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOrderLookup, err)
+	}
+	return order, nil
+}
+
+// markOrderPaid moves the order of a successful payment to paid.
+// Delivery failures are returned so the provider retries the notification. An order that
+// refuses the payment (e.g. it was cancelled meanwhile) is recorded for staff to resolve.
+func (s *paymentServiceImpl) markOrderPaid(payment *domain.Payment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), orderTimeout)
+	defer cancel()
+
+	err := s.orders.MarkOrderPaid(ctx, payment.OrderID, payment.TransactionID, payment.Amount)
+	if err == nil {
+		return nil
+	}
+
+	s.recordEvent(payment, &domain.PaymentEvent{
+		Type:     domain.PaymentEventNotification,
+		Provider: payment.Provider,
+		Note:     "Order could not be marked as paid",
+		Error:    err.Error(),
+	})
+	if errors.Is(err, client.ErrOrderRejected) {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrOrderLookup, err)
+}
+
+// transition validates and persists a status change together with its event.
+// A transition to the current status records the event and saves the payment without a status change.
+func (s *paymentServiceImpl) transition(payment *domain.Payment, next domain.PaymentStatus, event *domain.PaymentEvent) error {
+	from := payment.Status
+	if next != from && !payment.CanTransitionTo(next) {
+		event.Error = fmt.Sprintf("illegal transition from %s to %s", from, next)
+		s.recordEvent(payment, event)
+		return ErrInvalidStatus
+	}
+
+	event.FromStatus = from
+	event.ToStatus = next
+	event.CreatedAt = time.Now()
+	payment.Status = next
+	applied, err := s.paymentRepo.Transition(payment, from, event)
+	if err != nil || !applied {
+		payment.Status = from
+	}
+	if err != nil {
+		return err
+	}
+	if !applied {
+		// Another request moved the payment on since it was loaded
+		event.Error = fmt.Sprintf("status changed concurrently, expected %s", from)
+		s.recordEvent(payment, event)
+		return ErrInvalidStatus
+	}
+	return nil
+}
+
+// recordEvent appends an event that leaves the status unchanged.
+// Failures here are not fatal; the event is informational.
+func (s *paymentServiceImpl) recordEvent(payment *domain.Payment, event *domain.PaymentEvent) {
+	event.PaymentID = payment.ID
+	event.FromStatus = payment.Status
+	event.ToStatus = payment.Status
+	event.CreatedAt = time.Now()
+	_ = s.paymentRepo.AddEvent(event)
+}
+
+// payloadJSON encodes a provider request or response for the event log
+func payloadJSON(v any) string {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(payload)
+}
+
+func toPaymentEventResponse(event *domain.PaymentEvent) dto.PaymentEventResponse {
+	resp := dto.PaymentEventResponse{
+		ID:         event.ID,
+		Type:       event.Type,
+		FromStatus: event.FromStatus,
+		ToStatus:   event.ToStatus,
+		Provider:   event.Provider,
+		Note:       event.Note,
+		Error:      event.Error,
+		CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+	}
+	if event.Payload != "" {
+		resp.Payload = json.RawMessage(event.Payload)
+	}
+	return resp
+}
+
 // Helper: convert domain.Payment to dto.PaymentResponse
 func (s *paymentServiceImpl) toPaymentResponse(payment *domain.Payment) *dto.PaymentResponse {
 	resp := &dto.PaymentResponse{
-		ID:            payment.ID,
-		OrderID:       payment.OrderID,
-		UserID:        payment.UserID,
-		Amount:        payment.Amount,
-		Currency:      payment.Currency,
-		Method:        payment.Method,
-		Status:        payment.Status,
-		TransactionID: payment.TransactionID,
-		ProviderRef:   payment.ProviderRef,
-		FailureReason: payment.FailureReason,
-		CreatedAt:     payment.CreatedAt.Format(time.RFC3339),
+		ID:             payment.ID,
+		OrderID:        payment.OrderID,
+		UserID:         payment.UserID,
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+		BaseCurrency:   payment.BaseCurrency,
+		BaseAmount:     payment.BaseAmount,
+		ExchangeRate:   payment.ExchangeRate,
+		RateSource:     payment.RateSource,
+		Method:         payment.Method,
+		SavedMethodID:  payment.SavedMethodID,
+		Status:         payment.Status,
+		TransactionID:  payment.TransactionID,
+		Provider:       payment.Provider,
+		ProviderRef:    payment.ProviderRef,
+		PaymentCode:    payment.PaymentCode,
+		RedirectURL:    payment.RedirectURL,
+		FailureReason:  payment.FailureReason,
+		UnderReview:    payment.IsHeld(),
+		RefundedAmount: payment.RefundedAmount,
+		NetAmount:      payment.NetAmount(),
+		CreatedAt:      payment.CreatedAt.Format(time.RFC3339),
 	}
 
 	if payment.PaidAt != nil {
 		resp.PaidAt = payment.PaidAt.Format(time.RFC3339)
 	}
+	if payment.ExpiresAt != nil {
+		resp.ExpiresAt = payment.ExpiresAt.Format(time.RFC3339)
+	}
+	if payment.RateAsOf != nil && !payment.RateAsOf.IsZero() {
+		resp.RateAsOf = payment.RateAsOf.Format(time.RFC3339)
+	}
 
 	return resp
 }
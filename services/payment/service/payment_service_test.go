@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/client"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/risk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProvider accepts every request. Charges are left pending; refunds settle
+// with refundStatus, or immediately succeed if it is empty.
+type stubProvider struct {
+	refundStatus domain.PaymentStatus
+}
+
+func (stubProvider) Name() string { return "stub" }
+
+func (stubProvider) CreateCharge(ctx context.Context, req *provider.ChargeRequest) (*provider.Charge, error) {
+	return &provider.Charge{ProviderRef: "REF-" + req.TransactionID, Status: domain.PaymentStatusPending}, nil
+}
+
+func (stubProvider) Capture(ctx context.Context, providerRef string, amount float64) error {
+	return nil
+}
+
+func (stubProvider) Void(ctx context.Context, providerRef string) error {
+	return nil
+}
+
+func (p stubProvider) Refund(ctx context.Context, req *provider.RefundRequest) (*provider.Refund, error) {
+	status := p.refundStatus
+	if status == "" {
+		status = domain.PaymentStatusSuccess
+	}
+	return &provider.Refund{ProviderRef: "RF-" + req.Reference, Status: status}, nil
+}
+
+func (stubProvider) QueryStatus(ctx context.Context, providerRef string) (domain.PaymentStatus, error) {
+	return domain.PaymentStatusPending, nil
+}
+
+// stubOrders plays Order Service. It knows order 1, a pending order of 100,000 owned by user 7.
+type stubOrders struct {
+	orders  map[uint]*client.OrderInfo
+	paidBy  map[uint]string
+	markErr error // Returned by MarkOrderPaid while set
+}
+
+func newStubOrders() *stubOrders {
+	return &stubOrders{
+		orders: map[uint]*client.OrderInfo{1: {ID: 1, UserID: 7, Status: "pending", TotalAmount: 100_000}},
+		paidBy: make(map[uint]string),
+	}
+}
+
+func (o *stubOrders) GetOrder(ctx context.Context, orderID uint) (*client.OrderInfo, error) {
+	return o.orders[orderID], nil
+}
+
+func (o *stubOrders) MarkOrderPaid(ctx context.Context, orderID uint, transactionID string, amount float64) error {
+	if o.markErr != nil {
+		return o.markErr
+	}
+	o.paidBy[orderID] = transactionID
+	o.orders[orderID].Status = "paid"
+	return nil
+}
+
+func (o *stubOrders) CancelOrder(ctx context.Context, orderID uint, reason string) error {
+	o.orders[orderID].Status = "cancelled"
+	return nil
+}
+
+// testRates values one SGD at 11,650 IDR
+var testRates = currency.NewStaticRates(currency.IDR, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "test",
+	map[currency.Code]float64{currency.SGD: 11_650})
+
+// stubEvaluator returns a fixed risk assessment. The zero value allows every attempt.
+type stubEvaluator struct {
+	assessment risk.Assessment
+	attempts   []risk.Attempt
+}
+
+func (e *stubEvaluator) Evaluate(ctx context.Context, attempt *risk.Attempt) (*risk.Assessment, error) {
+	e.attempts = append(e.attempts, *attempt)
+	assessment := e.assessment
+	if assessment.Decision == "" {
+		assessment.Decision = domain.RiskDecisionAllow
+	}
+	return &assessment, nil
+}
+
+// newUncheckedPaymentService creates a payment service whose risk checks allow every attempt
+func newUncheckedPaymentService(paymentRepo *repository.MockPaymentRepository, savedMethods repository.SavedMethodRepository, router *provider.Router, orders OrderClient) PaymentService {
+	return NewPaymentService(paymentRepo, savedMethods, router, orders, DefaultExpiryPolicy(), testRates,
+		&stubEvaluator{}, repository.NewMockRiskRepository(paymentRepo))
+}
+
+func newTestPaymentService(t *testing.T) (PaymentService, *dto.PaymentResponse) {
+	t.Helper()
+	paymentService, _, payment := newTestPaymentServiceWithOrders(t)
+	return paymentService, payment
+}
+
+func newTestPaymentServiceWithOrders(t *testing.T) (PaymentService, *stubOrders, *dto.PaymentResponse) {
+	t.Helper()
+	router := provider.NewRouter()
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	orders := newStubOrders()
+	paymentService := newUncheckedPaymentService(repository.NewMockPaymentRepository(), repository.NewMockSavedMethodRepository(), router, orders)
+
+	payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{
+		OrderID: 1, Amount: 100_000, Method: domain.PaymentMethodQRIS,
+	})
+	require.NoError(t, err)
+	return paymentService, orders, payment
+}
+
+func TestPaymentService_CreatePayment_ChargesOrderTotal(t *testing.T) {
+	// Arrange
+	router := provider.NewRouter()
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	paymentService := newUncheckedPaymentService(repository.NewMockPaymentRepository(), repository.NewMockSavedMethodRepository(), router, newStubOrders())
+
+	// Act: no amount sent by the client
+	payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 100_000.0, payment.Amount)
+}
+
+func TestPaymentService_CreatePayment_SnapshotsExchangeRate(t *testing.T) {
+	// Arrange: an order priced in SGD
+	orders := newStubOrders()
+	orders.orders[1].TotalAmount = 85.84
+	orders.orders[1].Currency = "SGD"
+	router := provider.NewRouter()
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	paymentService := newUncheckedPaymentService(repository.NewMockPaymentRepository(), repository.NewMockSavedMethodRepository(), router, orders)
+
+	// Act
+	payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{
+		OrderID: 1, Currency: "sgd", Method: domain.PaymentMethodQRIS,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "SGD", payment.Currency)
+	assert.Equal(t, 85.84, payment.Amount)
+	assert.Equal(t, "IDR", payment.BaseCurrency)
+	assert.Equal(t, 11_650.0, payment.ExchangeRate)
+	assert.Equal(t, 1_000_036.0, payment.BaseAmount)
+	assert.Equal(t, "test", payment.RateSource)
+	assert.Equal(t, "2024-03-15T00:00:00Z", payment.RateAsOf)
+
+	full, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "S$ 85.84 by qris", full.Timeline[0].Note)
+}
+
+func TestPaymentService_CreatePayment_ValidatesOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   uint
+		orderID  uint
+		amount   float64
+		currency string
+		status   string
+		wantErr  error
+	}{
+		{name: "unknown order", userID: 7, orderID: 2, wantErr: ErrOrderNotFound},
+		{name: "order of another user", userID: 8, orderID: 1, wantErr: ErrOrderNotFound},
+		{name: "amount below total", userID: 7, orderID: 1, amount: 1_000, wantErr: ErrAmountMismatch},
+		{name: "cancelled order", userID: 7, orderID: 1, status: "cancelled", wantErr: ErrOrderNotPayable},
+		{name: "paid order", userID: 7, orderID: 1, status: "paid", wantErr: ErrOrderNotPayable},
+		{name: "currency other than the order's", userID: 7, orderID: 1, currency: "SGD", wantErr: ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			orders := newStubOrders()
+			if tt.status != "" {
+				orders.orders[1].Status = tt.status
+			}
+			router := provider.NewRouter()
+			router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+			paymentService := newUncheckedPaymentService(repository.NewMockPaymentRepository(), repository.NewMockSavedMethodRepository(), router, orders)
+
+			// Act
+			_, err := paymentService.CreatePayment(tt.userID, &dto.CreatePaymentRequest{
+				OrderID: tt.orderID, Amount: tt.amount, Currency: tt.currency, Method: domain.PaymentMethodQRIS,
+			})
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPaymentService_CreatePayment_OneLiveAttemptPerOrder(t *testing.T) {
+	// Arrange
+	paymentService, first := newTestPaymentService(t)
+	retry := &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS}
+
+	// Act: try again while the first attempt is pending, then after it fails
+	_, pendingErr := paymentService.CreatePayment(7, retry)
+	_, err := paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+		TransactionID: first.TransactionID, Status: domain.PaymentStatusFailed, FailureReason: "insufficient funds",
+	})
+	require.NoError(t, err)
+	second, err := paymentService.CreatePayment(7, retry)
+
+	// Assert
+	assert.ErrorIs(t, pendingErr, ErrPaymentExists)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.TransactionID, second.TransactionID)
+
+	latest, err := paymentService.GetPaymentByOrderID(1)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, latest.ID)
+}
+
+func TestPaymentService_ProcessPayment_MarksOrderPaid(t *testing.T) {
+	// Arrange
+	paymentService, orders, payment := newTestPaymentServiceWithOrders(t)
+
+	// Act
+	_, err := paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+		TransactionID: payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, payment.TransactionID, orders.paidBy[1])
+}
+
+func TestPaymentService_ProcessPayment_RetriesMarkingOrderPaid(t *testing.T) {
+	// Arrange: Order Service is unreachable when the success notification first arrives
+	paymentService, orders, payment := newTestPaymentServiceWithOrders(t)
+	orders.markErr = errors.New("connection refused")
+	req := &dto.ProcessPaymentRequest{TransactionID: payment.TransactionID, Status: domain.PaymentStatusSuccess}
+
+	_, err := paymentService.ProcessPayment(req)
+	require.ErrorIs(t, err, ErrOrderLookup)
+
+	// Act: the provider retries the notification
+	orders.markErr = nil
+	_, err = paymentService.ProcessPayment(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, payment.TransactionID, orders.paidBy[1])
+}
+
+func TestPaymentService_ProcessPayment_RecordsTimeline(t *testing.T) {
+	// Arrange
+	paymentService, payment := newTestPaymentService(t)
+
+	// Act
+	_, err := paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+		EventID: "evt-1", TransactionID: payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+	require.NoError(t, err)
+	got, err := paymentService.GetPayment(payment.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusSuccess, got.Status)
+	require.Len(t, got.Timeline, 3)
+	assert.Equal(t, domain.PaymentEventCreated, got.Timeline[0].Type)
+	assert.Equal(t, domain.PaymentEventCharge, got.Timeline[1].Type)
+	last := got.Timeline[2]
+	assert.Equal(t, domain.PaymentEventNotification, last.Type)
+	assert.Equal(t, domain.PaymentStatusPending, last.FromStatus)
+	assert.Equal(t, domain.PaymentStatusSuccess, last.ToStatus)
+	assert.Contains(t, string(last.Payload), `"event_id":"evt-1"`)
+}
+
+func TestPaymentService_ProcessPayment_RejectsIllegalTransition(t *testing.T) {
+	// Arrange: a fully refunded payment
+	paymentService, payment := newTestPaymentService(t)
+	for _, status := range []domain.PaymentStatus{domain.PaymentStatusSuccess, domain.PaymentStatusRefunded} {
+		_, err := paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+			TransactionID: payment.TransactionID, Status: status,
+		})
+		require.NoError(t, err)
+	}
+
+	// Act
+	_, err := paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+		TransactionID: payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+	got, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRefunded, got.Status)
+	rejected := got.Timeline[len(got.Timeline)-1]
+	assert.Equal(t, domain.PaymentEventNotification, rejected.Type)
+	assert.Equal(t, domain.PaymentStatusRefunded, rejected.ToStatus)
+	assert.NotEmpty(t, rejected.Error)
+}
+
+func TestPaymentService_ProcessPayment_RepeatedStatusIsIdempotent(t *testing.T) {
+	paymentService, payment := newTestPaymentService(t)
+	req := &dto.ProcessPaymentRequest{TransactionID: payment.TransactionID, Status: domain.PaymentStatusFailed}
+
+	_, err := paymentService.ProcessPayment(req)
+	require.NoError(t, err)
+	_, err = paymentService.ProcessPayment(req)
+
+	assert.NoError(t, err)
+}
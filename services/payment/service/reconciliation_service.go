@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+)
+
+var (
+	ErrReconciliationNotFound = errors.New("reconciliation not found")
+	ErrInvalidPeriod          = errors.New("reconciliation period must end after it starts")
+)
+
+// lookupBatchSize bounds the number of references looked up per query
+const lookupBatchSize = 1000
+
+// ReconcileRequest describes a settlement statement to reconcile
+type ReconcileRequest struct {
+	Provider string
+	FileName string
+	// Payments paid in [PeriodStart, PeriodEnd) are expected in the statement
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Statement   io.Reader
+}
+
+// ReconciliationService defines the interface for settlement reconciliation
+type ReconciliationService interface {
+	// Reconcile matches a statement against our payments and stores the report
+	Reconcile(req *ReconcileRequest) (*dto.ReconciliationResponse, error)
+	GetReconciliations(page, pageSize int) (*dto.ReconciliationListResponse, error)
+	// GetReconciliation returns a report with its entries, optionally only those with a status
+	GetReconciliation(id uint, status domain.ReconciliationStatus) (*dto.ReconciliationResponse, error)
+}
+
+type reconciliationServiceImpl struct {
+	paymentRepo        repository.PaymentRepository
+	reconciliationRepo repository.ReconciliationRepository
+}
+
+// NewReconciliationService creates a new instance of ReconciliationService
+func NewReconciliationService(
+	paymentRepo repository.PaymentRepository,
+	reconciliationRepo repository.ReconciliationRepository,
+) ReconciliationService {
+	return &reconciliationServiceImpl{
+		paymentRepo:        paymentRepo,
+		reconciliationRepo: reconciliationRepo,
+	}
+}
+
+func (s *reconciliationServiceImpl) Reconcile(req *ReconcileRequest) (*dto.ReconciliationResponse, error) {
+	if !req.PeriodEnd.After(req.PeriodStart) {
+		return nil, ErrInvalidPeriod
+	}
+
+	rows, err := ParseSettlementStatement(req.Statement)
+	if err != nil {
+		return nil, err
+	}
+
+	byTransactionID, byProviderRef, err := s.findStatementPayments(req.Provider, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation := &domain.Reconciliation{
+		Provider:    req.Provider,
+		FileName:    req.FileName,
+		PeriodStart: req.PeriodStart,
+		PeriodEnd:   req.PeriodEnd,
+		Rows:        len(rows),
+	}
+
+	settledOn := make(map[uint]int) // Payment ID to the first line that settled it
+	for _, row := range rows {
+		reconciliation.StatementTotal += row.Amount
+
+		payment := byTransactionID[row.TransactionID]
+		if payment == nil {
+			payment = byProviderRef[row.ProviderRef]
+		}
+		entry := domain.ReconciliationEntry{
+			Line:            row.Line,
+			TransactionID:   row.TransactionID,
+			ProviderRef:     row.ProviderRef,
+			StatementAmount: row.Amount,
+		}
+
+		switch {
+		case payment == nil:
+			entry.Status = domain.ReconciliationMissingPayment
+			entry.Note = "No matching payment"
+		default:
+			entry.PaymentID = &payment.ID
+			entry.PaymentAmount = payment.Amount
+			if entry.TransactionID == "" {
+				entry.TransactionID = payment.TransactionID
+			}
+			if entry.ProviderRef == "" {
+				entry.ProviderRef = payment.ProviderRef
+			}
+
+			if first, seen := settledOn[payment.ID]; seen {
+				entry.Status = domain.ReconciliationDuplicate
+				entry.Note = fmt.Sprintf("Already settled on line %d", first)
+				break
+			}
+			settledOn[payment.ID] = row.Line
+
+			switch {
+			case payment.Status != domain.PaymentStatusSuccess && payment.Status != domain.PaymentStatusRefunded:
+				entry.Status = domain.ReconciliationStatusMismatch
+				entry.Note = fmt.Sprintf("Payment is %s", payment.Status)
+			case math.Abs(row.Amount-payment.Amount) >= 0.01:
+				entry.Status = domain.ReconciliationAmountMismatch
+				entry.Note = fmt.Sprintf("Statement differs by %s", currency.Code(payment.Currency).Format(row.Amount-payment.Amount))
+			default:
+				entry.Status = domain.ReconciliationMatched
+			}
+		}
+
+		reconciliation.Count(entry.Status)
+		reconciliation.Entries = append(reconciliation.Entries, entry)
+	}
+
+	paid, err := s.paymentRepo.FindPaidBetween(req.Provider, req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range paid {
+		if _, seen := settledOn[payment.ID]; seen {
+			continue
+		}
+		id := payment.ID
+		entry := domain.ReconciliationEntry{
+			Status:        domain.ReconciliationMissingSettlement,
+			TransactionID: payment.TransactionID,
+			ProviderRef:   payment.ProviderRef,
+			PaymentID:     &id,
+			PaymentAmount: payment.Amount,
+			Note:          fmt.Sprintf("Paid at %s", payment.PaidAt.Format(time.RFC3339)),
+		}
+		reconciliation.Count(entry.Status)
+		reconciliation.Entries = append(reconciliation.Entries, entry)
+	}
+
+	if err := s.reconciliationRepo.Create(reconciliation); err != nil {
+		return nil, err
+	}
+
+	resp := toReconciliationResponse(reconciliation)
+	resp.Entries = toReconciliationEntryResponses(reconciliation.Entries)
+	return resp, nil
+}
+
+// findStatementPayments looks up the payments referenced by statement rows,
+// indexed by transaction ID and by the provider's reference
+func (s *reconciliationServiceImpl) findStatementPayments(provider string, rows []StatementRow) (map[string]*domain.Payment, map[string]*domain.Payment, error) {
+	var transactionIDs, providerRefs []string
+	for _, row := range rows {
+		if row.TransactionID != "" {
+			transactionIDs = append(transactionIDs, row.TransactionID)
+		}
+		if row.ProviderRef != "" {
+			providerRefs = append(providerRefs, row.ProviderRef)
+		}
+	}
+
+	byTransactionID := make(map[string]*domain.Payment)
+	byProviderRef := make(map[string]*domain.Payment)
+	index := func(payments []domain.Payment) {
+		for i := range payments {
+			payment := &payments[i]
+			byTransactionID[payment.TransactionID] = payment
+			if payment.ProviderRef != "" {
+				byProviderRef[payment.ProviderRef] = payment
+			}
+		}
+	}
+
+	for start := 0; start < len(transactionIDs); start += lookupBatchSize {
+		end := min(start+lookupBatchSize, len(transactionIDs))
+		payments, err := s.paymentRepo.FindByTransactionIDs(transactionIDs[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+		index(payments)
+	}
+	for start := 0; start < len(providerRefs); start += lookupBatchSize {
+		end := min(start+lookupBatchSize, len(providerRefs))
+		payments, err := s.paymentRepo.FindByProviderRefs(provider, providerRefs[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+		index(payments)
+	}
+	return byTransactionID, byProviderRef, nil
+}
+
+func (s *reconciliationServiceImpl) GetReconciliations(page, pageSize int) (*dto.ReconciliationListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	reconciliations, total, err := s.reconciliationRepo.FindAll(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ReconciliationResponse, len(reconciliations))
+	for i := range reconciliations {
+		responses[i] = *toReconciliationResponse(&reconciliations[i])
+	}
+
+	return &dto.ReconciliationListResponse{
+		Reconciliations: responses,
+		Total:           total,
+		Page:            page,
+		PageSize:        pageSize,
+		TotalPages:      int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *reconciliationServiceImpl) GetReconciliation(id uint, status domain.ReconciliationStatus) (*dto.ReconciliationResponse, error) {
+	reconciliation, err := s.reconciliationRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrReconciliationNotFound
+	}
+
+	entries, err := s.reconciliationRepo.FindEntries(id, status)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toReconciliationResponse(reconciliation)
+	resp.Entries = toReconciliationEntryResponses(entries)
+	return resp, nil
+}
+
+func toReconciliationResponse(reconciliation *domain.Reconciliation) *dto.ReconciliationResponse {
+	return &dto.ReconciliationResponse{
+		ID:             reconciliation.ID,
+		Provider:       reconciliation.Provider,
+		FileName:       reconciliation.FileName,
+		PeriodStart:    reconciliation.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:      reconciliation.PeriodEnd.Format(time.RFC3339),
+		Rows:           reconciliation.Rows,
+		StatementTotal: reconciliation.StatementTotal,
+		Summary: map[string]int{
+			string(domain.ReconciliationMatched):           reconciliation.Matched,
+			string(domain.ReconciliationMissingPayment):    reconciliation.MissingPayment,
+			string(domain.ReconciliationMissingSettlement): reconciliation.MissingSettlement,
+			string(domain.ReconciliationDuplicate):         reconciliation.Duplicate,
+			string(domain.ReconciliationAmountMismatch):    reconciliation.AmountMismatch,
+			string(domain.ReconciliationStatusMismatch):    reconciliation.StatusMismatch,
+		},
+		Discrepancies: reconciliation.Discrepancies(),
+		CreatedAt:     reconciliation.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toReconciliationEntryResponses(entries []domain.ReconciliationEntry) []dto.ReconciliationEntryResponse {
+	responses := make([]dto.ReconciliationEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = dto.ReconciliationEntryResponse{
+			Line:            entry.Line,
+			Status:          entry.Status,
+			TransactionID:   entry.TransactionID,
+			ProviderRef:     entry.ProviderRef,
+			PaymentID:       entry.PaymentID,
+			StatementAmount: entry.StatementAmount,
+			PaymentAmount:   entry.PaymentAmount,
+			Note:            entry.Note,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var statementDay = time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+func seedPayment(t *testing.T, repo *repository.MockPaymentRepository, txID, ref string, amount float64, status domain.PaymentStatus) {
+	t.Helper()
+	paidAt := statementDay.Add(10 * time.Hour)
+	payment := &domain.Payment{
+		TransactionID: txID, Provider: "stub", ProviderRef: ref, Amount: amount, Status: status,
+	}
+	if status == domain.PaymentStatusSuccess {
+		payment.PaidAt = &paidAt
+	}
+	require.NoError(t, repo.Create(payment))
+}
+
+func TestReconciliationService_Reconcile(t *testing.T) {
+	// Arrange
+	paymentRepo := repository.NewMockPaymentRepository()
+	seedPayment(t, paymentRepo, "TXN-1", "REF-1", 100_000, domain.PaymentStatusSuccess)
+	seedPayment(t, paymentRepo, "TXN-2", "REF-2", 50_000, domain.PaymentStatusSuccess)
+	seedPayment(t, paymentRepo, "TXN-3", "REF-3", 75_000, domain.PaymentStatusSuccess)
+	seedPayment(t, paymentRepo, "TXN-4", "REF-4", 20_000, domain.PaymentStatusPending)
+	seedPayment(t, paymentRepo, "TXN-5", "REF-5", 30_000, domain.PaymentStatusSuccess) // Not in the statement
+	reconciliationService := NewReconciliationService(paymentRepo, repository.NewMockReconciliationRepository())
+
+	statement := strings.Join([]string{
+		"transaction_id,provider_ref,amount,fee",
+		"TXN-1,REF-1,100000.00,1500",
+		",REF-2,49000,1000", // Matched by provider reference only
+		"TXN-3,,75000,1000",
+		"TXN-3,,75000,1000",
+		"TXN-4,REF-4,20000,500",
+		"TXN-9,REF-9,10000,500",
+	}, "\n")
+
+	// Act
+	report, err := reconciliationService.Reconcile(&ReconcileRequest{
+		Provider:    "stub",
+		FileName:    "settlement.csv",
+		PeriodStart: statementDay,
+		PeriodEnd:   statementDay.AddDate(0, 0, 1),
+		Statement:   strings.NewReader(statement),
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Rows)
+	assert.Equal(t, 329_000.0, report.StatementTotal)
+	assert.Equal(t, 5, report.Discrepancies)
+
+	statuses := make([]domain.ReconciliationStatus, len(report.Entries))
+	for i, entry := range report.Entries {
+		statuses[i] = entry.Status
+	}
+	assert.Equal(t, []domain.ReconciliationStatus{
+		domain.ReconciliationMatched,
+		domain.ReconciliationAmountMismatch,
+		domain.ReconciliationMatched,
+		domain.ReconciliationDuplicate,
+		domain.ReconciliationStatusMismatch,
+		domain.ReconciliationMissingPayment,
+		domain.ReconciliationMissingSettlement,
+	}, statuses)
+	assert.Equal(t, "TXN-2", report.Entries[1].TransactionID)
+	assert.Equal(t, "Already settled on line 4", report.Entries[3].Note)
+	assert.Equal(t, "TXN-5", report.Entries[6].TransactionID)
+
+	// The stored report can be queried by entry status
+	stored, err := reconciliationService.GetReconciliation(report.ID, domain.ReconciliationAmountMismatch)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Summary["amount_mismatch"])
+	require.Len(t, stored.Entries, 1)
+	assert.Equal(t, 3, stored.Entries[0].Line)
+}
+
+func TestParseSettlementStatement_Rejects(t *testing.T) {
+	tests := map[string]string{
+		"empty file":       "",
+		"missing amount":   "transaction_id\nTXN-1",
+		"no reference":     "transaction_id,provider_ref,amount\n,,100",
+		"non-numeric":      "transaction_id,amount\nTXN-1,abc",
+		"no id column":     "amount,fee\n100,1",
+		"unbalanced quote": "transaction_id,amount\n\"TXN-1,100",
+	}
+	for name, statement := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSettlementStatement(strings.NewReader(statement))
+			assert.ErrorIs(t, err, ErrInvalidStatement)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/currency"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+)
+
+var (
+	ErrPaymentNotSuccess       = errors.New("only successful payments can be refunded")
+	ErrRefundTooLarge          = errors.New("refund amount exceeds the refundable balance")
+	ErrRefundNotFound          = errors.New("refund not found")
+	ErrRefundReferenceConflict = errors.New("refund reference already used for a different amount")
+)
+
+// RefundService defines the interface for refund operations
+type RefundService interface {
+	// RequestRefund reserves a refund and submits it to the provider in the background.
+	// The returned refund is pending; its outcome arrives later. Repeating a reference returns
+	// that refund, submitting it again if it failed.
+	RequestRefund(paymentID, actorID uint, req *dto.RefundRequest) (*dto.RefundResponse, error)
+	GetRefunds(paymentID uint) ([]dto.RefundResponse, error)
+	// ProcessRefund applies a refund outcome reported by the provider
+	ProcessRefund(req *dto.ProcessPaymentRequest) (*dto.RefundResponse, error)
+}
+
+type refundServiceImpl struct {
+	paymentRepo repository.PaymentRepository
+	refundRepo  repository.RefundRepository
+	providers   *provider.Router
+	// dispatch runs provider calls off the request path
+	dispatch func(func())
+}
+
+// NewRefundService creates a new instance of RefundService
+func NewRefundService(
+	paymentRepo repository.PaymentRepository,
+	refundRepo repository.RefundRepository,
+	providers *provider.Router,
+) RefundService {
+	return &refundServiceImpl{
+		paymentRepo: paymentRepo,
+		refundRepo:  refundRepo,
+		providers:   providers,
+		dispatch:    func(fn func()) { go fn() },
+	}
+}
+
+func (s *refundServiceImpl) RequestRefund(paymentID, actorID uint, req *dto.RefundRequest) (*dto.RefundResponse, error) {
+	payment, err := s.paymentRepo.FindByID(paymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	reference := req.Reference
+	if reference == "" {
+		reference = "RF-" + uuid.New().String()
+	} else {
+		existing, err := s.refundRepo.FindByReference(payment.ID, reference)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			code := currency.Code(payment.Currency)
+			if req.Amount != 0 && code.Round(req.Amount) != code.Round(existing.Amount) {
+				return nil, ErrRefundReferenceConflict
+			}
+			if existing.Status != domain.RefundStatusFailed {
+				return toRefundResponse(existing), nil
+			}
+			// A failed refund returned nothing, so asking again with its reference re-issues it
+			return s.reissue(payment, existing)
+		}
+	}
+
+	if payment.Status != domain.PaymentStatusSuccess {
+		return nil, ErrPaymentNotSuccess
+	}
+
+	refund := &domain.Refund{
+		PaymentID:   payment.ID,
+		Reference:   reference,
+		Amount:      req.Amount,
+		Reason:      req.Reason,
+		Status:      domain.RefundStatusPending,
+		RequestedBy: actorID,
+	}
+	reserved, err := s.refundRepo.Reserve(refund)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, ErrRefundTooLarge
+	}
+
+	note := fmt.Sprintf("Refund %s of %s requested: %s", refund.Reference, currency.Code(payment.Currency).Format(refund.Amount), refund.Reason)
+	return s.start(payment, refund, note), nil
+}
+
+// reissue submits a failed refund again under the same reference
+func (s *refundServiceImpl) reissue(payment *domain.Payment, refund *domain.Refund) (*dto.RefundResponse, error) {
+	if payment.Status != domain.PaymentStatusSuccess {
+		return nil, ErrPaymentNotSuccess
+	}
+	previousFailure := refund.FailureReason
+	reopened, err := s.refundRepo.Reopen(refund)
+	if err != nil {
+		return nil, err
+	}
+	if !reopened {
+		// Either too little is left, or a concurrent request re-issued it first
+		current, err := s.refundRepo.FindByReference(payment.ID, refund.Reference)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil || current.Status == domain.RefundStatusFailed {
+			return nil, ErrRefundTooLarge
+		}
+		return toRefundResponse(current), nil
+	}
+
+	note := fmt.Sprintf("Refund %s of %s re-issued after failing: %s", refund.Reference, currency.Code(payment.Currency).Format(refund.Amount), previousFailure)
+	return s.start(payment, refund, note), nil
+}
+
+// start records a reserved refund on the payment's timeline and submits it in the background
+func (s *refundServiceImpl) start(payment *domain.Payment, refund *domain.Refund, note string) *dto.RefundResponse {
+	_ = s.paymentRepo.AddEvent(&domain.PaymentEvent{
+		PaymentID:  payment.ID,
+		Type:       domain.PaymentEventRefund,
+		FromStatus: payment.Status,
+		ToStatus:   payment.Status,
+		Provider:   payment.Provider,
+		Note:       note,
+		CreatedAt:  time.Now(),
+	})
+
+	// Copy so the background submission doesn't share state with the response
+	submitted := *refund
+	s.dispatch(func() { s.submit(payment, &submitted) })
+
+	return toRefundResponse(refund)
+}
+
+func (s *refundServiceImpl) GetRefunds(paymentID uint) ([]dto.RefundResponse, error) {
+	refunds, err := s.refundRepo.FindByPaymentID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.RefundResponse, len(refunds))
+	for i, refund := range refunds {
+		responses[i] = *toRefundResponse(&refund)
+	}
+	return responses, nil
+}
+
+func (s *refundServiceImpl) ProcessRefund(req *dto.ProcessPaymentRequest) (*dto.RefundResponse, error) {
+	payment, err := s.paymentRepo.FindByTransactionID(req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, ErrPaymentNotFound
+	}
+	if !notifiedBy(payment, req) {
+		return nil, ErrProviderMismatch
+	}
+
+	refund, err := s.refundRepo.FindByReference(payment.ID, req.RefundReference)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil {
+		return nil, ErrRefundNotFound
+	}
+
+	var status domain.RefundStatus
+	switch req.Status {
+	case domain.PaymentStatusSuccess:
+		status = domain.RefundStatusSuccess
+	case domain.PaymentStatusFailed:
+		status = domain.RefundStatusFailed
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	if refund.Status.IsFinal() {
+		// Providers may report the same outcome more than once
+		if refund.Status == status {
+			return toRefundResponse(refund), nil
+		}
+		return nil, ErrInvalidStatus
+	}
+
+	if req.ProviderRef != "" {
+		refund.ProviderRef = req.ProviderRef
+	}
+	settled, err := s.settle(refund, status, req.FailureReason, payloadJSON(req))
+	if err != nil {
+		return nil, err
+	}
+	if !settled {
+		// Settled concurrently, e.g. by the provider's synchronous answer
+		return s.ProcessRefund(req)
+	}
+	return toRefundResponse(refund), nil
+}
+
+// submit sends a reserved refund to the provider that captured the payment
+func (s *refundServiceImpl) submit(payment *domain.Payment, refund *domain.Refund) {
+	gateway, err := s.providers.ByName(payment.Provider)
+	if err != nil {
+		_, _ = s.settle(refund, domain.RefundStatusFailed, err.Error(), "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	result, err := gateway.Refund(ctx, &provider.RefundRequest{
+		ProviderRef:   payment.ProviderRef,
+		TransactionID: payment.TransactionID,
+		Reference:     refund.Reference,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+	})
+	if err != nil {
+		_, _ = s.settle(refund, domain.RefundStatusFailed, err.Error(), "")
+		return
+	}
+
+	refund.ProviderRef = result.ProviderRef
+	switch result.Status {
+	case domain.PaymentStatusSuccess:
+		_, _ = s.settle(refund, domain.RefundStatusSuccess, "", payloadJSON(result))
+	case domain.PaymentStatusFailed:
+		_, _ = s.settle(refund, domain.RefundStatusFailed, "Refused by provider", payloadJSON(result))
+	default:
+		_ = s.refundRepo.MarkSubmitted(refund)
+	}
+}
+
+// settle records the outcome of a refund and updates the payment's refunded balance.
+// The payment becomes refunded once refunds cover the whole captured amount.
+// It reports false if the refund had already been settled.
+func (s *refundServiceImpl) settle(refund *domain.Refund, status domain.RefundStatus, failureReason, payload string) (bool, error) {
+	now := time.Now()
+	refund.Status = status
+	refund.FailureReason = failureReason
+	refund.CompletedAt = &now
+
+	return s.refundRepo.Settle(refund, func(payment *domain.Payment, refundedTotal float64) *domain.PaymentEvent {
+		event := &domain.PaymentEvent{
+			Type:       domain.PaymentEventRefund,
+			FromStatus: payment.Status,
+			Provider:   payment.Provider,
+			Note:       fmt.Sprintf("Refund %s of %s %s", refund.Reference, currency.Code(payment.Currency).Format(refund.Amount), status),
+			Error:      failureReason,
+			Payload:    payload,
+			CreatedAt:  now,
+		}
+
+		payment.RefundedAmount = currency.Code(payment.Currency).Round(refundedTotal)
+		if payment.RefundedAmount >= payment.Amount && payment.CanTransitionTo(domain.PaymentStatusRefunded) {
+			payment.Status = domain.PaymentStatusRefunded
+		}
+		event.ToStatus = payment.Status
+		return event
+	})
+}
+
+func toRefundResponse(refund *domain.Refund) *dto.RefundResponse {
+	resp := &dto.RefundResponse{
+		ID:            refund.ID,
+		PaymentID:     refund.PaymentID,
+		Reference:     refund.Reference,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+		Status:        refund.Status,
+		ProviderRef:   refund.ProviderRef,
+		FailureReason: refund.FailureReason,
+		RequestedBy:   refund.RequestedBy,
+		CreatedAt:     refund.CreatedAt.Format(time.RFC3339),
+	}
+	if refund.CompletedAt != nil {
+		resp.CompletedAt = refund.CompletedAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRefundService returns a refund service for a captured payment of 100,000.
+// Provider calls run inline instead of in the background.
+func newTestRefundService(t *testing.T, gateway stubProvider) (RefundService, PaymentService, *dto.PaymentResponse) {
+	t.Helper()
+	router := provider.NewRouter()
+	router.Route(gateway, domain.PaymentMethodQRIS)
+	paymentRepo := repository.NewMockPaymentRepository()
+	paymentService := newUncheckedPaymentService(paymentRepo, repository.NewMockSavedMethodRepository(), router, newStubOrders())
+	refundService := NewRefundService(paymentRepo, repository.NewMockRefundRepository(paymentRepo), router)
+	refundService.(*refundServiceImpl).dispatch = func(fn func()) { fn() }
+
+	payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{
+		OrderID: 1, Amount: 100_000, Method: domain.PaymentMethodQRIS,
+	})
+	require.NoError(t, err)
+	_, err = paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+		TransactionID: payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+	require.NoError(t, err)
+	return refundService, paymentService, payment
+}
+
+func TestRefundService_MultiplePartialRefunds(t *testing.T) {
+	// Arrange
+	refundService, paymentService, payment := newTestRefundService(t, stubProvider{})
+
+	// Act
+	_, err := refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 30_000, Reason: "Damaged item"})
+	require.NoError(t, err)
+	_, err = refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 50_000, Reason: "Goodwill"})
+	require.NoError(t, err)
+	_, tooLarge := refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 30_000, Reason: "Too much"})
+
+	// Assert
+	assert.ErrorIs(t, tooLarge, ErrRefundTooLarge)
+	got, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusSuccess, got.Status)
+	assert.Equal(t, 80_000.0, got.RefundedAmount)
+	assert.Equal(t, 20_000.0, got.NetAmount)
+
+	refunds, err := refundService.GetRefunds(payment.ID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 2)
+	assert.Equal(t, domain.RefundStatusSuccess, refunds[0].Status)
+	assert.Equal(t, "Damaged item", refunds[0].Reason)
+}
+
+func TestRefundService_RefundingTheRestMarksPaymentRefunded(t *testing.T) {
+	refundService, paymentService, payment := newTestRefundService(t, stubProvider{})
+	_, err := refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 40_000, Reason: "Partial"})
+	require.NoError(t, err)
+
+	refund, err := refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Reason: "Remainder"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 60_000.0, refund.Amount)
+	got, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRefunded, got.Status)
+	assert.Equal(t, 0.0, got.NetAmount)
+}
+
+func TestRefundService_InFlightRefundsReserveBalance(t *testing.T) {
+	// Arrange: the provider settles refunds later by webhook
+	refundService, paymentService, payment := newTestRefundService(t, stubProvider{refundStatus: domain.PaymentStatusPending})
+	first, err := refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 60_000, Reason: "Return"})
+	require.NoError(t, err)
+
+	// Act & Assert: the pending refund counts against the balance until it fails
+	_, err = refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 60_000, Reason: "Return"})
+	assert.ErrorIs(t, err, ErrRefundTooLarge)
+
+	failed, err := refundService.ProcessRefund(&dto.ProcessPaymentRequest{
+		TransactionID:   payment.TransactionID,
+		RefundReference: first.Reference,
+		Status:          domain.PaymentStatusFailed,
+		FailureReason:   "Account closed",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RefundStatusFailed, failed.Status)
+
+	_, err = refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 60_000, Reason: "Return"})
+	assert.NoError(t, err)
+
+	got, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, got.RefundedAmount)
+}
+
+func TestRefundService_ReferenceIsIdempotent(t *testing.T) {
+	refundService, _, payment := newTestRefundService(t, stubProvider{})
+	req := &dto.RefundRequest{Amount: 10_000, Reason: "Return #4", Reference: "return-4"}
+
+	first, err := refundService.RequestRefund(payment.ID, 1, req)
+	require.NoError(t, err)
+	second, err := refundService.RequestRefund(payment.ID, 1, req)
+	require.NoError(t, err)
+	_, conflict := refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 5_000, Reason: "x", Reference: "return-4"})
+
+	assert.Equal(t, first.ID, second.ID)
+	assert.ErrorIs(t, conflict, ErrRefundReferenceConflict)
+	refunds, err := refundService.GetRefunds(payment.ID)
+	require.NoError(t, err)
+	assert.Len(t, refunds, 1)
+}
+
+func TestRefundService_ReusedReferenceReissuesFailedRefund(t *testing.T) {
+	// Arrange: the first attempt is refused by the provider
+	refundService, paymentService, payment := newTestRefundService(t, stubProvider{refundStatus: domain.PaymentStatusPending})
+	req := &dto.RefundRequest{Amount: 25_000, Reason: "Return #9", Reference: "return-9"}
+	first, err := refundService.RequestRefund(payment.ID, 1, req)
+	require.NoError(t, err)
+	_, err = refundService.ProcessRefund(&dto.ProcessPaymentRequest{
+		TransactionID: payment.TransactionID, RefundReference: "return-9", Status: domain.PaymentStatusFailed,
+	})
+	require.NoError(t, err)
+
+	// Act: the caller retries with the same reference
+	retried, err := refundService.RequestRefund(payment.ID, 1, req)
+	require.NoError(t, err)
+	_, err = refundService.ProcessRefund(&dto.ProcessPaymentRequest{
+		TransactionID: payment.TransactionID, RefundReference: "return-9", Status: domain.PaymentStatusSuccess,
+	})
+	require.NoError(t, err)
+
+	// Assert: the same refund was sent again rather than its failure reported back
+	assert.Equal(t, first.ID, retried.ID)
+	assert.Equal(t, domain.RefundStatusPending, retried.Status)
+	got, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 25_000.0, got.RefundedAmount)
+}
+
+func TestRefundService_PartialRefundsAddUpExactly(t *testing.T) {
+	// Arrange: a payment of S$0.30, which 0.1 at a time does not add up to exactly in float64
+	router := provider.NewRouter()
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	orders := newStubOrders()
+	orders.orders[1].Currency = "SGD"
+	orders.orders[1].TotalAmount = 0.3
+	paymentRepo := repository.NewMockPaymentRepository()
+	paymentService := newUncheckedPaymentService(paymentRepo, repository.NewMockSavedMethodRepository(), router, orders)
+	refundService := NewRefundService(paymentRepo, repository.NewMockRefundRepository(paymentRepo), router)
+	refundService.(*refundServiceImpl).dispatch = func(fn func()) { fn() }
+
+	payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS})
+	require.NoError(t, err)
+	_, err = paymentService.ProcessPayment(&dto.ProcessPaymentRequest{
+		TransactionID: payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+	require.NoError(t, err)
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_, err = refundService.RequestRefund(payment.ID, 1, &dto.RefundRequest{Amount: 0.1, Reason: "Partial"})
+		require.NoError(t, err, "refund %d", i+1)
+	}
+
+	// Assert
+	got, err := paymentService.GetPayment(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRefunded, got.Status)
+	assert.Equal(t, 0.3, got.RefundedAmount)
+}
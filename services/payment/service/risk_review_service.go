@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+)
+
+var (
+	ErrReviewNotFound      = errors.New("risk review not found")
+	ErrReviewResolved      = errors.New("risk review has already been resolved")
+	ErrInvalidReviewStatus = errors.New("invalid review status")
+)
+
+// RiskReviewService defines the interface for the manual review queue of held payments
+type RiskReviewService interface {
+	GetReviews(status domain.ReviewStatus, page, pageSize int) (*dto.RiskReviewListResponse, error)
+	// GetReview returns a review with the held payment and its timeline
+	GetReview(id uint) (*dto.RiskReviewResponse, error)
+	// Approve releases the held payment for charging
+	Approve(id, reviewerID uint, note string) (*dto.RiskReviewResponse, error)
+	// Reject cancels the held payment and its order
+	Reject(id, reviewerID uint, note string) (*dto.RiskReviewResponse, error)
+}
+
+type riskReviewServiceImpl struct {
+	risks    repository.RiskRepository
+	payments PaymentService
+	now      func() time.Time
+}
+
+// NewRiskReviewService creates a new instance of RiskReviewService
+func NewRiskReviewService(risks repository.RiskRepository, payments PaymentService) RiskReviewService {
+	return &riskReviewServiceImpl{
+		risks:    risks,
+		payments: payments,
+		now:      time.Now,
+	}
+}
+
+func (s *riskReviewServiceImpl) GetReviews(status domain.ReviewStatus, page, pageSize int) (*dto.RiskReviewListResponse, error) {
+	if status == domain.ReviewStatusNone {
+		status = domain.ReviewStatusPending
+	}
+	if !status.IsValid() {
+		return nil, ErrInvalidReviewStatus
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	assessments, total, err := s.risks.FindReviews(status, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]dto.RiskReviewResponse, len(assessments))
+	for i, assessment := range assessments {
+		reviews[i] = *toRiskReviewResponse(&assessment)
+	}
+
+	return &dto.RiskReviewListResponse{
+		Reviews:    reviews,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *riskReviewServiceImpl) GetReview(id uint) (*dto.RiskReviewResponse, error) {
+	assessment, err := s.findReview(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toRiskReviewResponse(assessment)
+	resp.Payment, err = s.payments.GetPayment(*assessment.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Approve records the approval before charging, so two reviewers cannot both release a payment.
+// If the charge then fails, the payment's timeline says why and it fails or expires as usual.
+func (s *riskReviewServiceImpl) Approve(id, reviewerID uint, note string) (*dto.RiskReviewResponse, error) {
+	assessment, err := s.resolve(id, domain.ReviewStatusApproved, reviewerID, note)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toRiskReviewResponse(assessment)
+	resp.Payment, err = s.payments.ReleaseHeldPayment(*assessment.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *riskReviewServiceImpl) Reject(id, reviewerID uint, note string) (*dto.RiskReviewResponse, error) {
+	assessment, err := s.resolve(id, domain.ReviewStatusRejected, reviewerID, note)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.payments.CancelHeldPayment(*assessment.PaymentID, note); err != nil {
+		return nil, err
+	}
+	resp := toRiskReviewResponse(assessment)
+	resp.Payment, err = s.payments.GetPayment(*assessment.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// findReview returns an assessment that was held for review
+func (s *riskReviewServiceImpl) findReview(id uint) (*domain.RiskAssessment, error) {
+	assessment, err := s.risks.FindByID(id)
+	if err != nil || assessment.ReviewStatus == domain.ReviewStatusNone || assessment.PaymentID == nil {
+		return nil, ErrReviewNotFound
+	}
+	return assessment, nil
+}
+
+// resolve records the decision on a pending review
+func (s *riskReviewServiceImpl) resolve(id uint, status domain.ReviewStatus, reviewerID uint, note string) (*domain.RiskAssessment, error) {
+	assessment, err := s.findReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.ReviewStatus != domain.ReviewStatusPending {
+		return nil, ErrReviewResolved
+	}
+
+	now := s.now()
+	assessment.ReviewStatus = status
+	assessment.ReviewedBy = reviewerID
+	assessment.ReviewedAt = &now
+	assessment.ReviewNote = note
+	resolved, err := s.risks.Resolve(assessment)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, ErrReviewResolved
+	}
+	return assessment, nil
+}
+
+func toRiskReviewResponse(assessment *domain.RiskAssessment) *dto.RiskReviewResponse {
+	resp := &dto.RiskReviewResponse{
+		ID:           assessment.ID,
+		UserID:       assessment.UserID,
+		OrderID:      assessment.OrderID,
+		PaymentID:    assessment.PaymentID,
+		ClientIP:     assessment.ClientIP,
+		Method:       assessment.Method,
+		Amount:       assessment.Amount,
+		Currency:     assessment.Currency,
+		BaseAmount:   assessment.BaseAmount,
+		Score:        assessment.Score,
+		Decision:     assessment.Decision,
+		Reasons:      assessment.Reasons,
+		ReviewStatus: assessment.ReviewStatus,
+		ReviewedBy:   assessment.ReviewedBy,
+		ReviewNote:   assessment.ReviewNote,
+		CreatedAt:    assessment.CreatedAt.Format(time.RFC3339),
+	}
+	if assessment.ReviewedAt != nil {
+		resp.ReviewedAt = assessment.ReviewedAt.Format(time.RFC3339)
+	}
+	return resp
+}
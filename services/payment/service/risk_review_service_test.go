@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/client"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/risk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAccounts reports every account as created a year before the test clock
+type stubAccounts struct{}
+
+func (stubAccounts) AccountCreatedAt(ctx context.Context, userID uint) (time.Time, error) {
+	return time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC), nil
+}
+
+type riskTestSetup struct {
+	payments  PaymentService
+	reviews   RiskReviewService
+	risks     *repository.MockRiskRepository
+	orders    *stubOrders
+	evaluator *stubEvaluator
+}
+
+func newRiskTestSetup(decision domain.RiskDecision) *riskTestSetup {
+	router := provider.NewRouter()
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	paymentRepo := repository.NewMockPaymentRepository()
+	risks := repository.NewMockRiskRepository(paymentRepo)
+	orders := newStubOrders()
+	evaluator := &stubEvaluator{assessment: risk.Assessment{Decision: decision, Score: 60, Reasons: []string{"test reason"}}}
+	payments := NewPaymentService(paymentRepo, repository.NewMockSavedMethodRepository(), router, orders,
+		DefaultExpiryPolicy(), testRates, evaluator, risks)
+	return &riskTestSetup{
+		payments:  payments,
+		reviews:   NewRiskReviewService(risks, payments),
+		risks:     risks,
+		orders:    orders,
+		evaluator: evaluator,
+	}
+}
+
+func TestPaymentService_CreatePayment_DeniedAttempt(t *testing.T) {
+	// Arrange
+	setup := newRiskTestSetup(domain.RiskDecisionDeny)
+
+	// Act
+	_, err := setup.payments.CreatePayment(7, &dto.CreatePaymentRequest{
+		OrderID: 1, Method: domain.PaymentMethodQRIS, ClientIP: "203.0.113.9",
+	})
+
+	// Assert: no payment, but the attempt is on record
+	assert.ErrorIs(t, err, ErrPaymentDenied)
+	_, err = setup.payments.GetPaymentByOrderID(1)
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+
+	assessments := setup.risks.All()
+	require.Len(t, assessments, 1)
+	assert.Equal(t, domain.RiskDecisionDeny, assessments[0].Decision)
+	assert.Equal(t, "203.0.113.9", assessments[0].ClientIP)
+	assert.Nil(t, assessments[0].PaymentID)
+	assert.Equal(t, 100_000.0, setup.evaluator.attempts[0].BaseAmount)
+}
+
+func TestPaymentService_CreatePayment_HeldForReview(t *testing.T) {
+	// Arrange
+	setup := newRiskTestSetup(domain.RiskDecisionReview)
+
+	// Act
+	payment, err := setup.payments.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS})
+
+	// Assert: stored but not charged, and without a payment window
+	require.NoError(t, err)
+	assert.True(t, payment.UnderReview)
+	assert.Equal(t, domain.PaymentStatusPending, payment.Status)
+	assert.Empty(t, payment.ProviderRef)
+	assert.Empty(t, payment.ExpiresAt)
+
+	reviews, err := setup.reviews.GetReviews("", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, reviews.Reviews, 1)
+	assert.Equal(t, payment.ID, *reviews.Reviews[0].PaymentID)
+	assert.Equal(t, []string{"test reason"}, reviews.Reviews[0].Reasons)
+}
+
+func TestRiskReviewService_Approve_ChargesHeldPayment(t *testing.T) {
+	// Arrange
+	setup := newRiskTestSetup(domain.RiskDecisionReview)
+	held, err := setup.payments.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS})
+	require.NoError(t, err)
+	reviewID := setup.risks.All()[0].ID
+
+	// Act
+	review, err := setup.reviews.Approve(reviewID, 99, "Known customer")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewStatusApproved, review.ReviewStatus)
+	assert.Equal(t, uint(99), review.ReviewedBy)
+	require.NotNil(t, review.Payment)
+	assert.False(t, review.Payment.UnderReview)
+	assert.Equal(t, "REF-"+held.TransactionID, review.Payment.ProviderRef)
+	assert.NotEmpty(t, review.Payment.ExpiresAt)
+
+	// Act & Assert: a review is resolved once
+	_, err = setup.reviews.Approve(reviewID, 98, "")
+	assert.ErrorIs(t, err, ErrReviewResolved)
+	_, err = setup.reviews.Reject(reviewID, 98, "")
+	assert.ErrorIs(t, err, ErrReviewResolved)
+}
+
+func TestRiskReviewService_Approve_CancelsWhenOrderNoLongerPayable(t *testing.T) {
+	// Arrange: the order expired while the payment waited for review
+	setup := newRiskTestSetup(domain.RiskDecisionReview)
+	_, err := setup.payments.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS})
+	require.NoError(t, err)
+	setup.orders.orders[1].Status = "cancelled"
+
+	// Act
+	_, err = setup.reviews.Approve(setup.risks.All()[0].ID, 99, "")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrOrderNotPayable)
+	payment, err := setup.payments.GetPaymentByOrderID(1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusCancelled, payment.Status)
+}
+
+func TestRiskReviewService_Reject_CancelsPaymentAndOrder(t *testing.T) {
+	// Arrange
+	setup := newRiskTestSetup(domain.RiskDecisionReview)
+	_, err := setup.payments.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, Method: domain.PaymentMethodQRIS})
+	require.NoError(t, err)
+
+	// Act
+	review, err := setup.reviews.Reject(setup.risks.All()[0].ID, 99, "Stolen card")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewStatusRejected, review.ReviewStatus)
+	assert.Equal(t, domain.PaymentStatusCancelled, review.Payment.Status)
+	assert.Equal(t, "Rejected: Stolen card", review.Payment.Timeline[len(review.Payment.Timeline)-1].Note)
+	assert.Equal(t, "cancelled", setup.orders.orders[1].Status)
+
+	pending, err := setup.reviews.GetReviews(domain.ReviewStatusPending, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending.Reviews)
+}
+
+func TestPaymentService_CreatePayment_UserVelocity(t *testing.T) {
+	// Arrange: the default rules over recorded attempts, allowing two attempts an hour
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	router := provider.NewRouter()
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	paymentRepo := repository.NewMockPaymentRepository()
+	risks := repository.NewMockRiskRepository(paymentRepo)
+	cfg := risk.DefaultConfig()
+	cfg.MaxAttemptsPerUser = 2
+	engine := risk.NewDefaultEngine(cfg, risk.NewRepositoryHistory(risks, paymentRepo), stubAccounts{})
+	orders := newStubOrders()
+	for id := uint(2); id <= 3; id++ {
+		orders.orders[id] = &client.OrderInfo{ID: id, UserID: 7, Status: "pending", TotalAmount: 100_000}
+	}
+	paymentService := NewPaymentService(paymentRepo, repository.NewMockSavedMethodRepository(), router, orders,
+		DefaultExpiryPolicy(), testRates, engine, risks)
+	paymentService.(*paymentServiceImpl).now = func() time.Time { return now }
+
+	// Act
+	var results []*dto.PaymentResponse
+	for id := uint(1); id <= 3; id++ {
+		payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: id, Method: domain.PaymentMethodQRIS})
+		require.NoError(t, err)
+		results = append(results, payment)
+	}
+
+	// Assert: the third attempt within the hour is held
+	assert.False(t, results[0].UnderReview)
+	assert.False(t, results[1].UnderReview)
+	assert.True(t, results[2].UnderReview)
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+)
+
+var (
+	ErrSavedMethodNotFound     = errors.New("saved payment method not found")
+	ErrSavedMethodExpired      = errors.New("saved payment method has expired")
+	ErrTokenizationUnsupported = errors.New("payment method cannot be saved with its provider")
+	ErrInvalidToken            = errors.New("payment token is invalid")
+	ErrRawCardNumber           = errors.New("card numbers must be tokenized by the payment provider")
+)
+
+// SavedMethodService defines the interface for saved payment method operations
+type SavedMethodService interface {
+	// SaveMethod keeps a provider token on file for the user. Saving the same card or
+	// account again returns the method already on file.
+	SaveMethod(userID uint, req *dto.SavePaymentMethodRequest) (*dto.SavedMethodResponse, error)
+	GetMethods(userID uint) ([]dto.SavedMethodResponse, error)
+	SetDefault(userID, id uint) (*dto.SavedMethodResponse, error)
+	// DeleteMethod removes a method and releases its token at the provider
+	DeleteMethod(userID, id uint) error
+}
+
+type savedMethodServiceImpl struct {
+	savedMethods repository.SavedMethodRepository
+	providers    *provider.Router
+	now          func() time.Time
+}
+
+// NewSavedMethodService creates a new instance of SavedMethodService
+func NewSavedMethodService(savedMethods repository.SavedMethodRepository, providers *provider.Router) SavedMethodService {
+	return &savedMethodServiceImpl{
+		savedMethods: savedMethods,
+		providers:    providers,
+		now:          time.Now,
+	}
+}
+
+func (s *savedMethodServiceImpl) SaveMethod(userID uint, req *dto.SavePaymentMethodRequest) (*dto.SavedMethodResponse, error) {
+	// A card number sent in place of a token must not get anywhere near storage or the provider
+	if domain.ContainsCardNumber(req.Token) {
+		return nil, ErrRawCardNumber
+	}
+
+	gateway, err := s.providers.ForMethod(req.Method)
+	if err != nil {
+		return nil, ErrMethodUnsupported
+	}
+	tokenizer, ok := gateway.(provider.Tokenizer)
+	if !ok {
+		return nil, ErrTokenizationUnsupported
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	details, err := tokenizer.DescribeToken(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, provider.ErrInvalidToken) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("%w: %v", ErrProviderFailure, err)
+	}
+	if details.Method != req.Method {
+		return nil, ErrInvalidToken
+	}
+
+	method := &domain.SavedPaymentMethod{
+		UserID:      userID,
+		Provider:    gateway.Name(),
+		Method:      details.Method,
+		Token:       req.Token,
+		Brand:       details.Brand,
+		Last4:       details.Last4,
+		ExpMonth:    details.ExpMonth,
+		ExpYear:     details.ExpYear,
+		Fingerprint: details.Fingerprint,
+	}
+	if err := checkMasked(method); err != nil {
+		return nil, err
+	}
+	if method.IsExpired(s.now()) {
+		return nil, ErrSavedMethodExpired
+	}
+
+	// Without a fingerprint there is no way to tell two cards apart, so each is saved on its own
+	var existing *domain.SavedPaymentMethod
+	if method.Fingerprint != "" {
+		existing, err = s.savedMethods.FindByFingerprint(userID, method.Provider, method.Fingerprint)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if existing != nil {
+		method = existing
+	} else if err := s.savedMethods.Create(method); err != nil {
+		return nil, err
+	}
+
+	if req.Default && !method.IsDefault {
+		if err := s.savedMethods.SetDefault(userID, method.ID); err != nil {
+			return nil, err
+		}
+		method.IsDefault = true
+	}
+	return s.toSavedMethodResponse(method), nil
+}
+
+func (s *savedMethodServiceImpl) GetMethods(userID uint) ([]dto.SavedMethodResponse, error) {
+	methods, err := s.savedMethods.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SavedMethodResponse, len(methods))
+	for i, method := range methods {
+		responses[i] = *s.toSavedMethodResponse(&method)
+	}
+	return responses, nil
+}
+
+func (s *savedMethodServiceImpl) SetDefault(userID, id uint) (*dto.SavedMethodResponse, error) {
+	method, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if method.IsExpired(s.now()) {
+		return nil, ErrSavedMethodExpired
+	}
+
+	if err := s.savedMethods.SetDefault(userID, method.ID); err != nil {
+		return nil, err
+	}
+	method.IsDefault = true
+	return s.toSavedMethodResponse(method), nil
+}
+
+func (s *savedMethodServiceImpl) DeleteMethod(userID, id uint) error {
+	method, err := s.find(userID, id)
+	if err != nil {
+		return err
+	}
+
+	gateway, err := s.providers.ByName(method.Provider)
+	if err == nil {
+		if tokenizer, ok := gateway.(provider.Tokenizer); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+			defer cancel()
+
+			// A token the provider no longer knows is as good as deleted
+			if err := tokenizer.DeleteToken(ctx, method.Token); err != nil && !errors.Is(err, provider.ErrInvalidToken) {
+				return fmt.Errorf("%w: %v", ErrProviderFailure, err)
+			}
+		}
+	}
+
+	return s.savedMethods.Delete(method)
+}
+
+// find returns one of the user's methods. Other users' methods are reported as missing.
+func (s *savedMethodServiceImpl) find(userID, id uint) (*domain.SavedPaymentMethod, error) {
+	method, err := s.savedMethods.FindByID(id)
+	if err != nil || method.UserID != userID {
+		return nil, ErrSavedMethodNotFound
+	}
+	return method, nil
+}
+
+// checkMasked refuses display details that carry more than a masked card number,
+// in case a provider or token ever hands back the full number
+func checkMasked(method *domain.SavedPaymentMethod) error {
+	for _, value := range []string{method.Token, method.Brand, method.Last4, method.Fingerprint} {
+		if domain.ContainsCardNumber(value) {
+			return ErrRawCardNumber
+		}
+	}
+	if len(method.Last4) > 4 {
+		return ErrRawCardNumber
+	}
+	return nil
+}
+
+func (s *savedMethodServiceImpl) toSavedMethodResponse(method *domain.SavedPaymentMethod) *dto.SavedMethodResponse {
+	return &dto.SavedMethodResponse{
+		ID:        method.ID,
+		Provider:  method.Provider,
+		Method:    method.Method,
+		Brand:     method.Brand,
+		Last4:     method.Last4,
+		ExpMonth:  method.ExpMonth,
+		ExpYear:   method.ExpYear,
+		IsDefault: method.IsDefault,
+		Expired:   method.IsExpired(s.now()),
+		CreatedAt: method.CreatedAt.Format(time.RFC3339),
+	}
+}
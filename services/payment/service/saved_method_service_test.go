@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/provider"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCardNumber = "4242424242424242"
+
+// stubTokenizer is a stubProvider that also keeps cards on file. It records the tokens it is asked about.
+type stubTokenizer struct {
+	stubProvider
+	tokens    map[string]provider.TokenDetails
+	described []string
+	deleted   []string
+	charges   []*provider.ChargeRequest
+}
+
+func newStubTokenizer() *stubTokenizer {
+	return &stubTokenizer{tokens: map[string]provider.TokenDetails{
+		"tok_visa":    {Method: domain.PaymentMethodCreditCard, Brand: "visa", Last4: "4242", ExpMonth: 12, ExpYear: 2034, Fingerprint: "fp-visa"},
+		"tok_visa_2":  {Method: domain.PaymentMethodCreditCard, Brand: "visa", Last4: "4242", ExpMonth: 12, ExpYear: 2034, Fingerprint: "fp-visa"},
+		"tok_mc":      {Method: domain.PaymentMethodCreditCard, Brand: "mastercard", Last4: "4444", ExpMonth: 6, ExpYear: 2033, Fingerprint: "fp-mc"},
+		"tok_expired": {Method: domain.PaymentMethodCreditCard, Brand: "visa", Last4: "0069", ExpMonth: 1, ExpYear: 2020, Fingerprint: "fp-expired"},
+		"tok_leaky":   {Method: domain.PaymentMethodCreditCard, Brand: "visa", Last4: testCardNumber, ExpMonth: 12, ExpYear: 2034, Fingerprint: "fp-leaky"},
+		// Some gateways do not report a fingerprint
+		"tok_plain_visa": {Method: domain.PaymentMethodCreditCard, Brand: "visa", Last4: "1111", ExpMonth: 3, ExpYear: 2035},
+		"tok_plain_mc":   {Method: domain.PaymentMethodCreditCard, Brand: "mastercard", Last4: "5100", ExpMonth: 9, ExpYear: 2036},
+	}}
+}
+
+func (p *stubTokenizer) Name() string { return "stub-tokenizer" }
+
+func (p *stubTokenizer) DescribeToken(ctx context.Context, token string) (*provider.TokenDetails, error) {
+	p.described = append(p.described, token)
+	details, ok := p.tokens[token]
+	if !ok {
+		return nil, provider.ErrInvalidToken
+	}
+	return &details, nil
+}
+
+func (p *stubTokenizer) DeleteToken(ctx context.Context, token string) error {
+	p.deleted = append(p.deleted, token)
+	return nil
+}
+
+func (p *stubTokenizer) CreateCharge(ctx context.Context, req *provider.ChargeRequest) (*provider.Charge, error) {
+	p.charges = append(p.charges, req)
+	return p.stubProvider.CreateCharge(ctx, req)
+}
+
+func newTestSavedMethodService() (SavedMethodService, *repository.MockSavedMethodRepository, *stubTokenizer, *provider.Router) {
+	gateway := newStubTokenizer()
+	router := provider.NewRouter()
+	router.Route(gateway, domain.PaymentMethodCreditCard)
+	router.Route(stubProvider{}, domain.PaymentMethodQRIS)
+	repo := repository.NewMockSavedMethodRepository()
+	return NewSavedMethodService(repo, router), repo, gateway, router
+}
+
+func saveCard(t *testing.T, savedMethodService SavedMethodService, userID uint, token string) *dto.SavedMethodResponse {
+	t.Helper()
+	method, err := savedMethodService.SaveMethod(userID, &dto.SavePaymentMethodRequest{
+		Method: domain.PaymentMethodCreditCard, Token: token,
+	})
+	require.NoError(t, err)
+	return method
+}
+
+func TestSavedMethodService_SaveMethod_StoresOnlyTokenAndMaskedDetails(t *testing.T) {
+	// Arrange
+	savedMethodService, repo, _, _ := newTestSavedMethodService()
+
+	// Act
+	method := saveCard(t, savedMethodService, 7, "tok_visa")
+
+	// Assert
+	assert.Equal(t, "visa", method.Brand)
+	assert.Equal(t, "4242", method.Last4)
+	assert.True(t, method.IsDefault)
+
+	stored := repo.All()
+	require.Len(t, stored, 1)
+	assert.Equal(t, "tok_visa", stored[0].Token)
+	row, err := json.Marshal(stored[0])
+	require.NoError(t, err)
+	assert.False(t, domain.ContainsCardNumber(string(row)))
+	assert.NotContains(t, string(row), "tok_visa", "tokens must not be exposed in JSON")
+}
+
+func TestSavedMethodService_SaveMethod_RefusesCardNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "plain", token: testCardNumber},
+		{name: "spaced", token: "4242 4242 4242 4242"},
+		{name: "dashed", token: "5555-5555-5555-4444"},
+		{name: "embedded", token: "card:" + testCardNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			savedMethodService, repo, gateway, _ := newTestSavedMethodService()
+
+			// Act
+			_, err := savedMethodService.SaveMethod(7, &dto.SavePaymentMethodRequest{
+				Method: domain.PaymentMethodCreditCard, Token: tt.token,
+			})
+
+			// Assert: refused before it is stored or forwarded anywhere
+			assert.ErrorIs(t, err, ErrRawCardNumber)
+			assert.Empty(t, repo.All())
+			assert.Empty(t, gateway.described)
+		})
+	}
+}
+
+func TestSavedMethodService_SaveMethod_RefusesUnmaskedProviderDetails(t *testing.T) {
+	// Arrange
+	savedMethodService, repo, _, _ := newTestSavedMethodService()
+
+	// Act
+	_, err := savedMethodService.SaveMethod(7, &dto.SavePaymentMethodRequest{
+		Method: domain.PaymentMethodCreditCard, Token: "tok_leaky",
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrRawCardNumber)
+	assert.Empty(t, repo.All())
+}
+
+func TestSavedMethodService_SaveMethod_Rejections(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  domain.PaymentMethod
+		token   string
+		wantErr error
+	}{
+		{name: "unknown token", method: domain.PaymentMethodCreditCard, token: "tok_unknown", wantErr: ErrInvalidToken},
+		{name: "expired card", method: domain.PaymentMethodCreditCard, token: "tok_expired", wantErr: ErrSavedMethodExpired},
+		{name: "provider without tokens", method: domain.PaymentMethodQRIS, token: "tok_visa", wantErr: ErrTokenizationUnsupported},
+		{name: "method without provider", method: domain.PaymentMethodEWallet, token: "tok_visa", wantErr: ErrMethodUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			savedMethodService, repo, _, _ := newTestSavedMethodService()
+
+			_, err := savedMethodService.SaveMethod(7, &dto.SavePaymentMethodRequest{Method: tt.method, Token: tt.token})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, repo.All())
+		})
+	}
+}
+
+func TestSavedMethodService_SaveMethod_ReturnsExistingCard(t *testing.T) {
+	// Arrange
+	savedMethodService, repo, _, _ := newTestSavedMethodService()
+	first := saveCard(t, savedMethodService, 7, "tok_visa")
+
+	// Act: a new token for the same card
+	second := saveCard(t, savedMethodService, 7, "tok_visa_2")
+
+	// Assert
+	assert.Equal(t, first.ID, second.ID)
+	assert.Len(t, repo.All(), 1)
+}
+
+func TestSavedMethodService_SaveMethod_WithoutFingerprintSavesEachCard(t *testing.T) {
+	// Arrange
+	savedMethodService, repo, _, _ := newTestSavedMethodService()
+	first := saveCard(t, savedMethodService, 7, "tok_plain_visa")
+
+	// Act: a different card, also without a fingerprint
+	second := saveCard(t, savedMethodService, 7, "tok_plain_mc")
+
+	// Assert
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, "5100", second.Last4)
+	assert.Len(t, repo.All(), 2)
+}
+
+func TestSavedMethodService_DefaultFollowsChangesAndDeletes(t *testing.T) {
+	// Arrange
+	savedMethodService, _, gateway, _ := newTestSavedMethodService()
+	visa := saveCard(t, savedMethodService, 7, "tok_visa")
+	mastercard := saveCard(t, savedMethodService, 7, "tok_mc")
+	assert.False(t, mastercard.IsDefault)
+
+	// Act & Assert: switching the default
+	_, err := savedMethodService.SetDefault(7, mastercard.ID)
+	require.NoError(t, err)
+	methods, err := savedMethodService.GetMethods(7)
+	require.NoError(t, err)
+	require.Len(t, methods, 2)
+	assert.Equal(t, mastercard.ID, methods[0].ID)
+	assert.True(t, methods[0].IsDefault)
+	assert.False(t, methods[1].IsDefault)
+
+	// Act & Assert: deleting the default promotes the remaining method
+	require.NoError(t, savedMethodService.DeleteMethod(7, mastercard.ID))
+	assert.Equal(t, []string{"tok_mc"}, gateway.deleted)
+	methods, err = savedMethodService.GetMethods(7)
+	require.NoError(t, err)
+	require.Len(t, methods, 1)
+	assert.Equal(t, visa.ID, methods[0].ID)
+	assert.True(t, methods[0].IsDefault)
+}
+
+func TestSavedMethodService_HidesOtherUsersMethods(t *testing.T) {
+	savedMethodService, _, _, _ := newTestSavedMethodService()
+	method := saveCard(t, savedMethodService, 7, "tok_visa")
+
+	_, err := savedMethodService.SetDefault(8, method.ID)
+	assert.ErrorIs(t, err, ErrSavedMethodNotFound)
+	assert.ErrorIs(t, savedMethodService.DeleteMethod(8, method.ID), ErrSavedMethodNotFound)
+}
+
+func TestPaymentService_CreatePayment_ChargesSavedMethod(t *testing.T) {
+	// Arrange
+	savedMethodService, repo, gateway, router := newTestSavedMethodService()
+	method := saveCard(t, savedMethodService, 7, "tok_visa")
+	paymentService := newUncheckedPaymentService(repository.NewMockPaymentRepository(), repo, router, newStubOrders())
+
+	// Act: no method sent, it comes from the saved one
+	payment, err := paymentService.CreatePayment(7, &dto.CreatePaymentRequest{OrderID: 1, SavedMethodID: &method.ID})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentMethodCreditCard, payment.Method)
+	require.NotNil(t, payment.SavedMethodID)
+	assert.Equal(t, method.ID, *payment.SavedMethodID)
+	require.Len(t, gateway.charges, 1)
+	assert.Equal(t, "tok_visa", gateway.charges[0].Token)
+}
+
+func TestPaymentService_CreatePayment_ValidatesSavedMethod(t *testing.T) {
+	// Arrange
+	savedMethodService, repo, _, router := newTestSavedMethodService()
+	method := saveCard(t, savedMethodService, 7, "tok_visa")
+
+	tests := []struct {
+		name    string
+		userID  uint
+		method  domain.PaymentMethod
+		now     time.Time
+		wantErr error
+	}{
+		{name: "another user's method", userID: 8, wantErr: ErrSavedMethodNotFound},
+		{name: "different method", userID: 7, method: domain.PaymentMethodQRIS, wantErr: ErrSavedMethodMismatch},
+		{name: "card expired since saved", userID: 7, now: time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC), wantErr: ErrSavedMethodExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := newStubOrders()
+			orders.orders[1].UserID = tt.userID
+			paymentService := newUncheckedPaymentService(repository.NewMockPaymentRepository(), repo, router, orders)
+			if !tt.now.IsZero() {
+				paymentService.(*paymentServiceImpl).now = func() time.Time { return tt.now }
+			}
+
+			_, err := paymentService.CreatePayment(tt.userID, &dto.CreatePaymentRequest{
+				OrderID: 1, Method: tt.method, SavedMethodID: &method.ID,
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidStatement = errors.New("invalid settlement statement")
+
+// StatementRow is one settled transaction in a provider's settlement statement
+type StatementRow struct {
+	Line          int
+	TransactionID string
+	ProviderRef   string
+	Amount        float64
+}
+
+// ParseSettlementStatement reads a CSV settlement statement. The first row names the columns:
+// amount and at least one of transaction_id and provider_ref are required; other columns are ignored.
+// Each row must identify its transaction by one of the two.
+func ParseSettlementStatement(r io.Reader) ([]StatementRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidStatement)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	amountCol, hasAmount := columns["amount"]
+	txCol, hasTx := columns["transaction_id"]
+	refCol, hasRef := columns["provider_ref"]
+	if !hasAmount || (!hasTx && !hasRef) {
+		return nil, fmt.Errorf("%w: header must have amount and transaction_id or provider_ref", ErrInvalidStatement)
+	}
+
+	field := func(record []string, col int, ok bool) string {
+		if !ok || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+
+	var rows []StatementRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := StatementRow{
+			Line:          line,
+			TransactionID: field(record, txCol, hasTx),
+			ProviderRef:   field(record, refCol, hasRef),
+		}
+		if row.TransactionID == "" && row.ProviderRef == "" {
+			return nil, fmt.Errorf("%w: line %d has neither transaction_id nor provider_ref", ErrInvalidStatement, line)
+		}
+		row.Amount, err = strconv.ParseFloat(field(record, amountCol, true), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d has an invalid amount", ErrInvalidStatement, line)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/repository"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrWebhookUnverified     = errors.New("webhook could not be verified")
+	ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+)
+
+// maxUnverifiedPayload is how much of the body is kept on the audit log until the signature
+// checks out, so unauthenticated callers cannot fill the log with large bodies
+const maxUnverifiedPayload = 1024
+
+// WebhookCall is a webhook request as received, before any parsing
+type WebhookCall struct {
+	Provider   string
+	Timestamp  string
+	Signature  string
+	RemoteAddr string
+	Payload    []byte
+}
+
+// WebhookAlerter is notified of webhook calls that fail verification
+type WebhookAlerter interface {
+	Alert(log *domain.WebhookLog, reason error)
+}
+
+// LogAlerter raises alerts as error-level log entries tagged for log-based alerting
+type LogAlerter struct {
+	Logger zerolog.Logger
+}
+
+// Alert logs the rejected webhook call
+func (a LogAlerter) Alert(log *domain.WebhookLog, reason error) {
+	a.Logger.Error().
+		Str("alert", "payment_webhook_unverified").
+		Str("provider", log.Provider).
+		Str("remote_addr", log.RemoteAddr).
+		Uint("webhook_log_id", log.ID).
+		Err(reason).
+		Msg("Rejected unverified payment webhook")
+}
+
+// WebhookService defines the interface for handling payment provider webhooks
+type WebhookService interface {
+	HandleWebhook(call *WebhookCall) (*dto.WebhookResult, error)
+}
+
+type webhookServiceImpl struct {
+	webhookRepo    repository.WebhookRepository
+	paymentService PaymentService
+	refundService  RefundService
+	verifier       *WebhookVerifier
+	alerter        WebhookAlerter
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	paymentService PaymentService,
+	refundService RefundService,
+	verifier *WebhookVerifier,
+	alerter WebhookAlerter,
+) WebhookService {
+	return &webhookServiceImpl{
+		webhookRepo:    webhookRepo,
+		paymentService: paymentService,
+		refundService:  refundService,
+		verifier:       verifier,
+		alerter:        alerter,
+	}
+}
+
+// HandleWebhook audits, verifies and applies a webhook call. Each provider event is applied at most once;
+// repeated deliveries are acknowledged as duplicates.
+func (s *webhookServiceImpl) HandleWebhook(call *WebhookCall) (*dto.WebhookResult, error) {
+	log := &domain.WebhookLog{
+		Provider:   call.Provider,
+		Timestamp:  call.Timestamp,
+		Signature:  call.Signature,
+		RemoteAddr: call.RemoteAddr,
+		Payload:    truncatePayload(call.Payload, maxUnverifiedPayload),
+		Outcome:    domain.WebhookOutcomeReceived,
+	}
+	if err := s.webhookRepo.CreateLog(log); err != nil {
+		// Without an audit record the call must not be applied
+		return nil, err
+	}
+
+	if err := s.verifier.Verify(call.Provider, call.Timestamp, call.Signature, call.Payload); err != nil {
+		s.finish(log, domain.WebhookOutcomeRejected, err)
+		s.alerter.Alert(log, err)
+		return nil, fmt.Errorf("%w: %w", ErrWebhookUnverified, err)
+	}
+	log.Verified = true
+	log.Payload = string(call.Payload)
+
+	var req dto.ProcessPaymentRequest
+	if err := json.Unmarshal(call.Payload, &req); err != nil {
+		s.finish(log, domain.WebhookOutcomeInvalid, err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	log.EventID = req.EventID
+	req.Provider = call.Provider
+	if req.EventID == "" || req.TransactionID == "" || req.Status == "" {
+		err := errors.New("event_id, transaction_id and status are required")
+		s.finish(log, domain.WebhookOutcomeInvalid, err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	recorded, err := s.webhookRepo.RecordEvent(&domain.WebhookEvent{
+		Provider:   call.Provider,
+		EventID:    req.EventID,
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		s.finish(log, domain.WebhookOutcomeFailed, err)
+		return nil, err
+	}
+	if !recorded {
+		s.finish(log, domain.WebhookOutcomeDuplicate, nil)
+		return &dto.WebhookResult{EventID: req.EventID, Duplicate: true}, nil
+	}
+
+	result := &dto.WebhookResult{EventID: req.EventID}
+	if req.RefundReference != "" {
+		result.Refund, err = s.refundService.ProcessRefund(&req)
+	} else {
+		result.Payment, err = s.paymentService.ProcessPayment(&req)
+	}
+	if err != nil {
+		if errors.Is(err, ErrProviderMismatch) {
+			// A valid signature for the wrong provider is as suspect as an invalid one
+			s.finish(log, domain.WebhookOutcomeRejected, err)
+			s.alerter.Alert(log, err)
+			return nil, err
+		}
+		// Let the provider's retry through instead of treating it as a replay,
+		// unless the event was refused outright: a retry would be refused the same way
+		if !errors.Is(err, ErrInvalidStatus) {
+			_ = s.webhookRepo.ForgetEvent(call.Provider, req.EventID)
+		}
+		s.finish(log, domain.WebhookOutcomeFailed, err)
+		return nil, err
+	}
+
+	s.finish(log, domain.WebhookOutcomeProcessed, nil)
+	return result, nil
+}
+
+// finish records the outcome on the audit log. A failure to update the log is not
+// reported to the provider: the call itself has already been handled.
+func (s *webhookServiceImpl) finish(log *domain.WebhookLog, outcome domain.WebhookOutcome, err error) {
+	log.Outcome = outcome
+	if err != nil {
+		log.Error = err.Error()
+	}
+	_ = s.webhookRepo.UpdateLog(log)
+}
+
+// truncatePayload returns at most limit bytes of the payload as valid UTF-8
+func truncatePayload(payload []byte, limit int) string {
+	if len(payload) <= limit {
+		return string(payload)
+	}
+	return strings.ToValidUTF8(string(payload[:limit]), "")
+}
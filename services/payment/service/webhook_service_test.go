@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/domain"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/payment/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWebhookRepository keeps the audit log and recorded events in memory
+type fakeWebhookRepository struct {
+	logs   []*domain.WebhookLog
+	events map[string]bool
+}
+
+func (r *fakeWebhookRepository) CreateLog(log *domain.WebhookLog) error {
+	log.ID = uint(len(r.logs) + 1)
+	copied := *log
+	r.logs = append(r.logs, &copied)
+	return nil
+}
+
+func (r *fakeWebhookRepository) UpdateLog(log *domain.WebhookLog) error {
+	copied := *log
+	r.logs[log.ID-1] = &copied
+	return nil
+}
+
+func (r *fakeWebhookRepository) RecordEvent(event *domain.WebhookEvent) (bool, error) {
+	key := event.Provider + "/" + event.EventID
+	if r.events[key] {
+		return false, nil
+	}
+	r.events[key] = true
+	return true, nil
+}
+
+func (r *fakeWebhookRepository) ForgetEvent(provider, eventID string) error {
+	delete(r.events, provider+"/"+eventID)
+	return nil
+}
+
+type recordingAlerter struct {
+	alerts []error
+}
+
+func (a *recordingAlerter) Alert(log *domain.WebhookLog, reason error) {
+	a.alerts = append(a.alerts, reason)
+}
+
+type webhookFixture struct {
+	service  WebhookService
+	payments PaymentService
+	repo     *fakeWebhookRepository
+	alerter  *recordingAlerter
+	verifier *WebhookVerifier
+	payment  *dto.PaymentResponse
+}
+
+func newWebhookFixture(t *testing.T) *webhookFixture {
+	payments, payment := newTestPaymentService(t)
+	f := &webhookFixture{
+		payments: payments,
+		repo:     &fakeWebhookRepository{events: map[string]bool{}},
+		alerter:  &recordingAlerter{},
+		verifier: NewWebhookVerifier(map[string]string{"stub": "stub-secret", "midtrans": "midtrans-secret"}, DefaultWebhookTolerance),
+		payment:  payment,
+	}
+	f.service = NewWebhookService(f.repo, payments, nil, f.verifier, f.alerter)
+	return f
+}
+
+// signedCall builds a webhook call signed with the given provider's secret
+func (f *webhookFixture) signedCall(t *testing.T, providerName string, req dto.ProcessPaymentRequest) *WebhookCall {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	timestamp, signature, err := f.verifier.Sign(providerName, body)
+	require.NoError(t, err)
+	return &WebhookCall{Provider: providerName, Timestamp: timestamp, Signature: signature, Payload: body}
+}
+
+func TestWebhookService_HandleWebhook_AppliesEventFromPaymentProvider(t *testing.T) {
+	// Arrange
+	f := newWebhookFixture(t)
+	call := f.signedCall(t, "stub", dto.ProcessPaymentRequest{
+		EventID: "evt-1", TransactionID: f.payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+
+	// Act
+	result, err := f.service.HandleWebhook(call)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusSuccess, result.Payment.Status)
+	assert.Equal(t, domain.WebhookOutcomeProcessed, f.repo.logs[0].Outcome)
+}
+
+func TestWebhookService_HandleWebhook_RejectsEventFromAnotherProvider(t *testing.T) {
+	// Arrange: a notification about a stub payment, signed with Midtrans' secret
+	f := newWebhookFixture(t)
+	call := f.signedCall(t, "midtrans", dto.ProcessPaymentRequest{
+		EventID: "evt-1", TransactionID: f.payment.TransactionID, Status: domain.PaymentStatusSuccess,
+	})
+
+	// Act
+	_, err := f.service.HandleWebhook(call)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrProviderMismatch)
+	assert.Equal(t, domain.WebhookOutcomeRejected, f.repo.logs[0].Outcome)
+	assert.Len(t, f.alerter.alerts, 1)
+
+	payment, err := f.payments.GetPayment(f.payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusPending, payment.Status)
+}
+
+func TestWebhookService_HandleWebhook_TruncatesUnverifiedPayload(t *testing.T) {
+	// Arrange
+	f := newWebhookFixture(t)
+	body := []byte(`{"event_id":"` + strings.Repeat("x", 64*1024) + `"}`)
+
+	// Act
+	_, err := f.service.HandleWebhook(&WebhookCall{Provider: "stub", Timestamp: "0", Signature: "forged", Payload: body})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrWebhookUnverified)
+	require.Len(t, f.repo.logs, 1)
+	assert.Equal(t, domain.WebhookOutcomeRejected, f.repo.logs[0].Outcome)
+	assert.Len(t, f.repo.logs[0].Payload, maxUnverifiedPayload)
+}
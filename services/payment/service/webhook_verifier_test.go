@@ -0,0 +1,79 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVerifier(now time.Time) *WebhookVerifier {
+	v := NewWebhookVerifier(map[string]string{"midtrans": "s3cret"}, DefaultWebhookTolerance)
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func TestWebhookVerifier_AcceptsSignedBody(t *testing.T) {
+	// Arrange
+	v := newTestVerifier(time.Unix(1_700_000_000, 0))
+	body := []byte(`{"event_id":"evt-1","transaction_id":"TXN-1","status":"success"}`)
+	timestamp, signature, err := v.Sign("midtrans", body)
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.NoError(t, v.Verify("midtrans", timestamp, signature, body))
+}
+
+func TestWebhookVerifier_RejectsTamperedBody(t *testing.T) {
+	v := newTestVerifier(time.Unix(1_700_000_000, 0))
+	timestamp, signature, err := v.Sign("midtrans", []byte(`{"status":"failed"}`))
+	require.NoError(t, err)
+
+	err = v.Verify("midtrans", timestamp, signature, []byte(`{"status":"success"}`))
+
+	assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+}
+
+func TestWebhookVerifier_RejectsStaleTimestamp(t *testing.T) {
+	// Arrange: a correctly signed body replayed ten minutes later
+	signedAt := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"event_id":"evt-1"}`)
+	timestamp, signature, err := newTestVerifier(signedAt).Sign("midtrans", body)
+	require.NoError(t, err)
+
+	// Act
+	err = newTestVerifier(signedAt.Add(10*time.Minute)).Verify("midtrans", timestamp, signature, body)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrStaleWebhookTimestamp)
+}
+
+func TestWebhookVerifier_RejectsTimestampNotCoveredBySignature(t *testing.T) {
+	v := newTestVerifier(time.Unix(1_700_000_000, 0))
+	body := []byte(`{"event_id":"evt-1"}`)
+	_, signature, err := v.Sign("midtrans", body)
+	require.NoError(t, err)
+
+	err = v.Verify("midtrans", strconv.FormatInt(1_700_000_001, 10), signature, body)
+
+	assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+}
+
+func TestWebhookVerifier_RejectsUnknownProvider(t *testing.T) {
+	v := newTestVerifier(time.Unix(1_700_000_000, 0))
+
+	err := v.Verify("xendit", "1700000000", "deadbeef", []byte(`{}`))
+
+	assert.ErrorIs(t, err, ErrUnknownWebhookProvider)
+}
+
+func TestParseWebhookSecrets(t *testing.T) {
+	secrets, err := ParseWebhookSecrets(" midtrans:abc , xendit:d:e ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"midtrans": "abc", "xendit": "d:e"}, secrets)
+
+	_, err = ParseWebhookSecrets("midtrans")
+	assert.Error(t, err)
+}
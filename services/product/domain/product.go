@@ -12,6 +12,8 @@ type Product struct {
 	CategoryID  uint      `json:"category_id"`
 	Category    *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
 	ImageURL    string    `json:"image_url"`
+	WeightGrams int       `json:"weight_grams" gorm:"default:0"`                         // Used for shipping rate calculation
+	TaxCategory string    `json:"tax_category" gorm:"size:20;not null;default:standard"` // See package tax
 	IsActive    bool      `json:"is_active" gorm:"default:true"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
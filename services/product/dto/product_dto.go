@@ -8,6 +8,8 @@ type CreateProductRequest struct {
 	Stock       int     `json:"stock" binding:"gte=0"`
 	CategoryID  uint    `json:"category_id"`
 	ImageURL    string  `json:"image_url"`
+	WeightGrams int     `json:"weight_grams" binding:"gte=0"`
+	TaxCategory string  `json:"tax_category" binding:"omitempty,oneof=standard reduced exempt"` // Defaults to standard
 }
 
 // UpdateProductRequest represents the payload for updating a product
@@ -18,6 +20,8 @@ type UpdateProductRequest struct {
 	Stock       *int     `json:"stock"`
 	CategoryID  *uint    `json:"category_id"`
 	ImageURL    *string  `json:"image_url"`
+	WeightGrams *int     `json:"weight_grams"`
+	TaxCategory *string  `json:"tax_category" binding:"omitempty,oneof=standard reduced exempt"`
 	IsActive    *bool    `json:"is_active"`
 }
 
@@ -31,6 +35,8 @@ type ProductResponse struct {
 	CategoryID  uint              `json:"category_id"`
 	Category    *CategoryResponse `json:"category,omitempty"`
 	ImageURL    string            `json:"image_url"`
+	WeightGrams int               `json:"weight_grams"`
+	TaxCategory string            `json:"tax_category"`
 	IsActive    bool              `json:"is_active"`
 }
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	pb "github.com/herman-xphp/go-microservices-ecommerce/proto/product"
+	"github.com/herman-xphp/go-microservices-ecommerce/services/product/dto"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/product/service"
 )
 
@@ -29,6 +30,29 @@ func (s *ProductGRPCServer) GetProduct(ctx context.Context, req *pb.GetProductRe
 		return nil, err
 	}
 
+	return toProductMessage(product), nil
+}
+
+// GetProducts returns product info for several IDs at once
+func (s *ProductGRPCServer) GetProducts(ctx context.Context, req *pb.GetProductsRequest) (*pb.GetProductsResponse, error) {
+	ids := make([]uint, len(req.ProductIds))
+	for i, id := range req.ProductIds {
+		ids[i] = uint(id)
+	}
+
+	products, err := s.productService.GetProductsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetProductsResponse{Products: make([]*pb.GetProductResponse, len(products))}
+	for i := range products {
+		resp.Products[i] = toProductMessage(&products[i])
+	}
+	return resp, nil
+}
+
+func toProductMessage(product *dto.ProductResponse) *pb.GetProductResponse {
 	categoryName := ""
 	if product.Category != nil {
 		categoryName = product.Category.Name
@@ -44,7 +68,9 @@ func (s *ProductGRPCServer) GetProduct(ctx context.Context, req *pb.GetProductRe
 		CategoryId:   uint64(product.CategoryID),
 		CategoryName: categoryName,
 		IsActive:     product.IsActive,
-	}, nil
+		WeightGrams:  int32(product.WeightGrams),
+		TaxCategory:  product.TaxCategory,
+	}
 }
 
 // CheckStock returns the current stock for a product
@@ -99,3 +125,27 @@ func (s *ProductGRPCServer) DecreaseStock(ctx context.Context, req *pb.DecreaseS
 		RemainingStock: int32(remainingStock),
 	}, nil
 }
+
+// IncreaseStock adds stock back for a product
+func (s *ProductGRPCServer) IncreaseStock(ctx context.Context, req *pb.IncreaseStockRequest) (*pb.IncreaseStockResponse, error) {
+	err := s.productService.IncreaseStock(uint(req.ProductId), int(req.Quantity))
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			return &pb.IncreaseStockResponse{
+				Success:      false,
+				ErrorMessage: "product not found",
+			}, nil
+		}
+		return &pb.IncreaseStockResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
+	remainingStock, _ := s.productService.CheckStock(uint(req.ProductId))
+
+	return &pb.IncreaseStockResponse{
+		Success:        true,
+		RemainingStock: int32(remainingStock),
+	}, nil
+}
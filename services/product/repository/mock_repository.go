@@ -31,6 +31,16 @@ func (m *MockProductRepository) FindByID(id uint) (*domain.Product, error) {
 	return nil, nil
 }
 
+func (m *MockProductRepository) FindByIDs(ids []uint) ([]domain.Product, error) {
+	var result []domain.Product
+	for _, id := range ids {
+		if product, ok := m.products[id]; ok {
+			result = append(result, *product)
+		}
+	}
+	return result, nil
+}
+
 func (m *MockProductRepository) FindAll(page, pageSize int) ([]domain.Product, int64, error) {
 	var result []domain.Product
 	for _, p := range m.products {
@@ -6,6 +6,8 @@ import "github.com/herman-xphp/go-microservices-ecommerce/services/product/domai
 type ProductRepository interface {
 	Create(product *domain.Product) error
 	FindByID(id uint) (*domain.Product, error)
+	// FindByIDs returns the products with the given IDs, inactive ones included
+	FindByIDs(ids []uint) ([]domain.Product, error)
 	FindAll(page, pageSize int) ([]domain.Product, int64, error)
 	FindByCategory(categoryID uint, page, pageSize int) ([]domain.Product, int64, error)
 	Update(product *domain.Product) error
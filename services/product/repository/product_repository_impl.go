@@ -27,6 +27,15 @@ func (r *productRepositoryImpl) FindByID(id uint) (*domain.Product, error) {
 	return &product, nil
 }
 
+func (r *productRepositoryImpl) FindByIDs(ids []uint) ([]domain.Product, error) {
+	var products []domain.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := r.db.Preload("Category").Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
 func (r *productRepositoryImpl) FindAll(page, pageSize int) ([]domain.Product, int64, error) {
 	var products []domain.Product
 	var total int64
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"math"
 
+	"github.com/herman-xphp/go-microservices-ecommerce/pkg/tax"
+
 	"github.com/herman-xphp/go-microservices-ecommerce/services/product/domain"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/product/dto"
 	"github.com/herman-xphp/go-microservices-ecommerce/services/product/repository"
@@ -21,6 +23,8 @@ type ProductService interface {
 	// Product CRUD
 	CreateProduct(req *dto.CreateProductRequest) (*dto.ProductResponse, error)
 	GetProduct(id uint) (*dto.ProductResponse, error)
+	// GetProductsByIDs returns the products found among ids, including inactive ones
+	GetProductsByIDs(ids []uint) ([]dto.ProductResponse, error)
 	GetProducts(page, pageSize int) (*dto.ProductListResponse, error)
 	GetProductsByCategory(categoryID uint, page, pageSize int) (*dto.ProductListResponse, error)
 	UpdateProduct(id uint, req *dto.UpdateProductRequest) (*dto.ProductResponse, error)
@@ -29,6 +33,7 @@ type ProductService interface {
 	// Stock operations (for gRPC)
 	CheckStock(productID uint) (int, error)
 	DecreaseStock(productID uint, quantity int) error
+	IncreaseStock(productID uint, quantity int) error
 
 	// Category CRUD
 	CreateCategory(req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error)
@@ -56,8 +61,13 @@ func (s *productServiceImpl) CreateProduct(req *dto.CreateProductRequest) (*dto.
 		Stock:       req.Stock,
 		CategoryID:  req.CategoryID,
 		ImageURL:    req.ImageURL,
+		WeightGrams: req.WeightGrams,
+		TaxCategory: string(tax.CategoryStandard),
 		IsActive:    true,
 	}
+	if req.TaxCategory != "" {
+		product.TaxCategory = req.TaxCategory
+	}
 
 	if err := s.productRepo.Create(product); err != nil {
 		return nil, err
@@ -80,6 +90,19 @@ func (s *productServiceImpl) GetProduct(id uint) (*dto.ProductResponse, error) {
 	return s.toProductResponse(product), nil
 }
 
+func (s *productServiceImpl) GetProductsByIDs(ids []uint) ([]dto.ProductResponse, error) {
+	products, err := s.productRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ProductResponse, len(products))
+	for i, p := range products {
+		responses[i] = *s.toProductResponse(&p)
+	}
+	return responses, nil
+}
+
 func (s *productServiceImpl) GetProducts(page, pageSize int) (*dto.ProductListResponse, error) {
 	if page < 1 {
 		page = 1
@@ -139,6 +162,12 @@ func (s *productServiceImpl) UpdateProduct(id uint, req *dto.UpdateProductReques
 	if req.ImageURL != nil {
 		product.ImageURL = *req.ImageURL
 	}
+	if req.WeightGrams != nil {
+		product.WeightGrams = *req.WeightGrams
+	}
+	if req.TaxCategory != nil {
+		product.TaxCategory = *req.TaxCategory
+	}
 	if req.IsActive != nil {
 		product.IsActive = *req.IsActive
 	}
@@ -185,6 +214,13 @@ func (s *productServiceImpl) DecreaseStock(productID uint, quantity int) error {
 	return s.productRepo.UpdateStock(productID, -quantity)
 }
 
+func (s *productServiceImpl) IncreaseStock(productID uint, quantity int) error {
+	if _, err := s.CheckStock(productID); err != nil {
+		return err
+	}
+	return s.productRepo.UpdateStock(productID, quantity)
+}
+
 func (s *productServiceImpl) CreateCategory(req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
 	category := &domain.Category{
 		Name: req.Name,
@@ -226,6 +262,8 @@ func (s *productServiceImpl) toProductResponse(p *domain.Product) *dto.ProductRe
 		Stock:       p.Stock,
 		CategoryID:  p.CategoryID,
 		ImageURL:    p.ImageURL,
+		WeightGrams: p.WeightGrams,
+		TaxCategory: p.TaxCategory,
 		IsActive:    p.IsActive,
 	}
 
@@ -148,6 +148,30 @@ func TestProductService_DecreaseStock_InsufficientStock(t *testing.T) {
 	assert.Equal(t, ErrInsufficientStock, err)
 }
 
+func TestProductService_IncreaseStock_Success(t *testing.T) {
+	// Arrange
+	productRepo := repository.NewMockProductRepository()
+	categoryRepo := repository.NewMockCategoryRepository()
+	productService := NewProductService(productRepo, categoryRepo)
+
+	createReq := &dto.CreateProductRequest{
+		Name:  "Test Product",
+		Price: 99.99,
+		Stock: 10,
+	}
+	created, err := productService.CreateProduct(createReq)
+	require.NoError(t, err)
+
+	// Act
+	err = productService.IncreaseStock(created.ID, 5)
+
+	// Assert
+	require.NoError(t, err)
+	stock, err := productService.CheckStock(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 15, stock)
+}
+
 func TestProductService_CreateCategory_Success(t *testing.T) {
 	// Arrange
 	productRepo := repository.NewMockProductRepository()